@@ -1,5 +1,11 @@
 // Package algorithms implements various algorithms to compute Generalized Hypertree Decompositions as well as
 // the more restricted set of Hypertree Decompositions.
+//
+// Concurrency: FindDecomp and FindDecompGraph may be called concurrently on the same instance, and repeatedly, since
+// they don't modify the algorithm itself. Any state kept across calls, such as the cache of DetKDecomp, is guarded
+// and only holds results valid for the current width and graph. The setters SetWidth and SetGenerator must not be
+// called while a search is running. To run searches with different widths at the same time, use Clone to get an
+// independent instance.
 package algorithms
 
 import (
@@ -18,6 +24,16 @@ type Algorithm interface {
 	FindDecomp() lib.Decomp
 	FindDecompGraph(G lib.Graph) lib.Decomp
 	SetWidth(K int)
+	Clone() Algorithm // Clone returns an independent copy of the algorithm, sharing no state across runs
+}
+
+// balance returns the predicate used to check for balanced separators, BalancedCheck unless pred is set. Only with
+// BalancedCheck are the BalSep algorithms sure to find a decomposition of the given width if there is one.
+func balance(pred lib.Predicate) lib.Predicate {
+	if pred == nil {
+		return lib.BalancedCheck{}
+	}
+	return pred
 }
 
 // Counters allow to track how often an algorithm had to backtrack, and at which level, and the toplevel completion as
@@ -81,3 +97,12 @@ func (c *Counters) String() string {
 type AlgorithmDebug interface {
 	GetCounters() Counters // GetCounters returns the counters collected during a run
 }
+
+// lastSpecial returns the vertices of the special edge added to H last, which are those H shares with the separator
+// of the parent call in the BalSep algorithms, and nil if there is none
+func lastSpecial(H lib.Graph) []int {
+	if H.NumSpecial() == 0 {
+		return nil
+	}
+	return H.Special[H.NumSpecial()-1].Vertices()
+}
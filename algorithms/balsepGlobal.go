@@ -1,11 +1,9 @@
 package algorithms
 
 import (
-	"reflect"
 	"runtime"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepGlobal implements the global Balanced Separator algorithm.
@@ -15,6 +13,15 @@ type BalSepGlobal struct {
 	Graph     lib.Graph
 	BalFactor int
 	Generator lib.SearchGenerator
+	Dedup     bool                // decompose isomorphic components only once
+	Dumper    *lib.SubtreeDumper  // if set, each decomposed subgraph is written out together with its subtree
+	Trace     *lib.SearchTrace    // if set, all separators tried are recorded
+	Recursion *lib.RecursionTrace // if set, all recursive calls are recorded
+	Order     lib.ComponentOrder  // the order in which the components of a separator are decomposed
+	SepOrder  lib.SeparatorOrder  // the order in which the edges are tried for separators
+	Balance   lib.Predicate       // which separators are balanced, BalancedCheck if nil, see balance
+	depth     int                 // of the current recursive call, for progress reports
+	call      int                 // the id of the current recursive call in Recursion
 }
 
 // SetGenerator defines the type of Search to use
@@ -27,6 +34,12 @@ func (b *BalSepGlobal) SetWidth(K int) {
 	b.K = K
 }
 
+// Clone returns an independent copy of the algorithm
+func (b *BalSepGlobal) Clone() Algorithm {
+	output := *b
+	return &output
+}
+
 func (b BalSepGlobal) findGHD() lib.Decomp {
 	return b.findDecomp(b.Graph)
 }
@@ -50,14 +63,19 @@ func baseCaseSmart(g lib.Graph, H lib.Graph) lib.Decomp {
 	// log.Printf("Base case reached. Number of Special Edges %d\n", H.Special.Len() )
 	var output lib.Decomp
 
-	if H.Edges.Len() <= 2 && len(H.Special) == 0 {
+	if H.Edges.Len() <= 2 && H.NumSpecial() == 0 {
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges}}
-	} else if H.Edges.Len() == 1 && len(H.Special) == 1 {
+			Root: lib.Node{Bag: H.Vertices(), Cover: lib.MinCover(H.Vertices(), H.Edges)}}
+		if output.Root.Cover.Len() > 1 { // neither edge contains the other, so a node for each keeps the width at 1
+			first, second := lib.NewEdges(H.Edges.Slice()[:1]), lib.NewEdges(H.Edges.Slice()[1:])
+			output.Root = lib.Node{Bag: first.Vertices(), Cover: first,
+				Children: []lib.Node{{Bag: second.Vertices(), Cover: second}}}
+		}
+	} else if H.Edges.Len() == 1 && H.NumSpecial() == 1 {
 		sp1 := H.Special[0]
 		output = lib.Decomp{Graph: H,
 			Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges,
-				Children: []lib.Node{{Bag: sp1.Vertices(), Cover: sp1}}}}
+				Children: []lib.Node{{Bag: sp1.Vertices(), Cover: sp1.Edges}}}}
 	} else {
 		return baseCase(g, H)
 	}
@@ -67,28 +85,47 @@ func baseCaseSmart(g lib.Graph, H lib.Graph) lib.Decomp {
 func baseCase(g lib.Graph, H lib.Graph) lib.Decomp {
 	// log.Printf("Base case reached. Number of Special Edges %d\n", H.Special.Len())
 	var output lib.Decomp
-	switch len(H.Special) {
+	switch H.NumSpecial() {
 	case 0:
 		output = lib.Decomp{Graph: g} // use g here to avoid reject
 	case 1:
 		sp1 := H.Special[0]
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: sp1.Vertices(), Cover: sp1}}
+			Root: lib.Node{Bag: sp1.Vertices(), Cover: sp1.Edges}}
 	case 2:
 		sp1 := H.Special[0]
 		sp2 := H.Special[1]
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: sp1.Vertices(), Cover: sp1,
-				Children: []lib.Node{{Bag: sp2.Vertices(), Cover: sp2}}}}
+			Root: lib.Node{Bag: sp1.Vertices(), Cover: sp1.Edges,
+				Children: []lib.Node{{Bag: sp2.Vertices(), Cover: sp2.Edges}}}}
 	}
 	return output
 }
 
+// baseCaseSpecial handles subgraphs without any edges left, but more than two special edges. If the vertices of all
+// special edges can be covered by at most K edges of g, a single node covering them does the job, with a leaf below it
+// for each special edge. As the cover may hide some of its vertices, this is only sound for GHDs.
+func baseCaseSpecial(g lib.Graph, H lib.Graph, K int) (lib.Decomp, bool) {
+	vertices := H.SpecialVertices()
+
+	cover, ok := lib.VertexSepCheck{Edges: lib.FilterVertices(g.Edges, vertices), K: K}.GetCover(vertices)
+	if !ok {
+		return lib.Decomp{}, false
+	}
+
+	root := lib.Node{Bag: vertices, Cover: cover}
+	for i := range H.Special {
+		root.Children = append(root.Children, lib.Node{Bag: H.Special[i].Vertices(), Cover: H.Special[i].Edges})
+	}
+	return lib.Decomp{Graph: H, Root: root}, true
+}
+
 func earlyTermination(H lib.Graph) lib.Decomp {
 	//We assume that H as less than K edges, and only one special edge
+	// the edges may contain each other, so only a smallest subset needed to cover the bag is used
 	return lib.Decomp{Graph: H,
-		Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges,
-			Children: []lib.Node{{Bag: H.Special[0].Vertices(), Cover: H.Special[0]}}}}
+		Root: lib.Node{Bag: H.Edges.Vertices(), Cover: lib.MinCover(H.Edges.Vertices(), H.Edges),
+			Children: []lib.Node{{Bag: H.Special[0].Vertices(), Cover: H.Special[0].Edges}}}}
 }
 
 func rerooting(H lib.Graph, balsep lib.Edges, subtrees []lib.Decomp) lib.Decomp {
@@ -106,68 +143,169 @@ func rerooting(H lib.Graph, balsep lib.Edges, subtrees []lib.Decomp) lib.Decomp
 	return lib.Decomp{Graph: H, Root: output}
 }
 
-func (b BalSepGlobal) findDecomp(H lib.Graph) lib.Decomp {
-	// log.Printf("Current SubGraph: %+v\n", H)
+func (b BalSepGlobal) findDecomp(H lib.Graph) (result lib.Decomp) {
+	b.depth++
+	lib.Stats.Enter(b.depth)
+	defer lib.Stats.Leave(b.depth)
+	b.call = b.Recursion.Enter(b.call, H, lastSpecial(H))
+	defer func() { b.Recursion.Leave(b.call, result) }()
+
+	if lib.LogRecursion.Enabled(lib.LogDebug) {
+		lib.LogRecursion.Printf(lib.LogDebug, "Decomposing %v at depth %d", H, b.depth)
+	}
 
 	//stop if there are at most two special edges left
 	if H.Len() <= 2 {
 		return baseCaseSmart(b.Graph, H)
 	}
 
+	// only special edges left, which might be covered all at once
+	if H.Edges.Len() == 0 {
+		if decomp, ok := baseCaseSpecial(b.Graph, H, b.K); ok {
+			return decomp
+		}
+	}
+
 	//Early termination
-	if H.Edges.Len() <= b.K && len(H.Special) == 1 {
+	if H.Edges.Len() <= b.K && H.NumSpecial() == 1 {
 		return earlyTermination(H)
 	}
 
 	var balsep lib.Edges
 
-	edges := lib.FilterVerticesStrict(b.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), false)
+	edges := lib.FilterVerticesStrict(b.Graph.Edges, H.Vertices())
+	generators := b.SepOrder.Generators(H, edges, b.K, runtime.GOMAXPROCS(-1), false)
 	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
-	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	pred := balance(b.Balance)
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
 	parallelSearch.FindNext(pred) // initial Search
 
 OUTER:
 	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
 		balsep = lib.GetSubset(edges, parallelSearch.GetResult())
 
-		// log.Printf("Balanced Sep chosen: %+v\n", Graph{Edges: balsep})
+		if lib.LogRecursion.Enabled(lib.LogDebug) {
+			lib.LogRecursion.Printf(lib.LogDebug, "Balanced separator %v chosen for %v",
+				H.Encoding().PrintEdges(balsep), H)
+		}
 
 		comps, _, _ := H.GetComponents(balsep, Vertices)
+		b.Order.Sort(comps)
+		b.Trace.Try(H, balsep, comps)
+		b.Recursion.Try(b.call)
 
-		// log.Printf("Comps of Sep: %+v\n", comps)
+		if lib.LogRecursion.Enabled(lib.LogDebug) {
+			lib.LogRecursion.Printf(lib.LogDebug, "Separator %v leaves %d components of %v",
+				H.Encoding().PrintEdges(balsep), len(comps), H)
+		}
 
-		SepSpecial := lib.NewEdges(balsep.Slice())
+		SepSpecial := lib.NewSpecialEdge(balsep)
 
 		var subtrees []lib.Decomp
-		ch := make(chan lib.Decomp)
+		ch := make(chan lib.Decomp, len(comps)) // buffered, so no goroutine blocks once a component was rejected
 
-		for i := range comps {
-			go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
-				comps[i].Special = append(comps[i].Special, SepSpecial)
-				ch <- b.findDecomp(comps[i])
-			}(i, comps, SepSpecial)
+		batch := newComponents(b.Generator)
+		if b.Dedup {
+			for _, class := range isoClasses(comps, balsep) {
+				class := class
+				batch.run(func(gen lib.SearchGenerator) {
+					sub := b
+					sub.Generator = gen
+					sub.decompClass(class, comps, SepSpecial, ch)
+				})
+			}
+		} else {
+			for i := range comps {
+				i := i
+				batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
+					comps[i].AddSpecial(SepSpecial)
+					sub := b
+					sub.Generator = gen
+					return sub.findDecomp(comps[i])
+				})
+			}
 		}
 
 		for i := 0; i < len(comps); i++ {
 			decomp := <-ch
-			if reflect.DeepEqual(decomp, lib.Decomp{}) {
-				// log.Printf("REJECTING %v: couldn't decompose %v with SP %v \n", Graph{Edges: balsep}, comps[i],
-				//  append(compsSp[i], SepSpecial))
+			if !decomp.Found() {
+				if lib.LogRecursion.Enabled(lib.LogDebug) {
+					lib.LogRecursion.Printf(lib.LogDebug, "Rejecting separator %v of %v, failed on a component",
+						H.Encoding().PrintEdges(balsep), H)
+				}
+				batch.stop()
 				subtrees = []lib.Decomp{}
-				//log.Printf("\n\nCurrent SubGraph: %v\n", H)
-				//log.Printf("Current Special Edges: %v\n\n", Sp)
+				b.Trace.Reject(H, balsep)
 				continue OUTER
 			}
-			// log.Printf("Produced Decomp: %+v\n", decomp)
-
 			subtrees = append(subtrees, decomp)
 		}
+		batch.stop()
 
-		return rerooting(H, balsep, subtrees)
+		output := rerooting(H, balsep, subtrees)
+		b.Dumper.Dump(output)
+		b.Trace.Accept(H, balsep)
+		return output
 	}
 
 	// log.Printf("REJECT: Couldn't find balsep for H %v SP %v\n", H, Sp)
+	b.Trace.Fail(H)
 	return lib.Decomp{} // empty Decomp signifiyng reject
 }
+
+// fixedVertices returns the vertices of the separator and of all special edges, which must stay fixed when
+// comparing components for isomorphism
+func fixedVertices(comp lib.Graph, balsep lib.Edges) []int {
+	output := append([]int{}, balsep.Vertices()...)
+	for i := range comp.Special {
+		output = append(output, comp.Special[i].Vertices()...)
+	}
+	return output
+}
+
+// isoClasses groups the indices of the components by their isomorphism hash
+func isoClasses(comps []lib.Graph, balsep lib.Edges) [][]int {
+	var output [][]int
+	classIndex := make(map[uint64]int)
+
+	for i := range comps {
+		hash := comps[i].IsoHash(fixedVertices(comps[i], balsep))
+		j, ok := classIndex[hash]
+		if !ok {
+			j = len(output)
+			classIndex[hash] = j
+			output = append(output, []int{})
+		}
+		output[j] = append(output[j], i)
+	}
+
+	return output
+}
+
+// decompClass decomposes the first component of a class, and clones the result for all components of the class
+// isomorphic to it. Components where this fails are decomposed on their own. One result per component is sent to ch.
+func (b BalSepGlobal) decompClass(class []int, comps []lib.Graph, SepSpecial lib.SpecialEdge, ch chan lib.Decomp) {
+	for _, i := range class {
+		comps[i].AddSpecial(SepSpecial)
+	}
+
+	rep := comps[class[0]]
+	decomp := b.findDecomp(rep)
+	ch <- decomp
+
+	for _, i := range class[1:] {
+		mapping := rep.IsoMapping(comps[i], fixedVertices(rep, SepSpecial.Edges))
+		if mapping != nil {
+			if !decomp.Found() {
+				ch <- decomp // isomorphic components can't be decomposed either
+				continue
+			}
+			if root, ok := decomp.Root.Remap(mapping, b.Graph.Edges); ok {
+				ch <- lib.Decomp{Graph: comps[i], Root: root}
+				continue
+			}
+		}
+		ch <- b.findDecomp(comps[i])
+	}
+}
@@ -1,15 +1,14 @@
 package algorithms
 
-// Combination of BalSep and DetKDecomp, executing Balsep first (for constant number of rounds) then switching to
-// DetKDecomp
+// Combination of BalSep and DetKDecomp, executing Balsep first (for constant number of rounds, or until the
+// components are small enough) then switching to DetKDecomp
 
 import (
-	"reflect"
+	"math"
 	"runtime"
 	"strconv"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepHybrid implements a hybridised algorithm, using BalSep Local and DetKDecomp in tandem
@@ -18,9 +17,16 @@ type BalSepHybrid struct {
 	Graph     lib.Graph
 	BalFactor int
 	Depth     int // how many rounds of balSep are used
+	Size      int // if positive, components with at most this many edges are passed on to DetKDecomp right away
 	Generator lib.SearchGenerator
+	Order     lib.ComponentOrder // the order in which the components of a separator are decomposed
+	SepOrder  lib.SeparatorOrder // the order in which the edges are tried for separators
+	Balance   lib.Predicate      // which separators are balanced, BalancedCheck if nil, see balance
 }
 
+// UnboundedDepth can be used as Depth of BalSepHybrid, so that only the Size of components decides when to switch
+const UnboundedDepth = math.MaxInt32
+
 // SetGenerator defines the type of Search to use
 func (b *BalSepHybrid) SetGenerator(Gen lib.SearchGenerator) {
 	b.Generator = Gen
@@ -31,6 +37,12 @@ func (b *BalSepHybrid) SetWidth(K int) {
 	b.K = K
 }
 
+// Clone returns an independent copy of the algorithm
+func (b *BalSepHybrid) Clone() Algorithm {
+	output := *b
+	return &output
+}
+
 func (b BalSepHybrid) findGHD(currentGraph lib.Graph) lib.Decomp {
 	return b.findDecomp(b.Depth, currentGraph)
 }
@@ -47,7 +59,14 @@ func (b BalSepHybrid) FindDecompGraph(G lib.Graph) lib.Decomp {
 
 // Name returns the name of the algorithm
 func (b BalSepHybrid) Name() string {
-	return "BalSep / DetK - Hybrid with Depth " + strconv.Itoa(b.Depth+1)
+	switch {
+	case b.Size <= 0:
+		return "BalSep / DetK - Hybrid with Depth " + strconv.Itoa(b.Depth+1)
+	case b.Depth == UnboundedDepth:
+		return "BalSep / DetK - Hybrid with Size " + strconv.Itoa(b.Size)
+	default:
+		return "BalSep / DetK - Hybrid with Depth " + strconv.Itoa(b.Depth+1) + " and Size " + strconv.Itoa(b.Size)
+	}
 }
 
 func decrease(count int) int {
@@ -59,6 +78,9 @@ func decrease(count int) int {
 }
 
 func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
+	lib.Stats.Enter(b.Depth - currentDepth + 1)
+	defer lib.Stats.Leave(b.Depth - currentDepth + 1)
+
 	// log.Println("Current Depth: ", (b.Depth - currentDepth))
 	// log.Printf("Current SubGraph: %+v\n", H)
 	// log.Printf("Current Special Edges: %+v\n\n", Sp)
@@ -68,19 +90,27 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 		return baseCaseSmart(b.Graph, H)
 	}
 
+	// only special edges left, which might be covered all at once
+	if H.Edges.Len() == 0 {
+		if decomp, ok := baseCaseSpecial(b.Graph, H, b.K); ok {
+			return decomp
+		}
+	}
+
 	//Early termination
-	if H.Edges.Len() <= b.K && len(H.Special) == 1 {
+	if H.Edges.Len() <= b.K && H.NumSpecial() == 1 {
 		return earlyTermination(H)
 	}
 
 	var balsep lib.Edges
 
 	//find a balanced separator
-	edges := lib.CutEdges(b.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), true)
+	edges := lib.CutEdges(b.Graph.Edges, H.Vertices())
+	generators := b.SepOrder.Generators(H, edges, b.K, runtime.GOMAXPROCS(-1), true)
 	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
-	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	pred := balance(b.Balance)
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
 	parallelSearch.FindNext(pred) // initial Search
 
 	var cache map[uint32]struct{}
@@ -99,64 +129,69 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 	INNER:
 		for !exhaustedSubedges {
 			comps, _, _ := H.GetComponents(balsep, Vertices)
+			b.Order.Sort(comps)
 
 			// log.Printf("Comps of Sep: %+v\n", comps)
 
-			SepSpecial := lib.NewEdges(balsep.Slice())
+			SepSpecial := lib.NewSpecialEdge(balsep)
 
-			ch := make(chan lib.Decomp)
+			ch := make(chan lib.Decomp, len(comps)) // buffered, so no goroutine blocks once a component was rejected
 			var subtrees []lib.Decomp
 
+			batch := newComponents(b.Generator)
 			for i := range comps {
-
-				if currentDepth > 0 {
-					go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
-						comps[i].Special = append(comps[i].Special, SepSpecial)
-						ch <- b.findDecomp(decrease(currentDepth), comps[i])
-					}(i, comps, SepSpecial)
+				i := i
+
+				if currentDepth > 0 && comps[i].Edges.Len() > b.Size {
+					batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
+						comps[i].AddSpecial(SepSpecial)
+						sub := b
+						sub.Generator = gen
+						return sub.findDecomp(decrease(currentDepth), comps[i])
+					})
 				} else {
-					go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
+					batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
 
 						// Base case handling
 						//stop if there are at most two special edges left
 						if comps[i].Len() <= 1 {
-							comps[i].Special = append(comps[i].Special, SepSpecial)
-							ch <- baseCaseSmart(b.Graph, comps[i])
-							return
+							comps[i].AddSpecial(SepSpecial)
+							return baseCaseSmart(b.Graph, comps[i])
 						}
 
 						//Early termination
-						if comps[i].Edges.Len() <= b.K && len(comps[i].Special) == 0 {
-							comps[i].Special = append(comps[i].Special, SepSpecial)
-							ch <- earlyTermination(comps[i])
-							return
+						if comps[i].Edges.Len() <= b.K && comps[i].NumSpecial() == 0 {
+							comps[i].AddSpecial(SepSpecial)
+							return earlyTermination(comps[i])
 						}
 
-						det := DetKDecomp{K: b.K, Graph: b.Graph, BalFactor: b.BalFactor, SubEdge: true}
+						det := DetKDecomp{K: b.K, Graph: b.Graph, BalFactor: b.BalFactor, SubEdge: true,
+							Order: b.Order, ctx: lib.SearchContext(gen)}
 						det.cache.Init()
 
-						result := det.findDecomp(comps[i], balsep.Vertices(), 0)
-						if !reflect.DeepEqual(result, lib.Decomp{}) {
+						result := det.findDecomp(comps[i], balsep.Vertices(), 0, 0)
+						if result.Found() {
 							result.SkipRerooting = true
 						} else {
-							// comps[i].Special = append(comps[i].Special, SepSpecial)
+							// comps[i].AddSpecial(SepSpecial)
 							// res2 := b.findDecomp(1000, comps[i])
-							// if !reflect.DeepEqual(res2, lib.Decomp{}) {
+							// if res2.Found() {
 							// 	fmt.Println("Result, ", res2)
 							// 	fmt.Println("H: ", comps[i], "balsep ", balsep)
 							// 	log.Panicln("Something is rotten in the state of this program")
 
 							// }
 						}
-						ch <- result
-					}(i, comps, SepSpecial)
+						return result
+					})
 				}
 
 			}
 
 			for i := 0; i < len(comps); i++ {
 				decomp := <-ch
-				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+				if !decomp.Found() {
+					batch.stop()
 					// log.Printf("balDet REJECTING %v: couldn't decompose a component of H %v \n",
 					//        Graph{Edges: balsep}, H)
 					// log.Println("\n\nCurrent Depth: ", (b.Depth - currentDepth))
@@ -191,12 +226,13 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 
 				subtrees = append(subtrees, decomp)
 			}
+			batch.stop()
 
 			output := lib.Node{Bag: balsep.Vertices(), Cover: balsep}
 
 			for _, s := range subtrees {
 				//TODO: Reroot only after all subtrees received
-				if currentDepth == 0 && s.SkipRerooting {
+				if s.SkipRerooting { // produced by DetKDecomp, which already has the separator at the root
 					// log.Println("\nFrom detK on", decomp.Graph, ":\n", decomp)
 					// local := BalSepGlobal{Graph: b.Graph, BalFactor: b.BalFactor}
 					// decomp_deux := local.findDecomp(K, comps[i], append(compsSp[i], SepSpecial))
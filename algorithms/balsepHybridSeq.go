@@ -1,11 +1,9 @@
 package algorithms
 
 import (
-	"reflect"
 	"strconv"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepHybridSeq is a purely sequential version of BalSepHybrid
@@ -15,6 +13,9 @@ type BalSepHybridSeq struct {
 	BalFactor int
 	Depth     int // how many rounds of balSep are used
 	Generator lib.SearchGenerator
+	Order     lib.ComponentOrder // the order in which the components of a separator are decomposed
+	SepOrder  lib.SeparatorOrder // the order in which the edges are tried for separators
+	Balance   lib.Predicate      // which separators are balanced, BalancedCheck if nil, see balance
 }
 
 // SetGenerator defines the type of Search to use
@@ -27,6 +28,12 @@ func (s *BalSepHybridSeq) SetWidth(K int) {
 	s.K = K
 }
 
+// Clone returns an independent copy of the algorithm
+func (s *BalSepHybridSeq) Clone() Algorithm {
+	output := *s
+	return &output
+}
+
 func (s BalSepHybridSeq) findGHD(currentGraph lib.Graph) lib.Decomp {
 	return s.findDecomp(s.Depth, currentGraph)
 }
@@ -47,28 +54,36 @@ func (s BalSepHybridSeq) Name() string {
 }
 
 func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
-	// log.Println("Current Depth: ", (b.Depth - currentDepth))
-	// log.Printf("Current SubGraph: %+v\n", H)
-	// log.Printf("Current Special Edges: %+v\n\n", Sp)
+	if lib.LogRecursion.Enabled(lib.LogDebug) {
+		lib.LogRecursion.Printf(lib.LogDebug, "Decomposing %v at depth %d", H, currentDepth)
+	}
 
 	//stop if there are at most two special edges left
 	if H.Len() <= 2 {
 		return baseCaseSmart(s.Graph, H)
 	}
 
+	// only special edges left, which might be covered all at once
+	if H.Edges.Len() == 0 {
+		if decomp, ok := baseCaseSpecial(s.Graph, H, s.K); ok {
+			return decomp
+		}
+	}
+
 	//Early termination
-	if H.Edges.Len() <= s.K && len(H.Special) == 1 {
+	if H.Edges.Len() <= s.K && H.NumSpecial() == 1 {
 		return earlyTermination(H)
 	}
 
 	var balsep lib.Edges
 
 	//find a balanced separator
-	edges := lib.CutEdges(s.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), s.K, 1, true) // create just one goroutine, making this sequential
+	edges := lib.CutEdges(s.Graph.Edges, H.Vertices())
+	generators := s.SepOrder.Generators(H, edges, s.K, 1, true) // create just one goroutine, making this sequential
 	parallelSearch := s.Generator.GetSearch(&H, &edges, s.BalFactor, generators)
-	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	pred := balance(s.Balance)
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
 	parallelSearch.FindNext(pred) // initial Search
 
 	var cache map[uint32]struct{}
@@ -82,16 +97,23 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 		//  balsepOrig := balsep
 		var sepSub *lib.SepSub
 
-		// log.Printf("Balanced Sep chosen: %+v\n", Graph{Edges: balsep})
+		if lib.LogRecursion.Enabled(lib.LogDebug) {
+			lib.LogRecursion.Printf(lib.LogDebug, "Balanced separator %v chosen for %v",
+				H.Encoding().PrintEdges(balsep), H)
+		}
 		exhaustedSubedges := false
 
 	INNER:
 		for !exhaustedSubedges {
 			comps, _, _ := H.GetComponents(balsep, Vertices)
+			s.Order.Sort(comps)
 
-			// log.Printf("Comps of Sep: %+v\n", comps)
+			if lib.LogRecursion.Enabled(lib.LogDebug) {
+				lib.LogRecursion.Printf(lib.LogDebug, "Separator %v leaves %d components of %v",
+					H.Encoding().PrintEdges(balsep), len(comps), H)
+			}
 
-			SepSpecial := lib.NewEdges(balsep.Slice())
+			SepSpecial := lib.NewSpecialEdge(balsep)
 
 			var subtrees []lib.Decomp
 			var outDecomps []lib.Decomp
@@ -101,39 +123,42 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 				var out lib.Decomp
 
 				if currentDepth > 0 {
-					out = func(i int, comps []lib.Graph, SepSpecial lib.Edges) lib.Decomp {
-						comps[i].Special = append(comps[i].Special, SepSpecial)
+					out = func(i int, comps []lib.Graph, SepSpecial lib.SpecialEdge) lib.Decomp {
+						comps[i].AddSpecial(SepSpecial)
 						return s.findDecomp(decrease(currentDepth), comps[i])
 					}(i, comps, SepSpecial)
 				} else {
-					out = func(i int, comps []lib.Graph, SepSpecial lib.Edges) lib.Decomp {
+					out = func(i int, comps []lib.Graph, SepSpecial lib.SpecialEdge) lib.Decomp {
 
 						// Base case handling
-						comps[i].Special = append(comps[i].Special, SepSpecial)
-
 						//stop if there are at most two special edges left
-						if comps[i].Len() <= 2 {
+						if comps[i].Len() <= 1 {
+							comps[i].AddSpecial(SepSpecial)
 							return baseCaseSmart(s.Graph, comps[i])
 							//outDecomp = append(outDecomp, baseCaseSmart(b.Graph, comps[i], Sp))
 
 						}
 
 						//Early termination
-						if comps[i].Edges.Len() <= s.K && len(comps[i].Special) == 1 {
+						if comps[i].Edges.Len() <= s.K && comps[i].NumSpecial() == 0 {
+							comps[i].AddSpecial(SepSpecial)
 							return earlyTermination(comps[i])
 							//outDecomp = append(outDecomp, earlyTermination(comps[i], Sp[0]))
 
 						}
 
-						det := DetKDecomp{K: s.K, Graph: s.Graph, BalFactor: s.BalFactor, SubEdge: true}
+						// the separator is passed on as the connection instead of as special edge, as in BalSepHybrid
+
+						det := DetKDecomp{K: s.K, Graph: s.Graph, BalFactor: s.BalFactor, SubEdge: true,
+							Order: s.Order, ctx: lib.SearchContext(s.Generator)}
 
 						// edgesFromSpecial := EdgesSpecial(Sp)
 						// comps[i].Edges.Append(edgesFromSpecial...)
 
 						// det.cache = make(map[uint64]*CompCache)
 						det.cache.Init()
-						result := det.findDecomp(comps[i], balsep.Vertices(), 0)
-						if !reflect.DeepEqual(result, lib.Decomp{}) && currentDepth == 0 {
+						result := det.findDecomp(comps[i], balsep.Vertices(), 0, 0)
+						if result.Found() && currentDepth == 0 {
 							result.SkipRerooting = true
 						}
 						return result
@@ -141,17 +166,19 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 				}
 
 				outDecomps = append(outDecomps, out)
+				if !out.Found() {
+					break // the separator is rejected anyway, so the remaining components need not be decomposed
+				}
 
 			}
 
 			for i := range outDecomps {
 				decomp := outDecomps[i]
-				if reflect.DeepEqual(decomp, lib.Decomp{}) {
-					// log.Printf("balDet REJECTING %v: couldn't decompose a component of H %v \n",
-					//        Graph{Edges: balsep}, H)
-					// log.Println("\n\nCurrent Depth: ", (b.Depth - currentDepth))
-					// log.Printf("Current SubGraph: %+v\n", H)
-					// log.Printf("Current Special Edges: %+v\n\n", Sp)
+				if !decomp.Found() {
+					if lib.LogRecursion.Enabled(lib.LogDebug) {
+						lib.LogRecursion.Printf(lib.LogDebug, "Rejecting separator %v of %v, failed on a component",
+							H.Encoding().PrintEdges(balsep), H)
+					}
 
 					subtrees = []lib.Decomp{}
 					if sepSub == nil {
@@ -176,8 +203,10 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 							continue INNER
 						}
 					}
-					//      log.Println("Sub Sep chosen: ", balsep, "Vertices: ", PrintVertices(balsep.Vertices()),
-					//         " of ", balsepOrig, " , ", Sp)
+					if lib.LogRecursion.Enabled(lib.LogDebug) {
+						lib.LogRecursion.Printf(lib.LogDebug, "Subedge separator %v chosen for %v",
+							H.Encoding().PrintEdges(balsep), H)
+					}
 					continue INNER
 				}
 
@@ -205,6 +234,8 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 		}
 	}
 
-	// log.Printf("REJECT: Couldn't find balsep for H %v SP %v\n", H, Sp)
+	if lib.LogRecursion.Enabled(lib.LogDebug) {
+		lib.LogRecursion.Printf(lib.LogDebug, "Rejecting %v: no balanced separator left", H)
+	}
 	return lib.Decomp{} // empty Decomp signifying reject
 }
@@ -1,7 +1,6 @@
 package algorithms
 
 import (
-	"reflect"
 	"runtime"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
@@ -15,6 +14,23 @@ type BalSepLocal struct {
 	Graph     lib.Graph
 	BalFactor int
 	Generator lib.SearchGenerator
+	// DeferSubedges changes the order of the search: instead of trying all subedge variants of a separator right
+	// after it failed, they are only tried once all separators have failed. This helps on instances where some
+	// separator works without subedges, but many others require lengthy subedge searches to be rejected.
+	DeferSubedges bool
+	Dumper        *lib.SubtreeDumper  // if set, each decomposed subgraph is written out together with its subtree
+	Trace         *lib.SearchTrace    // if set, all separators tried are recorded
+	Recursion     *lib.RecursionTrace // if set, all recursive calls are recorded
+	Order         lib.ComponentOrder  // the order in which the components of a separator are decomposed
+	SepOrder      lib.SeparatorOrder  // the order in which the edges are tried for separators
+	// Balance decides which separators are balanced, BalancedCheck if not set. Other predicates may miss
+	// decompositions of the given width, as the search is only complete for BalancedCheck.
+	Balance lib.Predicate
+	// MaxWeight, if positive, restricts the separators to those whose edges weigh at most this in total, see
+	// MinimizeWeight. Subedge variants of a separator are weighed as the separator itself.
+	MaxWeight float64
+	depth     int // of the current recursive call, for progress reports
+	call      int // the id of the current recursive call in Recursion
 }
 
 // SetGenerator defines the type of Search to use
@@ -27,6 +43,12 @@ func (b *BalSepLocal) SetWidth(K int) {
 	b.K = K
 }
 
+// Clone returns an independent copy of the algorithm
+func (b *BalSepLocal) Clone() Algorithm {
+	output := *b
+	return &output
+}
+
 func (b BalSepLocal) findGHD(K int) lib.Decomp {
 	return b.findDecomp(b.Graph)
 }
@@ -49,21 +71,18 @@ func (b BalSepLocal) Name() string {
 func searchSubEdge(g *BalSepLocal, H *lib.Graph, balsepOrig lib.Edges, sepSub *lib.SepSub) lib.Edges {
 	balsep := balsepOrig
 
-	// log.Printf("\n\nCurrent SubGraph: %v\n", H)
-	// log.Printf("Current Special Edges: %v\n\n", Sp)
 	if sepSub == nil {
 		balsep = lib.CutEdges(balsep, H.Vertices())
 		sepSub = lib.GetSepSub(g.Graph.Edges, balsep, g.K)
 	}
 	nextBalsepFound := false
-	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	pred := balance(g.Balance)
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
 
 	for !nextBalsepFound {
 		if sepSub.HasNext() {
 			balsep = sepSub.GetCurrent()
-			// log.Printf("Testing SSSep: %v of %v , Special Edges %v \n", Graph{Edges: balsep},
-			//        Graph{Edges: balsepOrig}, Sp)
 			if pred.Check(H, &balsep, g.BalFactor, Vertices) {
 				nextBalsepFound = true
 			}
@@ -71,104 +90,155 @@ func searchSubEdge(g *BalSepLocal, H *lib.Graph, balsepOrig lib.Edges, sepSub *l
 			return lib.NewEdges([]lib.Edge{})
 		}
 	}
-	// log.Println("Sub Sep chosen: ", balsep)
 	return balsep
 }
 
-func (b BalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
-	// log.Printf("\n\nCurrent SubGraph: %v\n", H)
+// decompWithSep tries to decompose H, using balsep as separator at the root
+func (b BalSepLocal) decompWithSep(H lib.Graph, balsep lib.Edges, Vertices map[int]*disjoint.Element) lib.Decomp {
+	comps, _, _ := H.GetComponents(balsep, Vertices)
+	b.Order.Sort(comps)
+	b.Trace.Try(H, balsep, comps)
+	b.Recursion.Try(b.call)
 
-	//stop if there are at most two special edges left
-	if H.Len() <= 2 {
-		return baseCaseSmart(b.Graph, H)
+	if lib.LogRecursion.Enabled(lib.LogDebug) {
+		lib.LogRecursion.Printf(lib.LogDebug, "Separator %v leaves %d components of %v",
+			H.Encoding().PrintEdges(balsep), len(comps), H)
 	}
 
-	//Early termination
-	if H.Edges.Len() <= b.K && len(H.Special) == 1 {
-		return earlyTermination(H)
+	SepSpecial := lib.NewSpecialEdge(balsep)
+
+	ch := make(chan lib.Decomp, len(comps)) // buffered, so no goroutine blocks once a component was rejected
+	var subtrees []lib.Decomp
+
+	batch := newComponents(b.Generator)
+	defer batch.stop()
+	for i := range comps {
+		i := i
+		batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
+			comps[i].AddSpecial(SepSpecial)
+			sub := b
+			sub.Generator = gen
+			return sub.findDecomp(comps[i])
+		})
 	}
-	var balsep lib.Edges
 
-	edges := lib.CutEdges(b.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), true)
-	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
-	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
-	parallelSearch.FindNext(pred) // initial Search
+	for i := 0; i < len(comps); i++ {
+		decomp := <-ch
+		if !decomp.Found() {
+			b.Trace.Reject(H, balsep)
+			return lib.Decomp{}
+		}
 
-	var cache map[uint32]struct{}
-	cache = make(map[uint32]struct{})
+		subtrees = append(subtrees, decomp)
+	}
 
-	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+	output := rerooting(H, balsep, subtrees)
+	b.Dumper.Dump(output)
+	b.Trace.Accept(H, balsep)
+	return output
+}
 
-		balsep = lib.GetSubset(edges, parallelSearch.GetResult())
+// decompWithSubSeps tries to decompose H using balanced subedge variants of balsep, skipping those in cache
+func (b BalSepLocal) decompWithSubSeps(H lib.Graph, balsep lib.Edges, cache map[uint32]struct{},
+	Vertices map[int]*disjoint.Element) lib.Decomp {
+	pred := balance(b.Balance)
+	sepSub := lib.GetSepSub(b.Graph.Edges, balsep, b.K)
 
-		var sepSub *lib.SepSub
-		// balsepOrig := balsep
-		// log.Printf("Balanced Sep chosen: %v for H %v \n", balsep, H)
+	for sepSub.HasNext() {
+		subSep := sepSub.GetCurrent()
+		if len(subSep.Vertices()) == 0 {
+			continue
+		}
+		if _, ok := cache[lib.IntHash(subSep.Vertices())]; ok { //skip since already seen
+			continue
+		}
+		if !pred.Check(&H, &subSep, b.BalFactor, Vertices) {
+			continue
+		}
+		cache[lib.IntHash(subSep.Vertices())] = lib.Empty
 
-		exhaustedSubedges := false
+		if lib.LogRecursion.Enabled(lib.LogDebug) {
+			lib.LogRecursion.Printf(lib.LogDebug, "Subedge separator %v chosen from %v",
+				H.Encoding().PrintEdges(subSep), H.Encoding().PrintEdges(balsep))
+		}
+		if decomp := b.decompWithSep(H, subSep, Vertices); decomp.Found() {
+			return decomp
+		}
+	}
 
-	INNER:
-		for !exhaustedSubedges {
-			comps, _, _ := H.GetComponents(balsep, Vertices)
+	return lib.Decomp{}
+}
 
-			// log.Printf("Comps of Sep: %v for H %v \n", comps, H)
+func (b BalSepLocal) findDecomp(H lib.Graph) (result lib.Decomp) {
+	b.depth++
+	lib.Stats.Enter(b.depth)
+	defer lib.Stats.Leave(b.depth)
+	b.call = b.Recursion.Enter(b.call, H, lastSpecial(H))
+	defer func() { b.Recursion.Leave(b.call, result) }()
 
-			SepSpecial := lib.NewEdges(balsep.Slice())
+	if lib.LogRecursion.Enabled(lib.LogDebug) {
+		lib.LogRecursion.Printf(lib.LogDebug, "Decomposing %v at depth %d", H, b.depth)
+	}
 
-			ch := make(chan lib.Decomp)
-			var subtrees []lib.Decomp
+	//stop if there are at most two special edges left
+	if H.Len() <= 2 {
+		return baseCaseSmart(b.Graph, H)
+	}
 
-			for i := range comps {
-				go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
-					comps[i].Special = append(comps[i].Special, SepSpecial)
-					ch <- b.findDecomp(comps[i])
-				}(i, comps, SepSpecial)
-			}
+	// only special edges left, which might be covered all at once
+	if H.Edges.Len() == 0 {
+		if decomp, ok := baseCaseSpecial(b.Graph, H, b.K); ok {
+			return decomp
+		}
+	}
 
-			for i := 0; i < len(comps); i++ {
-				decomp := <-ch
-				if reflect.DeepEqual(decomp, lib.Decomp{}) {
-					subtrees = []lib.Decomp{}
-					if sepSub == nil {
-						sepSub = lib.GetSepSub(b.Graph.Edges, balsep, b.K)
-					}
-					nextBalsepFound := false
-				thisLoop:
-					for !nextBalsepFound {
-						if sepSub.HasNext() {
-							balsep = sepSub.GetCurrent()
-							if len(balsep.Vertices()) == 0 {
-								continue thisLoop
-							}
-							_, ok := cache[lib.IntHash(balsep.Vertices())]
-							if ok { //skip since already seen
-								continue thisLoop
-							}
-							if pred.Check(&H, &balsep, b.BalFactor, Vertices) {
-								cache[lib.IntHash(balsep.Vertices())] = lib.Empty
-								nextBalsepFound = true
-							}
-						} else {
-							// log.Printf("No SubSep found for %v with Sp %v  \n", Graph{Edges: balsepOrig}, Sp)
-							exhaustedSubedges = true
-							continue INNER
-						}
-					}
-					// log.Println("Sub Sep chosen: ", balsep, "Vertices: ", PrintVertices(balsep.Vertices()), " of ",
-					// 	balsepOrig, " , ", Sp)
-					continue INNER
-				}
-
-				// log.Printf("Produced Decomp: %+v\n", decomp)
-				subtrees = append(subtrees, decomp)
-			}
+	//Early termination
+	if H.Edges.Len() <= b.K && H.NumSpecial() == 1 && (b.MaxWeight <= 0 || H.Edges.Weight() <= b.MaxWeight) {
+		return earlyTermination(H)
+	}
+
+	edges := lib.CutEdges(b.Graph.Edges, H.Vertices())
+	generators := b.SepOrder.Generators(H, edges, b.K, runtime.GOMAXPROCS(-1), true)
+	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
+	pred := balance(b.Balance)
+	if b.MaxWeight > 0 {
+		pred = lib.WeightCheck{Inner: pred, MaxWeight: b.MaxWeight}
+	}
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
 
-			return rerooting(H, balsep, subtrees)
+	cache := make(map[uint32]struct{})
+	var deferred []lib.Edges // separators whose subedges are only tried once all separators were tried
+
+	for parallelSearch.FindNext(pred); !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+		balsep := lib.GetSubset(edges, parallelSearch.GetResult())
+		if lib.LogRecursion.Enabled(lib.LogDebug) {
+			lib.LogRecursion.Printf(lib.LogDebug, "Balanced separator %v chosen for %v",
+				H.Encoding().PrintEdges(balsep), H)
+		}
+
+		if decomp := b.decompWithSep(H, balsep, Vertices); decomp.Found() {
+			return decomp
+		}
+
+		if b.DeferSubedges {
+			deferred = append(deferred, balsep)
+			continue
+		}
+		if decomp := b.decompWithSubSeps(H, balsep, cache, Vertices); decomp.Found() {
+			return decomp
 		}
 	}
 
-	// log.Printf("REJECT: Couldn't find balsep for H %v SP %v\n", H, Sp)
+	for _, balsep := range deferred {
+		if decomp := b.decompWithSubSeps(H, balsep, cache, Vertices); decomp.Found() {
+			return decomp
+		}
+	}
+
+	if lib.LogRecursion.Enabled(lib.LogDebug) {
+		lib.LogRecursion.Printf(lib.LogDebug, "Rejecting %v: no balanced separator left", H)
+	}
+	b.Trace.Fail(H)
 	return lib.Decomp{} // empty Decomp signifying reject
 }
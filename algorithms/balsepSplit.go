@@ -0,0 +1,133 @@
+package algorithms
+
+import (
+	"runtime"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// BalSepSplit splits the graph recursively by balanced separators and glues the decompositions of the components
+// together, like BalSepLocal, but without ever backtracking: the first balanced separator found is kept, smaller
+// ones preferred over larger ones, and neither other separators nor subedges are tried if a component can't be
+// decomposed. It may thus fail at widths at which a decomposition exists, but is much faster than the complete
+// algorithms, which makes it a good way to quickly find decompositions of some width near the optimum, e.g. for query
+// planning.
+type BalSepSplit struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Generator lib.SearchGenerator
+	Order     lib.ComponentOrder // the order in which the components of a separator are decomposed
+	SepOrder  lib.SeparatorOrder // the order in which the edges are tried for separators
+	Balance   lib.Predicate      // which separators are balanced, BalancedCheck if nil, see balance
+	depth     int                // of the current recursive call, for progress reports
+}
+
+// SetGenerator defines the type of Search to use
+func (b *BalSepSplit) SetGenerator(Gen lib.SearchGenerator) {
+	b.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (b *BalSepSplit) SetWidth(K int) {
+	b.K = K
+}
+
+// Clone returns an independent copy of the algorithm
+func (b *BalSepSplit) Clone() Algorithm {
+	output := *b
+	return &output
+}
+
+// FindDecomp finds a decomp
+func (b BalSepSplit) FindDecomp() lib.Decomp {
+	return b.findDecomp(b.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (b BalSepSplit) FindDecompGraph(G lib.Graph) lib.Decomp {
+	return b.findDecomp(G)
+}
+
+// Name returns the name of the algorithm
+func (b BalSepSplit) Name() string {
+	return "BalSep Split"
+}
+
+// findSep returns the first balanced separator of H found among the edges, using as few edges as possible, and false
+// if there is none of at most K edges
+func (b BalSepSplit) findSep(H lib.Graph, edges lib.Edges) (lib.Edges, bool) {
+	pred := balance(b.Balance)
+
+	for k := 1; k <= b.K; k++ {
+		generators := b.SepOrder.Generators(H, edges, k, runtime.GOMAXPROCS(-1), true)
+		parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
+		parallelSearch.FindNext(pred)
+		if !parallelSearch.SearchEnded() {
+			return lib.GetSubset(edges, parallelSearch.GetResult()), true
+		}
+	}
+
+	return lib.Edges{}, false
+}
+
+func (b BalSepSplit) findDecomp(H lib.Graph) lib.Decomp {
+	b.depth++
+	lib.Stats.Enter(b.depth)
+	defer lib.Stats.Leave(b.depth)
+
+	//stop if there are at most two special edges left
+	if H.Len() <= 2 {
+		return baseCaseSmart(b.Graph, H)
+	}
+
+	// only special edges left, which might be covered all at once
+	if H.Edges.Len() == 0 {
+		if decomp, ok := baseCaseSpecial(b.Graph, H, b.K); ok {
+			return decomp
+		}
+	}
+
+	//Early termination
+	if H.Edges.Len() <= b.K && H.NumSpecial() == 1 {
+		return earlyTermination(H)
+	}
+
+	edges := lib.CutEdges(b.Graph.Edges, H.Vertices())
+	balsep, ok := b.findSep(H, edges)
+	if !ok {
+		return lib.Decomp{}
+	}
+
+	Vertices := lib.ElementMap()
+	comps, _, _ := H.GetComponents(balsep, Vertices)
+	lib.ReleaseElementMap(Vertices)
+	b.Order.Sort(comps)
+
+	SepSpecial := lib.NewSpecialEdge(balsep)
+
+	ch := make(chan lib.Decomp, len(comps)) // buffered, so no goroutine blocks once a component was rejected
+	var subtrees []lib.Decomp
+
+	batch := newComponents(b.Generator)
+	defer batch.stop()
+	for i := range comps {
+		i := i
+		batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
+			comps[i].AddSpecial(SepSpecial)
+			sub := b
+			sub.Generator = gen
+			return sub.findDecomp(comps[i])
+		})
+	}
+
+	for i := 0; i < len(comps); i++ {
+		decomp := <-ch
+		if !decomp.Found() {
+			return lib.Decomp{} // no other separator is tried
+		}
+		subtrees = append(subtrees, decomp)
+	}
+
+	return rerooting(H, balsep, subtrees)
+}
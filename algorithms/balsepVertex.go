@@ -0,0 +1,143 @@
+package algorithms
+
+import (
+	"runtime"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// BalSepVertex implements a variant of the Balanced Separator algorithm, where separators are chosen as sets of
+// vertices, and only afterwards covered by at most K edges. This mirrors the separators used for computing tree
+// decompositions, and is mostly useful for comparisons with those. Note that the search space grows quickly with
+// the size of separators, so MaxVertices should be kept small.
+type BalSepVertex struct {
+	K           int
+	Graph       lib.Graph
+	BalFactor   int
+	MaxVertices int // maximal size of a separator, defaults to K times the size of the largest edge
+	Generator   lib.SearchGenerator
+	depth       int // of the current recursive call, for progress reports
+}
+
+// SetGenerator defines the type of Search to use
+func (b *BalSepVertex) SetGenerator(Gen lib.SearchGenerator) {
+	b.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (b *BalSepVertex) SetWidth(K int) {
+	b.K = K
+}
+
+// Clone returns an independent copy of the algorithm
+func (b *BalSepVertex) Clone() Algorithm {
+	output := *b
+	return &output
+}
+
+// FindDecomp finds a decomp
+func (b BalSepVertex) FindDecomp() lib.Decomp {
+	return b.findDecomp(b.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (b BalSepVertex) FindDecompGraph(G lib.Graph) lib.Decomp {
+	return b.findDecomp(G)
+}
+
+// Name returns the name of the algorithm
+func (b BalSepVertex) Name() string {
+	return "BalSep Vertex"
+}
+
+func (b BalSepVertex) maxVertices() int {
+	if b.MaxVertices > 0 {
+		return b.MaxVertices
+	}
+
+	largest := 0
+	for _, e := range b.Graph.Edges.Slice() {
+		if len(e.Vertices) > largest {
+			largest = len(e.Vertices)
+		}
+	}
+	return b.K * largest
+}
+
+func (b BalSepVertex) findDecomp(H lib.Graph) lib.Decomp {
+	b.depth++
+	lib.Stats.Enter(b.depth)
+	defer lib.Stats.Leave(b.depth)
+
+	//stop if there are at most two special edges left
+	if H.Len() <= 2 {
+		return baseCaseSmart(b.Graph, H)
+	}
+
+	// only special edges left, which might be covered all at once
+	if H.Edges.Len() == 0 {
+		if decomp, ok := baseCaseSpecial(b.Graph, H, b.K); ok {
+			return decomp
+		}
+	}
+
+	//Early termination
+	if H.Edges.Len() <= b.K && H.NumSpecial() == 1 {
+		return earlyTermination(H)
+	}
+
+	// each vertex becomes a pseudo-edge, so that the search over edge combinations picks sets of vertices
+	var singletons []lib.Edge
+	for _, v := range H.Vertices() {
+		singletons = append(singletons, lib.Edge{Name: v, Vertices: []int{v}})
+	}
+	vertices := lib.NewEdges(singletons)
+
+	generators := lib.SplitCombin(vertices.Len(), b.maxVertices(), runtime.GOMAXPROCS(-1), false)
+	parallelSearch := b.Generator.GetSearch(&H, &vertices, b.BalFactor, generators)
+	pred := lib.VertexSepCheck{Edges: b.Graph.Edges, K: b.K}
+
+	for parallelSearch.FindNext(pred); !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+		sep := lib.GetSubset(vertices, parallelSearch.GetResult())
+		sepVertices := sep.Vertices()
+		cover, _ := pred.GetCover(sepVertices)
+
+		// the separator restricted to exactly its vertices, used as special edge in the components
+		balsep := lib.CutEdges(cover, sepVertices)
+		comps, _, _ := H.GetComponents(balsep, nil)
+		special := lib.NewSpecialEdge(balsep)
+
+		ch := make(chan lib.Decomp, len(comps)) // buffered, as components may be decomposed inline by submit
+		batch := newComponents(b.Generator)
+		for i := range comps {
+			i := i
+			batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
+				comps[i].AddSpecial(special)
+				sub := b
+				sub.Generator = gen
+				return sub.findDecomp(comps[i])
+			})
+		}
+
+		var subtrees []lib.Decomp
+		rejected := false
+		for i := 0; i < len(comps); i++ {
+			decomp := <-ch
+			if !decomp.Found() {
+				rejected = true
+				continue // still receive from all goroutines
+			}
+			subtrees = append(subtrees, decomp)
+		}
+		batch.stop()
+		if rejected {
+			continue
+		}
+
+		output := rerooting(H, balsep, subtrees)
+		output.Root.Cover = cover
+		return output
+	}
+
+	return lib.Decomp{} // empty Decomp signifying reject
+}
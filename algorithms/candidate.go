@@ -0,0 +1,254 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// CandidateDecomp computes a GHD whose bags are all taken from a given family of candidate bags, and can each be
+// covered by at most K edges, if such a decomposition exists. This is the candidate tree decomposition problem,
+// which underlies soft hypertree width, where the candidates are produced from unions of edges. Any other source of
+// candidates, such as an external heuristic, can be used just as well.
+//
+// The search is a dynamic program over blocks, i.e. the components of the graph w.r.t. some candidate, visited in
+// order of their size. A block is solved by a candidate containing all vertices adjacent to it, and at least one of
+// its own, if all blocks it leaves within the component are solved. The candidate is then restricted to the block and
+// its adjacent vertices, as in the component normal form of decompositions, so that a decomposition is found whenever
+// one with all bags among the candidates exists. The bags of the result are thus subsets of candidates, covered by
+// the same edges.
+type CandidateDecomp struct {
+	K     int
+	Graph lib.Graph
+	Bags  [][]int // the candidate bags, used by FindDecomp and FindDecompGraph
+	ctx   context.Context
+}
+
+// SetGenerator does not change the search, only the context of the generator is used to allow for cancellation
+func (c *CandidateDecomp) SetGenerator(Gen lib.SearchGenerator) {
+	c.ctx = lib.SearchContext(Gen)
+}
+
+// SetWidth sets the current width parameter of the algorithm, a width ≤ 0 allowing candidates of any width
+func (c *CandidateDecomp) SetWidth(K int) {
+	c.K = K
+}
+
+// Clone returns an independent copy of the algorithm
+func (c *CandidateDecomp) Clone() Algorithm {
+	output := *c
+	return &output
+}
+
+// Name returns the name of the algorithm
+func (c CandidateDecomp) Name() string {
+	return "Candidate TD"
+}
+
+// FindDecomp finds a decomp with bags among the candidates
+func (c CandidateDecomp) FindDecomp() lib.Decomp {
+	return c.FindDecompRestricted(c.Graph, c.Bags)
+}
+
+// FindDecompGraph finds a decomp of G with bags among the candidates
+func (c CandidateDecomp) FindDecompGraph(G lib.Graph) lib.Decomp {
+	return c.FindDecompRestricted(G, c.Bags)
+}
+
+// a candidateBlock is a component of the graph w.r.t. some candidate, together with the vertices adjacent to it
+type candidateBlock struct {
+	vertices   []int
+	neighbours []int
+	solution   int   // index of the candidate solving the block, -1 if none was found
+	bag        []int // the candidate restricted to the block
+	children   []int // indices of the blocks left by the solution
+}
+
+// FindDecompRestricted finds a decomp of G whose bags are all taken from the given ones, each of which can be
+// covered by at most K edges. Vertices of the candidates not in G are ignored. Special edges are not supported, so the empty
+// decomposition is returned if G has any, and also if no such decomposition exists.
+func (c CandidateDecomp) FindDecompRestricted(G lib.Graph, bags [][]int) lib.Decomp {
+	if G.NumSpecial() > 0 {
+		return lib.Decomp{}
+	}
+	if G.Edges.Len() == 0 {
+		return lib.Decomp{Graph: G, Root: lib.Node{Bag: []int{}, Cover: lib.NewEdges([]lib.Edge{})}}
+	}
+
+	// only candidates within the width are kept, once each
+	var candidates [][]int
+	var covers []lib.Edges
+	seen := make(map[string]bool)
+	pred := lib.VertexSepCheck{Edges: G.Edges, K: c.K}
+	for _, bag := range bags {
+		bag = lib.RemoveDuplicates(lib.Inter(bag, G.Vertices()))
+		key := fmt.Sprint(bag)
+		if len(bag) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var cover lib.Edges
+		if c.K > 0 {
+			var ok bool
+			if cover, ok = pred.GetCover(bag); !ok {
+				continue
+			}
+		} else {
+			cover = lib.MinCover(bag, lib.FilterVertices(G.Edges, bag))
+		}
+		candidates = append(candidates, bag)
+		covers = append(covers, cover)
+	}
+
+	// the blocks left by each candidate, shared among candidates leaving the same ones
+	var blocks []*candidateBlock
+	index := make(map[string]int)
+	leaves := make([][]int, len(candidates))
+	for i, bag := range candidates {
+		for _, comp := range vertexComponents(G.Edges, bag) {
+			key := fmt.Sprint(comp)
+			j, ok := index[key]
+			if !ok {
+				j = len(blocks)
+				index[key] = j
+				blocks = append(blocks, &candidateBlock{vertices: comp, neighbours: neighbours(G.Edges, comp),
+					solution: -1})
+			}
+			leaves[i] = append(leaves[i], j)
+		}
+	}
+	// the whole graph is the last block to be solved, adjacent to nothing
+	root := &candidateBlock{vertices: G.Vertices(), solution: -1}
+	order := make([]*candidateBlock, len(blocks))
+	copy(order, blocks)
+	sort.SliceStable(order, func(i, j int) bool { return len(order[i].vertices) < len(order[j].vertices) })
+	order = append(order, root)
+
+	for _, b := range order {
+		if c.ctx != nil && c.ctx.Err() != nil {
+			return lib.Decomp{}
+		}
+
+		scope := append(append([]int{}, b.vertices...), b.neighbours...)
+	CANDIDATES:
+		for i, bag := range candidates {
+			if !lib.Subset(b.neighbours, bag) || len(lib.Inter(bag, b.vertices)) == 0 {
+				continue
+			}
+
+			var children []int
+			for _, j := range leaves[i] {
+				if !lib.Subset(blocks[j].vertices, b.vertices) {
+					continue // lies outside of b
+				}
+				if blocks[j].solution < 0 {
+					continue CANDIDATES
+				}
+				children = append(children, j)
+			}
+
+			b.solution, b.bag, b.children = i, lib.Inter(bag, scope), children
+			break
+		}
+	}
+	if root.solution < 0 {
+		return lib.Decomp{}
+	}
+
+	var build func(b *candidateBlock) lib.Node
+	build = func(b *candidateBlock) lib.Node {
+		output := lib.Node{Bag: b.bag, Cover: covers[b.solution]}
+		for _, j := range b.children {
+			output.Children = append(output.Children, build(blocks[j]))
+		}
+		return output
+	}
+
+	return lib.Decomp{Graph: G, Root: build(root)}
+}
+
+// vertexComponents returns the connected components of the vertices of the edges not in removed, each sorted
+func vertexComponents(edges lib.Edges, removed []int) [][]int {
+	out := make(map[int]bool, len(removed))
+	for _, v := range removed {
+		out[v] = true
+	}
+
+	parent := make(map[int]int)
+	var find func(v int) int
+	find = func(v int) int {
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+
+	var order []int
+	for _, e := range edges.Slice() {
+		first := -1
+		for _, v := range e.Vertices {
+			if out[v] {
+				continue
+			}
+			if _, ok := parent[v]; !ok {
+				parent[v] = v
+				order = append(order, v)
+			}
+			if first < 0 {
+				first = v
+			} else {
+				parent[find(v)] = find(first)
+			}
+		}
+	}
+
+	var output [][]int
+	position := make(map[int]int)
+	for _, v := range order {
+		r := find(v)
+		i, ok := position[r]
+		if !ok {
+			i = len(output)
+			position[r] = i
+			output = append(output, nil)
+		}
+		output[i] = append(output[i], v)
+	}
+	for i := range output {
+		sort.Ints(output[i])
+	}
+
+	return output
+}
+
+// neighbours returns the vertices outside of comp sharing an edge with some vertex of it, sorted
+func neighbours(edges lib.Edges, comp []int) []int {
+	in := make(map[int]bool, len(comp))
+	for _, v := range comp {
+		in[v] = true
+	}
+
+	var output []int
+	for _, e := range edges.Slice() {
+		touches := false
+		for _, v := range e.Vertices {
+			if in[v] {
+				touches = true
+				break
+			}
+		}
+		if !touches {
+			continue
+		}
+		for _, v := range e.Vertices {
+			if !in[v] {
+				output = append(output, v)
+			}
+		}
+	}
+
+	return lib.RemoveDuplicates(output)
+}
@@ -0,0 +1,114 @@
+package algorithms
+
+// componentSplit.go decomposes the connected components of a graph independently, since no separator is needed to
+// split a graph which is already disconnected
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// ComponentSplit decomposes each connected component of the graph on its own, with a clone of Inner each, all in
+// parallel, and joins the decompositions found as children of a fresh root with an empty bag and cover. As the
+// components share no vertices, the result is a decomposition of the whole graph, of the largest width among them.
+// Connected graphs are handed to Inner directly.
+type ComponentSplit struct {
+	K         int
+	Graph     lib.Graph
+	Inner     Algorithm
+	Generator lib.SearchGenerator
+}
+
+// SetGenerator defines the type of Search to use. Each component gets a copy with a context of its own, if it is a
+// ParallelSearchGen, and a ParallelSearchGen otherwise, so that the others are stopped once one of them fails.
+func (c *ComponentSplit) SetGenerator(Gen lib.SearchGenerator) {
+	c.Generator = Gen
+	c.Inner.SetGenerator(Gen)
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (c *ComponentSplit) SetWidth(K int) {
+	c.K = K
+	c.Inner.SetWidth(K)
+}
+
+// Clone returns an independent copy of the algorithm
+func (c *ComponentSplit) Clone() Algorithm {
+	return &ComponentSplit{K: c.K, Graph: c.Graph, Inner: c.Inner.Clone(), Generator: c.Generator}
+}
+
+// Name returns the name of the algorithm
+func (c *ComponentSplit) Name() string {
+	return c.Inner.Name()
+}
+
+// FindDecomp finds a decomp
+func (c *ComponentSplit) FindDecomp() lib.Decomp {
+	return c.FindDecompGraph(c.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (c *ComponentSplit) FindDecompGraph(G lib.Graph) lib.Decomp {
+	comps := G.ComponentsSplit()
+	if len(comps) <= 1 {
+		return c.Inner.FindDecompGraph(G)
+	}
+
+	decomp := c.DecompComponents(comps)
+	if decomp.Found() {
+		decomp.Graph = G
+	}
+	return decomp
+}
+
+// DecompComponents decomposes each of the given graphs, meant to be some of the components returned by
+// Graph.ComponentsSplit, and joins their decompositions under a fresh root. The decomposition returned is of the
+// union of the graphs, and empty if any of them has no decomposition of width K.
+func (c *ComponentSplit) DecompComponents(comps []lib.Graph) lib.Decomp {
+	if len(comps) == 0 {
+		return lib.Decomp{}
+	}
+
+	ctx, cancel := context.WithCancel(lib.SearchContext(c.Generator))
+	defer cancel() // stops the searches still running once one has failed
+
+	gen := lib.ParallelSearchGen{Ctx: ctx}
+	if parallel, ok := c.Generator.(lib.ParallelSearchGen); ok {
+		parallel.Ctx = ctx
+		gen = parallel
+	}
+
+	decomps := make([]lib.Decomp, len(comps))
+	var wg sync.WaitGroup
+	for i := range comps {
+		inner := c.Inner.Clone()
+		inner.SetGenerator(gen)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			decomps[i] = inner.FindDecompGraph(comps[i])
+			if !decomps[i].Found() {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	root := lib.Node{Bag: []int{}, Cover: lib.NewEdges([]lib.Edge{})}
+	var edges []lib.Edge
+	var special []lib.SpecialEdge
+	for i := range decomps {
+		if !decomps[i].Found() {
+			return lib.Decomp{}
+		}
+		root.Children = append(root.Children, decomps[i].Root)
+		edges = append(edges, comps[i].Edges.Slice()...)
+		special = append(special, comps[i].Special...)
+	}
+
+	graph := comps[0].WithEdges(lib.NewEdges(edges))
+	graph.Special = special
+	return lib.Decomp{Graph: graph, Root: root}
+}
@@ -1,38 +1,146 @@
 package algorithms
 
 import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
-	"reflect"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 	"github.com/cem-okulmus/disjoint"
 )
 
-// DetKDecomp computes for a graph and some width K a HD of width K if it exists
+// DetKDecomp computes for a graph and some width K a HD of width K if it exists. If RootK is set, the cover of the
+// root may use up to RootK edges instead, e.g. for query plans whose final node can afford a larger bag.
 type DetKDecomp struct {
 	K         int
+	RootK     int // width of the root, K if 0
 	Graph     lib.Graph
 	BalFactor int
 	SubEdge   bool
 	cache     lib.Cache
 	counters  *Counters
+	Dumper    *lib.SubtreeDumper  // if set, each decomposed subgraph is written out together with its subtree
+	Trace     *lib.SearchTrace    // if set, all separators tried are recorded
+	Recursion *lib.RecursionTrace // if set, all recursive calls are recorded
+	Order     lib.ComponentOrder  // the order in which the components of a separator are decomposed
+	ctx       context.Context     // taken from the generator, the search is abandoned once it is done
+}
+
+// cacheHeader identifies the graph and options the entries of a saved cache were found for
+type cacheHeader struct {
+	Graph   uint64
+	SubEdge bool
 }
 
 // SetGenerator defines the type of Search to use
 func (d *DetKDecomp) SetGenerator(Gen lib.SearchGenerator) {
-	// detkdecomp doesn't use parallel search, this stub is needed to fit the general interface
+	// detkdecomp doesn't use parallel search, only the context of the generator is used to allow for cancellation
+	d.ctx = lib.SearchContext(Gen)
+}
+
+// cancelled returns true if the context of the algorithm is done
+func (d *DetKDecomp) cancelled() bool {
+	return d.ctx != nil && d.ctx.Err() != nil
 }
 
 // SetWidth sets the current width parameter of the algorithm
 func (d *DetKDecomp) SetWidth(K int) {
-	d.cache.Reset() // reset the cache as the new width might invalidate any old results
+	d.cache.SetWidth(K) // drops the entries the new width invalidates
 
 	d.K = K
 }
 
+// SaveCache writes the entries of the cache to w, together with the graph and options they were found for
+func (d *DetKDecomp) SaveCache(w io.Writer) error {
+	if err := gob.NewEncoder(w).Encode(cacheHeader{Graph: d.Graph.Hash(), SubEdge: d.SubEdge}); err != nil {
+		return err
+	}
+	return d.cache.Save(w)
+}
+
+// LoadCache reads the entries written by SaveCache in a previous run on the same graph. A separator failing at some
+// width also fails for all smaller ones, so the entries are used for any width up to the one they were found at.
+// Widths are best set before loading, as setting a larger width afterwards drops the entries found at smaller ones.
+func (d *DetKDecomp) LoadCache(r io.Reader) error {
+	dat, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(dat) // gob reads no further than needed from a bytes.Buffer
+
+	var header cacheHeader
+	if err := gob.NewDecoder(buf).Decode(&header); err != nil {
+		return err
+	}
+	if header.Graph != d.Graph.Hash() {
+		return fmt.Errorf("cache was saved for a different graph")
+	}
+	if header.SubEdge != d.SubEdge {
+		return fmt.Errorf("cache was saved with local subedges set to %v", header.SubEdge)
+	}
+
+	d.cache.SetWidth(d.K)
+	return d.cache.Load(buf)
+}
+
+// Clone returns an independent copy of the algorithm
+func (d *DetKDecomp) Clone() Algorithm {
+	// the cache and counters are not copied, as they belong to a single instance
+	return &DetKDecomp{K: d.K, RootK: d.RootK, Graph: d.Graph, BalFactor: d.BalFactor, SubEdge: d.SubEdge,
+		Dumper: d.Dumper, Trace: d.Trace, Recursion: d.Recursion, Order: d.Order, ctx: d.ctx}
+}
+
 func (d *DetKDecomp) findHD(currentGraph lib.Graph) lib.Decomp {
-	d.cache.Init()
-	return d.findDecomp(currentGraph, []int{}, 0)
+	d.cache.SetWidth(d.K)
+	if d.RootK > 0 {
+		return d.findRoot(currentGraph)
+	}
+	return d.findDecomp(currentGraph, []int{}, 0, 0)
+}
+
+// findRoot decomposes H below a root covered by up to RootK edges of H, trying all such covers by increasing size,
+// while the components below are decomposed at width K as usual. Since the root has no parent to connect to, the
+// search for it can't be guided by a connector, unlike in findDecomp, whose roots always consist of a single edge.
+func (d *DetKDecomp) findRoot(H lib.Graph) (result lib.Decomp) {
+	call := d.Recursion.Enter(0, H, nil)
+	defer func() { d.Recursion.Leave(call, result) }()
+
+	if H.Edges.Len() == 0 && H.NumSpecial() <= 1 {
+		return baseCaseDetK(H)
+	}
+
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
+
+	edges := H.Edges.Slice()
+	for size := 1; size <= d.RootK && size <= len(edges); size++ {
+		combination := make([]int, size)
+		for i := range combination {
+			combination[i] = i
+		}
+
+		for ok := true; ok; ok = nextCover(combination, len(edges)) {
+			if d.cancelled() {
+				return lib.Decomp{}
+			}
+
+			var cover []lib.Edge
+			for _, i := range combination {
+				cover = append(cover, edges[i])
+			}
+			decomp := d.decompBelow(H, lib.NewEdges(cover), Vertices, call)
+			if decomp.Found() {
+				return decomp
+			}
+		}
+	}
+
+	d.Trace.Fail(H)
+	return lib.Decomp{}
 }
 
 // FindDecomp finds a decomp
@@ -48,6 +156,21 @@ func (d *DetKDecomp) Name() string {
 	return "DetK"
 }
 
+// CacheMemSize returns the approximate number of bytes used by the cache
+func (d *DetKDecomp) CacheMemSize() int {
+	return d.cache.MemSize()
+}
+
+// SetCacheLimit bounds the approximate number of bytes used by the cache, see lib.Cache.SetLimit
+func (d *DetKDecomp) SetCacheLimit(bytes int) {
+	d.cache.SetLimit(bytes)
+}
+
+// CacheEvictions returns the number of separators evicted from the cache to stay within its limit
+func (d *DetKDecomp) CacheEvictions() int {
+	return d.cache.Evictions()
+}
+
 // FindDecompGraph finds a decomp, for an explicit graph
 func (d *DetKDecomp) FindDecompGraph(G lib.Graph) lib.Decomp {
 	return d.findHD(G)
@@ -70,13 +193,13 @@ func baseCaseDetK(H lib.Graph) lib.Decomp {
 	// log.Printf("Base case reached. Number of Special Edges %d\n", len(Sp))
 	var children lib.Node
 
-	switch len(H.Special) {
+	switch H.NumSpecial() {
 	case 0:
 		return lib.Decomp{Graph: H, Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges}}
 
 	case 1:
 		sp1 := H.Special[0]
-		children = lib.Node{Bag: sp1.Vertices(), Cover: sp1}
+		children = lib.Node{Bag: sp1.Vertices(), Cover: sp1.Edges}
 	}
 
 	if H.Edges.Len() == 0 {
@@ -85,32 +208,90 @@ func baseCaseDetK(H lib.Graph) lib.Decomp {
 	return lib.Decomp{Graph: H, Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges, Children: []lib.Node{children}}}
 }
 
-func (d *DetKDecomp) findDecomp(H lib.Graph, oldSep []int, recDepth int) lib.Decomp {
+// decompBelow decomposes the components of H w.r.t. the cover of the root, returning an empty decomp if any fails
+func (d *DetKDecomp) decompBelow(H lib.Graph, sep lib.Edges, Vertices map[int]*disjoint.Element,
+	call int) lib.Decomp {
+	comps, _, _ := H.GetComponents(sep, Vertices)
+	d.Order.Sort(comps)
+	d.Trace.Try(H, sep, comps)
+	d.Recursion.Try(call)
+
+	if d.cache.CheckNegative(sep, comps) {
+		d.Trace.Reject(H, sep)
+		return lib.Decomp{}
+	}
+
+	var subtrees []lib.Node
+	for i := range comps {
+		decomp := d.findDecomp(comps[i], sep.Vertices(), 1, call)
+		if !decomp.Found() {
+			if !d.cancelled() { // not a real failure otherwise, so nothing is cached
+				d.cache.AddNegative(sep, comps[i])
+			}
+			d.Trace.Reject(H, sep)
+			return lib.Decomp{}
+		}
+		subtrees = append(subtrees, decomp.Root)
+	}
+
+	output := lib.Decomp{Graph: H, Root: lib.Node{Bag: sep.Vertices(), Cover: sep, Children: subtrees}}
+	d.Dumper.Dump(output)
+	d.Trace.Accept(H, sep)
+	return output
+}
+
+// nextCover advances the combination to the next one of the same size in lexicographic order, choosing from n
+// elements, and returns false once all were generated
+func nextCover(combination []int, n int) bool {
+	j := len(combination) - 1
+	for j >= 0 && combination[j] == n-len(combination)+j {
+		j--
+	}
+	if j < 0 {
+		return false
+	}
+	combination[j]++
+	for l := j + 1; l < len(combination); l++ {
+		combination[l] = combination[l-1] + 1
+	}
+	return true
+}
+
+// findDecomp decomposes H, below a node with the bag oldSep. The call is the id of the calling call in Recursion.
+func (d *DetKDecomp) findDecomp(H lib.Graph, oldSep []int, recDepth int, call int) (result lib.Decomp) {
 	recDepth = recDepth + 1 // increase the recursive depth
+	lib.Stats.Enter(recDepth)
+	defer lib.Stats.Leave(recDepth)
 
-	verticesCurrent := append(H.Vertices())
+	verticesCurrent := H.Vertices()
 	verticesExtended := append(verticesCurrent, oldSep...)
 	conn := lib.Inter(oldSep, verticesCurrent)
 	compVertices := lib.Diff(verticesCurrent, oldSep)
 	bound := lib.FilterVertices(d.Graph.Edges, conn)
+	call = d.Recursion.Enter(call, H, conn)
+	defer func() { d.Recursion.Leave(call, result) }()
 
-	// log.Printf("\n\nD Current oldSep: %v, Conn: %v\n", lib.PrintVertices(oldSep), lib.PrintVertices(conn))
-	// log.Printf("D Current SubGraph: %v ( %v hash) \n", H, H.Edges.Hash())
-	// log.Printf("D Current SubGraph: %v ( %v edges) (hash: %v )\n", H, H.Edges.Len(), H.Edges.Hash())
-	// log.Println("D Hedges ", H)
-	// log.Println("D Comp Vertices: ", lib.PrintVertices(compVertices))
+	if lib.LogRecursion.Enabled(lib.LogDebug) {
+		lib.LogRecursion.Printf(lib.LogDebug, "Decomposing %v at depth %d, connected via %v",
+			H, recDepth, H.Encoding().PrintVertices(conn))
+	}
 
 	// Base case if H <= K
-	if H.Edges.Len() == 0 && len(H.Special) <= 1 {
+	if H.Edges.Len() == 0 && H.NumSpecial() <= 1 {
 		return baseCaseDetK(H)
 	}
 
 	gen := lib.NewCover(d.K, conn, bound, H.Edges.Vertices())
 
-	var Vertices = make(map[int]*disjoint.Element)
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
 
 OUTER:
 	for gen.HasNext {
+		if d.cancelled() {
+			return lib.Decomp{}
+		}
+
 		out := gen.NextSubset()
 
 		if out == -1 {
@@ -122,11 +303,11 @@ OUTER:
 
 		var sep lib.Edges
 		sep = lib.GetSubset(bound, gen.Subset)
+		lib.Stats.Consume(0, 1) // the covers count as a single generator
 
 		// if !Subset(conn, sep.Vertices()) {
 		//  log.Panicln("Cover messed up! 137")
 		// }
-		// log.Println("Next Cover ", sep)
 
 		addEdges := false
 
@@ -169,12 +350,22 @@ OUTER:
 			subEdges:
 				for true {
 
-					// log.Println("Sep chosen ", sepActual, " out ", out)
+					if lib.LogRecursion.Enabled(lib.LogDebug) {
+						lib.LogRecursion.Printf(lib.LogDebug, "Separator %v chosen for %v",
+							H.Encoding().PrintEdges(sepActual), H)
+					}
 					comps, _, _ := H.GetComponents(sepActual, Vertices)
+					d.Order.Sort(comps)
+					d.Trace.Try(H, sepActual, comps)
+					d.Recursion.Try(call)
 
 					//check cache for previous encounters
 					if d.cache.CheckNegative(sepActual, comps) {
-						// log.Println("Skipping sep", sepActual, "due to cache.")
+						d.Trace.Reject(H, sepActual)
+						if lib.LogCache.Enabled(lib.LogDebug) {
+							lib.LogCache.Printf(lib.LogDebug, "Skipping separator %v of %v, known to fail",
+								H.Encoding().PrintEdges(sepActual), H)
+						}
 						if addEdges {
 							iAdd++
 							continue addingEdges
@@ -183,23 +374,25 @@ OUTER:
 						}
 					}
 
-					// log.Printf("Comps of Sep: %v, len: %v\n", comps, len(comps))
-
 					var subtrees []lib.Node
 					bag := lib.Inter(sepActual.Vertices(), verticesExtended)
 
 					for i := range comps {
-						decomp := d.findDecomp(comps[i], bag, recDepth)
-						if reflect.DeepEqual(decomp, lib.Decomp{}) {
+						decomp := d.findDecomp(comps[i], bag, recDepth, call)
+						if !decomp.Found() {
+							if d.cancelled() {
+								return lib.Decomp{} // not a real failure, so nothing is cached
+							}
 							if d.counters != nil {
 								d.counters.AddBacktrack(recDepth)
 							}
 
 							d.cache.AddNegative(sepActual, comps[i])
-							// log.Printf("detK REJECTING %v: couldn't decompose %v  \n",
-							// 	lib.Graph{Edges: sepActual}, comps[i])
-							// log.Printf("\n\nCurrent oldSep: %v\n", lib.PrintVertices(oldSep))
-							// log.Printf("Current SubGraph: %v ( %v edges) %v\n", H, H.Edges.Len(), H.Edges.Hash())
+							d.Trace.Reject(H, sepActual)
+							if lib.LogRecursion.Enabled(lib.LogDebug) {
+								lib.LogRecursion.Printf(lib.LogDebug, "Rejecting separator %v of %v, failed on %v",
+									H.Encoding().PrintEdges(sepActual), H, comps[i])
+							}
 
 							if d.SubEdge {
 								if sepSub == nil {
@@ -225,8 +418,10 @@ OUTER:
 										}
 									}
 								}
-								// log.Printf("Sub Sep chosen: %vof %v \n", lib.Graph{Edges: sepActual},
-								// 	lib.Graph{Edges: sepActualOrigin})
+								if lib.LogRecursion.Enabled(lib.LogDebug) {
+									lib.LogRecursion.Printf(lib.LogDebug, "Subedge separator %v chosen for %v",
+										H.Encoding().PrintEdges(sepActual), H)
+								}
 								continue subEdges
 							}
 
@@ -242,11 +437,15 @@ OUTER:
 						subtrees = append(subtrees, decomp.Root)
 					}
 
-					return lib.Decomp{Graph: H, Root: lib.Node{Bag: bag, Cover: sepActual, Children: subtrees}}
+					output := lib.Decomp{Graph: H, Root: lib.Node{Bag: bag, Cover: sepActual, Children: subtrees}}
+					d.Dumper.Dump(output)
+					d.Trace.Accept(H, sepActual)
+					return output
 				}
 			}
 		}
 	}
 
+	d.Trace.Fail(H)
 	return lib.Decomp{} // Reject if no separator could be found
 }
@@ -0,0 +1,109 @@
+package algorithms
+
+// enumerate.go continues the search of DetKDecomp past the first decomposition found, e.g. for query optimisers
+// wanting several candidate plans to compare by cost
+
+import (
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Enumerate returns up to n structurally distinct decompositions of the graph of width K, i.e. differing in some
+// bag, cover or in how the nodes are connected, regardless of the order of children. The same separators are tried
+// as by FindDecomp, but for each of them, the decompositions of all components are combined, instead of only the
+// first ones found. The negative cache is used as usual, since a separator known to fail for some component fails
+// for all combinations, while no decomposition found is ever cached, so no alternative is suppressed. RootK and
+// subedges are not supported, and the decompositions are returned in the order they were found.
+func (d *DetKDecomp) Enumerate(n int) []lib.Decomp {
+	if n <= 0 {
+		return nil
+	}
+	d.cache.SetWidth(d.K)
+
+	output := d.enumerate(d.Graph, []int{}, n)
+	for i := range output {
+		output[i].Graph = d.Graph
+	}
+	return output
+}
+
+// enumerate returns up to n distinct decompositions of H, below a node with the bag oldSep
+func (d *DetKDecomp) enumerate(H lib.Graph, oldSep []int, n int) []lib.Decomp {
+	if H.Edges.Len() == 0 && H.NumSpecial() <= 1 {
+		return []lib.Decomp{baseCaseDetK(H)}
+	}
+
+	verticesCurrent := H.Vertices()
+	verticesExtended := append(append([]int{}, verticesCurrent...), oldSep...)
+	conn := lib.Inter(oldSep, verticesCurrent)
+	compVertices := lib.Diff(verticesCurrent, oldSep)
+	bound := lib.FilterVertices(d.Graph.Edges, conn)
+	gen := lib.NewCover(d.K, conn, bound, H.Edges.Vertices())
+
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
+
+	var output []lib.Decomp
+	seen := make(map[string]bool)
+
+	for gen.HasNext {
+		if d.cancelled() {
+			return output
+		}
+		if gen.NextSubset() == -1 {
+			continue
+		}
+
+		// as in findDecomp, a separator not reaching into H is extended by each edge of H in turn
+		sep := lib.GetSubset(bound, gen.Subset)
+		seps := []lib.Edges{sep}
+		if len(lib.Inter(sep.Vertices(), compVertices)) == 0 {
+			seps = nil
+			for i := 0; d.K-sep.Len() > 0 && i < H.Edges.Len(); i++ {
+				seps = append(seps, lib.NewEdges(append(sep.Slice(), H.Edges.Slice()[i])))
+			}
+		}
+
+		for _, sepActual := range seps {
+			comps, _, _ := H.GetComponents(sepActual, Vertices)
+			d.Order.Sort(comps)
+			if d.cache.CheckNegative(sepActual, comps) {
+				continue
+			}
+			bag := lib.Inter(sepActual.Vertices(), verticesExtended)
+
+			// all combinations of the decompositions of the components, up to n of them
+			combinations := [][]lib.Node{{}}
+			for i := range comps {
+				below := d.enumerate(comps[i], bag, n)
+				if len(below) == 0 {
+					if !d.cancelled() { // not a real failure otherwise, so nothing is cached
+						d.cache.AddNegative(sepActual, comps[i])
+					}
+					combinations = nil
+					break
+				}
+
+				var next [][]lib.Node
+				for _, c := range combinations {
+					for j := 0; j < len(below) && len(next) < n; j++ {
+						next = append(next, append(append([]lib.Node{}, c...), below[j].Root))
+					}
+				}
+				combinations = next
+			}
+
+			for _, children := range combinations {
+				root := lib.Node{Bag: bag, Cover: sepActual, Children: children}
+				if key := (lib.Decomp{Root: root}).CanonicalKey(); !seen[key] {
+					seen[key] = true
+					output = append(output, lib.Decomp{Graph: H, Root: root})
+				}
+				if len(output) == n {
+					return output
+				}
+			}
+		}
+	}
+
+	return output
+}
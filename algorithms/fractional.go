@@ -0,0 +1,85 @@
+package algorithms
+
+import (
+	"math"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Fractional turns the decompositions found by another algorithm into fractional hypertree decompositions. The bags
+// are kept, but each cover is replaced by an optimal fractional edge cover of the bag, using all edges of the graph.
+// As the integral cover is one possible solution, the fractional width is at most the width the other algorithm was
+// run with. Bags are not chosen with their fractional cover in mind, so the fractional width is not necessarily the
+// best possible.
+type Fractional struct {
+	K     int
+	Graph lib.Graph
+	Inner Algorithm
+}
+
+// SetGenerator defines the type of Search to use
+func (f *Fractional) SetGenerator(Gen lib.SearchGenerator) {
+	f.Inner.SetGenerator(Gen)
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (f *Fractional) SetWidth(K int) {
+	f.K = K
+	f.Inner.SetWidth(K)
+}
+
+// Clone returns an independent copy of the algorithm
+func (f *Fractional) Clone() Algorithm {
+	return &Fractional{K: f.K, Graph: f.Graph, Inner: f.Inner.Clone()}
+}
+
+// Name returns the name of the algorithm
+func (f *Fractional) Name() string {
+	return "Fractional " + f.Inner.Name()
+}
+
+// FindDecomp finds a decomp
+func (f *Fractional) FindDecomp() lib.Decomp {
+	return f.FindDecompGraph(f.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (f *Fractional) FindDecompGraph(G lib.Graph) lib.Decomp {
+	return MakeFractional(f.Inner.FindDecompGraph(G))
+}
+
+// MakeFractional replaces the cover of each node by an optimal fractional edge cover of its bag, using the edges of
+// the graph of the decomp. Only edges of positive weight are kept in the cover.
+func MakeFractional(decomp lib.Decomp) lib.Decomp {
+	if !decomp.Found() {
+		return decomp
+	}
+
+	decomp.Root = makeFractional(decomp.Root, decomp.Graph.Edges)
+	return decomp
+}
+
+func makeFractional(n lib.Node, edges lib.Edges) lib.Node {
+	weight, weights := lib.FractionalCover(n.Bag, edges)
+
+	// keep the integral cover in the unlikely case that no fractional one was found
+	if !math.IsInf(weight, 1) {
+		var cover []lib.Edge
+		n.Weights = make(map[int]float64)
+		for i, e := range edges.Slice() {
+			if weights[i] > 1e-9 {
+				cover = append(cover, e)
+				n.Weights[e.Name] = weights[i]
+			}
+		}
+		n.Cover = lib.NewEdges(cover)
+	}
+
+	var children []lib.Node
+	for i := range n.Children {
+		children = append(children, makeFractional(n.Children[i], edges))
+	}
+	n.Children = children
+
+	return n
+}
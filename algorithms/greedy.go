@@ -0,0 +1,203 @@
+package algorithms
+
+import (
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// GreedyDecomp produces a GHD in near-linear time, without any search: vertices of the primal graph are eliminated
+// one by one, each time choosing one of minimum degree (or minimum fill-in), which yields a tree decomposition of the
+// primal graph, whose bags are then covered greedily by edges. The width is often far from optimal, but the result
+// is a quick fallback and an upper bound for the exact search.
+type GreedyDecomp struct {
+	K       int
+	Graph   lib.Graph
+	MinFill bool // choose the vertex adding the fewest new neighbourships instead of one of minimum degree
+}
+
+// SetGenerator does nothing, as no search is performed
+func (g *GreedyDecomp) SetGenerator(Gen lib.SearchGenerator) {}
+
+// SetWidth sets the current width parameter of the algorithm
+func (g *GreedyDecomp) SetWidth(K int) {
+	g.K = K
+}
+
+// Clone returns an independent copy of the algorithm
+func (g *GreedyDecomp) Clone() Algorithm {
+	output := *g
+	return &output
+}
+
+// Name returns the name of the algorithm
+func (g GreedyDecomp) Name() string {
+	return "Greedy"
+}
+
+// FindDecomp returns the greedy decomposition of the graph, if its width is at most K
+func (g GreedyDecomp) FindDecomp() lib.Decomp {
+	return g.FindDecompGraph(g.Graph)
+}
+
+// FindDecompGraph returns the greedy decomposition of G, if its width is at most K
+func (g GreedyDecomp) FindDecompGraph(G lib.Graph) lib.Decomp {
+	decomp := g.Decompose(G)
+	if !decomp.Found() || decomp.CheckWidth() > g.K {
+		return lib.Decomp{}
+	}
+	return decomp
+}
+
+// Decompose returns the greedy decomposition of G, of whatever width. Special edges are not supported, so the empty
+// decomposition is returned if G has any.
+func (g GreedyDecomp) Decompose(G lib.Graph) lib.Decomp {
+	if G.NumSpecial() > 0 {
+		return lib.Decomp{}
+	}
+	if G.Edges.Len() == 0 || len(G.Vertices()) == 0 {
+		return lib.Decomp{Graph: G, Root: lib.Node{Bag: []int{}, Cover: lib.NewEdges([]lib.Edge{})}}
+	}
+
+	neighbours := make(map[int]map[int]bool)
+	for _, e := range G.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if _, ok := neighbours[v]; !ok {
+				neighbours[v] = make(map[int]bool)
+			}
+			for _, w := range e.Vertices {
+				if v != w {
+					neighbours[v][w] = true
+				}
+			}
+		}
+	}
+
+	// fill returns the number of pairs of neighbours of v not yet adjacent
+	fill := func(v int) int {
+		output := 0
+		for w := range neighbours[v] {
+			for u := range neighbours[v] {
+				if w < u && !neighbours[w][u] {
+					output++
+				}
+			}
+		}
+		return output
+	}
+	score := func(v int) int {
+		if g.MinFill {
+			return fill(v)
+		}
+		return len(neighbours[v])
+	}
+
+	vertices := G.Vertices()
+	scores := make(map[int]int, len(vertices))
+	for _, v := range vertices {
+		scores[v] = score(v)
+	}
+
+	bags := make(map[int][]int, len(vertices))
+	position := make(map[int]int, len(vertices))
+	var order []int
+
+	for len(scores) > 0 {
+		v, best := -1, -1
+		for _, u := range vertices { // in the order of the graph, so the result is deterministic
+			if s, ok := scores[u]; ok && (best < 0 || s < best) {
+				v, best = u, s
+			}
+		}
+
+		bag := []int{v}
+		for w := range neighbours[v] {
+			bag = append(bag, w)
+		}
+		bags[v] = bag
+		position[v] = len(order)
+		order = append(order, v)
+
+		// the neighbours of v become a clique, and v is removed
+		affected := make(map[int]bool)
+		for w := range neighbours[v] {
+			delete(neighbours[w], v)
+			for u := range neighbours[v] {
+				if u != w {
+					neighbours[w][u] = true
+				}
+			}
+			affected[w] = true
+			if g.MinFill {
+				for u := range neighbours[w] {
+					affected[u] = true
+				}
+			}
+		}
+		delete(neighbours, v)
+		delete(scores, v)
+		for w := range affected {
+			scores[w] = score(w)
+		}
+	}
+
+	// the parent of the bag of v is that of the neighbour of v eliminated next
+	children := make(map[int][]int)
+	var roots []int
+	for _, v := range order {
+		parent := -1
+		for _, w := range bags[v][1:] {
+			if parent < 0 || position[w] < position[parent] {
+				parent = w
+			}
+		}
+		if parent < 0 {
+			roots = append(roots, v)
+		} else {
+			children[parent] = append(children[parent], v)
+		}
+	}
+
+	var build func(v int) lib.Node
+	build = func(v int) lib.Node {
+		output := lib.Node{Bag: bags[v], Cover: greedyCover(bags[v], G.Edges)}
+		for _, c := range children[v] {
+			child := build(c)
+			if lib.Subset(child.Bag, output.Bag) { // redundant bag, its subtree is attached directly
+				output.Children = append(output.Children, child.Children...)
+			} else {
+				output.Children = append(output.Children, child)
+			}
+		}
+		return output
+	}
+
+	// the trees of different connected components share no vertices, so they can simply be joined
+	root := build(roots[len(roots)-1])
+	for _, r := range roots[:len(roots)-1] {
+		root.Children = append(root.Children, build(r))
+	}
+
+	return lib.Decomp{Graph: G, Root: root}
+}
+
+// greedyCover covers the vertices by repeatedly choosing the edge covering the most vertices not covered so far
+func greedyCover(vertices []int, edges lib.Edges) lib.Edges {
+	var output []lib.Edge
+	uncovered := vertices
+	candidates := lib.FilterVertices(edges, vertices).Slice()
+
+	for len(uncovered) > 0 {
+		best, covered := -1, 0
+		for i, e := range candidates {
+			if n := len(lib.Inter(e.Vertices, uncovered)); n > covered {
+				best, covered = i, n
+			}
+		}
+		if best < 0 {
+			break // only possible if the vertices don't belong to the edges
+		}
+		output = append(output, candidates[best])
+		uncovered = lib.Diff(uncovered, candidates[best].Vertices)
+	}
+
+	return lib.NewEdges(output)
+}
@@ -2,11 +2,9 @@ package algorithms
 
 import (
 	"container/heap"
-	"reflect"
 	"runtime"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepLocal implements the local Balanced Separator algorithm for computing GHDs.
@@ -17,6 +15,7 @@ type JCostBalSepLocal struct {
 	BalFactor int
 	Generator lib.SearchGenerator
 	JCosts    lib.EdgesCostMap
+	depth     int // of the current recursive call, for progress reports
 }
 
 // SetGenerator defines the type of Search to use
@@ -29,6 +28,12 @@ func (b *JCostBalSepLocal) SetWidth(K int) {
 	b.K = K
 }
 
+// Clone returns an independent copy of the algorithm
+func (b *JCostBalSepLocal) Clone() Algorithm {
+	output := *b
+	return &output
+}
+
 func (b JCostBalSepLocal) findGHD(K int) lib.Decomp {
 	return b.findDecomp(b.Graph)
 }
@@ -61,14 +66,14 @@ func baseCaseSmartCosts(g lib.Graph, H lib.Graph, jc lib.EdgesCostMap) lib.Decom
 		cost = jc.Cost(s)
 	}
 
-	if H.Edges.Len() <= 2 && len(H.Special) == 0 {
+	if H.Edges.Len() <= 2 && H.NumSpecial() == 0 {
 		output = lib.Decomp{Graph: H,
 			Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges, Cost: cost}}
-	} else if H.Edges.Len() == 1 && len(H.Special) == 1 {
+	} else if H.Edges.Len() == 1 && H.NumSpecial() == 1 {
 		sp1 := H.Special[0]
 		output = lib.Decomp{Graph: H,
 			Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges, Cost: cost,
-				Children: []lib.Node{lib.Node{Bag: sp1.Vertices(), Cover: sp1}}}}
+				Children: []lib.Node{lib.Node{Bag: sp1.Vertices(), Cover: sp1.Edges}}}}
 	} else {
 		return baseCase(g, H)
 	}
@@ -88,7 +93,7 @@ func earlyTerminationCosts(H lib.Graph, jc lib.EdgesCostMap) lib.Decomp {
 
 	return lib.Decomp{Graph: H,
 		Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges, Cost: cost,
-			Children: []lib.Node{lib.Node{Bag: H.Special[0].Vertices(), Cover: H.Special[0]}}}}
+			Children: []lib.Node{lib.Node{Bag: H.Special[0].Vertices(), Cover: H.Special[0].Edges}}}}
 }
 
 func rerootingCosts(H lib.Graph, balsep lib.Edges, subtrees []lib.Decomp, cost float64) lib.Decomp {
@@ -160,6 +165,10 @@ func orderSeparators(b JCostBalSepLocal, edges lib.Edges, ps lib.Search, pred li
 }
 
 func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
+	b.depth++
+	lib.Stats.Enter(b.depth)
+	defer lib.Stats.Leave(b.depth)
+
 	// log.Printf("\n\nCurrent SubGraph: %v\n", H)
 
 	//stop if there are at most two special edges left
@@ -168,16 +177,17 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 	}
 
 	//Early termination
-	if H.Edges.Len() <= b.K && len(H.Special) == 1 {
+	if H.Edges.Len() <= b.K && H.NumSpecial() == 1 {
 		return earlyTerminationCosts(H, b.JCosts)
 	}
 	var balsep lib.Edges
 
-	edges := lib.CutEdges(b.Graph.Edges, append(H.Vertices()))
+	edges := lib.CutEdges(b.Graph.Edges, H.Vertices())
 	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), false)
 	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
 	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	Vertices := lib.ElementMap()
+	defer lib.ReleaseElementMap(Vertices)
 	// parallelSearch.FindNext(pred) // initial Search
 
 	var cache map[uint32]struct{}
@@ -202,21 +212,26 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 
 			// log.Printf("Comps of Sep: %v for H %v \n", comps, H)
 
-			SepSpecial := lib.NewEdges(balsep.Slice())
+			SepSpecial := lib.NewSpecialEdge(balsep)
 
-			ch := make(chan lib.Decomp)
+			ch := make(chan lib.Decomp, len(comps)) // buffered, so no goroutine blocks once a component was rejected
 			var subtrees []lib.Decomp
 
+			batch := newComponents(b.Generator)
 			for i := range comps {
-				go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
-					comps[i].Special = append(comps[i].Special, SepSpecial)
-					ch <- b.findDecomp(comps[i])
-				}(i, comps, SepSpecial)
+				i := i
+				batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
+					comps[i].AddSpecial(SepSpecial)
+					sub := b
+					sub.Generator = gen
+					return sub.findDecomp(comps[i])
+				})
 			}
 
 			for i := 0; i < len(comps); i++ {
 				decomp := <-ch
-				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+				if !decomp.Found() {
+					batch.stop()
 					subtrees = []lib.Decomp{}
 					if sepSub == nil {
 						sepSub = lib.GetSepSub(b.Graph.Edges, balsep, b.K)
@@ -251,6 +266,7 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 				// log.Printf("Produced Decomp: %+v\n", decomp)
 				subtrees = append(subtrees, decomp)
 			}
+			batch.stop()
 
 			return rerootingCosts(H, balsep, subtrees, sep.Cost)
 		}
@@ -0,0 +1,118 @@
+package algorithms
+
+// pool.go bounds the number of goroutines used to decompose the components of a separator in parallel. Without a
+// bound, every level of the recursion spawns a goroutine per component, which exhausts memory on wide instances.
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// A workerPool hands out a bounded number of slots, each allowing one extra goroutine to run
+type workerPool struct {
+	slots chan struct{}
+}
+
+// pool is shared by all algorithms, so the bound holds for the whole process
+var pool = newWorkerPool(runtime.GOMAXPROCS(0))
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, size)}
+}
+
+// SetProcs sets the maximal number of goroutines the recursive calls of all algorithms may run on, a value ≤ 0
+// meaning the number of CPUs used by the runtime. It must not be called while a search is running.
+func SetProcs(n int) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	pool = newWorkerPool(n)
+}
+
+// Sequential makes the recursive calls of all algorithms run inline, one component after another in the order they
+// were found, until SetProcs is called. It must not be called while a search is running.
+func Sequential() {
+	pool = newWorkerPool(0)
+}
+
+// Procs returns the maximal number of goroutines used for recursive calls, see SetProcs
+func Procs() int {
+	return cap(pool.slots)
+}
+
+// A components batch submits the recursive calls for the components of one separator. As the separator is rejected
+// once any of them fails, the calls not yet started are skipped from then on, which matters most if they are run
+// inline, one after another, and those still running are cancelled through the context of the generator handed to
+// them. A batch must be stopped once its results are received or no longer needed.
+type components struct {
+	rejected  int32
+	generator lib.SearchGenerator // the generator of the calls, whose searches end once the batch is cancelled
+	cancel    context.CancelFunc
+	running   sync.WaitGroup
+}
+
+// newComponents returns a batch of calls using searches set up like those of gen
+func newComponents(gen lib.SearchGenerator) *components {
+	ctx, cancel := context.WithCancel(lib.SearchContext(gen))
+	return &components{generator: lib.WithContext(gen, ctx), cancel: cancel}
+}
+
+// run runs f with the generator of the batch on a goroutine of its own if a slot of the pool is free and
+// lib.Goroutines grants one, and otherwise in the calling goroutine, before returning. Callers must thus never block
+// on results of f before all of their calls to run returned.
+func (c *components) run(f func(gen lib.SearchGenerator)) {
+	c.running.Add(1)
+	p := pool
+	select {
+	case p.slots <- struct{}{}:
+		if lib.Goroutines.TryAcquire(1) != 0 {
+			go func() {
+				defer func() {
+					lib.Goroutines.Release(1)
+					<-p.slots
+					c.running.Done() // only once the goroutine no longer counts towards any bound
+				}()
+				f(c.generator)
+			}()
+			return
+		}
+		<-p.slots
+	default:
+	}
+
+	defer c.running.Done()
+	f(c.generator)
+}
+
+// submit runs f as by run, and sends its result to ch, or the empty decomposition right away if another component
+// of the batch was already rejected. A rejection cancels the calls still running.
+func (c *components) submit(ch chan<- lib.Decomp, f func(gen lib.SearchGenerator) lib.Decomp) {
+	c.run(func(gen lib.SearchGenerator) {
+		if atomic.LoadInt32(&c.rejected) != 0 {
+			ch <- lib.Decomp{}
+			return
+		}
+		decomp := f(gen)
+		if !decomp.Found() {
+			atomic.StoreInt32(&c.rejected, 1)
+			c.cancel()
+		}
+		ch <- decomp
+	})
+}
+
+// stop cancels the calls still running, and waits for all calls to return, so that none outlives the search of the
+// separator
+func (c *components) stop() {
+	c.cancel()
+	c.running.Wait()
+}
+
+// Busy returns the number of goroutines of the pool currently running
+func Busy() int {
+	return len(pool.slots)
+}
@@ -0,0 +1,142 @@
+package algorithms
+
+// portfolio.go races several algorithms against each other on the same instance, since no single algorithm is the
+// fastest on all of them
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Portfolio runs all of its members concurrently on the same graph and width, and returns the first decomposition
+// found by any of them. The others are then cancelled via the context of their searches, which is derived from the
+// one of the generator. Members failing to find a decomposition drop out of the race, so that a fast but incomplete
+// algorithm, such as BalSepSplit, can be paired with a complete one: the portfolio fails only once all members have.
+// Members not checking the context keep running in the background until they are done, but their result is ignored.
+type Portfolio struct {
+	K         int
+	Graph     lib.Graph
+	Members   []Algorithm
+	Generator lib.SearchGenerator
+	won       *winner // shared by copies of the portfolio, but not by clones
+}
+
+// winner records which member found the last decomposition returned by a portfolio
+type winner struct {
+	mux  sync.Mutex
+	name string
+}
+
+// NewPortfolio sets up a portfolio of the registered algorithms of the given names, all with the same settings
+func NewPortfolio(names []string, c Config) (*Portfolio, error) {
+	output := &Portfolio{K: c.K, Graph: c.Graph, won: &winner{}}
+
+	for _, name := range names {
+		factory, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		output.Members = append(output.Members, factory(c))
+	}
+
+	return output, nil
+}
+
+// SetGenerator defines the type of Search to use. Each member gets a copy with a context of its own, if it is a
+// ParallelSearchGen, and a ParallelSearchGen otherwise.
+func (p *Portfolio) SetGenerator(Gen lib.SearchGenerator) {
+	p.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (p *Portfolio) SetWidth(K int) {
+	p.K = K
+	for i := range p.Members {
+		p.Members[i].SetWidth(K)
+	}
+}
+
+// Clone returns an independent copy of the algorithm
+func (p *Portfolio) Clone() Algorithm {
+	output := &Portfolio{K: p.K, Graph: p.Graph, Generator: p.Generator, won: &winner{}}
+	for i := range p.Members {
+		output.Members = append(output.Members, p.Members[i].Clone())
+	}
+	return output
+}
+
+// Name returns the name of the algorithm
+func (p *Portfolio) Name() string {
+	var names []string
+	for i := range p.Members {
+		names = append(names, p.Members[i].Name())
+	}
+	return "Portfolio (" + strings.Join(names, ", ") + ")"
+}
+
+// Winner returns the name of the member which found the last decomposition returned, or the empty string if there is
+// none yet or the last race failed
+func (p *Portfolio) Winner() string {
+	if p.won == nil {
+		return ""
+	}
+	p.won.mux.Lock()
+	defer p.won.mux.Unlock()
+
+	return p.won.name
+}
+
+// FindDecomp finds a decomp
+func (p *Portfolio) FindDecomp() lib.Decomp {
+	return p.FindDecompGraph(p.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (p *Portfolio) FindDecompGraph(G lib.Graph) lib.Decomp {
+	decomp, name := p.Race(G)
+	if p.won != nil {
+		p.won.mux.Lock()
+		p.won.name = name
+		p.won.mux.Unlock()
+	}
+	return decomp
+}
+
+// Race runs all members on G and returns the first decomposition found together with the name of the member which
+// found it, or an empty decomp and name if all of them failed or the context of the generator is done
+func (p *Portfolio) Race(G lib.Graph) (lib.Decomp, string) {
+	ctx, cancel := context.WithCancel(lib.SearchContext(p.Generator))
+	defer cancel() // stops the members still running
+
+	gen := lib.ParallelSearchGen{Ctx: ctx}
+	if parallel, ok := p.Generator.(lib.ParallelSearchGen); ok {
+		parallel.Ctx = ctx
+		gen = parallel
+	}
+
+	type result struct {
+		decomp lib.Decomp
+		name   string
+	}
+	ch := make(chan result, len(p.Members)) // buffered, so no member blocks once the race is decided
+
+	for i := range p.Members {
+		member := p.Members[i].Clone() // so that members may be run by several races at once
+		member.SetGenerator(gen)
+		go func() {
+			ch <- result{decomp: member.FindDecompGraph(G), name: member.Name()}
+		}()
+	}
+
+	for i := 0; i < len(p.Members); i++ {
+		r := <-ch
+		if r.decomp.Found() {
+			return r.decomp, r.name
+		}
+	}
+
+	return lib.Decomp{}, ""
+}
@@ -0,0 +1,75 @@
+package algorithms
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// A ProbeResult summarises the outcome of decomposing randomly sampled induced subgraphs
+type ProbeResult struct {
+	Samples  int     // number of samples decomposed
+	Rejected int     // number of samples for which no decomposition was found
+	Largest  float64 // largest fraction of vertices in a successfully decomposed sample
+}
+
+// Plausible reports whether the full graph may still have a decomposition of the probed width. For GHDs, a single
+// rejected sample proves that none exists, as the width of induced subgraphs can't exceed that of the full graph.
+func (p ProbeResult) Plausible() bool {
+	return p.Rejected == 0
+}
+
+// Confidence is a heuristic estimate in [0,1] for a decomposition of the full graph to exist. It is based on how
+// large the accepted samples were, and is 0 if some sample was rejected.
+func (p ProbeResult) Confidence() float64 {
+	if !p.Plausible() || p.Samples == 0 {
+		return 0
+	}
+	return p.Largest
+}
+
+func (p ProbeResult) String() string {
+	if !p.Plausible() {
+		return fmt.Sprintf("width exceeded: %d of %d samples not decomposable", p.Rejected, p.Samples)
+	}
+	return fmt.Sprintf("plausible: all %d samples decomposable, largest covering %.0f%% of vertices, "+
+		"confidence %.2f", p.Samples, p.Largest*100, p.Confidence())
+}
+
+// Probe decomposes a number of random induced subgraphs of H with the given algorithm, whose width must be set
+// beforehand. Samples grow from a fifth to nine tenths of the vertices, and probing stops at the first rejected one.
+func Probe(solver Algorithm, H lib.Graph, samples int, r *rand.Rand) ProbeResult {
+	var output ProbeResult
+	vertices := H.Vertices()
+
+	for i := 0; i < samples; i++ {
+		fraction := 0.2
+		if samples > 1 {
+			fraction = 0.2 + 0.7*float64(i)/float64(samples-1)
+		}
+
+		size := int(fraction * float64(len(vertices)))
+		if size == 0 {
+			size = 1
+		}
+		var sampled []int
+		for _, j := range r.Perm(len(vertices))[:size] {
+			sampled = append(sampled, vertices[j])
+		}
+
+		sub := H.WithEdges(lib.CutEdges(H.Edges, sampled))
+		decomp := solver.FindDecompGraph(sub)
+		output.Samples++
+
+		if !decomp.Found() || !decomp.Correct(sub) {
+			output.Rejected++
+			return output
+		}
+		if fraction > output.Largest {
+			output.Largest = fraction
+		}
+	}
+
+	return output
+}
@@ -0,0 +1,108 @@
+package algorithms
+
+// registry.go maps names to algorithms, so that they can be chosen by name and other packages can add their own
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// A Config holds the settings common to the algorithms set up via the registry. Algorithms ignore the settings they
+// don't support, and leave those not set at their defaults.
+type Config struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Order     lib.ComponentOrder // the order in which the components of a separator are decomposed
+	SepOrder  lib.SeparatorOrder // the order in which the edges are tried for separators
+	Balance   lib.Predicate      // which separators are balanced, BalancedCheck if nil, see balance
+}
+
+// A Factory sets up an algorithm with the given settings
+type Factory func(c Config) Algorithm
+
+var registryMux sync.RWMutex
+
+// registry holds the factories of all algorithms by name, starting with those of this package. Except for vertex,
+// split and greedy, each of them finds a GHD of width K whenever one exists, as long as Balance is not set.
+var registry = map[string]Factory{
+	"local": func(c Config) Algorithm {
+		return &BalSepLocal{K: c.K, Graph: c.Graph, BalFactor: c.BalFactor, Order: c.Order, SepOrder: c.SepOrder,
+			Balance: c.Balance}
+	},
+	"global": func(c Config) Algorithm {
+		return &BalSepGlobal{K: c.K, Graph: c.Graph.ComputeSubEdges(c.K), BalFactor: c.BalFactor, Order: c.Order,
+			SepOrder: c.SepOrder, Balance: c.Balance}
+	},
+	"det": func(c Config) Algorithm {
+		return &DetKDecomp{K: c.K, Graph: c.Graph, BalFactor: c.BalFactor, SubEdge: true, Order: c.Order}
+	},
+	"balDet": func(c Config) Algorithm {
+		return &BalSepHybrid{K: c.K, Graph: c.Graph, BalFactor: c.BalFactor, Depth: 1, Order: c.Order,
+			SepOrder: c.SepOrder, Balance: c.Balance}
+	},
+	"seqBalDet": func(c Config) Algorithm {
+		return &BalSepHybridSeq{K: c.K, Graph: c.Graph, BalFactor: c.BalFactor, Depth: 1, Order: c.Order,
+			SepOrder: c.SepOrder, Balance: c.Balance}
+	},
+	"vertex": func(c Config) Algorithm {
+		return &BalSepVertex{K: c.K, Graph: c.Graph, BalFactor: c.BalFactor}
+	},
+	"split": func(c Config) Algorithm {
+		return &BalSepSplit{K: c.K, Graph: c.Graph, BalFactor: c.BalFactor, Order: c.Order, SepOrder: c.SepOrder,
+			Balance: c.Balance}
+	},
+	"greedy": func(c Config) Algorithm {
+		return &GreedyDecomp{K: c.K, Graph: c.Graph}
+	},
+}
+
+// Register adds an algorithm under the given name, panicking if the name is taken. It is meant to be called from the
+// init function of the package implementing the algorithm.
+func Register(name string, factory Factory) {
+	registryMux.Lock()
+	defer registryMux.Unlock()
+
+	if factory == nil {
+		log.Panicln("Algorithm", name, "registered without a factory")
+	}
+	if _, ok := registry[name]; ok {
+		log.Panicln("Algorithm", name, "registered twice")
+	}
+	registry[name] = factory
+}
+
+// Get returns the factory of the algorithm of the given name
+func Get(name string) (Factory, error) {
+	registryMux.RLock()
+	defer registryMux.RUnlock()
+
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q, supported are: %v", name, names())
+	}
+	return factory, nil
+}
+
+// Names returns the names of all registered algorithms, in alphabetical order
+func Names() []string {
+	registryMux.RLock()
+	defer registryMux.RUnlock()
+
+	return names()
+}
+
+func names() []string {
+	var output []string
+
+	for name := range registry {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+
+	return output
+}
@@ -0,0 +1,101 @@
+package algorithms
+
+// restarts.go restarts a search which takes too long, hoping that another order of the separators finds a
+// decomposition sooner, in the style of a Las Vegas algorithm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Restarts runs Inner with a time budget, and starts it over once the budget is used up without an answer, doubling
+// the budget each time, so that an answer is found eventually. This only makes sense if Inner tries separators in
+// another order for each run, such as with the separator order lib.RandomOffset, which starts the searches at random
+// edges. The restarts reuse the same clone of Inner, so that anything it has cached carries over.
+type Restarts struct {
+	K         int
+	Graph     lib.Graph
+	Inner     Algorithm
+	Budget    time.Duration // the budget of the first run
+	Generator lib.SearchGenerator
+	count     *int32 // shared by copies, but not by clones
+}
+
+// NewRestarts sets up restarts of inner, with the given budget for the first run
+func NewRestarts(K int, G lib.Graph, inner Algorithm, budget time.Duration, gen lib.SearchGenerator) *Restarts {
+	return &Restarts{K: K, Graph: G, Inner: inner, Budget: budget, Generator: gen, count: new(int32)}
+}
+
+// SetGenerator defines the type of Search to use. Each run gets a copy with a context of its own, if it is a
+// ParallelSearchGen, and a ParallelSearchGen otherwise, which ends once the budget is used up.
+func (r *Restarts) SetGenerator(Gen lib.SearchGenerator) {
+	r.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (r *Restarts) SetWidth(K int) {
+	r.K = K
+	r.Inner.SetWidth(K)
+}
+
+// Clone returns an independent copy of the algorithm
+func (r *Restarts) Clone() Algorithm {
+	return NewRestarts(r.K, r.Graph, r.Inner.Clone(), r.Budget, r.Generator)
+}
+
+// Name returns the name of the algorithm
+func (r *Restarts) Name() string {
+	return r.Inner.Name()
+}
+
+// Count returns the number of restarts done so far, which are only counted if set up by NewRestarts
+func (r *Restarts) Count() int {
+	if r.count == nil {
+		return 0
+	}
+	return int(atomic.LoadInt32(r.count))
+}
+
+// FindDecomp finds a decomp
+func (r *Restarts) FindDecomp() lib.Decomp {
+	return r.FindDecompGraph(r.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph. A run ending within its budget is final, whether it found a
+// decomposition or not, while the empty decomp is returned right away if the context of the generator is done.
+// Without a positive budget, Inner is run just once.
+func (r *Restarts) FindDecompGraph(G lib.Graph) lib.Decomp {
+	parent := lib.SearchContext(r.Generator)
+	inner := r.Inner.Clone()
+	if r.Budget <= 0 {
+		inner.SetGenerator(r.Generator)
+		return inner.FindDecompGraph(G)
+	}
+
+	for budget := r.Budget; ; budget *= 2 {
+		ctx, cancel := context.WithTimeout(parent, budget)
+		gen := lib.ParallelSearchGen{Ctx: ctx}
+		if parallel, ok := r.Generator.(lib.ParallelSearchGen); ok {
+			parallel.Ctx = ctx
+			gen = parallel
+		}
+		inner.SetGenerator(gen)
+
+		decomp := inner.FindDecompGraph(G)
+		timedOut := ctx.Err() != nil
+		cancel()
+		if decomp.Found() || !timedOut || parent.Err() != nil {
+			return decomp
+		}
+
+		if r.count != nil {
+			atomic.AddInt32(r.count, 1)
+		}
+		if lib.LogSearch.Enabled(lib.LogInfo) {
+			lib.LogSearch.Printf(lib.LogInfo, "No decomposition found within %v, restarting", budget)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package algorithms
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// SmallWidth handles the widths 1 and 2 with specialised procedures, and otherwise hands over to a more general
+// algorithm. For width 1, a join tree is constructed via GYÖ reduction in polynomial time. For width 2, acyclic graphs
+// are still answered by a join tree, and all others by Width2, a search for hypertree decompositions restricted to
+// covers of at most two edges, which is polynomial as well. If Width2 finds none, the answer is final for DetKDecomp
+// without subedges, as it looks for hypertree decompositions too, while all other algorithms are tried anyway, since
+// a generalized hypertree decomposition of width 2 may still exist.
+type SmallWidth struct {
+	K        int
+	Graph    lib.Graph
+	Fallback Algorithm
+	ctx      context.Context // taken from the generator, Width2 is abandoned once it is done
+	used     string
+}
+
+// SetGenerator defines the type of Search to use
+func (s *SmallWidth) SetGenerator(Gen lib.SearchGenerator) {
+	s.ctx = lib.SearchContext(Gen)
+	s.Fallback.SetGenerator(Gen)
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (s *SmallWidth) SetWidth(K int) {
+	s.K = K
+	s.Fallback.SetWidth(K)
+}
+
+// Clone returns an independent copy of the algorithm
+func (s *SmallWidth) Clone() Algorithm {
+	return &SmallWidth{K: s.K, Graph: s.Graph, Fallback: s.Fallback.Clone(), ctx: s.ctx}
+}
+
+// Name returns the name of the algorithm
+func (s *SmallWidth) Name() string {
+	return s.Fallback.Name()
+}
+
+// Used returns which procedure answered the last call of FindDecompGraph, "join tree" or "width 2 search", or the
+// empty string if it was the fallback
+func (s *SmallWidth) Used() string {
+	return s.used
+}
+
+// FindDecomp finds a decomp
+func (s *SmallWidth) FindDecomp() lib.Decomp {
+	return s.FindDecompGraph(s.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (s *SmallWidth) FindDecompGraph(G lib.Graph) lib.Decomp {
+	s.used = ""
+	if s.K > 2 || G.NumSpecial() > 0 || G.Edges.Len() == 0 {
+		return s.Fallback.FindDecompGraph(G)
+	}
+
+	if decomp, ok := JoinTree(G); ok {
+		s.used = "join tree"
+		return decomp
+	}
+	if s.K == 1 {
+		s.used = "join tree"
+		return lib.Decomp{} // cyclic, so no decomp of width 1 exists
+	}
+
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if decomp, ok := Width2(ctx, G); ok {
+		s.used = "width 2 search"
+		return decomp
+	}
+	if det, ok := s.Fallback.(*DetKDecomp); ok && !det.SubEdge && ctx.Err() == nil {
+		s.used = "width 2 search"
+		return lib.Decomp{}
+	}
+
+	return s.Fallback.FindDecompGraph(G)
+}
+
+// JoinTree computes a join tree of an acyclic graph, using GYÖ reduction. Each removed ear becomes a child of the
+// edge it was contained in. Returns false if the graph is cyclic.
+func JoinTree(H lib.Graph) (lib.Decomp, bool) {
+	edges := H.Edges.Slice()
+	if len(edges) == 0 {
+		return lib.Decomp{}, false
+	}
+
+	vertices := make([][]int, len(edges)) // vertices of each edge, without repetitions
+	for i := range edges {
+		vertices[i] = lib.RemoveDuplicates(append([]int{}, edges[i].Vertices...))
+	}
+
+	removed := make([]bool, len(edges))
+	parent := make([]int, len(edges))
+	var order []int // removed edges, in order of removal
+
+	occurrences := make(map[int]int)
+	for i := range edges {
+		for _, v := range vertices[i] {
+			occurrences[v]++
+		}
+	}
+
+	remaining := len(edges)
+	for remaining > 1 {
+		foundEar := false
+
+		for i := range edges {
+			if removed[i] {
+				continue
+			}
+
+			// vertices of the ear shared with any other remaining edge
+			var shared []int
+			for _, v := range vertices[i] {
+				if occurrences[v] > 1 {
+					shared = append(shared, v)
+				}
+			}
+
+			witness := -1
+			for j := range edges {
+				if j != i && !removed[j] && lib.Subset(shared, edges[j].Vertices) {
+					witness = j
+					break
+				}
+			}
+			if witness == -1 {
+				continue
+			}
+
+			removed[i] = true
+			parent[i] = witness
+			order = append(order, i)
+			for _, v := range vertices[i] {
+				occurrences[v]--
+			}
+			remaining--
+			foundEar = true
+		}
+
+		if !foundEar {
+			return lib.Decomp{}, false
+		}
+	}
+
+	root := -1
+	for i := range edges {
+		if !removed[i] {
+			root = i
+		}
+	}
+
+	// build the tree bottom-up, as all children of an ear were removed before it
+	nodes := make([]lib.Node, len(edges))
+	for i := range edges {
+		nodes[i] = lib.Node{Bag: edges[i].Vertices, Cover: lib.NewEdges([]lib.Edge{edges[i]})}
+	}
+	for _, i := range order {
+		p := parent[i]
+		nodes[p].Children = append(nodes[p].Children, nodes[i])
+	}
+
+	return lib.Decomp{Graph: H, Root: nodes[root]}, true
+}
+
+// Width2 looks for a hypertree decomposition of H of width at most 2, in the way of det-k-decomp, but trying all
+// covers of one or two edges directly, without the generic machinery for separators. Each node covers the
+// vertices it shares with its parent, and the vertices of its component not in its bag form the components of its
+// children. As failed components are remembered, this takes polynomial time. Special edges are not supported, and
+// false is returned if there is no such decomposition, or ctx is done.
+func Width2(ctx context.Context, H lib.Graph) (lib.Decomp, bool) {
+	w := width2{ctx: ctx, edges: H.Edges.Slice(), incident: make(map[int][]int), failed: make(map[string]bool)}
+	for i, e := range w.edges {
+		for _, v := range lib.RemoveDuplicates(append([]int{}, e.Vertices...)) {
+			w.incident[v] = append(w.incident[v], i)
+		}
+	}
+
+	vertices := append([]int{}, H.Vertices()...)
+	sort.Ints(vertices)
+	root, ok := w.decompose(vertices, nil)
+	if !ok {
+		return lib.Decomp{}, false
+	}
+	return lib.Decomp{Graph: H, Root: root}, true
+}
+
+// width2 holds the state of a call of Width2
+type width2 struct {
+	ctx      context.Context
+	edges    []lib.Edge
+	incident map[int][]int   // the edges containing each vertex
+	failed   map[string]bool // components with connectors known to have no decomposition
+}
+
+// decompose returns a decomposition of the edges containing some vertex of comp, whose root covers conn, where
+// both are sorted
+func (w *width2) decompose(comp []int, conn []int) (lib.Node, bool) {
+	key := intsKey(comp) + "|" + intsKey(conn)
+	if w.failed[key] || w.ctx.Err() != nil {
+		return lib.Node{}, false
+	}
+
+	scope := lib.RemoveDuplicates(append(append([]int{}, comp...), conn...))
+	var candidates []int // edges sharing a vertex with the component or connector
+	for _, v := range scope {
+		candidates = append(candidates, w.incident[v]...)
+	}
+	candidates = lib.RemoveDuplicates(candidates)
+	sort.Ints(candidates)
+
+	// covers of single edges first, so that no node is wider than needed
+	for size := 1; size <= 2; size++ {
+		for a := range candidates {
+			for b := a + size - 1; b < len(candidates); b++ {
+				cover := []lib.Edge{w.edges[candidates[a]]}
+				if size == 2 {
+					cover = append(cover, w.edges[candidates[b]])
+				}
+				if node, ok := w.tryCover(comp, conn, scope, cover); ok {
+					return node, true
+				}
+				if size == 1 {
+					break
+				}
+			}
+		}
+	}
+
+	w.failed[key] = true
+	return lib.Node{}, false
+}
+
+// tryCover tries to decompose the component with the given cover at the root
+func (w *width2) tryCover(comp, conn, scope []int, cover []lib.Edge) (lib.Node, bool) {
+	edges := lib.NewEdges(cover)
+	covered := edges.Vertices()
+	if !lib.Subset(conn, covered) || len(lib.Inter(comp, covered)) == 0 {
+		return lib.Node{}, false
+	}
+	bag := lib.Inter(scope, covered)
+	sort.Ints(bag)
+
+	output := lib.Node{Bag: bag, Cover: edges}
+	for _, child := range w.components(lib.Diff(comp, bag)) {
+		var childVertices []int
+		for _, v := range child {
+			for _, e := range w.incident[v] {
+				childVertices = append(childVertices, w.edges[e].Vertices...)
+			}
+		}
+		childConn := lib.Inter(bag, childVertices)
+
+		node, ok := w.decompose(child, childConn)
+		if !ok {
+			return lib.Node{}, false
+		}
+		output.Children = append(output.Children, node)
+	}
+
+	return output, true
+}
+
+// components splits the vertices into the sets connected via edges, each sorted
+func (w *width2) components(vertices []int) [][]int {
+	remaining := make(map[int]bool, len(vertices))
+	for _, v := range vertices {
+		remaining[v] = true
+	}
+
+	var output [][]int
+	for _, start := range vertices {
+		if !remaining[start] {
+			continue
+		}
+		delete(remaining, start)
+		comp := []int{start}
+		for i := 0; i < len(comp); i++ {
+			for _, e := range w.incident[comp[i]] {
+				for _, v := range w.edges[e].Vertices {
+					if remaining[v] {
+						delete(remaining, v)
+						comp = append(comp, v)
+					}
+				}
+			}
+		}
+		sort.Ints(comp)
+		output = append(output, comp)
+	}
+
+	return output
+}
+
+// intsKey returns a string identifying the sorted slice
+func intsKey(s []int) string {
+	var output strings.Builder
+	for i, x := range s {
+		if i > 0 {
+			output.WriteByte(',')
+		}
+		output.WriteString(strconv.Itoa(x))
+	}
+	return output.String()
+}
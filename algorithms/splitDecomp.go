@@ -27,17 +27,24 @@ func (d *SplitDecomp) SetWidth(K int) {
 	d.K = K
 }
 
+// Clone returns an independent copy of the algorithm
+func (d *SplitDecomp) Clone() *SplitDecomp {
+	output := *d
+	return &output
+}
+
 // FindDecompGraph finds a decomp, for an explicit lib.Graph
 func (d *SplitDecomp) FindDecompGraph(G lib.Graph) lib.Decomp {
-	if G.Edges.Len() < d.K {
-		d.K = G.Edges.Len()
+	k := d.K // not stored, so later calls on larger graphs still use the full width
+	if G.Edges.Len() < k {
+		k = G.Edges.Len()
 	}
 
-	rootCover := lib.NewEdges(G.Edges.Slice()[:d.K])
+	rootCover := lib.NewEdges(G.Edges.Slice()[:k])
 	root := lib.Node{Bag: rootCover.Vertices(), Cover: rootCover}
 
-	if G.Edges.Len() > d.K {
-		childCover := lib.NewEdges(G.Edges.Slice()[d.K:])
+	if G.Edges.Len() > k {
+		childCover := lib.NewEdges(G.Edges.Slice()[k:])
 		child := lib.Node{Bag: childCover.Vertices(), Cover: childCover}
 		root.Children = []lib.Node{child}
 	}
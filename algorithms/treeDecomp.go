@@ -0,0 +1,163 @@
+package algorithms
+
+import (
+	"runtime"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TreeDecomp computes an ordinary tree decomposition of the primal graph of a hypergraph, whose bags hold at most
+// K+1 vertices, i.e. of treewidth at most K. It uses the balanced separator approach of BalSepVertex, though
+// separators are bounded by their number of vertices instead of the edges needed to cover them, and no covers are
+// computed. The nodes of the result have empty covers.
+type TreeDecomp struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Generator lib.SearchGenerator
+	depth     int // of the current recursive call, for progress reports
+}
+
+// SetGenerator defines the type of Search to use
+func (t *TreeDecomp) SetGenerator(Gen lib.SearchGenerator) {
+	t.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (t *TreeDecomp) SetWidth(K int) {
+	t.K = K
+}
+
+// Clone returns an independent copy of the algorithm
+func (t *TreeDecomp) Clone() Algorithm {
+	output := *t
+	return &output
+}
+
+// Name returns the name of the algorithm
+func (t TreeDecomp) Name() string {
+	return "TreeDecomp"
+}
+
+// FindDecomp finds a tree decomposition of the graph
+func (t TreeDecomp) FindDecomp() lib.Decomp {
+	return t.FindDecompGraph(t.Graph)
+}
+
+// FindDecompGraph finds a tree decomposition of the primal graph of G
+func (t TreeDecomp) FindDecompGraph(G lib.Graph) lib.Decomp {
+	if t.Generator == nil {
+		t.Generator = lib.ParallelSearchGen{}
+	}
+
+	decomp := t.findDecomp(G.Primal())
+	if !decomp.Found() {
+		return decomp
+	}
+
+	return lib.Decomp{Graph: G, Root: bagsOnly(decomp.Root)}
+}
+
+// bagsOnly returns a copy of the subtree rooted at n with all covers removed
+func bagsOnly(n lib.Node) lib.Node {
+	output := lib.Node{Bag: n.Bag, Cover: lib.NewEdges([]lib.Edge{})}
+	for i := range n.Children {
+		output.Children = append(output.Children, bagsOnly(n.Children[i]))
+	}
+	return output
+}
+
+// tdBaseCase decomposes H without any further search if possible, and returns false otherwise. A single bag suffices
+// if H has at most K+1 vertices, and at most two edges or special edges are each put in a bag of their own.
+func (t TreeDecomp) tdBaseCase(H lib.Graph) (lib.Decomp, bool) {
+	var specials []lib.Node
+	for _, sp := range H.Special {
+		specials = append(specials, lib.Node{Bag: sp.Vertices(), Cover: sp.Edges})
+	}
+
+	if len(H.Vertices()) <= t.K+1 {
+		return lib.Decomp{Graph: H, Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges, Children: specials}}, true
+	}
+	if H.Len() > 2 {
+		return lib.Decomp{}, false
+	}
+
+	// special edges come last, so that the one added most recently ends up in a leaf, where it is rerooted at
+	var nodes []lib.Node
+	for _, e := range H.Edges.Slice() {
+		nodes = append(nodes, lib.Node{Bag: e.Vertices, Cover: lib.NewEdges([]lib.Edge{e})})
+	}
+	nodes = append(nodes, specials...)
+	for i := range nodes {
+		if len(nodes[i].Bag) > t.K+1 {
+			return lib.Decomp{}, false
+		}
+	}
+
+	root := nodes[0]
+	if len(nodes) == 2 {
+		root.Children = []lib.Node{nodes[1]}
+	}
+	return lib.Decomp{Graph: H, Root: root}, true
+}
+
+func (t TreeDecomp) findDecomp(H lib.Graph) lib.Decomp {
+	t.depth++
+	lib.Stats.Enter(t.depth)
+	defer lib.Stats.Leave(t.depth)
+
+	if decomp, ok := t.tdBaseCase(H); ok {
+		return decomp
+	}
+	if H.Len() <= 2 {
+		return lib.Decomp{} // an edge or special edge too large for any bag
+	}
+
+	// each vertex becomes a pseudo-edge, so that the search over edge combinations picks sets of vertices
+	var singletons []lib.Edge
+	for _, v := range H.Vertices() {
+		singletons = append(singletons, lib.Edge{Name: v, Vertices: []int{v}})
+	}
+	vertices := lib.NewEdges(singletons)
+
+	generators := lib.SplitCombin(vertices.Len(), t.K+1, runtime.GOMAXPROCS(-1), false)
+	parallelSearch := t.Generator.GetSearch(&H, &vertices, t.BalFactor, generators)
+	pred := lib.BalancedCheck{}
+
+	for parallelSearch.FindNext(pred); !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+		sep := lib.GetSubset(vertices, parallelSearch.GetResult())
+		comps, _, _ := H.GetComponents(sep, nil)
+		special := lib.NewSpecialEdge(sep)
+
+		ch := make(chan lib.Decomp, len(comps)) // buffered, as components may be decomposed inline by submit
+		batch := newComponents(t.Generator)
+		for i := range comps {
+			i := i
+			batch.submit(ch, func(gen lib.SearchGenerator) lib.Decomp {
+				comps[i].AddSpecial(special)
+				sub := t
+				sub.Generator = gen
+				return sub.findDecomp(comps[i])
+			})
+		}
+
+		var subtrees []lib.Decomp
+		rejected := false
+		for i := 0; i < len(comps); i++ {
+			decomp := <-ch
+			if !decomp.Found() {
+				rejected = true
+				continue // still receive from all goroutines
+			}
+			subtrees = append(subtrees, decomp)
+		}
+		batch.stop()
+		if rejected {
+			continue
+		}
+
+		return rerooting(H, sep, subtrees)
+	}
+
+	return lib.Decomp{} // empty Decomp signifying reject
+}
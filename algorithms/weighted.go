@@ -0,0 +1,37 @@
+package algorithms
+
+import (
+	"math"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// MinimizeWeight searches for a GHD of width b.K whose covers weigh as little as possible, see Decomp.WeightedWidth.
+// The local BalSep algorithm is run repeatedly, each time only allowing separators strictly lighter than the
+// heaviest cover found so far, until no lighter decomposition is found. The empty decomposition is returned if there
+// is none of width b.K at all.
+//
+// Only the separators are restricted by their weight, not the covers of the base cases, so the result is an upper
+// bound on the optimal weight, though an exact one if all edges weigh the same.
+func MinimizeWeight(b BalSepLocal) lib.Decomp {
+	b.MaxWeight = 0
+	best := b.FindDecomp()
+
+	for best.Found() {
+		best.Graph = b.Graph
+		weight := best.WeightedWidth()
+
+		b.MaxWeight = math.Nextafter(weight, 0)
+		next := b.FindDecomp()
+		if !next.Found() {
+			break
+		}
+		next.Graph = b.Graph
+		if next.WeightedWidth() >= weight {
+			break
+		}
+		best = next
+	}
+
+	return best
+}
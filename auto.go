@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// upperBound returns a decomposition found without any search, whose width bounds the width of the graph from
+// above: the greedy one, unless it is no better than the trivial one or a hypertree decomposition is needed
+func upperBound(graph lib.Graph, hd, fractional bool) lib.Decomp {
+	var output lib.Decomp
+	if !hd {
+		output = algo.GreedyDecomp{}.Decompose(graph)
+	}
+	if !output.Found() || output.CheckWidth() > graph.Edges.Len() {
+		output = lib.TrivialDecomp(graph)
+	}
+	if fractional {
+		output = algo.MakeFractional(output)
+	}
+	return output
+}
+
+// closeGap searches for the width of the graph between the lower bound and the width of upper, by bisection. Each
+// width tried either yields a better decomposition, whose width becomes the new upper bound, or raises the lower
+// bound above it. It returns the best decomposition found and the final bounds, which only differ if ctx expired
+// before the gap was closed.
+func closeGap(ctx context.Context, solver algo.Algorithm, graph lib.Graph, decompose func() lib.Decomp, lower int,
+	upper lib.Decomp, status *progress, bench bool) (lib.Decomp, int, int) {
+	best := upper
+	high := upper.CheckWidth()
+
+	for lower < high {
+		if !bench {
+			fmt.Printf("Bounds after %v: %v ≤ width ≤ %v\n", time.Since(status.start).Round(time.Millisecond),
+				lower, high)
+		}
+
+		k := (lower + high - 1) / 2
+		solver.SetWidth(k)
+		status.SetWidth(k)
+
+		decomp := decompose()
+		if ctx.Err() != nil {
+			break
+		}
+		if decomp.Found() && decomp.Correct(graph) {
+			best = decomp
+			high = decomp.CheckWidth()
+		} else {
+			lower = k + 1
+		}
+	}
+
+	return best, lower, high
+}
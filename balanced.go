@@ -5,16 +5,19 @@
 // Georg Gottlob, Cem Okulmus, Reinhard Pichler, released in Proc. IJCAI 2020.
 // https://www.ijcai.org/Proceedings/2020/161
 //
-// The tool is split into three packages. main is responsible to actually run the various algorithms supported
-// by the tool, lib is used to implement various functionality used by the algorithms and lastly algorithms which
-// implements the actual algorithms to compute various decompositions.
+// The tool is split into four packages. main is responsible to actually run the various algorithms supported
+// by the tool, lib is used to implement various functionality used by the algorithms, algorithms which
+// implements the actual algorithms to compute various decompositions, and lastly eval which evaluates conjunctive
+// queries along a computed decomposition.
 //
 // In addition to this, there is also a tool subdirectory in the repository which is intended to support functionality
 // not directly related to the computation of decompositions, such as changing the formatting of hypergraphs, or fixing
-// a faulty decomposition.
+// a faulty decomposition. The cmd subdirectory contains verifycert, a small standalone checker for the decomposition
+// certificates produced with the -cert flag.
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
@@ -22,18 +25,23 @@ import (
 	"io/ioutil"
 	"log"
 	"math"
+	"math/rand"
 	"os"
-	"reflect"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	jsoniter "github.com/json-iterator/go"
 
 	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/eval"
 	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/disjoint"
 )
 
 // Decomp used to improve readability
@@ -58,6 +66,34 @@ func logActive(b bool) {
 	}
 }
 
+// specValue is the value of the log flag, setting the levels of the loggers of lib as it is parsed. The values true
+// and false, as used for the flag by configurations, turn all of them fully on and off. As the flag used to be a
+// boolean one, it can still be given on its own, meaning true, so levels must be given as in -log=info.
+type specValue string
+
+func (s *specValue) String() string {
+	return string(*s)
+}
+
+// IsBoolFlag lets the flag be given without a value, see specValue
+func (s *specValue) IsBoolFlag() bool {
+	return true
+}
+
+func (s *specValue) Set(v string) error {
+	switch v {
+	case "true":
+		v = lib.LogDebug.String()
+	case "false":
+		v = lib.LogOff.String()
+	}
+	if err := lib.SetLogLevels(v); err != nil {
+		return err
+	}
+	*s = specValue(v)
+	return nil
+}
+
 func check(e error) {
 	if e != nil {
 		panic(e)
@@ -83,7 +119,8 @@ func (l labelTime) String() string {
 	return fmt.Sprintf("%s : %.5f ms", l.label, l.time)
 }
 
-func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Graph, gml string, json string, K int, skipCheck bool) {
+func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Graph, gml string, dot string,
+	json string, cert string, K int, skipCheck bool) {
 	decomp.RestoreSubedges()
 
 	fmt.Println("Used algorithm: " + algorithm + " @" + Version)
@@ -102,7 +139,10 @@ func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Grap
 		fmt.Println(time)
 	}
 
-	fmt.Println("\nWidth: ", decomp.CheckWidth())
+	fmt.Println("\nWidth: ", decomp.Width())
+	if decomp.Found() {
+		fmt.Printf("Nodes: %d, depth: %d, bag sizes: %v\n", decomp.NumNodes(), decomp.Depth(), decomp.BagStats())
+	}
 	var correct bool
 	if !skipCheck {
 		correct = decomp.Correct(graph)
@@ -119,6 +159,14 @@ func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Grap
 		f.WriteString(decomp.ToGML())
 		f.Sync()
 	}
+	if correct && len(dot) > 0 {
+		f, err := os.Create(dot)
+		check(err)
+
+		defer f.Close()
+		f.WriteString(decomp.ToDOT())
+		f.Sync()
+	}
 	if correct && len(json) > 0 {
 		f, err := os.Create(json)
 		check(err)
@@ -127,10 +175,110 @@ func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Grap
 		f.Write(lib.WriteDecomp(decomp))
 		f.Sync()
 	}
+	if correct && len(cert) > 0 {
+		f, err := os.Create(cert)
+		check(err)
+
+		defer f.Close()
+		f.WriteString(decomp.ToCertificate())
+		f.Sync()
+	}
+}
+
+// exportComponents computes the components of graph w.r.t. the separator given as a comma-separated list of edge
+// names, and writes each component into its own file in HyperBench format, placed in the directory dir
+func exportComponents(graph Graph, names string, dir string, graphPath string) {
+	var sep []Edge
+
+	for _, name := range strings.Split(names, ",") {
+		e, ok := graph.EdgeByName(strings.TrimSpace(name))
+		if !ok {
+			fmt.Println("Edge", strings.TrimSpace(name), "not found in hypergraph.")
+			return
+		}
+		sep = append(sep, e)
+	}
+
+	comps, _, isolated := graph.GetComponents(lib.NewEdges(sep), make(map[int]*disjoint.Element))
+
+	base := strings.TrimSuffix(filepath.Base(graphPath), filepath.Ext(graphPath))
+	for i := range comps {
+		path := filepath.Join(dir, fmt.Sprintf("%s_comp%d.hg", base, i+1))
+		f, err := os.Create(path)
+		check(err)
+		f.WriteString(comps[i].ToHyperBench())
+		f.Close()
+
+		fmt.Println("Component", i+1, "with", comps[i].Edges.Len(), "edges written to", path)
+	}
+	if len(isolated) > 0 {
+		fmt.Println("Edges fully covered by separator (not written):", lib.NewEdges(isolated))
+	}
+}
+
+// loadDecomp reads in a decomposition of graph, choosing the format based on the file extension: .gml for GML
+// (as used by DetKDecomp), .json for the JSON format of BalancedGo, and the PACE 2019 htd format otherwise
+func loadDecomp(path string, graph Graph, encoding map[string]int) Decomp {
+	dat, err := ioutil.ReadFile(path)
+	check(err)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gml":
+		return lib.GetDecompGML(string(dat), graph, encoding)
+	case ".json":
+		return lib.GetDecomp(dat, graph, encoding)
+	default:
+		return lib.GetDecompPACE(string(dat), graph, encoding)
+	}
+}
+
+// loadBags reads in candidate bags for graph, one bag per line, given as vertex names separated by spaces or commas.
+// Vertices of graphs in PACE format may also be given by their numbers alone.
+func loadBags(path string, graph Graph) ([][]int, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var output [][]int
+	for i, line := range strings.Split(string(dat), "\n") {
+		var bag []int
+		for _, name := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			v, ok := graph.VertexByName(name)
+			if !ok {
+				v, ok = graph.VertexByName("V" + name)
+			}
+			if !ok {
+				return nil, fmt.Errorf("line %v: no vertex %v in the graph", i+1, name)
+			}
+			bag = append(bag, v)
+		}
+		if len(bag) > 0 {
+			output = append(output, bag)
+		}
+	}
+
+	return output, nil
 }
 
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(verify(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "seps" {
+		os.Exit(seps(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		os.Exit(worker(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "gen" {
+		os.Exit(gen(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "trace" {
+		os.Exit(trace(os.Args[2:]))
+	}
+
 	// ==============================================
 	// Command-Line Argument Parsing
 
@@ -138,51 +286,277 @@ func main() {
 	flagSet.SetOutput(ioutil.Discard)
 
 	// input flags
-	graphPath := flagSet.String("graph", "", "input (for format see hyperbench.dbai.tuwien.ac.at/downloads/manual.pdf)")
+	graphPath := flagSet.String("graph", "", "input (for format see hyperbench.dbai.tuwien.ac.at/downloads/manual.pdf),"+
+		"\n\tor a directory or glob pattern to decompose each instance in it (see jobs)")
 	width := flagSet.Int("width", 0, "a positive, non-zero integer indicating the width of the GHD to search for")
 	exact := flagSet.Bool("exact", false, "Compute exact width (width flag ignored)")
 	approx := flagSet.Int("approx", 0, "Compute approximated width and set a timeout in seconds (width flag ignored)")
+	auto := flagSet.Bool("auto", false, "Compute exact width by searching only between a lower bound and the width "+
+		"of a greedy decomposition,\n\treporting the bounds as they close in (width flag ignored)")
 
 	// algorithms  flags
 	localBal := flagSet.Bool("local", false, "Use local BalSep algorithm")
 	globalBal := flagSet.Bool("global", false, "Use global BalSep algorithm")
+	vertexBal := flagSet.Bool("vertex", false, "Use BalSep with separators chosen as vertex sets, covered afterwards")
+	splitFlag := flagSet.Bool("split", false, "Use BalSep without backtracking, keeping the first balanced separator "+
+		"found,\n\tmuch faster but may fail at widths where a decomposition exists")
+	twFlag := flagSet.Bool("tw", false, "Compute a tree decomposition of the primal graph instead, of treewidth at "+
+		"most width\n\t(or of the smallest treewidth with \"exact\")")
+	tdFile := flagSet.String("td", "", "Used in combination with \"tw\": output the tree decomposition into the "+
+		"specified file, in PACE .td format")
+	bagsFile := flagSet.String("bags", "", "Use only the candidate bags in the given file, one per line as vertex "+
+		"names separated by spaces\n\tor commas, each coverable by at most width edges")
+	greedyFlag := flagSet.Bool("greedy", false, "Use the greedy decomposition by eliminating vertices of minimum "+
+		"degree, fast but often not of minimal width")
+	maxVertices := flagSet.Int("maxVertices", 0, "Used in combination with \"vertex\": maximal size of a separator, "+
+		"default is width times the largest edge size")
 	detKFlag := flagSet.Bool("det", false, "Use DetKDecomp algorithm")
+	rootWidth := flagSet.Int("rootwidth", 0, "Used in combination with \"det\": width of the cover of the root, "+
+		"larger or smaller than the width of the other nodes,\n\te.g. for query plans whose final projection can "+
+		"afford a larger bag")
+	enumerate := flagSet.Int("enumerate", 0, "Used in combination with \"det\" and \"width\": find up to the given "+
+		"number of structurally distinct decompositions\n\tinstead of just one, and report how many there are, "+
+		"e.g. as candidate plans for a query optimiser")
 	localBIP := flagSet.Bool("localbip", false, "Used in combination with \"det\": turns on local subedge handling")
+	cacheFile := flagSet.String("cachefile", "", "Used in combination with \"det\": reuse the failed separators "+
+		"stored in this file by earlier runs\n\ton the same graph, and store those of this run in it")
+	cacheSize := flagSet.String("cacheSize", "", "Used in combination with \"det\": approximate memory budget of the "+
+		"cache (e.g. 4GB),\n\tevicting the least recently used separators once it is exceeded")
 	balDetFlag := flagSet.Int("balDet", 0, "Use the Hybrid BalSep-DetK algorithm. Number indicates depth, must be ≥ 1")
 	seqBalDetFlag := flagSet.Int("seqBalDet", 0, "Use sequential Hybrid BalSep - DetK algorithm.")
+	hybridFlag := flagSet.Int("hybrid", 0, "Use the Hybrid BalSep-DetK algorithm, switching to DetK for components "+
+		"with at most the given number of edges")
+	algorithmFlag := flagSet.String("algorithm", "", "Use the algorithm of the given name, one of: "+
+		strings.Join(algo.Names(), ", ")+"\n\t(the same as the flag of that name, with depth 1 for balDet and seqBalDet)")
+	portfolioFlag := flagSet.String("portfolio", "", "Comma-separated list of algorithms (see algorithm) to run "+
+		"concurrently, e.g. balDet,split,local,\n\tusing the first decomposition found and cancelling the others")
 
 	// heuristic flags
 	heur := "1 ... Vertex Degree Ordering\n\t2 ... Max. Separator Ordering\n\t3 ... MCSO\n\t4 ... Edge Degree Ordering"
 	useHeuristic := flagSet.Int("heuristic", 0, "turn on to activate edge ordering\n\t"+heur)
 	gyö := flagSet.Bool("g", false, "perform a GYÖ reduct")
 	typeC := flagSet.Bool("t", false, "perform a Type Collapse")
+	componentsFlag := flagSet.Bool("components", false, "Decompose each connected component of the graph on its own, "+
+		"in parallel,\n\tjoining the decompositions under a fresh root")
+	twinsFlag := flagSet.Bool("twins", false, "Remove duplicate edges and collapse vertices contained in the same "+
+		"edges before the search,\n\tadding them back to the decomposition found")
 	hingeFlag := flagSet.Bool("h", false, "use hingeTree Optimization")
 
 	//other optional  flags
 	cpuprofile := flagSet.String("cpuprofile", "", "write cpu profile to file")
-	logging := flagSet.Bool("log", false, "turn on extensive logs")
+	var logging specValue
+	flagSet.Var(&logging, "log", "turn on logs up to the given level, for all components or by component, e.g. "+
+		"-log=info or\n\t-log=search=debug,cache=info, for the components "+strings.Join(lib.LogComponents(), ", ")+
+		" and the levels off, error, warn, info, debug;\n\t-log on its own turns on all of them at level debug")
 	computeSubedges := flagSet.Bool("sub", false, "turn off subedge computation for global option")
-	balanceFactorFlag := flagSet.Int("balfactor", 2, "Changes the factor that balanced separator check uses, default 2")
+	dedup := flagSet.Bool("dedup", false, "Used in combination with \"global\": decompose isomorphic components "+
+		"of a separator only once")
+	subedgeLimit := flagSet.Int("subedges", 0, "Add up to this many subedges to the hypergraph before the search, "+
+		"largest first, so that the search over its edges\n\tis complete for GHDs once all relevant ones are added "+
+		"(-1 for no bound, needs a fixed width and replaces the subedges of global)")
+	deferSub := flagSet.Bool("deferSub", false, "Used in combination with \"local\": only try subedges of "+
+		"separators once all separators were tried without them")
+	compOrder := flagSet.String("compOrder", "found", "Order in which the components of a separator are decomposed, "+
+		"one of: "+strings.Join(lib.ComponentOrders(), ", ")+"\n\t(largest fails fast on infeasible widths, smallest "+
+		"finds easy wins early; local, global, det, balDet, hybrid, seqBalDet and split only)")
+	heuristicOrder := flagSet.String("heuristicOrder", "none", "Order in which the edges are tried for separators, "+
+		"one of: "+strings.Join(lib.SeparatorOrders(), ", ")+"\n\t(coverage prefers edges with many vertices of "+
+		"the subgraph, degree those intersecting many of its edges; local, global, balDet, hybrid, seqBalDet and split only)")
+	restart := flagSet.Duration("restart", 0, "Restart the search after the given time without an answer, doubling "+
+		"the time with each restart,\n\tand starting the searches at random edges unless another heuristicOrder is "+
+		"chosen (local, global, balDet, hybrid, seqBalDet and split only)")
+	checkpoint := flagSet.String("checkpoint", "", "Save the state of the search for separators at the root into the "+
+		"given file, and resume from it if it exists,\n\tskipping the separators rejected before a crash or "+
+		"preemption (local, global, balDet, hybrid and seqBalDet only)")
+	checkpointInterval := flagSet.Duration("checkpointInterval", time.Minute, "Used in combination with "+
+		"\"checkpoint\": how often the state is saved")
+	stats := flagSet.Bool("stats", false, "Print statistics of the hypergraph, such as degree and arity distributions "+
+		"and a lower bound on the width\n\t(no decomposition is computed)")
+	selfCheckFlag := flagSet.Bool("selfcheck", false, "Compare the result with the width computed by brute force, "+
+		"for graphs of at most "+fmt.Sprint(lib.BruteForceLimit)+" vertices\n\t(panics on any contradiction)")
+	featuresPath := flagSet.String("features", "", "Write structural metrics of the hypergraph as JSON into the given "+
+		"file, e.g. for ML research,\n\tincluding probe statistics if an algorithm and width are chosen (see probe)")
+	probe := flagSet.Int("probe", 0, "Decompose the given number of random induced subgraphs first, to quickly "+
+		"estimate if the width is plausible")
+	fractional := flagSet.Bool("fractional", false, "Replace the covers of the decomposition found by optimal "+
+		"fractional edge covers of the bags,\n\tand report the fractional width")
+	balanceMeasure := flagSet.String("balance", "edges", "How the components of a separator are measured to check "+
+		"that it's balanced, one of: "+strings.Join(lib.BalanceMeasures(), ", ")+"\n\t(anything but edges may miss "+
+		"decompositions; local, global, balDet, hybrid, seqBalDet and split only)")
+	maxComps := flagSet.Int("maxComps", 0, "If positive, only separators leaving at most this many components are "+
+		"balanced (local, global, balDet, hybrid, seqBalDet and split only)")
+	smallWidthFlag := flagSet.Bool("smallWidth", false, "Use specialised procedures for the widths 1 and 2, a join "+
+		"tree via GYÖ reduction and a search\n\tfor hypertree decompositions restricted to covers of two edges, before "+
+		"the chosen algorithm")
+	hdFlag := flagSet.Bool("hd", false, "Compute a hypertree decomposition, satisfying the special condition, "+
+		"instead of a GHD\n\t(det without localbip only, the output is checked for the special condition)")
+	balanceFactorFlag := flagSet.Int("balfactor", 2, "Factor b of the balanced separators, leaving no component with "+
+		"more than (b-1)/b of the edges,\n\tat least 2; larger factors admit more separators, shrinking the search "+
+		"space,\n\tbut the components shrink more slowly, deepening the recursion")
 	numCPUs := flagSet.Int("cpu", -1, "Set number of CPUs to use")
+	procs := flagSet.Int("procs", 0, "Maximal number of goroutines decomposing components in parallel, further "+
+		"components are decomposed inline,\n\tdefault is the number of CPUs used")
+	deterministic := flagSet.Bool("deterministic", false, "Run single-threaded, trying separators and components "+
+		"in a fixed order,\n\tso that every run produces the same decomposition (overrides cpu and procs)")
+	maxGoroutines := flagSet.Int("maxgoroutines", 0, "Maximal number of goroutines spawned by all algorithms "+
+		"together, for search workers and components alike,\n\twork beyond it is done inline, default is no bound")
+	noPool := flagSet.Bool("noPool", false, "Allocate the scratch space for computing components anew each time, "+
+		"instead of reusing it")
+	memInterval := flagSet.Duration("memreport", 0, "Report approximate memory usage of the data structures "+
+		"on stderr in the given interval (e.g. 10s)")
+	progressInterval := flagSet.Duration("progress", 0, "Report the progress of the search on stderr in the given "+
+		"interval (e.g. 10s): separators checked per generator,\n\trecursive calls by depth, cache hit rate and "+
+		"goroutines")
 	bench := flagSet.Bool("bench", false, "Benchmark mode, reduces unneeded output (incompatible with -log flag)")
 	gml := flagSet.String("gml", "", "Output the produced decomposition into the specified gml file ")
+	dot := flagSet.String("dot", "", "Output the produced decomposition into the specified dot file, for Graphviz")
 	jsonFlag := flagSet.String("json", "", "Output the produced decomposition into the specified json file ")
+	certFlag := flagSet.String("cert", "", "Output a certificate of the produced decomposition into the specified "+
+		"file, to be checked by cmd/verifycert")
 	pace := flagSet.Bool("pace", false, "Use PACE 2019 format for graphs (see pacechallenge.org/2019/htd/htd_format/)")
+	formatFlag := flagSet.String("format", "hyperbench", "Input format of the hypergraph, one of: "+
+		strings.Join(lib.Formats(), ", ")+"\n\t(incidence expects one \"vertex edge\" pair per line)")
 	complete := flagSet.Bool("complete", false, "Forces the computation of complete decompositions.")
 	jCostPath := flagSet.String("joinCost", "", "The file path to a join cost function.")
+	weightsPath := flagSet.String("weights", "", "Read weights of edges, e.g. the sizes of the relations, from "+
+		"\"edge name,weight\" lines in the given CSV file,\n\tand report the weighted width (largest total weight "+
+		"of a cover)")
+	minWeight := flagSet.Bool("minWeight", false, "Used in combination with \"local\" and \"weights\": search for "+
+		"a decomposition of the given width whose covers weigh as little as possible")
+	sepComps := flagSet.String("sepComps", "", "Comma-separated list of edge names, writes each component "+
+		"w.r.t. this separator into its own .hg file (no decomposition is computed)")
+	compDir := flagSet.String("compDir", ".", "Output directory for the files produced by sepComps")
+	dumpDir := flagSet.String("dumpSubtrees", "", "Write each decomposed subgraph together with its subtree into "+
+		"the given directory,\n\tin the order they are produced (local, global and det only)")
+	traceFlag := flagSet.String("traceSearch", "", "Debugging: record all separators tried and the components they "+
+		"produce,\n\twritten as JSON if the file ends in .json and as Graphviz DOT otherwise (local, global and det "+
+		"only, small instances)")
+	recursionFlag := flagSet.String("traceRecursion", "", "Record each recursive call, with the size of its "+
+		"subgraph, the separators tried and chosen, its outcome and duration,\n\twritten as JSON if the file ends "+
+		"in .json and in a compact binary format otherwise (local, global and det only),\n\tsee the trace "+
+		"subcommand for converting it into a Graphviz DOT tree or a flame graph")
+	checkPath := flagSet.String("check", "", "Validate the decomposition in the given file (.gml, .json or PACE .htd), "+
+		"e.g. produced by another solver,\n\tand compare its width against the chosen algorithm, if any")
+	checkDirFlag := flagSet.String("checkDir", "", "Validate all decompositions (.gml, .json or PACE .htd) in the "+
+		"given directory in parallel,\n\teach against the graph file of the same base name (graph flag not needed)")
+	checkCSV := flagSet.String("checkCSV", "check.csv", "Used in combination with \"checkDir\": file for the summary "+
+		"of widths and validity")
+	benchDirFlag := flagSet.String("benchDir", "", "Run the algorithms given by \"benchAlgos\" on all graphs in "+
+		"the given directory at each width given by \"benchWidths\",\n\teach run bounded by \"timeout\" "+
+		"(graph flag not needed)")
+	benchAlgos := flagSet.String("benchAlgos", "local,det", "Used in combination with \"benchDir\": "+
+		"comma-separated list of algorithms, out of: "+strings.Join(algo.Names(), ", "))
+	benchWidths := flagSet.String("benchWidths", "1-3", "Used in combination with \"benchDir\": width or range of "+
+		"widths, such as 2-5")
+	benchOut := flagSet.String("benchOut", "bench.csv", "Used in combination with \"benchDir\": file for the "+
+		"results of all runs,\n\twith time, width found, correctness and memory allocated (JSON if it ends in .json)")
+	serveAddr := flagSet.String("serve", "", "Serve decompositions over HTTP on the given address (e.g. :8080), "+
+		"answering POST requests to /decompose\n\twith a hypergraph, width, algorithm and timeout (graph flag not "+
+		"needed)")
+	maxJobs := flagSet.Int("maxJobs", 0, "Used in combination with \"serve\": maximal number of decompositions run "+
+		"at the same time, further requests are turned away (no limit if 0)")
+	minimize := flagSet.Bool("minimize", false, "Post-process the decomposition found: remove nodes whose bag is "+
+		"contained in a neighbour's,\n\tmerge adjacent nodes if the width allows and shrink covers to minimal sets "+
+		"(the special condition of HDs may be lost)")
+	improve := flagSet.Duration("improve", 0, "Post-process the decomposition found: lower its width by local "+
+		"search for at most the given time (e.g. 30s),\n\tgiving nodes smaller covers or splitting them in two "+
+		"(the special condition of HDs may be lost)")
+	rootFlag := flagSet.String("root", "", "Comma-separated list of vertices, such as the output variables of a "+
+		"query: reroot the decomposition found\n\tat a node whose bag contains all of them")
+	evalCSV := flagSet.String("evalCSV", "", "Evaluate the hypergraph as conjunctive query along the produced "+
+		"decomposition,\n\treading the relation of each edge from <edge name>.csv in the given directory")
+	evalHeader := flagSet.Bool("evalHeader", false, "Used in combination with \"evalCSV\": the first line of each "+
+		"CSV file names the columns")
+	planPath := flagSet.String("plan", "", "Write the plan for evaluating the hypergraph as conjunctive query along "+
+		"the produced decomposition\n\tto a file, as a single SQL query if its name ends in .sql and as JSON otherwise")
+
+	configPath := flagSet.String("config", "", "Load a pipeline configuration (.json, .yaml or .yml) setting any of "+
+		"these flags,\n\tflags given on the command line take precedence")
+	timeout := flagSet.Duration("timeout", 0, "Abort the computation after the given duration (e.g. 30s or 10m)")
+	jobs := flagSet.Int("jobs", 1, "Used with a directory or glob pattern as graph: number of instances decomposed "+
+		"at the same time,\n\teach by the algorithm given by its flag, bounded by \"timeout\" and written as one "+
+		"line of JSON")
 
 	parseError := flagSet.Parse(os.Args[1:])
+	if parseError == nil && *configPath != "" {
+		var settings map[string]string
+		settings, parseError = loadConfig(*configPath)
+		if parseError == nil {
+			parseError = applyConfig(flagSet, settings)
+		}
+	}
+	if parseError == nil {
+		parseError = lib.CheckBalFactor(*balanceFactorFlag)
+	}
 	if parseError != nil {
 		fmt.Print("Parse Error:\n", parseError.Error(), "\n\n")
 	}
 
+	if parseError == nil && *checkDirFlag != "" {
+		if *pace {
+			*formatFlag = "pace"
+		}
+		checkDir(*checkDirFlag, *formatFlag, *checkCSV)
+		return
+	}
+	if parseError == nil && *benchDirFlag != "" {
+		if *pace {
+			*formatFlag = "pace"
+		}
+		benchDir(*benchDirFlag, *formatFlag, *benchAlgos, *benchWidths, *benchOut, *balanceFactorFlag, *timeout)
+		return
+	}
+	if parseError == nil && *serveAddr != "" {
+		if err := serve(*serveAddr, *maxJobs, *balanceFactorFlag, *timeout); err != nil {
+			fmt.Println("Server stopped: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if parseError == nil && *graphPath != "" && isBatchInput(*graphPath) && (*width > 0 || *exact) {
+		if *pace {
+			*formatFlag = "pace"
+		}
+		name := *algorithmFlag
+		switch {
+		case name != "":
+		case *localBal:
+			name = "local"
+		case *globalBal:
+			name = "global"
+		case *detKFlag:
+			name = "det"
+		case *vertexBal:
+			name = "vertex"
+		case *splitFlag:
+			name = "split"
+		case *greedyFlag:
+			name = "greedy"
+		}
+		if name == "" {
+			fmt.Println("Choose an algorithm for the instances, e.g. via \"algorithm\".")
+			return
+		}
+		if *exact && name == "greedy" {
+			fmt.Println("The greedy decomposition may miss any width, it cannot compute the exact width.")
+			return
+		}
+		paths, err := batchPaths(*graphPath)
+		check(err)
+		batch(paths, *formatFlag, name, *width, *exact, *balanceFactorFlag, *timeout, *jobs)
+		return
+	}
+
 	// Output usage message if graph and width not specified
-	if parseError != nil || *graphPath == "" || (*width <= 0 && !*exact && *approx == 0) {
+	if parseError != nil || *graphPath == "" || (*width <= 0 && !*exact && *approx == 0 && !*auto && *sepComps == "" &&
+		*checkPath == "" && *featuresPath == "" &&
+		!*stats) {
 		out := fmt.Sprint("Usage of BalancedGo (", Version, ", https://github.com/cem-okulmus/BalancedGo/commit/",
 			Build, ", ", Date, ")")
 		fmt.Fprintln(os.Stderr, out)
 		flagSet.VisitAll(func(f *flag.Flag) {
-			if f.Name != "width" && f.Name != "graph" && f.Name != "exact" && f.Name != "approx" {
+			if f.Name != "width" && f.Name != "graph" && f.Name != "exact" && f.Name != "approx" && f.Name != "auto" {
 				return
 			}
 			s := fmt.Sprintf("%T", f.Value) // used to get type of flag
@@ -196,7 +570,7 @@ func main() {
 
 		fmt.Println("\nOptional Arguments: ")
 		flagSet.VisitAll(func(f *flag.Flag) {
-			if f.Name == "width" || f.Name == "graph" || f.Name == "exact" || f.Name == "approx" {
+			if f.Name == "width" || f.Name == "graph" || f.Name == "exact" || f.Name == "approx" || f.Name == "auto" {
 				return
 			}
 			s := fmt.Sprintf("%T", f.Value) // used to get type of flag
@@ -218,6 +592,57 @@ func main() {
 		fmt.Println("Cannot have exact and approx flags set at the same time. Make up your mind.")
 		return
 	}
+	if *auto && (*exact || *approx > 0) {
+		fmt.Println("The auto flag already computes the exact width, it cannot be combined with exact or approx.")
+		return
+	}
+
+	// the algorithms of this package are set up by their own flags, which take more options than the registry, others
+	// registered by name are set up with the common settings
+	var registered algo.Factory
+	if *algorithmFlag != "" {
+		factory, err := algo.Get(*algorithmFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		switch *algorithmFlag {
+		case "local":
+			*localBal = true
+		case "global":
+			*globalBal = true
+		case "det":
+			*detKFlag = true
+		case "balDet":
+			if *balDetFlag == 0 {
+				*balDetFlag = 1
+			}
+		case "seqBalDet":
+			if *seqBalDetFlag == 0 {
+				*seqBalDetFlag = 1
+			}
+		case "vertex":
+			*vertexBal = true
+		case "split":
+			*splitFlag = true
+		case "greedy":
+			*greedyFlag = true
+		default:
+			registered = factory
+		}
+	}
+	if *greedyFlag && (*exact || *auto) {
+		fmt.Println("The greedy decomposition may miss any width, it cannot compute the exact width.")
+		return
+	}
+
+	// the context is passed on to the algorithms via their search generator, cancelling all workers once done
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
 
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
@@ -230,24 +655,82 @@ func main() {
 	}
 
 	if *bench { // no logging output when running benchmarks
-		*logging = false
+		check(logging.Set("false"))
 	}
-	logActive(*logging)
+	logActive(lib.LoggingEnabled(lib.LogError))
 
 	BalFactor := *balanceFactorFlag
+	lib.SetPooling(!*noPool)
 
-	runtime.GOMAXPROCS(*numCPUs)
+	if *deterministic {
+		// a single CPU also fixes how the search space is split among the generators
+		runtime.GOMAXPROCS(1)
+		algo.Sequential()
+	} else {
+		runtime.GOMAXPROCS(*numCPUs)
+		algo.SetProcs(*procs)
+	}
+	lib.Goroutines.SetMax(*maxGoroutines)
 
 	dat, err := ioutil.ReadFile(*graphPath)
 	check(err)
 
-	var parsedGraph Graph
-	var parseGraph lib.ParseGraph
+	if *pace {
+		*formatFlag = "pace"
+	}
 
-	if !*pace {
-		parsedGraph, parseGraph = lib.GetGraph(string(dat))
-	} else {
-		parsedGraph = lib.GetGraphPACE(string(dat))
+	parsedGraph, parseGraph, err := lib.GetGraphFormat(*formatFlag, string(dat))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if *weightsPath != "" {
+		f, err := os.Open(*weightsPath)
+		check(err)
+		weights, err := lib.ReadWeights(f)
+		f.Close()
+		if err == nil {
+			parsedGraph, err = parsedGraph.SetWeights(weights)
+		}
+		if err != nil {
+			fmt.Println("Couldn't use weights:", err)
+			return
+		}
+	}
+
+	if *stats {
+		printStats(parsedGraph)
+		return
+	}
+
+	if *sepComps != "" {
+		exportComponents(parsedGraph, *sepComps, *compDir, *graphPath)
+		return
+	}
+
+	if *twFlag { // on the graph as parsed, as some of the preprocessing changes the primal graph
+		treeDecomposition(ctx, parsedGraph, *width, *exact, BalFactor, *tdFile, *deterministic)
+		return
+	}
+
+	var features instanceFeatures
+	if *featuresPath != "" { // computed before any preprocessing changes the graph
+		features = instanceFeatures{Graph: *graphPath, Features: parsedGraph.Features()}
+	}
+
+	checkedWidth := 0
+	if *checkPath != "" {
+		checked := loadDecomp(*checkPath, parsedGraph, parseGraph.Encoding)
+		correct := checked.Correct(parsedGraph)
+
+		fmt.Println("Checked decomposition: ", *checkPath)
+		fmt.Println("Width: ", checked.CheckWidth())
+		fmt.Println("Correct: ", correct)
+		if correct {
+			checkedWidth = checked.CheckWidth()
+		}
+		fmt.Println()
 	}
 
 	originalGraph := parsedGraph
@@ -293,6 +776,16 @@ func main() {
 			fmt.Printf("Ordering: %v\n", parsedGraph.String())
 		}
 	}
+	var twins lib.TwinReduct
+	if *twinsFlag {
+		reducedGraph, twins = parsedGraph.RemoveTwins()
+		parsedGraph = reducedGraph
+		if !*bench { // be silent when benchmarking
+			vertices, edges := twins.Count()
+			fmt.Print("Removed ", vertices, " twin vertices and ", edges, " duplicate edges\n\n")
+		}
+	}
+
 	var removalMap map[int][]int
 	// Performing Type Collapse
 	if *typeC {
@@ -336,7 +829,22 @@ func main() {
 	}
 
 	// Add all subedges to graph
-	if *globalBal && !*computeSubedges {
+	allSubedges := false
+	if *subedgeLimit != 0 {
+		if *width <= 0 {
+			fmt.Println("Subedges can only be added for a fixed width")
+			return
+		}
+		edges := parsedGraph.Edges.Len()
+		parsedGraph, allSubedges = parsedGraph.AddSubEdges(*width, *subedgeLimit)
+
+		if !*bench {
+			fmt.Println("Added", parsedGraph.Edges.Len()-edges, "subedges")
+			if !allSubedges {
+				fmt.Println("Not all relevant subedges were added, the search may miss decompositions")
+			}
+		}
+	} else if *globalBal && !*computeSubedges {
 		parsedGraph = parsedGraph.ComputeSubEdges(*width)
 
 		fmt.Println("Graph with subedges \n", parsedGraph)
@@ -360,7 +868,34 @@ func main() {
 		}
 	}
 
+	order, err := lib.ParseComponentOrder(*compOrder)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	sepOrder, err := lib.ParseSeparatorOrder(*heuristicOrder)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if *restart > 0 && sepOrder == lib.Lexicographic {
+		sepOrder = lib.RandomOffset // restarting the same search would only repeat it
+	}
+	if *deterministic {
+		lib.SeedRandomOffsets(1)
+	}
+	measure, err := lib.ParseBalanceMeasure(*balanceMeasure)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var balance lib.Predicate // BalancedCheck, unless another measure or a bound on the components is chosen
+	if measure != lib.ByEdges || *maxComps > 0 {
+		balance = measure.Predicate(*maxComps)
+	}
+
 	var solver algo.Algorithm
+	var weighted *algo.BalSepLocal // used if the weight is to be minimized
 
 	// Check for multiple flags
 	chosen := 0
@@ -371,17 +906,38 @@ func main() {
 			Graph:     parsedGraph,
 			BalFactor: BalFactor,
 			Depth:     *balDetFlag - 1,
+			Order:     order,
+			SepOrder:  sepOrder,
+			Balance:   balance,
 		}
 		solver = balDet
 		chosen++
 	}
 
+	if *hybridFlag > 0 {
+		hybrid := &algo.BalSepHybrid{
+			K:         *width,
+			Graph:     parsedGraph,
+			BalFactor: BalFactor,
+			Depth:     algo.UnboundedDepth,
+			Size:      *hybridFlag,
+			Order:     order,
+			SepOrder:  sepOrder,
+			Balance:   balance,
+		}
+		solver = hybrid
+		chosen++
+	}
+
 	if *seqBalDetFlag > 0 {
 		seqBalDet := &algo.BalSepHybridSeq{
 			K:         *width,
 			Graph:     parsedGraph,
 			BalFactor: BalFactor,
 			Depth:     *seqBalDetFlag - 1,
+			Order:     order,
+			SepOrder:  sepOrder,
+			Balance:   balance,
 		}
 		solver = seqBalDet
 		chosen++
@@ -393,6 +949,7 @@ func main() {
 			Graph:     parsedGraph,
 			BalFactor: BalFactor,
 			SubEdge:   *localBIP,
+			Order:     order,
 		}
 		solver = det
 		chosen++
@@ -403,18 +960,100 @@ func main() {
 			K:         *width,
 			Graph:     parsedGraph,
 			BalFactor: BalFactor,
+			Dedup:     *dedup,
+			Order:     order,
+			SepOrder:  sepOrder,
+			Balance:   balance,
 		}
 		solver = global
 		chosen++
 	}
 
-	if *localBal {
-		local := &algo.BalSepLocal{
+	if *vertexBal {
+		vertex := &algo.BalSepVertex{
+			K:           *width,
+			Graph:       parsedGraph,
+			BalFactor:   BalFactor,
+			MaxVertices: *maxVertices,
+		}
+		solver = vertex
+		chosen++
+	}
+
+	if *splitFlag {
+		solver = &algo.BalSepSplit{
+			K:         *width,
+			Graph:     parsedGraph,
+			BalFactor: BalFactor,
+			Order:     order,
+			SepOrder:  sepOrder,
+			Balance:   balance,
+		}
+		chosen++
+	}
+
+	if *greedyFlag {
+		solver = &algo.GreedyDecomp{K: *width, Graph: parsedGraph}
+		chosen++
+	}
+
+	var portfolio *algo.Portfolio // to report the algorithm which won the race
+	if *portfolioFlag != "" {
+		var err error
+		portfolio, err = algo.NewPortfolio(strings.Split(*portfolioFlag, ","), algo.Config{
+			K:         *width,
+			Graph:     parsedGraph,
+			BalFactor: BalFactor,
+			Order:     order,
+			SepOrder:  sepOrder,
+			Balance:   balance,
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if *deterministic {
+			fmt.Println("A portfolio races its algorithms against each other, so it can't be deterministic")
+			return
+		}
+		solver = portfolio
+		chosen++
+	}
+
+	if registered != nil {
+		solver = registered(algo.Config{
 			K:         *width,
 			Graph:     parsedGraph,
 			BalFactor: BalFactor,
+			Order:     order,
+			SepOrder:  sepOrder,
+			Balance:   balance,
+		})
+		chosen++
+	}
+
+	if *bagsFile != "" {
+		bags, err := loadBags(*bagsFile, parsedGraph)
+		if err != nil {
+			fmt.Println("Couldn't read candidate bags:", err)
+			return
+		}
+		solver = &algo.CandidateDecomp{K: *width, Graph: parsedGraph, Bags: bags}
+		chosen++
+	}
+
+	if *localBal {
+		local := &algo.BalSepLocal{
+			K:             *width,
+			Graph:         parsedGraph,
+			BalFactor:     BalFactor,
+			DeferSubedges: *deferSub,
+			Order:         order,
+			SepOrder:      sepOrder,
+			Balance:       balance,
 		}
 		solver = local
+		weighted = local
 		chosen++
 	}
 
@@ -423,12 +1062,30 @@ func main() {
 		return
 	}
 
+	// DetK without subedges enforces the special condition during search: the bag of each node holds all vertices of
+	// its cover inside the current component, and the subtree below only holds vertices of the component
+	if *hdFlag && (!*detKFlag || *localBIP || *fractional || *subedgeLimit != 0) {
+		fmt.Println("Hypertree decompositions can only be computed by det, without localbip, subedges and " +
+			"fractional covers")
+		return
+	}
+	if *hdFlag && *improve > 0 {
+		fmt.Println("Improving the width may violate the special condition, so it can't be combined with hd")
+		return
+	}
+
+	if *minWeight && (weighted == nil || *exact || *approx > 0 || *auto || *hingeFlag || *jCostPath != "") {
+		fmt.Println("Minimizing the weight is only supported by local, for a fixed width and without hinge trees " +
+			"or join costs")
+		return
+	}
+
 	if *jCostPath != "" {
 		if !*localBal && *balDetFlag == 0 {
 			fmt.Println("Join cost can be used only in combination with: local, balDet.")
 			return
 		}
-		if *pace {
+		if *formatFlag == "pace" {
 			fmt.Println("Join cost cannot be used with PACE input format.")
 			return
 		}
@@ -463,7 +1120,12 @@ func main() {
 			rec := record[:last]
 			comb := make([]int, len(rec))
 			for p, s := range rec {
-				comb[p] = parseGraph.Encoding[s]
+				e, ok := parsedGraph.EdgeByName(s)
+				if !ok {
+					fmt.Println("Edge", s, "of join cost file not found in hypergraph.")
+					return
+				}
+				comb[p] = e.Name
 			}
 			sort.Ints(comb)
 			w.Put(comb, cost)
@@ -497,18 +1159,305 @@ func main() {
 		}
 	}
 
+	if *featuresPath != "" {
+		if solver != nil && *width > 0 {
+			samples := *probe
+			if samples <= 0 {
+				samples = defaultFeatureSamples
+			}
+			features.Probe = probeInstance(solver, lib.ParallelSearchGen{Ctx: ctx, Sequential: *deterministic},
+				parsedGraph, *width, samples)
+		}
+		writeFeatures(features, *featuresPath)
+	}
+
+	if *rootWidth > 0 {
+		if det, ok := solver.(*algo.DetKDecomp); ok {
+			det.RootK = *rootWidth
+		} else {
+			fmt.Println("A root width is only supported by DetK")
+			return
+		}
+	}
+
+	if *restart > 0 {
+		switch solver.(type) {
+		case *algo.BalSepLocal, *algo.BalSepGlobal, *algo.BalSepHybrid, *algo.BalSepHybridSeq, *algo.BalSepSplit:
+		default:
+			fmt.Println("Restarts are only supported by local, global, balDet, hybrid, seqBalDet and split")
+			return
+		}
+	}
+
+	if *checkpoint != "" {
+		switch solver.(type) {
+		case *algo.BalSepLocal, *algo.BalSepGlobal, *algo.BalSepHybrid, *algo.BalSepHybridSeq:
+		default:
+			fmt.Println("A checkpoint is only supported by local, global, balDet, hybrid and seqBalDet")
+			return
+		}
+		switch {
+		case *restart > 0 || sepOrder == lib.RandomOffset:
+			fmt.Println("A checkpoint can't be resumed with restarts or the random heuristicOrder, as the " +
+				"separators are tried in another order in each run")
+			return
+		case *deferSub:
+			fmt.Println("A checkpoint doesn't support deferSub, as separators are only rejected once their " +
+				"subedges were tried")
+			return
+		case *checkpointInterval <= 0:
+			fmt.Println("The checkpointInterval must be positive")
+			return
+		}
+	}
+
+	if *enumerate > 0 {
+		det, ok := solver.(*algo.DetKDecomp)
+		switch {
+		case !ok:
+			fmt.Println("Enumeration is only supported by DetK")
+		case *exact || *approx > 0 || *auto || *rootWidth > 0 || *localBIP:
+			fmt.Println("Enumeration needs a fixed width, and supports neither a root width nor local subedges")
+		case *typeC || *gyö || *twinsFlag || *complete:
+			fmt.Println("Enumeration doesn't support the reductions t, g and twins, nor complete decompositions")
+		default:
+			enumerateDecomps(ctx, det, originalGraph, *enumerate, *jsonFlag)
+		}
+		return
+	}
+
+	if solver != nil && !*exact && *approx == 0 && !*auto && *rootWidth <= *width {
+		if infeasible, reason := parsedGraph.Infeasible(*width); infeasible {
+			fmt.Println("No decomposition of width", *width, "exists:", reason)
+			return
+		}
+	}
+
 	if solver != nil {
 
-		solver.SetGenerator(lib.ParallelSearchGen{})
+		var gen lib.SearchGenerator = lib.ParallelSearchGen{Ctx: ctx, Sequential: *deterministic}
+		if *checkpoint != "" {
+			checkpointGen := lib.NewCheckpointGen(gen, parsedGraph, fmt.Sprint(solver.Name(), ", balance factor ",
+				*balanceFactorFlag, ", ", *heuristicOrder, " order"))
+			if state, err := ioutil.ReadFile(*checkpoint); err == nil {
+				if err = checkpointGen.Restore(state); err != nil {
+					fmt.Println("Couldn't resume from checkpoint:", err)
+					return
+				}
+				fmt.Println("Resuming from checkpoint", *checkpoint)
+			} else if !os.IsNotExist(err) {
+				fmt.Println(err)
+				return
+			}
+			checkpointGen.Interval = *checkpointInterval
+			checkpointGen.Save = func(state []byte) { saveCheckpoint(*checkpoint, state) }
+			defer checkpointGen.Flush() // also once the time limit is reached
+			gen = checkpointGen
+		}
+		solver.SetGenerator(gen)
+
+		var dumper *lib.SubtreeDumper
+		if *dumpDir != "" {
+			check(os.MkdirAll(*dumpDir, 0755))
+			dumper = &lib.SubtreeDumper{Dir: *dumpDir}
+			switch s := solver.(type) {
+			case *algo.BalSepLocal:
+				s.Dumper = dumper
+			case *algo.BalSepGlobal:
+				s.Dumper = dumper
+			case *algo.DetKDecomp:
+				s.Dumper = dumper
+			default:
+				fmt.Println("Dumping subtrees is not supported by", solver.Name())
+			}
+		}
+
+		if *traceFlag != "" {
+			trace := &lib.SearchTrace{}
+			switch s := solver.(type) {
+			case *algo.BalSepLocal:
+				s.Trace = trace
+			case *algo.BalSepGlobal:
+				s.Trace = trace
+			case *algo.DetKDecomp:
+				s.Trace = trace
+			default:
+				fmt.Println("Tracing the search is not supported by", solver.Name())
+			}
+			defer func() {
+				var out []byte
+				if strings.HasSuffix(*traceFlag, ".json") {
+					var err error
+					out, err = trace.ToJSON()
+					check(err)
+				} else {
+					out = []byte(trace.ToDOT())
+				}
+				check(ioutil.WriteFile(*traceFlag, out, 0644))
+				if trace.Truncated {
+					fmt.Println("Search trace truncated after", trace.Len(), "nodes")
+				}
+			}()
+		}
+
+		if *recursionFlag != "" {
+			recursion := &lib.RecursionTrace{}
+			switch s := solver.(type) {
+			case *algo.BalSepLocal:
+				s.Recursion = recursion
+			case *algo.BalSepGlobal:
+				s.Recursion = recursion
+			case *algo.DetKDecomp:
+				s.Recursion = recursion
+			default:
+				fmt.Println("Tracing the recursion is not supported by", solver.Name())
+			}
+			defer func() {
+				var out []byte
+				var err error
+				if strings.HasSuffix(*recursionFlag, ".json") {
+					out, err = recursion.ToJSON()
+				} else {
+					out, err = recursion.GobEncode()
+				}
+				check(err)
+				check(ioutil.WriteFile(*recursionFlag, out, 0644))
+				if recursion.Truncated {
+					fmt.Println("Recursion trace truncated after", recursion.Len(), "calls")
+				}
+			}()
+		}
+
+		if *cacheFile != "" {
+			if det, ok := solver.(*algo.DetKDecomp); ok {
+				if f, err := os.Open(*cacheFile); err == nil {
+					if err := det.LoadCache(f); err != nil {
+						fmt.Println("Couldn't use cache file", *cacheFile, ":", err)
+					}
+					f.Close()
+				}
+				defer func() {
+					f, err := os.Create(*cacheFile)
+					check(err)
+					defer f.Close()
+					check(det.SaveCache(f))
+				}()
+			} else {
+				fmt.Println("A cache file is only supported by DetK")
+			}
+		}
+
+		if *cacheSize != "" {
+			limit, err := lib.ParseBytes(*cacheSize)
+			check(err)
+			if det, ok := solver.(*algo.DetKDecomp); ok {
+				det.SetCacheLimit(limit)
+				defer func() {
+					if n := det.CacheEvictions(); n > 0 {
+						fmt.Println("Separators evicted from the cache:", n)
+					}
+				}()
+			} else {
+				fmt.Println("A cache size is only supported by DetK")
+			}
+		}
+
+		var memReport lib.MemReport // also used for the progress reported on SIGUSR1
+		memReport.Add("graph", parsedGraph.MemSize)
+		if det, ok := solver.(*algo.DetKDecomp); ok {
+			memReport.Add("cache", det.CacheMemSize)
+		}
+		if *memInterval > 0 {
+			stop := memReport.Start(*memInterval, os.Stderr)
+			defer stop()
+		}
+
+		var restarts *algo.Restarts
+		if *restart > 0 {
+			restarts = algo.NewRestarts(*width, parsedGraph, solver, *restart,
+				lib.ParallelSearchGen{Ctx: ctx, Sequential: *deterministic})
+			solver = restarts
+		}
+
+		var smallWidth *algo.SmallWidth
+		if *smallWidthFlag && *jCostPath == "" && *rootWidth == 0 {
+			smallWidth = &algo.SmallWidth{K: *width, Graph: parsedGraph, Fallback: solver}
+			smallWidth.SetGenerator(gen)
+			solver = smallWidth
+		}
+
+		if *componentsFlag {
+			solver = &algo.ComponentSplit{K: *width, Graph: parsedGraph, Inner: solver, Generator: gen}
+			if !*bench {
+				fmt.Println("Connected components:", len(parsedGraph.ComponentsSplit()))
+			}
+		}
+
+		if *fractional {
+			solver = &algo.Fractional{K: *width, Graph: parsedGraph, Inner: solver}
+		}
+
+		status := &progress{start: time.Now(), solver: solver.Name(), width: int32(*width), mem: &memReport}
+		stopSignals := watchSignals(status, lib.LoggingEnabled(lib.LogError))
+		defer stopSignals()
+		if *progressInterval > 0 {
+			stopProgress := status.Start(*progressInterval, os.Stderr)
+			defer stopProgress()
+		}
+
+		if *probe > 0 && !*exact && *approx == 0 && !*auto {
+			seed := time.Now().UnixNano()
+			if *deterministic {
+				seed = 1
+			}
+			r := rand.New(rand.NewSource(seed))
+			startProbe := time.Now()
+			result := algo.Probe(solver, parsedGraph, *probe, r)
+			fmt.Printf("Probe (%v): %v\n", time.Since(startProbe), result)
+			if !result.Plausible() {
+				fmt.Println("Skipping full search, no decomposition of width", *width, "exists.")
+				return
+			}
+		}
 
 		var decomp Decomp
+		gapClosed := false // set by the auto mode once the width is known
 		start := time.Now()
 
 		if *exact {
-			solved := false
-			k := 1
-			for ; !solved; k++ {
+			// Widths are tried in increasing order. Negative cache entries only hold for the width they were found
+			// at and smaller ones, as any subgraph fails only for too few edges, so they are dropped by SetWidth, but
+			// widths below the lower bound are skipped right away.
+			k := lib.LowerBound(parsedGraph)
+			if k > 1 && !*bench {
+				fmt.Println("Skipping widths below the lower bound", k)
+			}
+
+			// the greedy decomposition bounds the widths to be tried from above, but needn't be an HD
+			var upper Decomp
+			if !*hdFlag {
+				upper = algo.GreedyDecomp{}.Decompose(parsedGraph)
+				if *fractional {
+					upper = algo.MakeFractional(upper)
+				}
+				if !*bench {
+					fmt.Println("Upper bound from greedy decomposition:", upper.CheckWidth())
+				}
+			}
+
+			for solved := false; !solved; k++ {
+				if k >= parsedGraph.Edges.Len() {
+					decomp = lib.TrivialDecomp(parsedGraph)
+					solved = true
+					continue
+				}
+				if upper.Found() && k >= upper.CheckWidth() {
+					decomp = upper
+					solved = true
+					continue
+				}
 				solver.SetWidth(k)
+				status.SetWidth(k)
 
 				if *hingeFlag {
 					decomp = hinget.DecompHinge(solver, parsedGraph)
@@ -516,9 +1465,15 @@ func main() {
 					decomp = solver.FindDecomp()
 				}
 
-				solved = decomp.Correct(parsedGraph)
+				if ctx.Err() != nil {
+					break
+				}
+				solved = decomp.Found() && decomp.Correct(parsedGraph)
+			}
+			if ctx.Err() == nil {
+				*width = k - 1 // for correct output
+				fmt.Println("Exact width: ", *width)
 			}
-			*width = k - 1 // for correct output
 		} else if *approx > 0 {
 			ch := make(chan int, 1)
 			go func() {
@@ -533,7 +1488,8 @@ func main() {
 				var newDecomp Decomp
 				for !solved {
 					newK := k - 1
-					solver.SetWidth(newK)
+					solver.SetWidth(newK) // widths only decrease, so negative cache entries are kept
+					status.SetWidth(newK)
 
 					if *hingeFlag {
 						newDecomp = hinget.DecompHinge(solver, parsedGraph)
@@ -556,8 +1512,42 @@ func main() {
 			case <-time.After(time.Duration(*approx) * time.Second):
 				*width = decomp.CheckWidth()
 			}
+		} else if *auto {
+			lower := lib.LowerBound(parsedGraph)
+			upper := upperBound(parsedGraph, *hdFlag, *fractional)
+			if !*bench {
+				fmt.Println("Lower bound:", lower, "upper bound from greedy decomposition:", upper.CheckWidth())
+			}
+
+			decompose := func() Decomp {
+				if *hingeFlag {
+					return hinget.DecompHinge(solver, parsedGraph)
+				}
+				return solver.FindDecomp()
+			}
+			var high int
+			decomp, lower, high = closeGap(ctx, solver, parsedGraph, decompose, lower, upper, status, *bench)
+			*width = high
+
+			gapClosed = lower == high
+			if gapClosed {
+				fmt.Println("Exact width: ", *width)
+			} else {
+				fmt.Printf("Time limit of %v reached with %v ≤ width ≤ %v, using the best decomposition found\n",
+					*timeout, lower, high)
+			}
+		} else if parsedGraph.Edges.Len() > 0 && *width >= parsedGraph.Edges.Len() {
+			// any search would succeed at the root, so there is nothing to gain from running the algorithm
+			decomp = lib.TrivialDecomp(parsedGraph)
+			fmt.Println("Width", *width, "is not smaller than the number of edges, using the trivial decomposition "+
+				"of width", decomp.CheckWidth())
+			if *fractional {
+				decomp = algo.MakeFractional(decomp)
+			}
 		} else {
-			if *hingeFlag {
+			if *minWeight {
+				decomp = algo.MinimizeWeight(*weighted)
+			} else if *hingeFlag {
 				decomp = hinget.DecompHinge(solver, parsedGraph)
 			} else {
 				decomp = solver.FindDecomp()
@@ -568,12 +1558,25 @@ func main() {
 		msec := d.Seconds() * float64(time.Second/time.Millisecond)
 		times = append(times, labelTime{time: msec, label: "Decomposition"})
 
+		if ctx.Err() != nil && !*auto {
+			fmt.Println("No decomposition found within the time limit of", *timeout)
+			return
+		}
+
+		if *memInterval > 0 {
+			memReport.Add("decomposition", decomp.MemSize)
+			fmt.Fprint(os.Stderr, memReport.String())
+		}
+		if *maxGoroutines > 0 && !*bench {
+			fmt.Println("Peak concurrent goroutines:", lib.Goroutines.Peak(), "of", *maxGoroutines)
+		}
+
 		// complete Decomposition post-processing
 		if *complete {
 			decomp.Root.RemoveVertices(addedVertices)
 		}
 
-		if !reflect.DeepEqual(decomp, Decomp{}) || (len(ops) > 0 && parsedGraph.Edges.Len() == 0) {
+		if decomp.Found() || (len(ops) > 0 && parsedGraph.Edges.Len() == 0) {
 			var result bool
 			decomp.Root, result = decomp.Root.RestoreGYÖ(ops)
 			if !result {
@@ -585,15 +1588,123 @@ func main() {
 				fmt.Println("Partial decomp:", decomp.Root)
 				log.Panicln("Type Collapse reduction failed")
 			}
+			decomp.Root, result = decomp.Root.RestoreTwins(twins)
+			if !result {
+				fmt.Println("Partial decomp:", decomp.Root)
+				log.Panicln("Twin removal failed")
+			}
 		}
 
-		if !reflect.DeepEqual(decomp, Decomp{}) {
+		if decomp.Found() {
 			decomp.Graph = originalGraph
+			if *minimize {
+				decomp = decomp.Minimize(decomp.CheckWidth())
+			}
+			if *improve > 0 {
+				before := decomp.Width()
+				improveCtx, cancel := context.WithTimeout(context.Background(), *improve)
+				decomp = decomp.Improve(improveCtx)
+				cancel()
+				fmt.Println("Improved width from", before, "to", decomp.Width())
+			}
+			decomp = decomp.AddIsolated() // only now, as minimizing and improving would drop the extra leaves
+			if *rootFlag != "" {
+				var vertices []int
+				for _, name := range strings.Split(*rootFlag, ",") {
+					v, ok := decomp.Graph.VertexByName(strings.TrimSpace(name))
+					if !ok {
+						fmt.Println("Vertex", strings.TrimSpace(name), "not found in hypergraph.")
+						return
+					}
+					vertices = append(vertices, v)
+				}
+				if decomp, err = decomp.RerootAt(vertices); err != nil {
+					fmt.Println("Can't reroot the decomposition:", err)
+					return
+				}
+			}
+			if *fractional && (len(ops) > 0 || len(removalMap) > 0 || len(twins.Vertices) > 0 || *minimize ||
+				*improve > 0) {
+				decomp = algo.MakeFractional(decomp) // the covers changed when restoring the reductions or minimizing
+			}
+			decomp.SetConnectors()
+		}
+		outputStanza(solver.Name(), decomp, times, originalGraph, *gml, *dot, *jsonFlag, *certFlag, *width, false)
+		if portfolio != nil && portfolio.Winner() != "" {
+			fmt.Println("Portfolio won by:", portfolio.Winner())
+		}
+		if restarts != nil {
+			fmt.Println("Restarts:", restarts.Count())
+		}
+		if smallWidth != nil && smallWidth.Used() != "" {
+			fmt.Println("Answered by the specialised procedure:", smallWidth.Used())
+		}
+		if parseGraph.Query != nil && decomp.Found() {
+			fmt.Print("Atoms per bag:\n", parseGraph.Query.BagAtoms(decomp))
+		}
+		if *hdFlag && decomp.Found() {
+			if !decomp.SpecialCondition() {
+				log.Panicln("Special condition violated, not a hypertree decomposition")
+			}
+			fmt.Println("Special condition satisfied: hypertree decomposition")
+		}
+		if *fractional && decomp.Found() {
+			fmt.Printf("Fractional width: %.3f\n", decomp.FractionalWidth())
+		}
+		if *weightsPath != "" && decomp.Found() {
+			fmt.Printf("Weighted width: %.3f\n", decomp.WeightedWidth())
+		}
+		if *selfCheckFlag {
+			if *fractional {
+				fmt.Println("Self-check skipped, not supported for fractional decompositions")
+			} else {
+				// det without any subedges computes HDs, whose width may exceed the generalized hypertree width
+				complete := !*hdFlag && !(*detKFlag && !*localBIP && !allSubedges) && *approx == 0 && balance == nil &&
+					!*splitFlag && !*greedyFlag && *rootWidth == 0
+				selfCheck(originalGraph, decomp, *width, *exact || gapClosed, complete)
+			}
+		}
+
+		if *checkPath != "" {
+			if checkedWidth > 0 {
+				fmt.Println("Width of checked decomposition: ", checkedWidth, ", computed: ", decomp.CheckWidth())
+			} else {
+				fmt.Println("Checked decomposition is not a valid GHD, computed width: ", decomp.CheckWidth())
+			}
+		}
+
+		if *evalCSV != "" && decomp.Found() {
+			evaluator := eval.Evaluator{
+				Graph:    originalGraph,
+				Encoding: parseGraph.Encoding,
+				Storage:  eval.CSVStorage{Dir: *evalCSV, Header: *evalHeader},
+			}
+			start := time.Now()
+			count, err := evaluator.Count(decomp)
+			check(err)
+			fmt.Println("Answers: ", count, " (evaluated in ", time.Since(start), ")")
+		}
+
+		if *planPath != "" && decomp.Found() {
+			evaluator := eval.Evaluator{Graph: originalGraph, Encoding: parseGraph.Encoding}
+			plan, err := evaluator.Plan(decomp)
+			check(err)
+			var out []byte
+			if strings.HasSuffix(*planPath, ".sql") {
+				out = []byte(plan.SQL())
+			} else {
+				out, err = json.MarshalIndent(plan, "", "  ")
+				check(err)
+			}
+			check(ioutil.WriteFile(*planPath, out, 0644))
 		}
-		outputStanza(solver.Name(), decomp, times, originalGraph, *gml, *jsonFlag, *width, false)
 
 		return
 	}
 
+	if *checkPath != "" || *featuresPath != "" {
+		return // only validation or the features were requested
+	}
+
 	fmt.Println("No algorithm or procedure selected.")
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// isBatchInput reports whether the graph flag names several instances, as a directory or a glob pattern
+func isBatchInput(path string) bool {
+	if strings.ContainsAny(path, "*?[") {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// batchPaths returns the files in the directory, or those matching the glob pattern, in lexical order
+func batchPaths(pattern string) ([]string, error) {
+	info, err := os.Stat(pattern)
+	if err != nil || !info.IsDir() {
+		var output []string
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches { // Glob returns its matches in lexical order already
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				output = append(output, path)
+			}
+		}
+		return output, nil
+	}
+
+	var output []string
+	err = filepath.Walk(pattern, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			output = append(output, path)
+		}
+		return nil
+	})
+	sort.Strings(output)
+	return output, err
+}
+
+// batch decomposes each of the given instances with the named algorithm, at the given width or, if exact, at the
+// smallest width found by trying widths from 1 on, each instance bounded by the timeout if positive. Up to jobs
+// instances are decomposed at the same time, and one line of JSON is written per instance, in the order of the
+// paths, as soon as it and all instances before it are done. Parsing is done by one goroutine only, since the parsers
+// share the global vertex encoding.
+func batch(paths []string, format, algorithm string, width int, exact bool, balFactor int, timeout time.Duration,
+	jobs int) {
+	if _, err := benchSolver(algorithm, lib.Graph{}, 1, balFactor); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	type instance struct {
+		path  string
+		graph lib.Graph
+		err   error
+		done  chan benchRun
+	}
+	instances := make(chan *instance, jobs)
+	ordered := make(chan *instance, len(paths))
+
+	go func() {
+		for _, path := range paths {
+			graph, err := loadBenchGraph(path, format)
+			in := &instance{path: path, graph: graph, err: err, done: make(chan benchRun, 1)}
+			ordered <- in
+			instances <- in
+		}
+		close(ordered)
+		close(instances)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for in := range instances {
+				if in.err != nil {
+					in.done <- benchRun{Error: in.err.Error()}
+					continue
+				}
+				in.done <- batchRun(in.graph, algorithm, width, exact, balFactor, timeout)
+			}
+		}()
+	}
+
+	solved := 0
+	for in := range ordered {
+		run := <-in.done
+		run.Graph, run.Algorithm = in.path, algorithm
+		if run.Found && run.Correct {
+			solved++
+		}
+		out, err := json.Marshal(run)
+		check(err)
+		fmt.Println(string(out))
+	}
+	wg.Wait()
+
+	fmt.Fprintln(os.Stderr, "Decomposed", solved, "of", len(paths), "instances")
+}
+
+// batchRun decomposes a single instance, turning a panic of the algorithm into an error so that the batch goes on.
+// If exact, the widths are tried in increasing order, sharing the timeout.
+func batchRun(graph lib.Graph, algorithm string, width int, exact bool, balFactor int,
+	timeout time.Duration) (output benchRun) {
+	defer func() {
+		if r := recover(); r != nil {
+			output = benchRun{Width: output.Width, Error: fmt.Sprint(r)}
+		}
+	}()
+
+	if !exact {
+		solver, _ := benchSolver(algorithm, graph, width, balFactor)
+		output = runBench(solver, graph, timeout)
+		output.Width = width
+		return output
+	}
+
+	var total float64
+	deadline := time.Now().Add(timeout)
+	for k := 1; ; k++ {
+		remaining := time.Until(deadline)
+		if timeout > 0 && remaining <= 0 {
+			output.TimedOut = true
+			return output
+		}
+		if timeout <= 0 {
+			remaining = 0
+		}
+
+		solver, _ := benchSolver(algorithm, graph, k, balFactor)
+		output = runBench(solver, graph, remaining)
+		total += output.Millis
+		output.Width, output.Millis = k, total
+		if output.Found || output.TimedOut || k >= graph.Edges.Len() {
+			return output
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// A benchRun is the outcome of running one algorithm on one graph at one width
+type benchRun struct {
+	Graph     string  `json:"graph"`
+	Algorithm string  `json:"algorithm"`
+	Width     int     `json:"width"`
+	Found     bool    `json:"found"`
+	Correct   bool    `json:"correct"`
+	Result    int     `json:"resultWidth"` // the width of the decomposition found, if any
+	TimedOut  bool    `json:"timedOut"`
+	Millis    float64 `json:"ms"`
+	Alloc     uint64  `json:"allocBytes"` // bytes allocated during the run
+	Error     string  `json:"error,omitempty"`
+}
+
+// parseWidths parses a single width or an inclusive range such as 2-5
+func parseWidths(s string) (int, int, error) {
+	parts := strings.SplitN(s, "-", 2)
+	low, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid width range %q", s)
+	}
+	high := low
+	if len(parts) == 2 {
+		if high, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+			return 0, 0, fmt.Errorf("invalid width range %q", s)
+		}
+	}
+	if low <= 0 || high < low {
+		return 0, 0, fmt.Errorf("invalid width range %q, widths must be positive and increasing", s)
+	}
+	return low, high, nil
+}
+
+// benchSolver sets up the named algorithm for the graph at width K
+func benchSolver(name string, graph lib.Graph, K, balFactor int) (algo.Algorithm, error) {
+	factory, err := algo.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(algo.Config{K: K, Graph: graph, BalFactor: balFactor}), nil
+}
+
+// runBench runs the solver once, bounded by the timeout if positive, and records the outcome
+func runBench(solver algo.Algorithm, graph lib.Graph, timeout time.Duration) benchRun {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	solver.SetGenerator(lib.ParallelSearchGen{Ctx: ctx})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	decomp := solver.FindDecomp()
+
+	output := benchRun{Millis: float64(time.Since(start)) / float64(time.Millisecond)}
+	runtime.ReadMemStats(&after)
+	output.Alloc = after.TotalAlloc - before.TotalAlloc
+	output.TimedOut = ctx.Err() != nil
+
+	if decomp.Found() {
+		decomp.Graph = graph
+		decomp.RestoreSubedges()
+		output.Found = true
+		output.Correct = decomp.Correct(graph)
+		output.Result = decomp.CheckWidth()
+	}
+	return output
+}
+
+// benchDir runs each of the algorithms on every graph file in dir, at each width in the range, one run after another
+// so that their times are comparable, and writes the results to outPath, as JSON if it ends in .json and as CSV
+// otherwise
+func benchDir(dir, format, algorithms, widths, outPath string, balFactor int, timeout time.Duration) {
+	low, high, err := parseWidths(widths)
+	check(err)
+	names := strings.Split(algorithms, ",")
+	for _, name := range names {
+		if _, err := benchSolver(name, lib.Graph{}, 1, balFactor); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	var paths []string
+	check(filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	}))
+	sort.Strings(paths)
+
+	var runs []benchRun
+	for _, path := range paths {
+		graph, err := loadBenchGraph(path, format)
+		if err != nil {
+			runs = append(runs, benchRun{Graph: path, Error: err.Error()})
+			continue
+		}
+
+		for _, name := range names {
+			for k := low; k <= high; k++ {
+				solver, _ := benchSolver(name, graph, k, balFactor)
+				run := runBench(solver, graph, timeout)
+				run.Graph, run.Algorithm, run.Width = path, name, k
+				runs = append(runs, run)
+			}
+		}
+	}
+
+	if strings.HasSuffix(outPath, ".json") {
+		out, err := json.MarshalIndent(runs, "", "  ")
+		check(err)
+		check(ioutil.WriteFile(outPath, out, 0644))
+	} else {
+		writeBenchCSV(runs, outPath)
+	}
+
+	fmt.Println("Ran", len(runs), "benchmarks on", len(paths), "graphs, results written to", outPath)
+}
+
+// loadBenchGraph parses the graph in the given file, turning a panic of the parser into an error
+func loadBenchGraph(path, format string) (graph lib.Graph, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return lib.Graph{}, err
+	}
+	graph, _, err = lib.GetGraphFormat(format, string(dat))
+	return graph, err
+}
+
+func writeBenchCSV(runs []benchRun, outPath string) {
+	f, err := os.Create(outPath)
+	check(err)
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"graph", "algorithm", "width", "found", "correct", "resultWidth", "timedOut", "ms",
+		"allocBytes", "error"})
+	for _, r := range runs {
+		w.Write([]string{r.Graph, r.Algorithm, strconv.Itoa(r.Width), strconv.FormatBool(r.Found),
+			strconv.FormatBool(r.Correct), strconv.Itoa(r.Result), strconv.FormatBool(r.TimedOut),
+			strconv.FormatFloat(r.Millis, 'f', 3, 64), strconv.FormatUint(r.Alloc, 10), r.Error})
+	}
+	w.Flush()
+	check(w.Error())
+}
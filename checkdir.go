@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// decompExtensions lists the file extensions recognised as decompositions by loadDecomp
+var decompExtensions = map[string]bool{".gml": true, ".json": true, ".htd": true}
+
+// A checkPair is a decomposition found in a directory, together with the graph of the same base name
+type checkPair struct {
+	graphPath  string
+	decompPath string
+	graph      Graph
+	decomp     Decomp
+	err        string // set if either file couldn't be read
+	width      int
+	correct    bool
+}
+
+// findCheckPairs walks dir and pairs each decomposition with the other file sharing its base name. Decompositions
+// without a graph are returned with an error message.
+func findCheckPairs(dir string) ([]checkPair, error) {
+	stems := make(map[string][]string) // path without extension -> paths with this stem
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			stem := strings.TrimSuffix(path, filepath.Ext(path))
+			stems[stem] = append(stems[stem], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var output []checkPair
+	for _, paths := range stems {
+		sort.Strings(paths)
+		var graphPath string
+		var decompPaths []string
+		for _, p := range paths {
+			if decompExtensions[strings.ToLower(filepath.Ext(p))] {
+				decompPaths = append(decompPaths, p)
+			} else if graphPath == "" {
+				graphPath = p
+			}
+		}
+
+		for _, p := range decompPaths {
+			pair := checkPair{graphPath: graphPath, decompPath: p}
+			if graphPath == "" {
+				pair.err = "no graph found"
+			}
+			output = append(output, pair)
+		}
+	}
+
+	sort.Slice(output, func(i, j int) bool { return output[i].decompPath < output[j].decompPath })
+	return output, nil
+}
+
+// load parses the graph and decomposition of a pair, recording any failure in the pair itself
+func (c *checkPair) load(format string) {
+	defer func() {
+		if r := recover(); r != nil { // the parsers panic on malformed input
+			c.err = fmt.Sprint(r)
+		}
+	}()
+
+	dat, err := ioutil.ReadFile(c.graphPath)
+	if err != nil {
+		c.err = err.Error()
+		return
+	}
+
+	graph, parseGraph, err := lib.GetGraphFormat(format, string(dat))
+	if err != nil {
+		c.err = err.Error()
+		return
+	}
+	c.graph = graph
+	c.decomp = loadDecomp(c.decompPath, graph, parseGraph.Encoding)
+}
+
+// checkDir validates all decompositions found in dir against their graphs, and writes a summary CSV to csvPath.
+// Parsing is done sequentially, since the parsers share the global vertex encoding, while the validation itself
+// runs in parallel.
+func checkDir(dir string, format string, csvPath string) {
+	pairs, err := findCheckPairs(dir)
+	check(err)
+
+	for i := range pairs {
+		if pairs[i].err == "" {
+			pairs[i].load(format)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range pairs {
+		if pairs[i].err != "" {
+			continue
+		}
+		wg.Add(1)
+		go func(c *checkPair) {
+			defer wg.Done()
+			c.correct = c.decomp.Correct(c.graph)
+			c.width = c.decomp.CheckWidth()
+		}(&pairs[i])
+	}
+	wg.Wait()
+
+	f, err := os.Create(csvPath)
+	check(err)
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"graph", "decomposition", "width", "correct", "error"})
+	numCorrect := 0
+	for _, c := range pairs {
+		if c.correct {
+			numCorrect++
+		}
+		w.Write([]string{c.graphPath, c.decompPath, strconv.Itoa(c.width), strconv.FormatBool(c.correct), c.err})
+	}
+	w.Flush()
+	check(w.Error())
+
+	fmt.Println("Checked ", len(pairs), " decompositions, ", numCorrect, " correct, summary written to ", csvPath)
+}
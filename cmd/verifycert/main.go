@@ -0,0 +1,272 @@
+// verifycert checks a decomposition certificate, as exported by BalancedGo with the -cert flag, independently of the
+// code used to compute it. It verifies that the certificate describes a generalized hypertree decomposition of the
+// hypergraph it contains, and reports its width.
+//
+// The code is kept deliberately simple and uses only the standard library, so that it can be audited easily.
+// Usage: verifycert <certificate file>
+//
+// The certificate format is line-based, each line starting with a single letter:
+//
+//	c <text>                    comment
+//	g <vertices> <edges>        number of vertices and edges, once before any other line
+//	e <edge> <vertex>...        an edge of the hypergraph and its vertices
+//	n <node> <parent>           a node of the tree, parent 0 marks the root; parents come before their children
+//	b <node> <vertex>...        the bag of a node
+//	l <node> <edge>...          the cover (lambda label) of a node
+//
+// Vertices, edges and nodes are positive integers.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type certificate struct {
+	numVertices int
+	numEdges    int
+	edges       map[int][]int // edge -> vertices
+	parent      map[int]int   // node -> parent, 0 for the root
+	bags        map[int][]int // node -> vertices
+	covers      map[int][]int // node -> edges
+	order       []int         // nodes in order of declaration
+}
+
+func parseInts(fields []string, line int) ([]int, error) {
+	var output []int
+	for _, f := range fields {
+		i, err := strconv.Atoi(f)
+		if err != nil || i <= 0 {
+			return nil, fmt.Errorf("line %d: %q is not a positive integer", line, f)
+		}
+		output = append(output, i)
+	}
+	return output, nil
+}
+
+func parse(file *os.File) (*certificate, error) {
+	c := &certificate{
+		numVertices: -1,
+		edges:       make(map[int][]int),
+		parent:      make(map[int]int),
+		bags:        make(map[int][]int),
+		covers:      make(map[int][]int),
+	}
+
+	reader := bufio.NewReader(file)
+	line := 0
+	for {
+		text, readErr := reader.ReadString('\n')
+		if readErr != nil && text == "" {
+			break
+		}
+		line++
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if fields[0] != "g" && c.numVertices == -1 {
+			return nil, fmt.Errorf("line %d: \"g\" line must come first", line)
+		}
+
+		var ints []int
+		if fields[0] == "n" || fields[0] == "g" {
+			// parents may be 0
+			for _, f := range fields[1:] {
+				i, err := strconv.Atoi(f)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("line %d: %q is not a non-negative integer", line, f)
+				}
+				ints = append(ints, i)
+			}
+		} else {
+			var err error
+			if ints, err = parseInts(fields[1:], line); err != nil {
+				return nil, err
+			}
+		}
+
+		switch fields[0] {
+		case "g":
+			if len(ints) != 2 || c.numVertices != -1 {
+				return nil, fmt.Errorf("line %d: expected a single \"g <vertices> <edges>\"", line)
+			}
+			c.numVertices, c.numEdges = ints[0], ints[1]
+		case "e":
+			if len(ints) < 1 {
+				return nil, fmt.Errorf("line %d: edge without name", line)
+			}
+			if _, ok := c.edges[ints[0]]; ok {
+				return nil, fmt.Errorf("line %d: edge %d declared twice", line, ints[0])
+			}
+			c.edges[ints[0]] = ints[1:]
+		case "n":
+			if len(ints) != 2 || ints[0] == 0 {
+				return nil, fmt.Errorf("line %d: expected \"n <node> <parent>\"", line)
+			}
+			if _, ok := c.parent[ints[0]]; ok {
+				return nil, fmt.Errorf("line %d: node %d declared twice", line, ints[0])
+			}
+			if _, ok := c.parent[ints[1]]; ints[1] != 0 && !ok {
+				return nil, fmt.Errorf("line %d: parent %d of node %d not declared before", line, ints[1], ints[0])
+			}
+			c.parent[ints[0]] = ints[1]
+			c.order = append(c.order, ints[0])
+		case "b", "l":
+			if len(ints) < 1 {
+				return nil, fmt.Errorf("line %d: missing node", line)
+			}
+			if _, ok := c.parent[ints[0]]; !ok {
+				return nil, fmt.Errorf("line %d: node %d not declared before", line, ints[0])
+			}
+			if fields[0] == "b" {
+				c.bags[ints[0]] = append(c.bags[ints[0]], ints[1:]...)
+			} else {
+				c.covers[ints[0]] = append(c.covers[ints[0]], ints[1:]...)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unknown line type %q", line, fields[0])
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	if c.numVertices == -1 {
+		return nil, fmt.Errorf("missing \"g\" line")
+	}
+	return c, nil
+}
+
+// verify checks all conditions of a GHD, and returns its width
+func verify(c *certificate) (int, error) {
+	// the hypergraph must match its declaration
+	vertices := make(map[int]bool)
+	for _, vs := range c.edges {
+		for _, v := range vs {
+			vertices[v] = true
+		}
+	}
+	if len(c.edges) != c.numEdges || len(vertices) != c.numVertices {
+		return 0, fmt.Errorf("hypergraph has %d vertices and %d edges, but declares %d and %d", len(vertices),
+			len(c.edges), c.numVertices, c.numEdges)
+	}
+
+	// the tree must have a single root; as parents are declared first, there can be no cycles
+	roots := 0
+	for _, p := range c.parent {
+		if p == 0 {
+			roots++
+		}
+	}
+	if roots != 1 {
+		return 0, fmt.Errorf("tree must have exactly one root, found %d", roots)
+	}
+
+	width := 0
+	for _, n := range c.order {
+		// each bag must be covered by the edges of the cover
+		covered := make(map[int]bool)
+		for _, e := range c.covers[n] {
+			vs, ok := c.edges[e]
+			if !ok {
+				return 0, fmt.Errorf("node %d: cover uses unknown edge %d", n, e)
+			}
+			for _, v := range vs {
+				covered[v] = true
+			}
+		}
+		for _, v := range c.bags[n] {
+			if !covered[v] {
+				return 0, fmt.Errorf("node %d: vertex %d of bag not covered", n, v)
+			}
+		}
+		if len(c.covers[n]) > width {
+			width = len(c.covers[n])
+		}
+	}
+
+	// each edge must be contained in some bag
+	for e, vs := range c.edges {
+		found := false
+		for _, n := range c.order {
+			if subset(vs, c.bags[n]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("edge %d not contained in any bag", e)
+		}
+	}
+
+	// for each vertex, the nodes containing it must form a subtree: exactly one of them has a parent without it
+	for v := range vertices {
+		tops := 0
+		for _, n := range c.order {
+			if !contains(c.bags[n], v) {
+				continue
+			}
+			p := c.parent[n]
+			if p == 0 || !contains(c.bags[p], v) {
+				tops++
+			}
+		}
+		if tops != 1 {
+			return 0, fmt.Errorf("nodes containing vertex %d are not connected", v)
+		}
+	}
+
+	return width, nil
+}
+
+func contains(vs []int, v int) bool {
+	for _, w := range vs {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+func subset(as []int, bs []int) bool {
+	for _, a := range as {
+		if !contains(bs, a) {
+			return false
+		}
+	}
+	return true
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: verifycert <certificate file>")
+		os.Exit(2)
+	}
+
+	file, err := os.Open(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer file.Close()
+
+	c, err := parse(file)
+	if err != nil {
+		fmt.Println("Invalid certificate:", err)
+		os.Exit(1)
+	}
+
+	width, err := verify(c)
+	if err != nil {
+		fmt.Println("Invalid decomposition:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Valid generalized hypertree decomposition of width", width)
+}
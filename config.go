@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A pipeline configuration assigns values to the command-line flags, so that a full setup of input, preprocessing,
+// algorithm, width and outputs can be stored in a single file. Keys are the names of the flags, and may be grouped
+// into arbitrarily named sections, e.g.
+//
+//	{
+//	  "input": {"graph": "query.hg", "format": "hyperbench"},
+//	  "preprocessing": {"g": true, "t": true},
+//	  "algorithm": {"det": true, "width": 3, "timeout": "10m"},
+//	  "output": {"gml": "query.gml"}
+//	}
+//
+// The same can be written in YAML, using one "key: value" pair per line and indented sections. Only this simple
+// subset of YAML is supported.
+
+// loadConfig reads a pipeline configuration in JSON or YAML format, chosen by the file extension
+func loadConfig(path string) (map[string]string, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseConfigYAML(dat)
+	default:
+		return parseConfigJSON(dat)
+	}
+}
+
+func parseConfigJSON(dat []byte) (map[string]string, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(dat, &raw); err != nil {
+		return nil, fmt.Errorf("config: %v", err)
+	}
+
+	output := make(map[string]string)
+	if err := flattenConfig(raw, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// flattenConfig collects all key-value pairs, dissolving any sections
+func flattenConfig(raw map[string]interface{}, output map[string]string) error {
+	for k, v := range raw {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if err := flattenConfig(value, output); err != nil {
+				return err
+			}
+		case string:
+			output[k] = value
+		case bool:
+			output[k] = strconv.FormatBool(value)
+		case float64:
+			output[k] = strconv.FormatFloat(value, 'f', -1, 64)
+		case []interface{}: // lists, e.g. the edges of a separator
+			var elements []string
+			for _, e := range value {
+				elements = append(elements, fmt.Sprint(e))
+			}
+			output[k] = strings.Join(elements, ",")
+		default:
+			return fmt.Errorf("config: unsupported value %v for key %s", v, k)
+		}
+	}
+	return nil
+}
+
+func parseConfigYAML(dat []byte) (map[string]string, error) {
+	output := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(dat))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("config: expected \"key: value\" at line %d", lineNum)
+		}
+		key := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+		if value == "" {
+			continue // start of a section
+		}
+		value = strings.Trim(value, "\"'")
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]") // inline lists
+
+		output[key] = value
+	}
+
+	return output, scanner.Err()
+}
+
+// applyConfig sets all flags given in the configuration, unless they were set explicitly on the command line
+func applyConfig(flagSet *flag.FlagSet, settings map[string]string) error {
+	explicit := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	for k, v := range settings {
+		if k == "config" {
+			return fmt.Errorf("config: nested configuration files are not supported")
+		}
+		if flagSet.Lookup(k) == nil {
+			return fmt.Errorf("config: unknown option %s", k)
+		}
+		if explicit[k] {
+			continue
+		}
+		if err := flagSet.Set(k, v); err != nil {
+			return fmt.Errorf("config: invalid value %q for %s: %v", v, k, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// The seps subcommand can search for balanced separators on several machines at once. With -listen, it coordinates
+// the search: the combinations of edges are split into units by SplitCombin, which are handed out over HTTP to the
+// workers connecting to it, started on any number of hosts as
+//
+//	BalancedGo worker -coordinator host:port [-cpu 8]
+//
+// Each worker reports the separators it found, together with the state of its unit after them, at the given interval.
+// If a worker stops reporting, its unit is handed out again from the last state reported, so that each separator is
+// printed exactly once. Once no units are left to hand out, the unit with the most combinations remaining is split
+// between its worker and the idle one at the next report, so that no worker idles while there's work left.
+//
+// The workers run any search they are given, so the coordinator should only listen on a trusted network.
+
+// A distJob describes the search to the workers
+type distJob struct {
+	Edges     []lib.Edge
+	K         int
+	BalFactor int
+	Interval  time.Duration // how often the workers report
+}
+
+// A distReport is sent by a worker to report on its unit, or to ask for one
+type distReport struct {
+	Worker string
+	Unit   int                 // the unit searched, or -1 if the worker has none
+	State  jsoniter.RawMessage // the state of the unit, after the separators found
+	Found  [][]int             // the separators found since the last report, as indices of the edges
+	Done   bool                // set if the unit has been searched completely
+}
+
+// A distReply tells a worker which unit to search next
+type distReply struct {
+	Unit     int                 // -1 if there is none at the moment
+	State    jsoniter.RawMessage // the state to continue from, not set if the worker keeps its state
+	Finished bool                // set once the search is over
+}
+
+// A distUnit is a part of the search, given by the state of a CombinationIterator
+type distUnit struct {
+	state    []byte
+	owner    string    // the worker searching the unit, empty if none
+	lastSeen time.Time // the time of the last report of the owner
+	steal    bool      // set if the unit is to be split at the next report of its owner
+}
+
+// A coordinator hands out the units of a search to workers and collects the separators they find
+type coordinator struct {
+	job      distJob
+	lease    time.Duration // how long a unit stays with a worker not reporting
+	found    func(sep []int)
+	mutex    sync.Mutex
+	units    map[int]*distUnit
+	queue    []int // units not assigned to any worker
+	nextUnit int
+	finished bool
+	done     chan struct{} // closed once the search is finished
+}
+
+// newCoordinator splits the search for separators of the graph into the given number of units. The function found is
+// called with each separator reported, one call at a time.
+func newCoordinator(graph lib.Graph, k, balFactor, units int, interval time.Duration,
+	found func(sep []int)) *coordinator {
+	c := &coordinator{
+		job:   distJob{Edges: graph.Edges.Slice(), K: k, BalFactor: balFactor, Interval: interval},
+		lease: 4 * interval,
+		found: found,
+		units: make(map[int]*distUnit),
+		done:  make(chan struct{}),
+	}
+	for _, gen := range lib.SplitCombin(graph.Edges.Len(), k, units, false) {
+		c.addUnit(gen.Save())
+	}
+	if len(c.units) == 0 {
+		c.finish()
+	}
+
+	return c
+}
+
+func (c *coordinator) addUnit(state []byte) {
+	c.units[c.nextUnit] = &distUnit{state: state}
+	c.queue = append(c.queue, c.nextUnit)
+	c.nextUnit++
+}
+
+func (c *coordinator) finish() {
+	if !c.finished {
+		c.finished = true
+		close(c.done)
+	}
+}
+
+func (c *coordinator) handleJob(w http.ResponseWriter, r *http.Request) {
+	c.reply(w, http.StatusOK, c.job)
+}
+
+func (c *coordinator) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var report distReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		http.Error(w, "malformed report: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, sep := range report.Found {
+		for _, i := range sep {
+			if i < 0 || i >= len(c.job.Edges) {
+				http.Error(w, fmt.Sprintf("edge %v out of range", i), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	c.reply(w, http.StatusOK, c.report(report))
+}
+
+// report takes in the report of a worker, and returns the unit it should search next
+func (c *coordinator) report(report distReport) distReply {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if unit, ok := c.units[report.Unit]; ok && unit.owner == report.Worker && !c.finished {
+		for _, sep := range report.Found {
+			c.found(sep)
+		}
+		if !report.Done {
+			unit.state, unit.lastSeen = report.State, time.Now()
+			if !unit.steal {
+				return distReply{Unit: report.Unit}
+			}
+
+			unit.steal = false
+			var it lib.CombinationIterator
+			if err := json.Unmarshal(unit.state, &it); err == nil {
+				if other := it.Split(); other != nil {
+					unit.state = it.Save()
+					c.addUnit(other.Save())
+				}
+			}
+			return distReply{Unit: report.Unit, State: unit.state}
+		}
+		delete(c.units, report.Unit)
+	} // otherwise the unit was handed out again, and its separators are reported by its new owner
+
+	if c.finished {
+		return distReply{Unit: -1, Finished: true}
+	}
+
+	// hand out the units of workers which stopped reporting again
+	for id, unit := range c.units {
+		if unit.owner != "" && time.Since(unit.lastSeen) > c.lease {
+			unit.owner = ""
+			c.queue = append(c.queue, id)
+		}
+	}
+
+	if len(c.queue) > 0 {
+		id := c.queue[0]
+		c.queue = c.queue[1:]
+		c.units[id].owner, c.units[id].lastSeen = report.Worker, time.Now()
+		return distReply{Unit: id, State: c.units[id].state}
+	}
+	if len(c.units) == 0 {
+		c.finish()
+		return distReply{Unit: -1, Finished: true}
+	}
+
+	c.markSteal()
+	return distReply{Unit: -1}
+}
+
+// markSteal marks the unit with the most combinations remaining to be split, unless one is marked already
+func (c *coordinator) markSteal() {
+	var chosen *distUnit
+	var most float32
+	for _, unit := range c.units {
+		if unit.steal {
+			return
+		}
+		var it lib.CombinationIterator
+		if err := json.Unmarshal(unit.state, &it); err != nil {
+			continue
+		}
+		if remaining := (1 - it.GetPercentage()) / float32(it.StepSize); chosen == nil || remaining > most {
+			chosen, most = unit, remaining
+		}
+	}
+	if chosen != nil {
+		chosen.steal = true
+	}
+}
+
+func (c *coordinator) reply(w http.ResponseWriter, status int, output interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(output); err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't reply to worker:", err)
+	}
+}
+
+// coordinate serves the search to workers on addr, until it's finished or ctx is done
+func coordinate(ctx context.Context, addr string, c *coordinator) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/job", c.handleJob)
+	mux.HandleFunc("/report", c.handleReport)
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+
+	fmt.Fprintln(os.Stderr, "Coordinating search on", listener.Addr(), "for", len(c.units), "units")
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		c.mutex.Lock()
+		c.finish()
+		c.mutex.Unlock()
+	}
+
+	// give the workers the chance to learn that the search is over
+	time.Sleep(2 * c.job.Interval)
+	return server.Close()
+}
+
+// worker runs the worker subcommand on the given arguments, and returns the exit code
+func worker(args []string) int {
+	flagSet := flag.NewFlagSet("worker", flag.ContinueOnError)
+	addr := flagSet.String("coordinator", "", "Address of the coordinator, started by seps -listen")
+	cpus := flagSet.Int("cpu", runtime.GOMAXPROCS(-1), "Number of units searched at the same time")
+	name := flagSet.String("name", "", "Name of the worker, reported to the coordinator, the host name and process "+
+		"ID by default")
+
+	if err := flagSet.Parse(args); err != nil || *addr == "" || *cpus <= 0 {
+		fmt.Fprintln(os.Stderr, "Usage: BalancedGo worker -coordinator <host:port> [-cpu <n>]")
+		flagSet.SetOutput(os.Stderr)
+		flagSet.PrintDefaults()
+		return 2
+	}
+	if *name == "" {
+		host, _ := os.Hostname()
+		*name = host + ":" + strconv.Itoa(os.Getpid())
+	}
+	client := distClient{url: "http://" + *addr, http: &http.Client{Timeout: time.Minute}}
+
+	var job distJob
+	if err := client.call("/job", nil, &job); err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't get the job from the coordinator:", err)
+		return 1
+	}
+	graph := lib.Graph{Edges: lib.NewEdges(job.Edges)}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, *cpus)
+	for i := 0; i < *cpus; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if err := client.work(id, graph, job); err != nil {
+				errs <- err
+			}
+		}(*name + "/" + strconv.Itoa(i))
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		fmt.Fprintln(os.Stderr, "Lost the coordinator:", err)
+		return 1
+	default:
+		return 0
+	}
+}
+
+// A distClient talks to a coordinator
+type distClient struct {
+	url  string
+	http *http.Client
+}
+
+// call posts the request to the path, or gets it if the request is nil, and decodes the reply into output. Failed
+// calls are tried again a few times, as the coordinator may be busy.
+func (d distClient) call(path string, request interface{}, output interface{}) error {
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+
+		var resp *http.Response
+		if request == nil {
+			resp, err = d.http.Get(d.url + path)
+		} else {
+			var body []byte
+			if body, err = json.Marshal(request); err != nil {
+				return err
+			}
+			resp, err = d.http.Post(d.url+path, "application/json", bytes.NewReader(body))
+		}
+		if err != nil {
+			continue
+		}
+		var body []byte
+		body, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%v: %s", resp.Status, bytes.TrimSpace(body))
+		}
+		return json.Unmarshal(body, output)
+	}
+	return err
+}
+
+// work searches the units handed out by the coordinator, until the search is finished
+func (d distClient) work(id string, graph lib.Graph, job distJob) error {
+	report := distReport{Worker: id, Unit: -1}
+	var gen lib.CombinationIterator
+
+	for {
+		var reply distReply
+		if err := d.call("/report", report, &reply); err != nil {
+			return err
+		}
+		if reply.Finished {
+			return nil
+		}
+		if reply.Unit < 0 {
+			report = distReport{Worker: id, Unit: -1}
+			time.Sleep(job.Interval)
+			continue
+		}
+		if reply.State != nil {
+			gen = lib.CombinationIterator{}
+			if err := json.Unmarshal(reply.State, &gen); err != nil {
+				return err
+			}
+		}
+
+		found, done := searchUnit(graph, &gen, job, time.Now().Add(job.Interval))
+		report = distReport{Worker: id, Unit: reply.Unit, State: gen.Save(), Found: found, Done: done}
+	}
+}
+
+// searchUnit returns the separators generated by gen until the deadline, and whether gen is exhausted
+func searchUnit(graph lib.Graph, gen *lib.CombinationIterator, job distJob, deadline time.Time) ([][]int, bool) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	var found [][]int
+	searchGen := lib.ParallelSearchGen{Ctx: ctx, Sequential: true}
+	search := searchGen.GetSearch(&graph, &graph.Edges, job.BalFactor, []lib.Generator{gen})
+	for {
+		search.FindNext(lib.BalancedCheck{})
+		if search.SearchEnded() {
+			return found, ctx.Err() == nil
+		}
+		found = append(found, append([]int{}, search.GetResult()...))
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// enumerateDecomps prints up to n structurally distinct decompositions of the graph found by det, and how many were
+// found. If jsonPath is set, the correct ones are written to it as a JSON array.
+func enumerateDecomps(ctx context.Context, det *algo.DetKDecomp, graph lib.Graph, n int, jsonPath string) {
+	det.SetGenerator(lib.ParallelSearchGen{Ctx: ctx})
+
+	start := time.Now()
+	decomps := det.Enumerate(n)
+	msec := time.Since(start).Seconds() * 1000
+
+	var encoded []string
+	for i := range decomps {
+		decomp := decomps[i]
+		decomp.Graph = graph
+		decomp.RestoreSubedges()
+		correct := decomp.Correct(graph)
+
+		fmt.Println("Decomposition", i+1, "( width", decomp.Width(), ")\n", decomp)
+		fmt.Println("Correct: ", correct)
+		if correct {
+			encoded = append(encoded, string(lib.WriteDecomp(decomp)))
+		}
+	}
+
+	fmt.Println("Used algorithm: " + det.Name() + " @" + Version)
+	fmt.Printf("Time: %.5f ms\n", msec)
+	switch {
+	case ctx.Err() != nil:
+		fmt.Println("Search timed out after", len(decomps), "decompositions of width", det.K)
+	case len(decomps) == n:
+		fmt.Println("Found", n, "decompositions of width", det.K, "(the limit, there may be more)")
+	default:
+		fmt.Println("Found all", len(decomps), "decompositions of width", det.K)
+	}
+
+	if jsonPath != "" {
+		check(ioutil.WriteFile(jsonPath, []byte("["+strings.Join(encoded, ",")+"]"), 0644))
+	}
+}
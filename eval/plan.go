@@ -0,0 +1,230 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// A Plan describes the evaluation of a conjunctive query along a decomposition of its hypergraph, in the order of the
+// algorithm of Yannakakis, so that it can be run by any database. As for the Evaluator, each relation is named after
+// its edge and its attributes after the vertices of the edge.
+//
+// The relation of each bag is computed first. Then the steps are performed in order, each replacing the relation of
+// its target bag: the semijoins from the leaves up to the root and back down remove all dangling tuples, after which
+// the joins from the leaves up leave the answers in the relation of the root, to be projected onto the attributes.
+type Plan struct {
+	Attributes []string   `json:"attributes"` // the attributes of the answers
+	Bags       []PlanBag  `json:"bags"`       // in preorder, so that each parent comes before its children
+	Steps      []PlanStep `json:"steps"`
+}
+
+// A PlanBag is a node of the decomposition. Its relation is the natural join of the relations of its cover,
+// projected onto its attributes, and reduced by semijoins with the filters, the relations of all edges it is the
+// first bag to contain.
+type PlanBag struct {
+	ID         int            `json:"id"`     // the position of the bag in the plan
+	Parent     int            `json:"parent"` // the ID of the parent, -1 for the root
+	Attributes []string       `json:"attributes"`
+	Cover      []PlanRelation `json:"cover"`
+	Filters    []PlanRelation `json:"filters,omitempty"`
+}
+
+// A PlanRelation is a relation of the query, given by the name and the vertices of its edge. Subedges in a cover are
+// given by an edge containing them.
+type PlanRelation struct {
+	Name       string   `json:"name"`
+	Attributes []string `json:"attributes"`
+}
+
+// A PlanStep replaces the relation of the target bag by its semijoin or natural join with the relation of the source
+// bag, on the attributes they share
+type PlanStep struct {
+	Op     string   `json:"op"` // semijoin or join
+	Target int      `json:"target"`
+	Source int      `json:"source"`
+	On     []string `json:"on"`
+}
+
+// Plan converts the decomposition into the plan followed by Evaluate
+func (e Evaluator) Plan(decomp lib.Decomp) (Plan, error) {
+	if !decomp.Found() {
+		return Plan{}, errors.New("can't plan along empty decomposition")
+	}
+	names := e.names()
+	attributes := func(vertices []int) []string {
+		output := make([]string, len(vertices))
+		for i, v := range vertices {
+			output[i] = names[v]
+		}
+		return output
+	}
+	relation := func(edge lib.Edge) PlanRelation {
+		return PlanRelation{Name: names[edge.Name], Attributes: attributes(edge.Vertices)}
+	}
+
+	var output Plan
+	output.Attributes = attributes(e.Attributes())
+
+	nodes := flatten(decomp.Root, -1, []evalNode{})
+	for i, n := range nodes {
+		bag := PlanBag{ID: i, Parent: n.parent, Attributes: attributes(n.bag)}
+		for _, edge := range n.cover {
+			f, err := e.coverEdge(edge)
+			if err != nil {
+				return Plan{}, err
+			}
+			bag.Cover = append(bag.Cover, relation(f))
+		}
+		output.Bags = append(output.Bags, bag)
+	}
+
+	// every edge filters the first node whose bag contains it
+	for _, edge := range e.Graph.Edges.Slice() {
+		covered := false
+		for i := range nodes {
+			if subset(edge.Vertices, nodes[i].bag) {
+				output.Bags[i].Filters = append(output.Bags[i].Filters, relation(edge))
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return Plan{}, fmt.Errorf("edge %v not covered by the decomposition", edge)
+		}
+	}
+
+	step := func(op string, target, source int, on []int) {
+		output.Steps = append(output.Steps, PlanStep{Op: op, Target: target, Source: source, On: attributes(on)})
+	}
+	for i := len(nodes) - 1; i > 0; i-- {
+		step("semijoin", nodes[i].parent, i, lib.Inter(nodes[nodes[i].parent].bag, nodes[i].bag))
+	}
+	for i := 1; i < len(nodes); i++ {
+		step("semijoin", i, nodes[i].parent, lib.Inter(nodes[i].bag, nodes[nodes[i].parent].bag))
+	}
+	for i := len(nodes) - 1; i > 0; i-- {
+		step("join", nodes[i].parent, i, lib.Inter(nodes[nodes[i].parent].bag, nodes[i].bag))
+	}
+
+	return output, nil
+}
+
+// quote turns a name into a quoted SQL identifier
+func quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// columns returns the columns of the given attributes of the table or alias, separated by commas
+func columns(table string, attributes []string) string {
+	var output []string
+	for _, a := range attributes {
+		output = append(output, table+"."+quote(a))
+	}
+	return strings.Join(output, ", ")
+}
+
+// equal returns the conditions equating the given attributes of two tables or aliases, joined by AND
+func equal(left, right string, attributes []string) string {
+	var output []string
+	for _, a := range attributes {
+		output = append(output, left+"."+quote(a)+" = "+right+"."+quote(a))
+	}
+	return strings.Join(output, " AND ")
+}
+
+// where returns the WHERE clause of the given conditions, if there are any
+func where(conditions []string) string {
+	var output []string
+	for _, c := range conditions {
+		if c != "" {
+			output = append(output, c)
+		}
+	}
+	if len(output) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(output, " AND ")
+}
+
+// SQL returns a single query computing the answers along the plan, with a common table expression for the relation
+// of each bag and the result of each step. The tables must have a column named after each attribute of their
+// relation, as is the case for the tables read by SQLStorage.
+func (p Plan) SQL() string {
+	var ctes []string
+	current := make([]string, len(p.Bags))      // the table holding the current relation of each bag
+	attributes := make([][]string, len(p.Bags)) // the attributes of the current relation of each bag
+
+	for i, bag := range p.Bags {
+		// each attribute is taken from the first relation of the cover which has it
+		from := make(map[string]string)
+		var tables, conditions []string
+		for j, r := range bag.Cover {
+			alias := fmt.Sprint("r", j)
+			tables = append(tables, quote(r.Name)+" AS "+alias)
+			for _, a := range r.Attributes {
+				if first, ok := from[a]; ok {
+					conditions = append(conditions, alias+"."+quote(a)+" = "+first+"."+quote(a))
+				} else {
+					from[a] = alias
+				}
+			}
+		}
+		for _, f := range bag.Filters {
+			var on []string
+			for _, a := range f.Attributes {
+				on = append(on, "f."+quote(a)+" = "+from[a]+"."+quote(a))
+			}
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM "+quote(f.Name)+" AS f"+where(on)+")")
+		}
+		var selected []string
+		for _, a := range bag.Attributes {
+			selected = append(selected, from[a]+"."+quote(a)+" AS "+quote(a))
+		}
+
+		current[i], attributes[i] = fmt.Sprint("bag", bag.ID), bag.Attributes
+		ctes = append(ctes, current[i]+" AS (SELECT DISTINCT "+strings.Join(selected, ", ")+" FROM "+
+			strings.Join(tables, ", ")+where(conditions)+")")
+	}
+
+	for i, s := range p.Steps {
+		name := fmt.Sprint("step", i)
+		target, source := current[s.Target], current[s.Source]
+		switch s.Op {
+		case "semijoin":
+			ctes = append(ctes, name+" AS (SELECT * FROM "+target+" AS t WHERE EXISTS (SELECT 1 FROM "+source+
+				" AS s"+where([]string{equal("s", "t", s.On)})+"))")
+		case "join":
+			var extra []string
+			for _, a := range attributes[s.Source] {
+				if !contains(attributes[s.Target], a) {
+					extra = append(extra, a)
+				}
+			}
+			selected := columns("t", attributes[s.Target])
+			if len(extra) > 0 {
+				selected += ", " + columns("s", extra)
+			}
+			ctes = append(ctes, name+" AS (SELECT DISTINCT "+selected+" FROM "+target+" AS t, "+source+" AS s"+
+				where([]string{equal("t", "s", s.On)})+")")
+			attributes[s.Target] = append(append([]string{}, attributes[s.Target]...), extra...)
+		}
+		current[s.Target] = name
+	}
+
+	root := current[0] // the bags are in preorder
+	return "WITH\n  " + strings.Join(ctes, ",\n  ") + "\nSELECT " + columns(root, p.Attributes) + " FROM " + root +
+		";\n"
+}
+
+// contains checks whether s is in the list
+func contains(list []string, s string) bool {
+	for _, t := range list {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+// Package eval evaluates conjunctive queries along a decomposition of their hypergraph, using the algorithm of
+// Yannakakis. Each edge of the hypergraph corresponds to a relation, each vertex to an attribute, and the relations
+// are read from a pluggable Storage backend.
+package eval
+
+import (
+	"strings"
+)
+
+// A Tuple is a row of a relation, with values given as strings
+type Tuple []string
+
+// A Relation is a set of tuples over a list of attributes, where each attribute is a vertex of the hypergraph
+type Relation struct {
+	Attributes []int
+	Tuples     []Tuple
+}
+
+// positions returns for each of the given attributes its position in the relation, or -1 if not present
+func (r Relation) positions(attributes []int) []int {
+	index := make(map[int]int)
+	for i, a := range r.Attributes {
+		index[a] = i
+	}
+
+	output := make([]int, len(attributes))
+	for i, a := range attributes {
+		pos, ok := index[a]
+		if !ok {
+			pos = -1
+		}
+		output[i] = pos
+	}
+	return output
+}
+
+// shared returns the attributes occurring in both relations, in the order of r
+func (r Relation) shared(s Relation) []int {
+	var output []int
+	pos := s.positions(r.Attributes)
+	for i, a := range r.Attributes {
+		if pos[i] >= 0 {
+			output = append(output, a)
+		}
+	}
+	return output
+}
+
+func key(t Tuple, pos []int) string {
+	var b strings.Builder
+	for _, p := range pos {
+		b.WriteString(t[p])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// index groups the tuples of r by their values on the given attributes
+func (r Relation) index(attributes []int) map[string][]Tuple {
+	pos := r.positions(attributes)
+	output := make(map[string][]Tuple)
+	for _, t := range r.Tuples {
+		k := key(t, pos)
+		output[k] = append(output[k], t)
+	}
+	return output
+}
+
+// Project restricts r to those of the given attributes it contains, removing duplicate tuples
+func (r Relation) Project(attributes []int) Relation {
+	var output Relation
+	var pos []int
+	for i, p := range r.positions(attributes) {
+		if p >= 0 {
+			output.Attributes = append(output.Attributes, attributes[i])
+			pos = append(pos, p)
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, t := range r.Tuples {
+		k := key(t, pos)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		projected := make(Tuple, len(pos))
+		for i, p := range pos {
+			projected[i] = t[p]
+		}
+		output.Tuples = append(output.Tuples, projected)
+	}
+	return output
+}
+
+// Join computes the natural join of r and s
+func (r Relation) Join(s Relation) Relation {
+	shared := r.shared(s)
+	posR := r.positions(shared)
+	indexS := s.index(shared)
+
+	var extra []int // positions of attributes of s not in r
+	inR := make(map[int]bool)
+	for _, a := range r.Attributes {
+		inR[a] = true
+	}
+	output := Relation{Attributes: append([]int{}, r.Attributes...)}
+	for i, a := range s.Attributes {
+		if !inR[a] {
+			extra = append(extra, i)
+			output.Attributes = append(output.Attributes, a)
+		}
+	}
+
+	for _, t := range r.Tuples {
+		for _, u := range indexS[key(t, posR)] {
+			joined := make(Tuple, 0, len(output.Attributes))
+			joined = append(joined, t...)
+			for _, p := range extra {
+				joined = append(joined, u[p])
+			}
+			output.Tuples = append(output.Tuples, joined)
+		}
+	}
+	return output
+}
+
+// Semijoin keeps only those tuples of r which agree with some tuple of s on their shared attributes
+func (r Relation) Semijoin(s Relation) Relation {
+	shared := r.shared(s)
+	posR := r.positions(shared)
+	indexS := s.index(shared)
+
+	output := Relation{Attributes: r.Attributes}
+	for _, t := range r.Tuples {
+		if _, ok := indexS[key(t, posR)]; ok {
+			output.Tuples = append(output.Tuples, t)
+		}
+	}
+	return output
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A Storage provides the relations associated to the edges of a hypergraph
+type Storage interface {
+	// Load returns all tuples of the relation with the given name, with the values ordered as in attributes
+	Load(name string, attributes []string) ([]Tuple, error)
+}
+
+// columnOrder returns the position of each attribute among the columns. If the columns don't name all attributes,
+// they are matched by position instead.
+func columnOrder(name string, columns []string, attributes []string) ([]int, error) {
+	index := make(map[string]int)
+	for i, c := range columns {
+		index[c] = i
+	}
+
+	output := make([]int, len(attributes))
+	byName := true
+	for i, a := range attributes {
+		pos, ok := index[a]
+		if !ok {
+			byName = false
+			break
+		}
+		output[i] = pos
+	}
+	if byName {
+		return output, nil
+	}
+
+	if len(columns) != len(attributes) {
+		return nil, fmt.Errorf("relation %s has %d columns, expected %d", name, len(columns), len(attributes))
+	}
+	for i := range output {
+		output[i] = i
+	}
+	return output, nil
+}
+
+// CSVStorage reads each relation from the file <name>.csv in the directory Dir. If Header is set, the first line
+// of each file names the columns, otherwise the columns are taken in the order of the vertices of the edge.
+type CSVStorage struct {
+	Dir    string
+	Header bool
+	Comma  rune // field delimiter, defaults to ','
+}
+
+// Load reads the CSV file of the given relation
+func (c CSVStorage) Load(name string, attributes []string) ([]Tuple, error) {
+	file, err := os.Open(filepath.Join(c.Dir, name+".csv"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	if c.Comma != 0 {
+		reader.Comma = c.Comma
+	}
+	reader.FieldsPerRecord = -1
+
+	var order []int
+	if c.Header {
+		header, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("relation %s: %v", name, err)
+		}
+		for i := range header {
+			header[i] = strings.TrimSpace(header[i])
+		}
+		if order, err = columnOrder(name, header, attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	var output []Tuple
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("relation %s: %v", name, err)
+		}
+		if order == nil { // match columns by position
+			if len(record) != len(attributes) {
+				return nil, fmt.Errorf("relation %s: row %v has %d columns, expected %d", name, record,
+					len(record), len(attributes))
+			}
+			output = append(output, Tuple(record))
+			continue
+		}
+		if len(record) <= maxPos(order) {
+			return nil, fmt.Errorf("relation %s: row %v too short", name, record)
+		}
+
+		tuple := make(Tuple, len(order))
+		for i, p := range order {
+			tuple[i] = record[p]
+		}
+		output = append(output, tuple)
+	}
+
+	return output, nil
+}
+
+func maxPos(order []int) int {
+	output := -1
+	for _, p := range order {
+		if p > output {
+			output = p
+		}
+	}
+	return output
+}
+
+// SQLStorage reads each relation from the table of the same name in a database. Any database/sql driver may be
+// used, such as those for SQLite or PostgreSQL. Columns are matched to the attributes by name if possible, and by
+// position otherwise. Rows containing NULL values are skipped, as they can't participate in any join.
+type SQLStorage struct {
+	DB *sql.DB
+}
+
+// Load queries the whole table of the given relation
+func (s SQLStorage) Load(name string, attributes []string) ([]Tuple, error) {
+	rows, err := s.DB.Query("SELECT * FROM \"" + strings.Replace(name, "\"", "\"\"", -1) + "\"")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	order, err := columnOrder(name, columns, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	var output []Tuple
+OUTER:
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		tuple := make(Tuple, len(order))
+		for i, p := range order {
+			if !values[p].Valid {
+				continue OUTER
+			}
+			tuple[i] = values[p].String
+		}
+		output = append(output, tuple)
+	}
+
+	return output, rows.Err()
+}
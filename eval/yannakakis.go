@@ -0,0 +1,225 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// An Evaluator computes the answers of the conjunctive query described by a hypergraph. Edges are mapped to relations
+// and vertices to attributes via their names in the encoding produced when parsing the hypergraph.
+type Evaluator struct {
+	Graph    lib.Graph
+	Encoding map[string]int
+	Storage  Storage
+}
+
+type evalNode struct {
+	parent    int
+	bag       []int
+	cover     []lib.Edge
+	shared    []int // attributes shared with the parent
+	relation  Relation
+	byParents map[string][]Tuple
+}
+
+// Attributes returns the vertices of the graph in ascending order, which is also the order of values in each answer
+func (e Evaluator) Attributes() []int {
+	output := append([]int{}, e.Graph.Vertices()...)
+	sort.Ints(output)
+	return output
+}
+
+// flatten lists the nodes of the tree rooted at n in preorder, together with the index of their parents
+func flatten(n lib.Node, parent int, nodes []evalNode) []evalNode {
+	nodes = append(nodes, evalNode{parent: parent, bag: n.Bag, cover: n.Cover.Slice()})
+	current := len(nodes) - 1
+	for i := range n.Children {
+		nodes = flatten(n.Children[i], current, nodes)
+	}
+	return nodes
+}
+
+func subset(a []int, b []int) bool {
+	set := make(map[int]bool)
+	for _, v := range b {
+		set[v] = true
+	}
+	for _, v := range a {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// names returns the name of each edge and vertex
+func (e Evaluator) names() map[int]string {
+	output := make(map[int]string)
+	for k, v := range e.Encoding {
+		output[v] = k
+	}
+	return output
+}
+
+// loadRelations reads the relation of each edge of the graph from the storage
+func (e Evaluator) loadRelations() (map[int]Relation, error) {
+	names := e.names()
+
+	output := make(map[int]Relation)
+	for _, edge := range e.Graph.Edges.Slice() {
+		name, ok := names[edge.Name]
+		if !ok {
+			return nil, fmt.Errorf("no name for edge %d", edge.Name)
+		}
+		var attributes []string
+		for _, v := range edge.Vertices {
+			attr, ok := names[v]
+			if !ok {
+				return nil, fmt.Errorf("no name for vertex %d in edge %s", v, name)
+			}
+			attributes = append(attributes, attr)
+		}
+
+		tuples, err := e.Storage.Load(name, attributes)
+		if err != nil {
+			return nil, err
+		}
+		output[edge.Name] = Relation{Attributes: edge.Vertices, Tuples: tuples}
+	}
+
+	return output, nil
+}
+
+// coverEdge returns the edge of the graph whose relation is used for an edge of a cover. Ad-hoc subedges are
+// answered by any edge of the graph containing them.
+func (e Evaluator) coverEdge(edge lib.Edge) (lib.Edge, error) {
+	for _, f := range e.Graph.Edges.Slice() {
+		if f.Name == edge.Name {
+			return f, nil
+		}
+	}
+	for _, f := range e.Graph.Edges.Slice() {
+		if subset(edge.Vertices, f.Vertices) {
+			return f, nil
+		}
+	}
+	return lib.Edge{}, fmt.Errorf("cover edge %v not contained in any edge of the graph", edge)
+}
+
+// reduce computes the relation of each node of the decomposition, and removes all dangling tuples via a
+// bottom-up and a top-down pass of semijoins
+func (e Evaluator) reduce(decomp lib.Decomp) ([]evalNode, error) {
+	if !decomp.Found() {
+		return nil, errors.New("can't evaluate along empty decomposition")
+	}
+
+	relations, err := e.loadRelations()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := flatten(decomp.Root, -1, []evalNode{})
+	for i := range nodes {
+		r := Relation{Tuples: []Tuple{{}}} // the join of no relations
+		for _, edge := range nodes[i].cover {
+			f, err := e.coverEdge(edge)
+			if err != nil {
+				return nil, err
+			}
+			r = r.Join(relations[f.Name])
+		}
+		nodes[i].relation = r.Project(nodes[i].bag)
+	}
+
+	// every edge filters the first node whose bag contains it
+	for _, edge := range e.Graph.Edges.Slice() {
+		covered := false
+		for i := range nodes {
+			if subset(edge.Vertices, nodes[i].bag) {
+				nodes[i].relation = nodes[i].relation.Semijoin(relations[edge.Name])
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return nil, fmt.Errorf("edge %v not covered by the decomposition", edge)
+		}
+	}
+
+	for i := len(nodes) - 1; i > 0; i-- {
+		p := nodes[i].parent
+		nodes[p].relation = nodes[p].relation.Semijoin(nodes[i].relation)
+	}
+	for i := 1; i < len(nodes); i++ {
+		p := nodes[i].parent
+		nodes[i].relation = nodes[i].relation.Semijoin(nodes[p].relation)
+	}
+
+	for i := range nodes {
+		if i > 0 {
+			nodes[i].shared = nodes[i].relation.shared(nodes[nodes[i].parent].relation)
+		}
+		nodes[i].byParents = nodes[i].relation.index(nodes[i].shared)
+	}
+
+	return nodes, nil
+}
+
+// Evaluate computes all answers of the query along the given decomposition, and passes them to out one at a time,
+// with values ordered as in Attributes. The evaluation stops early once out returns false.
+func (e Evaluator) Evaluate(decomp lib.Decomp, out func(Tuple) bool) error {
+	nodes, err := e.reduce(decomp)
+	if err != nil {
+		return err
+	}
+
+	attributes := e.Attributes()
+	binding := make(map[int]string)
+
+	// after full reduction, every partial answer extends to a full one, so this never backtracks needlessly
+	var enumerate func(i int) bool
+	enumerate = func(i int) bool {
+		if i == len(nodes) {
+			answer := make(Tuple, len(attributes))
+			for j, a := range attributes {
+				answer[j] = binding[a]
+			}
+			return out(answer)
+		}
+
+		var k Tuple
+		for _, a := range nodes[i].shared {
+			k = append(k, binding[a])
+		}
+		allPos := make([]int, len(k))
+		for j := range allPos {
+			allPos[j] = j
+		}
+
+		for _, t := range nodes[i].byParents[key(k, allPos)] {
+			for j, a := range nodes[i].relation.Attributes {
+				binding[a] = t[j]
+			}
+			if !enumerate(i + 1) {
+				return false
+			}
+		}
+		return true
+	}
+	enumerate(0)
+
+	return nil
+}
+
+// Count returns the number of answers of the query
+func (e Evaluator) Count(decomp lib.Decomp) (int, error) {
+	count := 0
+	err := e.Evaluate(decomp, func(Tuple) bool {
+		count++
+		return true
+	})
+	return count, err
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// defaultFeatureSamples is the number of samples probed for the features, if not set by the probe flag
+const defaultFeatureSamples = 5
+
+// probeFeatures are the statistics of probing an algorithm at a width, see algo.Probe
+type probeFeatures struct {
+	Algorithm  string  `json:"algorithm"`
+	Width      int     `json:"width"`
+	Samples    int     `json:"samples"`    // number of random induced subgraphs decomposed
+	Rejected   int     `json:"rejected"`   // number of samples without a decomposition
+	Largest    float64 `json:"largest"`    // largest fraction of vertices in a decomposed sample
+	Confidence float64 `json:"confidence"` // heuristic estimate for a decomposition of the whole graph to exist
+	Millis     float64 `json:"millis"`     // time spent on all samples
+}
+
+// instanceFeatures is the feature vector written by the features flag
+type instanceFeatures struct {
+	Graph string `json:"graph"`
+	lib.Features
+	Probe *probeFeatures `json:"probe,omitempty"` // only set if an algorithm and width were chosen
+}
+
+// probeInstance probes a copy of the solver, so that no state is carried over to the actual search
+func probeInstance(solver algo.Algorithm, gen lib.SearchGenerator, graph lib.Graph, width, samples int) *probeFeatures {
+	clone := solver.Clone()
+	clone.SetGenerator(gen)
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	start := time.Now()
+	result := algo.Probe(clone, graph, samples, r)
+
+	return &probeFeatures{
+		Algorithm:  solver.Name(),
+		Width:      width,
+		Samples:    result.Samples,
+		Rejected:   result.Rejected,
+		Largest:    result.Largest,
+		Confidence: result.Confidence(),
+		Millis:     time.Since(start).Seconds() * float64(time.Second/time.Millisecond),
+	}
+}
+
+// writeFeatures writes the features as JSON to the given file
+func writeFeatures(features instanceFeatures, path string) {
+	out, err := json.MarshalIndent(features, "", "  ")
+	check(err)
+	check(ioutil.WriteFile(path, out, 0644))
+}
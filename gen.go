@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// The gen subcommand produces a random hypergraph in HyperBench format, for scaling studies and for fuzzing the
+// algorithms without collections of instances:
+//
+//	BalancedGo gen -vertices 100 -edges 80 -arity 3 [-model uniform] [-seed 1] [-out graph.hg]
+//
+// The seed is fixed unless given, so that the same arguments always produce the same hypergraph. The exit code is 0
+// once the hypergraph is written, and 2 if the arguments are invalid or the output couldn't be written.
+
+// gen runs the gen subcommand on the given arguments, and returns the exit code
+func gen(args []string) int {
+	flagSet := flag.NewFlagSet("gen", flag.ContinueOnError)
+	vertices := flagSet.Int("vertices", 0, "The number of vertices to choose from, those in no edge are left out")
+	edges := flagSet.Int("edges", 0, "The number of edges")
+	arity := flagSet.Int("arity", 2, "The number of vertices in each edge")
+	modelName := flagSet.String("model", "uniform", "How the vertices of each edge are chosen, one of: "+
+		strings.Join(lib.GraphModels(), ", "))
+	seed := flagSet.Int64("seed", 1, "The seed of the random number generator")
+	out := flagSet.String("out", "", "Write the hypergraph to the given file instead of the standard output")
+
+	if err := flagSet.Parse(args); err != nil || *vertices <= 0 || *edges <= 0 {
+		fmt.Fprintln(os.Stderr, "Usage: BalancedGo gen -vertices <n> -edges <m> [-arity <a>] [-model <model>] "+
+			"[-seed <seed>] [-out <file>]")
+		flagSet.SetOutput(os.Stderr)
+		flagSet.PrintDefaults()
+		return 2
+	}
+
+	model, err := lib.ParseGraphModel(*modelName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	graph, err := lib.RandomGraph(model, *vertices, *edges, *arity, *seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if *out == "" {
+		fmt.Print(graph.ToHyperBench())
+		return 0
+	}
+	if err := ioutil.WriteFile(*out, []byte(graph.ToHyperBench()), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package lib
+
+// balance.go implements alternatives to BalancedCheck, which measure the components w.r.t. a separator differently
+// or restrict their number, to study their effect on the decompositions found and on the time it takes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/disjoint"
+)
+
+// A BalanceMeasure decides how the size of the components w.r.t. a separator is measured, when checking whether the
+// separator is balanced
+type BalanceMeasure int
+
+// The supported balance measures
+const (
+	ByEdges    BalanceMeasure = iota // the number of edges and special edges, as done by BalancedCheck
+	ByVertices                       // the number of vertices outside the separator, see VertexBalancedCheck
+)
+
+var balanceMeasures = map[string]BalanceMeasure{
+	"edges":    ByEdges,
+	"vertices": ByVertices,
+}
+
+// BalanceMeasures returns the names of all balance measures, in alphabetical order
+func BalanceMeasures() []string {
+	var output []string
+
+	for name := range balanceMeasures {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+
+	return output
+}
+
+// ParseBalanceMeasure returns the balance measure of the given name
+func ParseBalanceMeasure(name string) (BalanceMeasure, error) {
+	measure, ok := balanceMeasures[name]
+	if !ok {
+		return ByEdges, fmt.Errorf("unknown balance measure %q, supported are: %v", name, BalanceMeasures())
+	}
+	return measure, nil
+}
+
+// Predicate returns the predicate checking for balanced separators w.r.t. the measure. If maxComps is positive, the
+// separators must also leave at most that many components.
+func (m BalanceMeasure) Predicate(maxComps int) Predicate {
+	var output Predicate = BalancedCheck{}
+	if m == ByVertices {
+		output = VertexBalancedCheck{}
+	}
+	if maxComps > 0 {
+		output = ComponentBound{Inner: output, MaxComps: maxComps}
+	}
+	return output
+}
+
+// VertexBalancedCheck looks for separators balanced w.r.t. the number of vertices instead of edges: no component may
+// have more than (b-1)/b of the vertices of H outside the separator, for balance factor b. So that the recursion
+// still ends, each component together with the separator, added as special edge, must also be smaller than H.
+type VertexBalancedCheck struct{}
+
+// Check performs the needed computation to ensure whether sep is balanced w.r.t. the number of vertices
+func (v VertexBalancedCheck) Check(H *Graph, sep *Edges, balFactor int, Vertices map[int]*disjoint.Element) bool {
+	comps, _, _ := H.GetComponents(*sep, Vertices)
+	sepVertices := sep.VertexSet()
+
+	// each vertex outside sep belongs to exactly one component
+	sizes := make([]int, len(comps))
+	total := 0
+	for i := range comps {
+		for _, x := range comps[i].Vertices() {
+			if !sepVertices.Has(x) {
+				sizes[i]++
+			}
+		}
+		total += sizes[i]
+	}
+
+	balancednessLimit := (total * (balFactor - 1)) / balFactor
+	for i := range comps {
+		if sizes[i] > balancednessLimit || comps[i].Len()+1 >= H.Len() {
+			return false
+		}
+	}
+
+	return !H.IsSpecial(*sep)
+}
+
+// ComponentBound restricts another predicate to separators leaving at most MaxComps components
+type ComponentBound struct {
+	Inner    Predicate
+	MaxComps int
+}
+
+// Check performs the check of the inner predicate, for separators leaving few enough components
+func (c ComponentBound) Check(H *Graph, sep *Edges, balFactor int, Vertices map[int]*disjoint.Element) bool {
+	if !c.Inner.Check(H, sep, balFactor, Vertices) {
+		return false
+	}
+	comps, _, _ := H.GetComponents(*sep, Vertices)
+	return len(comps) <= c.MaxComps
+}
@@ -3,7 +3,6 @@
 package lib
 
 import (
-	"bytes"
 	"sort"
 )
 
@@ -41,10 +40,24 @@ func mem(as []int, b int) bool {
 	return false
 }
 
+// smallSet is the size up to which the set operations on slices compare all pairs of elements, instead of building
+// a VertexSet first
+const smallSet = 8
+
 // Diff computes the set difference between two slices a b
 func Diff(a, b []int) []int {
 	output := make([]int, 0, len(a))
 
+	if len(b) > smallSet {
+		set := NewVertexSet(b)
+		for _, n := range a {
+			if !set.Has(n) {
+				output = append(output, n)
+			}
+		}
+		return output
+	}
+
 OUTER:
 	for _, n := range a {
 		for _, k := range b {
@@ -88,6 +101,17 @@ func diffEdges(a Edges, e ...Edge) Edges {
 // Inter is the set intersection between slices as and bs
 func Inter(as, bs []int) []int {
 	var output []int
+
+	if len(bs) > smallSet {
+		set := NewVertexSet(bs)
+		for _, a := range as {
+			if set.Has(a) {
+				output = append(output, a)
+			}
+		}
+		return output
+	}
+
 OUTER:
 	for _, a := range as {
 		for _, b := range bs {
@@ -106,14 +130,10 @@ func Subset(as []int, bs []int) bool {
 	if len(as) == 0 {
 		return true
 	}
-	encounteredB := make(map[int]struct{})
-	var Empty struct{}
-	for _, b := range bs {
-		encounteredB[b] = Empty
-	}
+	set := NewVertexSet(bs)
 
 	for _, a := range as {
-		if _, ok := encounteredB[a]; !ok {
+		if !set.Has(a) {
 			return false
 		}
 	}
@@ -175,26 +195,11 @@ func sortBySliceBool(a []bool, b []int) {
 	sort.Sort(sortByOtherBool(two))
 }
 
-// PrintVertices will pretty print an int slice using the encodings in the m map
+// PrintVertices will pretty print an int slice, use Encoding.PrintVertices with the encoding of a graph to print names
 func PrintVertices(vertices []int) string {
-	mutex.RLock()
-	defer mutex.RUnlock()
-
-	var buffer bytes.Buffer
-
-	buffer.WriteString("(")
-	for i, v := range vertices {
-		buffer.WriteString(m[v])
-		if i != len(vertices)-1 {
-			buffer.WriteString(", ")
-		}
-	}
-	buffer.WriteString(")")
-
-	return buffer.String()
+	return NewEncoding().PrintVertices(vertices)
 }
 
-// max returns the larger of two integers a and b
 func max(a, b int) int {
 	if a > b {
 		return a
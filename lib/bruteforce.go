@@ -0,0 +1,94 @@
+package lib
+
+// bruteforce.go computes the generalized hypertree width of small graphs exhaustively, without any of the heuristics
+// of the actual algorithms, to serve as ground truth when checking their results
+
+import "math/bits"
+
+// BruteForceLimit is the largest number of vertices for which BruteForceWidth computes the width
+const BruteForceLimit = 16
+
+// BruteForceWidth returns the generalized hypertree width of the graph, and false if it has more than
+// BruteForceLimit vertices.
+//
+// Every GHD can be turned into one whose bags are those of a tree decomposition of the primal graph, produced by an
+// elimination ordering, without increasing the width, since the number of edges needed to cover a bag can only
+// decrease for its subsets. The best ordering is found by dynamic programming over the sets of vertices eliminated
+// first, in time exponential in the number of vertices.
+func BruteForceWidth(G Graph) (int, bool) {
+	vertices := G.Vertices()
+	n := len(vertices)
+	if n > BruteForceLimit {
+		return 0, false
+	}
+
+	index := make(map[int]uint, n)
+	for i, v := range vertices {
+		index[v] = uint(i)
+	}
+
+	// the neighbours of each vertex in the primal graph, as bitmasks over the indices
+	adjacent := make([]uint32, n)
+	for _, e := range G.Edges.Slice() {
+		var mask uint32
+		for _, v := range e.Vertices {
+			mask |= 1 << index[v]
+		}
+		for _, v := range e.Vertices {
+			adjacent[index[v]] |= mask &^ (1 << index[v])
+		}
+	}
+
+	covers := make(map[uint32]int) // the size of a smallest edge cover of each bag seen so far
+	coverSize := func(bag uint32) int {
+		if size, ok := covers[bag]; ok {
+			return size
+		}
+		var bagVertices []int
+		for m := bag; m != 0; m &= m - 1 {
+			bagVertices = append(bagVertices, vertices[bits.TrailingZeros32(m)])
+		}
+		size := MinCover(bagVertices, FilterVertices(G.Edges, bagVertices)).Len()
+		covers[bag] = size
+		return size
+	}
+
+	// bag returns the bag created when eliminating v after all vertices in eliminated: v and all vertices not yet
+	// eliminated reachable from it via eliminated vertices
+	bag := func(eliminated uint32, v uint) uint32 {
+		reached := uint32(1) << v
+		frontier := reached
+		for frontier != 0 {
+			var next uint32
+			for m := frontier & (eliminated | 1<<v); m != 0; m &= m - 1 {
+				next |= adjacent[bits.TrailingZeros32(m)]
+			}
+			frontier = next &^ reached
+			reached |= next
+		}
+		return reached &^ eliminated
+	}
+
+	// width[S] is the smallest width of eliminating the vertices in S first, in any order
+	width := make([]int, 1<<uint(n))
+	for s := uint32(1); s < uint32(len(width)); s++ {
+		width[s] = -1
+		for m := s; m != 0; m &= m - 1 {
+			v := uint(bits.TrailingZeros32(m))
+			rest := s &^ (1 << v)
+
+			w := width[rest]
+			if w >= width[s] && width[s] >= 0 {
+				continue
+			}
+			if size := coverSize(bag(rest, v)); size > w {
+				w = size
+			}
+			if width[s] < 0 || w < width[s] {
+				width[s] = w
+			}
+		}
+	}
+
+	return width[len(width)-1], true
+}
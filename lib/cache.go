@@ -2,44 +2,155 @@ package lib
 
 // cache.go implements a cache for hypergraph decomposition algorithms, loosely based on Samer and Gottlob 2009
 
-import "sync"
+import (
+	"encoding/gob"
+	"io"
+	"sync"
+)
 
 // compCache stores the hashes of subgraphs for which a separator is known to have failed or succeeded
 type compCache struct {
 	Succ []uint64
-	Fail []uint64
+	Fail []failure
 }
 
-// Cache implements a caching mechanism for generic hypergraph decomposition algorithms
+// A failure records a subgraph for which a separator failed, together with the width this was found at. Failing at
+// some width means no decomposition within that width exists, which also rules out all smaller widths, but says
+// nothing about larger ones: given enough edges, every subgraph can be decomposed. A separator of DetK covering the
+// connector and all edges of the subgraph leaves only its special edges, each covered by a leaf of its own, so no
+// failure is independent of the width, not even one due to the connectivity of the subgraph, and none is tagged so.
+type failure struct {
+	Comp  uint64
+	Width int
+}
+
+// cacheShards is the number of parts the entries of a cache are split into, each behind a lock of its own, so that
+// parallel branches of the search rarely wait for each other
+const cacheShards = 64
+
+// A cacheShard holds the entries of all separators whose hash is mapped to it
+type cacheShard struct {
+	cache map[uint64]*compCache
+	plain map[uint64]map[uint64]int // known failures for subgraphs without special edges, by separator, with width
+	lru   cacheLRU                  // the order in which separators were used, to stay within a memory limit
+	mux   sync.RWMutex
+}
+
+func (s *cacheShard) reset() {
+	s.cache = make(map[uint64]*compCache)
+	s.plain = make(map[uint64]map[uint64]int)
+}
+
+// Cache implements a caching mechanism for generic hypergraph decomposition algorithms. The entries are spread over
+// shards by the hash of their separator, so only lookups and additions for separators in the same shard contend.
 type Cache struct {
-	cache    map[uint64]*compCache
-	cacheMux *sync.RWMutex
-	once     sync.Once
+	shards *[cacheShards]cacheShard
+	width  int // the width new entries are found at, only changed while all shards are locked
+	once   sync.Once
+}
+
+// shard returns the shard holding the entries of a separator
+func (c *Cache) shard(sep uint64) *cacheShard {
+	return &c.shards[sep%cacheShards]
+}
+
+// lock locks all shards, in order
+func (c *Cache) lock() {
+	for i := range c.shards {
+		c.shards[i].mux.Lock()
+	}
+}
+
+func (c *Cache) unlock() {
+	for i := range c.shards {
+		c.shards[i].mux.Unlock()
+	}
+}
+
+// rlock locks all shards for reading, in order
+func (c *Cache) rlock() {
+	for i := range c.shards {
+		c.shards[i].mux.RLock()
+	}
+}
+
+func (c *Cache) runlock() {
+	for i := range c.shards {
+		c.shards[i].mux.RUnlock()
+	}
 }
 
 // CopyRef allows for safe copying of a cache by reference, not value
 func (c *Cache) CopyRef(other *Cache) {
-	if c.cache == nil { // to be sure only an initialised cache is copied
-		c.Init()
-	}
-	c.cacheMux.RLock()
-	defer c.cacheMux.RUnlock()
+	c.Init() // to be sure only an initialised cache is copied
 
-	other.cache = c.cache
-	other.cacheMux = c.cacheMux
+	other.shards = c.shards
 	other.once.Do(func() {}) // if cache is copied, it's assumed to already be initialised, so once is pre-fired here
 }
 
 // Reset will throw out all saved cache entries
 func (c *Cache) Reset() {
-	if c.cacheMux == nil {
+	if c.shards == nil {
 		return // don't do anything if cache wasn't initialised yet
 	}
-	c.cacheMux.Lock()
-	defer c.cacheMux.Unlock()
+	c.lock()
+	defer c.unlock()
+
+	for i := range c.shards {
+		c.shards[i].reset()
+		c.shards[i].recount()
+	}
+}
+
+// SetWidth sets the width at which new entries are found, and drops all entries that don't hold for it. Failures
+// found at a width of at least K are kept, so they are shared when moving to smaller widths. When moving to larger
+// widths, no failures are kept, as none of them is independent of the width.
+func (c *Cache) SetWidth(K int) {
+	c.Init()
+	c.lock()
+	defer c.unlock()
+
+	smaller := K < c.width
+	LogCache.Printf(LogInfo, "Moving from width %d to %d", c.width, K)
+	c.width = K
 
-	c.cache = make(map[uint64]*compCache)
+	for i := range c.shards {
+		s := &c.shards[i]
+		if smaller { // successes only hold for larger widths
+			for _, comps := range s.cache {
+				comps.Succ = nil
+			}
+		}
 
+		for sep, set := range s.plain {
+			for comp, width := range set {
+				if width < K {
+					delete(set, comp)
+				}
+			}
+			if len(set) == 0 {
+				delete(s.plain, sep)
+			}
+		}
+		for sep, comps := range s.cache {
+			comps.Fail = c.holding(comps.Fail)
+			if len(comps.Fail) == 0 && len(comps.Succ) == 0 {
+				delete(s.cache, sep)
+			}
+		}
+		s.recount()
+	}
+}
+
+// holding returns the failures which hold for the current width
+func (c *Cache) holding(fails []failure) []failure {
+	var output []failure
+	for _, f := range fails {
+		if f.Width >= c.width {
+			output = append(output, f)
+		}
+	}
+	return output
 }
 
 // Init needs to be called to initialise the cache
@@ -48,85 +159,206 @@ func (c *Cache) Init() {
 }
 
 func (c *Cache) initFunction() {
-	if c.cache == nil {
-		var newMutex sync.RWMutex
-		c.cacheMux = &newMutex
-		c.cache = make(map[uint64]*compCache)
+	if c.shards == nil {
+		c.shards = new([cacheShards]cacheShard)
+		for i := range c.shards {
+			c.shards[i].reset()
+			c.shards[i].lru.init()
+		}
 	}
 }
 
-// Len returns the number of bindings in the cache
+// Len returns the number of separators with entries in the cache, counting those with failures of subgraphs with
+// and without special edges once
 func (c *Cache) Len() int {
-	c.cacheMux.RLock()
-	defer c.cacheMux.RUnlock()
+	c.rlock()
+	defer c.runlock()
+
+	var output int
+	for i := range c.shards {
+		s := &c.shards[i]
+		output = output + len(s.cache)
+		for sep := range s.plain {
+			if _, ok := s.cache[sep]; !ok {
+				output++
+			}
+		}
+	}
+	return output
+}
 
-	return len(c.cache)
+// cacheFile is the serialised form of a cache
+type cacheFile struct {
+	Cache map[uint64]compCache
+	Plain map[uint64]map[uint64]int
+}
+
+// Save writes all entries of the cache to w, to be read in again by Load
+func (c *Cache) Save(w io.Writer) error {
+	c.Init()
+	c.rlock()
+	defer c.runlock()
+
+	file := cacheFile{Cache: make(map[uint64]compCache), Plain: make(map[uint64]map[uint64]int)}
+	for i := range c.shards {
+		for sep, comps := range c.shards[i].cache {
+			file.Cache[sep] = *comps
+		}
+		for sep, set := range c.shards[i].plain {
+			file.Plain[sep] = set
+		}
+	}
+
+	return gob.NewEncoder(w).Encode(file)
+}
+
+// Load reads entries written by Save, adding those which hold for the current width to the cache. As the hashes of
+// separators and subgraphs depend on the names of the edges, the entries are only meaningful for the same graph,
+// parsed the same way.
+func (c *Cache) Load(r io.Reader) error {
+	var file cacheFile
+	if err := gob.NewDecoder(r).Decode(&file); err != nil {
+		return err
+	}
+
+	c.Init()
+	c.lock()
+	defer c.unlock()
+
+	for sep, comps := range file.Cache {
+		s := c.shard(sep)
+		prev, ok := s.cache[sep]
+		if !ok {
+			prev = &compCache{}
+			s.cache[sep] = prev
+		}
+		prev.Succ = append(prev.Succ, comps.Succ...)
+		prev.Fail = append(prev.Fail, c.holding(comps.Fail)...)
+	}
+	for sep, comps := range file.Plain {
+		s := c.shard(sep)
+		for comp, width := range comps {
+			if width < c.width {
+				continue
+			}
+			set, ok := s.plain[sep]
+			if !ok {
+				set = make(map[uint64]int, len(comps))
+				s.plain[sep] = set
+			}
+			if prev, ok := set[comp]; !ok || width > prev {
+				set[comp] = width
+			}
+		}
+	}
+	for i := range c.shards {
+		c.shards[i].recount()
+	}
+
+	return nil
 }
 
 // AddPositive adds a separator sep and subgraph comp as a known successor case
 // TODO: not really used and tested
 func (c *Cache) AddPositive(sep Edges, comp Graph) {
-	c.cacheMux.Lock()
-
-	_, ok := c.cache[sep.Hash()]
+	hash := sep.Hash()
+	s := c.shard(hash)
+	s.mux.Lock()
+	defer s.mux.Unlock()
 
+	prev, ok := s.cache[hash]
 	if !ok {
-		var newCache compCache
-		c.cache[sep.Hash()] = &newCache
+		prev = &compCache{}
+		s.cache[hash] = prev
 	}
 
-	c.cache[sep.Hash()].Succ = append(c.cache[sep.Hash()].Succ, comp.Hash())
-	c.cacheMux.Unlock()
+	prev.Succ = append(prev.Succ, comp.Hash())
+	s.account(hash)
 }
 
-// AddNegative adds a separator sep and subgraph comp as a known failure case
+// AddNegative adds a separator sep and subgraph comp as a known failure case. Subgraphs without special edges are
+// kept apart in a set per separator, which avoids the scan over all failures of a separator when checking them.
 func (c *Cache) AddNegative(sep Edges, comp Graph) {
-	c.cacheMux.Lock()
-	defer c.cacheMux.Unlock()
+	hash := sep.Hash()
+	s := c.shard(hash)
+	s.mux.Lock()
+	defer s.mux.Unlock()
 
-	_, ok := c.cache[sep.Hash()]
+	if len(comp.Special) == 0 {
+		set, ok := s.plain[hash]
+		if !ok {
+			set = make(map[uint64]int)
+			s.plain[hash] = set
+		}
+		set[comp.Edges.Hash()] = c.width
+		s.account(hash)
+		return
+	}
+
+	prev, ok := s.cache[hash]
 	if !ok {
-		var newCache compCache
-		c.cache[sep.Hash()] = &newCache
+		prev = &compCache{}
+		s.cache[hash] = prev
 	}
 
-	c.cache[sep.Hash()].Fail = append(c.cache[sep.Hash()].Fail, comp.Hash())
+	prev.Fail = append(prev.Fail, failure{Comp: comp.Hash(), Width: c.width})
+	s.account(hash)
 }
 
 // CheckNegative checks for a separator sep and a subgraph whether it is a known failure case
 func (c *Cache) CheckNegative(sep Edges, comps []Graph) bool {
-	c.cacheMux.RLock()
-	defer c.cacheMux.RUnlock()
+	hash := sep.Hash()
+	s := c.shard(hash)
+	s.mux.RLock()
+	defer s.mux.RUnlock()
 
 	//check cache for previous encounters
-	compCachePrev, ok := c.cache[sep.Hash()]
+	compCachePrev, ok := s.cache[hash]
+	plainPrev, okPlain := s.plain[hash]
 
-	if !ok { // sep not encountered before
+	if !ok && !okPlain { // sep not encountered before
+		Stats.CacheLookup(false)
 		return false
 	}
+	s.touch(hash)
 
 	for j := range comps {
+		if len(comps[j].Special) == 0 {
+			if _, found := plainPrev[comps[j].Edges.Hash()]; found {
+				Stats.CacheLookup(true)
+				return true
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
 		for i := range compCachePrev.Fail {
-			if comps[j].Hash() == compCachePrev.Fail[i] {
+			if comps[j].Hash() == compCachePrev.Fail[i].Comp {
+				Stats.CacheLookup(true)
 				return true
 			}
 		}
 	}
 
+	Stats.CacheLookup(false)
 	return false
 }
 
 // CheckPositive checks for a separator sep and a subgraph whether it is a known successor case
 // TODO: not really used and tested
 func (c *Cache) CheckPositive(sep Edges, comps []Graph) bool {
-	c.cacheMux.RLock()
-	defer c.cacheMux.RUnlock()
+	hash := sep.Hash()
+	s := c.shard(hash)
+	s.mux.RLock()
+	defer s.mux.RUnlock()
 
-	compCachePrev, ok := c.cache[sep.Hash()]
+	compCachePrev, ok := s.cache[hash]
 
 	if !ok { // sep not encountered before
 		return false
 	}
+	s.touch(hash)
 
 	for j := range comps {
 		for i := range compCachePrev.Succ {
@@ -0,0 +1,187 @@
+package lib
+
+// cachelimit.go bounds the memory used by a cache. Once the approximate size of its entries exceeds the limit, the
+// entries of the separators least recently added to or looked up are evicted. Evicting entries is always safe, as
+// they only serve to skip separators known to fail. The limit is split evenly among the shards of the cache, each
+// keeping track of the order its own separators were used in.
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cacheLRU keeps the separators of a shard in the order they were last used, together with the size of their entries
+type cacheLRU struct {
+	limited  bool
+	limit    int // in bytes
+	size     int // approximate size of all entries, only tracked if limited
+	order    *list.List
+	elements map[uint64]*list.Element
+	evicted  int
+	mux      sync.Mutex // lookups only hold a read lock of the shard, but still move separators to the front
+}
+
+// lruEntry is the value of an element of the order
+type lruEntry struct {
+	sep  uint64
+	size int
+}
+
+func (l *cacheLRU) init() {
+	l.order = list.New()
+	l.elements = make(map[uint64]*list.Element)
+}
+
+// SetLimit bounds the approximate number of bytes used by the entries of the cache, evicting the least recently used
+// ones if needed. A limit ≤ 0 means no bound. As each shard stays within its part of the limit on its own, the
+// separators evicted are only the least recently used ones among those of the same shard.
+func (c *Cache) SetLimit(bytes int) {
+	c.Init()
+	c.lock()
+	defer c.unlock()
+
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lru.limited = bytes > 0
+		s.lru.limit = bytes / cacheShards
+		if i < bytes%cacheShards {
+			s.lru.limit++
+		}
+		s.recount()
+	}
+}
+
+// Limit returns the bound on the approximate number of bytes used by the cache, see SetLimit
+func (c *Cache) Limit() int {
+	c.Init()
+	c.rlock()
+	defer c.runlock()
+
+	var output int
+	for i := range c.shards {
+		output = output + c.shards[i].lru.limit
+	}
+	return output
+}
+
+// Evictions returns the number of separators whose entries were evicted to keep the cache within its limit
+func (c *Cache) Evictions() int {
+	c.Init()
+	c.rlock()
+	defer c.runlock()
+
+	var output int
+	for i := range c.shards {
+		output = output + c.shards[i].lru.evicted
+	}
+	return output
+}
+
+// entrySize returns the approximate number of bytes used by the entries of a separator, needs the shard to be locked
+func (s *cacheShard) entrySize(sep uint64) int {
+	var output int
+	if comps, ok := s.cache[sep]; ok {
+		output = output + comps.memSize()
+	}
+	if set, ok := s.plain[sep]; ok {
+		output = output + plainMemSize(set)
+	}
+	return output
+}
+
+// touch marks a separator as used, needs the shard to be at least read locked
+func (s *cacheShard) touch(sep uint64) {
+	if !s.lru.limited {
+		return
+	}
+	s.lru.mux.Lock()
+	defer s.lru.mux.Unlock()
+
+	if e, ok := s.lru.elements[sep]; ok {
+		s.lru.order.MoveToFront(e)
+	}
+}
+
+// account updates the size of the entries of a separator after they changed, marks it as used and evicts others if
+// the limit is exceeded. Needs the shard to be locked.
+func (s *cacheShard) account(sep uint64) {
+	if !s.lru.limited {
+		return
+	}
+	s.lru.mux.Lock()
+	defer s.lru.mux.Unlock()
+
+	size := s.entrySize(sep)
+	if e, ok := s.lru.elements[sep]; ok {
+		entry := e.Value.(*lruEntry)
+		s.lru.size = s.lru.size - entry.size + size
+		entry.size = size
+		s.lru.order.MoveToFront(e)
+	} else {
+		s.lru.elements[sep] = s.lru.order.PushFront(&lruEntry{sep: sep, size: size})
+		s.lru.size = s.lru.size + size
+	}
+
+	s.evict()
+}
+
+// evict removes the least recently used separators until the shard is within its limit, the most recently used one
+// is always kept. Needs the shard and the order to be locked.
+func (s *cacheShard) evict() {
+	for s.lru.size > s.lru.limit && s.lru.order.Len() > 1 {
+		entry := s.lru.order.Remove(s.lru.order.Back()).(*lruEntry)
+		delete(s.lru.elements, entry.sep)
+		delete(s.cache, entry.sep)
+		delete(s.plain, entry.sep)
+		s.lru.size = s.lru.size - entry.size
+		s.lru.evicted++
+		if LogCache.Enabled(LogDebug) {
+			LogCache.Printf(LogDebug, "Evicted the entries of separator %x, %d bytes", entry.sep, entry.size)
+		}
+	}
+}
+
+// recount brings the order in line with the entries of the shard after many of them changed at once, keeping the
+// order of the separators still present and adding new ones as least recently used. Needs the shard to be locked.
+func (s *cacheShard) recount() {
+	s.lru.mux.Lock()
+	defer s.lru.mux.Unlock()
+
+	if !s.lru.limited {
+		s.lru.size = 0
+		s.lru.init()
+		return
+	}
+
+	s.lru.size = 0
+	for e := s.lru.order.Front(); e != nil; {
+		next := e.Next()
+		entry := e.Value.(*lruEntry)
+		_, ok := s.cache[entry.sep]
+		_, okPlain := s.plain[entry.sep]
+		if !ok && !okPlain {
+			s.lru.order.Remove(e)
+			delete(s.lru.elements, entry.sep)
+		} else {
+			entry.size = s.entrySize(entry.sep)
+			s.lru.size = s.lru.size + entry.size
+		}
+		e = next
+	}
+
+	add := func(sep uint64) {
+		if _, ok := s.lru.elements[sep]; !ok {
+			size := s.entrySize(sep)
+			s.lru.elements[sep] = s.lru.order.PushBack(&lruEntry{sep: sep, size: size})
+			s.lru.size = s.lru.size + size
+		}
+	}
+	for sep := range s.cache {
+		add(sep)
+	}
+	for sep := range s.plain {
+		add(sep)
+	}
+
+	s.evict()
+}
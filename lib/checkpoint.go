@@ -0,0 +1,173 @@
+package lib
+
+// checkpoint.go saves the state of the search for separators of the whole graph, so that a decomposition can be
+// resumed after a crash or preemption without trying again the separators rejected so far
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A CheckpointGen wraps a SearchGenerator, keeping the state of the searches for separators of Graph itself, i.e. at
+// the root of a decomposition. The state is recorded each time the next separator is looked for, when all the
+// separators returned before are known to be rejected, and once the search is exhausted. Searches ended by the
+// cancellation of the context are never recorded as rejecting anything. A search with the generators of a recorded
+// one continues from where the recorded one was, so a run resumed from the state skips the rejected separators.
+// The separators below the root are searched for again, as are the widths below the one of the state in exact mode,
+// unless their search at the root was exhausted.
+type CheckpointGen struct {
+	SearchGenerator
+	Graph    Graph
+	Setting  string        // describes the algorithm and options, which must be the same for a run resumed
+	Interval time.Duration // how often the state is passed to Save, if positive
+	Save     func(state []byte)
+
+	mux      *sync.Mutex
+	saved    map[string][][]byte // the generators of each search by the key of their initial state
+	restored map[string]bool     // the searches already continued from a restored state
+	lastSave time.Time
+}
+
+// checkpointState is the encoding of the state of a CheckpointGen
+type checkpointState struct {
+	Graph    uint64
+	Setting  string
+	Searches map[string][][]byte
+}
+
+// NewCheckpointGen returns a CheckpointGen for the searches of gen for separators of G, described by setting
+func NewCheckpointGen(gen SearchGenerator, G Graph, setting string) *CheckpointGen {
+	return &CheckpointGen{SearchGenerator: gen, Graph: G, Setting: setting, mux: &sync.Mutex{},
+		saved: make(map[string][][]byte), restored: make(map[string]bool), lastSave: time.Now()}
+}
+
+// Context returns the context of the wrapped SearchGenerator
+func (c *CheckpointGen) Context() context.Context {
+	return SearchContext(c.SearchGenerator)
+}
+
+// GetSearch sets up a search of the wrapped generator, which is checkpointed if it's for separators of Graph
+func (c *CheckpointGen) GetSearch(H *Graph, Edges *Edges, BalFactor int, Gens []Generator) Search {
+	search := c.SearchGenerator.GetSearch(H, Edges, BalFactor, Gens)
+	if len(H.Special) > 0 || H.Edges.Len() != c.Graph.Edges.Len() || H.Edges.Hash() != c.Graph.Edges.Hash() {
+		return search
+	}
+
+	key := searchKey(Gens)
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if state, ok := c.saved[key]; ok && !c.restored[key] && len(state) == len(Gens) {
+		for i := range Gens {
+			if err := Gens[i].Restore(state[i]); err != nil {
+				log.Panicf("Can't restore a search matching the checkpoint: %v", err)
+			}
+		}
+		if LogSearch.Enabled(LogInfo) {
+			LogSearch.Printf(LogInfo, "Resuming a search for separators of %v from the checkpoint", H)
+		}
+	}
+	c.restored[key] = true
+
+	return &checkpointSearch{Search: search, gen: c, key: key, generators: Gens}
+}
+
+// searchKey identifies a search by the initial state of its generators
+func searchKey(gens []Generator) string {
+	h := fnv.New64a()
+	for _, gen := range gens {
+		h.Write(gen.Save())
+	}
+	return strconv.FormatUint(h.Sum64(), 16) + "/" + strconv.Itoa(len(gens))
+}
+
+// record keeps the state of the generators of a search, saving all states if the interval has passed since the last
+// time. The generators must not be in use by the search.
+func (c *CheckpointGen) record(key string, gens []Generator) {
+	state := make([][]byte, len(gens))
+	for i := range gens {
+		state[i] = gens[i].Save()
+	}
+
+	c.mux.Lock()
+	c.saved[key] = state
+	due := c.Save != nil && c.Interval > 0 && time.Since(c.lastSave) >= c.Interval
+	c.mux.Unlock()
+
+	if due {
+		c.Flush()
+	}
+}
+
+// Flush passes the state recorded so far to Save, if set
+func (c *CheckpointGen) Flush() {
+	if c.Save == nil {
+		return
+	}
+	c.Save(c.State())
+
+	c.mux.Lock()
+	c.lastSave = time.Now()
+	c.mux.Unlock()
+}
+
+// State encodes the state recorded so far, such that Restore continues the searches from it
+func (c *CheckpointGen) State() []byte {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	output, err := json.Marshal(checkpointState{Graph: c.Graph.Edges.Hash(), Setting: c.Setting, Searches: c.saved})
+	if err != nil {
+		panic(err)
+	}
+	return output
+}
+
+// Restore makes the searches continue from a state encoded by State, which must come from a CheckpointGen for the
+// same graph and setting. It must be called before any search is set up.
+func (c *CheckpointGen) Restore(state []byte) error {
+	var saved checkpointState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+	if saved.Graph != c.Graph.Edges.Hash() {
+		return fmt.Errorf("state was saved for another graph")
+	}
+	if saved.Setting != c.Setting {
+		return fmt.Errorf("state was saved for %q, not %q", saved.Setting, c.Setting)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if saved.Searches != nil {
+		c.saved = saved.Searches
+	}
+	return nil
+}
+
+// A checkpointSearch records the state of its generators for its CheckpointGen
+type checkpointSearch struct {
+	Search
+	gen        *CheckpointGen
+	key        string
+	generators []Generator
+}
+
+// FindNext records the state of the generators first, as the separator found before is rejected once the next one is
+// looked for, unless the context is done, and again if the search is exhausted
+func (s *checkpointSearch) FindNext(pred Predicate) {
+	ctx := s.gen.Context()
+	if ctx.Err() == nil {
+		s.gen.record(s.key, s.generators)
+	}
+
+	s.Search.FindNext(pred)
+
+	if s.Search.SearchEnded() && ctx.Err() == nil {
+		s.gen.record(s.key, s.generators)
+	}
+}
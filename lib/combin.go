@@ -8,6 +8,8 @@ package lib
 // Package combin implements routines involving combinatorics (permutations,
 // combinations, etc.).
 
+import "fmt"
+
 // binomial returns the binomial coefficient of (n,k), also commonly referred to
 // as "n choose k".
 //
@@ -116,7 +118,8 @@ func nextCombinationStep(s []int, n, k, step int) (bool, int) {
 // Step simply advances the iterator multiple steps at a time
 // Returns the number of steps performed
 func (c *CombinationIterator) advance(step int) (bool, int) {
-	if c.Empty {
+	if c.Empty || c.K <= 0 || c.N <= 0 { // nothing to choose from, or nothing to choose
+		c.Empty = true
 		return false, 0
 	}
 	if c.Combination == nil {
@@ -201,12 +204,77 @@ func (c *CombinationIterator) Confirm() {
 	c.Confirmed = true
 }
 
-//SplitCombin generates multiple iterators, splitting the search space into multiple "splits"
+// Save encodes the state of the iterator, such that Restore continues with the same combinations, including any
+// candidate not yet confirmed
+func (c *CombinationIterator) Save() []byte {
+	output, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return output
+}
+
+// Restore continues from a state encoded by Save. The state must come from an iterator over the same combinations,
+// with the same step size, i.e. from the same split of SplitCombin.
+func (c *CombinationIterator) Restore(state []byte) error {
+	var saved CombinationIterator
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+	if saved.N != c.N || saved.OldK != c.OldK || saved.StepSize != c.StepSize || saved.Extended != c.Extended {
+		return fmt.Errorf("state of %v out of %v combinations with step %v doesn't match the iterator", saved.OldK,
+			saved.N, saved.StepSize)
+	}
+	if saved.K < 0 || saved.K > saved.OldK || saved.Combination != nil && len(saved.Combination) != saved.K {
+		return fmt.Errorf("invalid combination %v in state", saved.Combination)
+	}
+	for i, x := range saved.Combination {
+		if x < 0 || x >= saved.N || i > 0 && x <= saved.Combination[i-1] {
+			return fmt.Errorf("invalid combination %v in state", saved.Combination)
+		}
+	}
+
+	*c = saved
+	return nil
+}
+
+// Split divides the combinations remaining for the iterator with another one, returned by Split, by doubling the step
+// size of both, such that each continues with every other of them. It returns nil if no combinations remain for the
+// new iterator. The iterator must not be in use by a search.
+func (c *CombinationIterator) Split() *CombinationIterator {
+	if c.Empty {
+		return nil
+	}
+
+	other := *c
+	other.Combination = append([]int(nil), c.Combination...)
+	other.BalSep = false
+	if other.Combination == nil { // the first combination is yet to be returned by c
+		other.HasNext()
+	}
+	other.Confirmed = true // the current combination is left to c, so that other moves on by one step first
+	if !other.HasNext() {
+		return nil
+	}
+
+	c.StepSize *= 2
+	other.StepSize *= 2
+	return &other
+}
+
+//SplitCombin generates multiple iterators, splitting the search space into multiple "splits". If there is nothing to
+// choose from, or nothing to choose, no iterators are produced at all.
 func SplitCombin(n int, k int, split int, unextended bool) []Generator {
 	if k > n {
 		k = n
 	}
 	var output []Generator
+	if k <= 0 {
+		return output
+	}
+	if split < 1 {
+		split = 1
+	}
 
 	initial := CombinationIterator{N: n, OldK: k, K: k, StepSize: split, Extended: !unextended, Confirmed: true}
 	output = append(output, &initial)
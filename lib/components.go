@@ -0,0 +1,240 @@
+//go:build !componentsref
+// +build !componentsref
+
+package lib
+
+// components.go computes the connected components of a graph w.r.t. a separator with a union-find data structure.
+// Building with the tag componentsref replaces it by the straightforward search in components_reference.go, to verify
+// the results of the algorithms don't depend on it.
+
+import "github.com/cem-okulmus/disjoint"
+
+// components implements GetComponents with a union-find data structure over the vertices
+func (g Graph) components(sep Edges, vertices map[int]*disjoint.Element) ([]Graph, map[int]int, []Edge) {
+	if len(g.Special) == 0 {
+		return g.getComponentsPlain(sep, vertices)
+	}
+
+	var outputG []Graph
+
+	// var vertices = make(map[int]*disjoint.Element, len(g.Vertices()))
+	var comps = make(map[*disjoint.Element][]Edge)
+	var compsSp = make(map[*disjoint.Element][]SpecialEdge)
+
+	scratch := getComponentScratch(0, sep)
+	defer scratch.release()
+	balSepCache := scratch.sep
+
+	//  Set up the disjoint sets for each node
+	resetElements(g, vertices)
+
+	// Merge together the connected components
+	for k := range g.Edges.Slice() {
+		for i := 0; i < len(g.Edges.Slice()[k].Vertices); i++ {
+			if balSepCache.Has(g.Edges.Slice()[k].Vertices[i]) {
+				continue
+			}
+			for j := i + 1; j < len(g.Edges.Slice()[k].Vertices); j++ {
+				if balSepCache.Has(g.Edges.Slice()[k].Vertices[j]) {
+					continue
+				}
+
+				disjoint.Union(vertices[g.Edges.Slice()[k].Vertices[i]], vertices[g.Edges.Slice()[k].Vertices[j]])
+				i = j - 1
+				break
+			}
+		}
+	}
+
+	for k := range g.Special {
+		for i := 0; i < len(g.Special[k].Vertices())-1; i++ {
+			if balSepCache.Has(g.Special[k].Vertices()[i]) {
+				continue
+			}
+			for j := i + 1; j < len(g.Special[k].Vertices()); j++ {
+				if balSepCache.Has(g.Special[k].Vertices()[j]) {
+					continue
+				}
+				disjoint.Union(vertices[g.Special[k].Vertices()[i]], vertices[g.Special[k].Vertices()[j]])
+				i = j - 1
+				break
+			}
+		}
+	}
+
+	var isolatedEdges []Edge
+
+	//sort each edge and special edge to a corresponding component
+	for i := range g.Edges.Slice() {
+		var vertexRep int
+		found := false
+		for _, v := range g.Edges.Slice()[i].Vertices {
+			if balSepCache.Has(v) {
+				continue
+			}
+			vertexRep = v
+			found = true
+			break
+		}
+		if !found {
+			isolatedEdges = append(isolatedEdges, g.Edges.Slice()[i])
+			continue
+		}
+
+		slice, ok := comps[vertices[vertexRep].Find()]
+		if !ok {
+			newslice := make([]Edge, 0, g.Edges.Len())
+			comps[vertices[vertexRep].Find()] = newslice
+			slice = newslice
+		}
+
+		comps[vertices[vertexRep].Find()] = append(slice, g.Edges.Slice()[i])
+	}
+
+	var isolatedSp []SpecialEdge
+	for i := range g.Special {
+		var vertexRep int
+		found := false
+		for _, v := range g.Special[i].Vertices() {
+			if balSepCache.Has(v) {
+				continue
+			}
+			vertexRep = v
+			found = true
+			break
+		}
+		if !found {
+			isolatedSp = append(isolatedSp, g.Special[i])
+			continue
+		}
+
+		slice, ok := compsSp[vertices[vertexRep].Find()]
+		if !ok {
+			newslice := make([]SpecialEdge, 0, len(g.Special))
+			compsSp[vertices[vertexRep].Find()] = newslice
+			slice = newslice
+		}
+
+		compsSp[vertices[vertexRep].Find()] = append(slice, g.Special[i])
+	}
+
+	edgeToComp := make(map[int]int)
+
+	// Store the components as graphs, in the order their first edge or special edge appears in g
+	for _, k := range componentOrder(g, vertices, balSepCache) {
+		slice, ok := comps[k]
+		if !ok {
+			slice = []Edge{} // only special edges
+		}
+		for i := range slice {
+			edgeToComp[slice[i].Name] = len(outputG)
+		}
+		g := Graph{Edges: NewEdges(slice), Special: compsSp[k], encoding: g.encoding}
+		outputG = append(outputG, g)
+	}
+
+	for i := range isolatedSp {
+		g := Graph{Edges: NewEdges([]Edge{}), Special: []SpecialEdge{isolatedSp[i]}, encoding: g.encoding}
+		outputG = append(outputG, g)
+	}
+
+	return outputG, edgeToComp, isolatedEdges
+}
+
+// componentOrder returns the representatives of the components found by GetComponents, in the order their first
+// edge or special edge appears in g, so that components are always returned in the same order
+func componentOrder(g Graph, vertices map[int]*disjoint.Element, sepVertices VertexSet) []*disjoint.Element {
+	var output []*disjoint.Element
+	seen := make(map[*disjoint.Element]bool)
+
+	add := func(edgeVertices []int) {
+		for _, v := range edgeVertices {
+			if sepVertices.Has(v) {
+				continue
+			}
+			if root := vertices[v].Find(); !seen[root] {
+				seen[root] = true
+				output = append(output, root)
+			}
+			return
+		}
+	}
+	for _, e := range g.Edges.Slice() {
+		add(e.Vertices)
+	}
+	for i := range g.Special {
+		add(g.Special[i].Vertices())
+	}
+
+	return output
+}
+
+// getComponentsPlain is the same as components, for the common case of graphs without special edges
+func (g Graph) getComponentsPlain(sep Edges, vertices map[int]*disjoint.Element) ([]Graph, map[int]int, []Edge) {
+	edges := g.Edges.Slice()
+	scratch := getComponentScratch(len(edges), sep)
+	defer scratch.release()
+	balSepCache, reps := scratch.sep, scratch.reps
+
+	//  Set up the disjoint sets for each node
+	resetElements(g, vertices)
+
+	// Merge together the connected components, each edge only needs to be joined along its first free vertex
+	for k := range edges {
+		for _, v := range edges[k].Vertices {
+			if balSepCache.Has(v) {
+				continue
+			}
+			if reps[k] == nil {
+				reps[k] = vertices[v]
+			} else {
+				disjoint.Union(reps[k], vertices[v])
+			}
+		}
+	}
+
+	// Number the components in the order their first edge appears in g, the root of each pointing to its number
+	// via the first edge found in it
+	var isolatedEdges []Edge
+	first := make(map[*disjoint.Element]int)
+	for k := range edges {
+		if reps[k] == nil {
+			isolatedEdges = append(isolatedEdges, edges[k])
+			scratch.comps[k] = -1
+			continue
+		}
+
+		root := reps[k].Find()
+		c, ok := first[root]
+		if !ok {
+			c = len(scratch.sizes)
+			first[root] = c
+			scratch.sizes = append(scratch.sizes, 0)
+		}
+		scratch.comps[k] = c
+		scratch.sizes[c]++
+	}
+
+	// All components share one backing array, each limited to its own part of it
+	all := make([]Edge, len(edges)-len(isolatedEdges))
+	slices := make([][]Edge, len(scratch.sizes))
+	offset := 0
+	for c, size := range scratch.sizes {
+		slices[c] = all[offset : offset : offset+size]
+		offset += size
+	}
+	edgeToComp := make(map[int]int, len(all))
+	for k := range edges {
+		if c := scratch.comps[k]; c >= 0 {
+			slices[c] = append(slices[c], edges[k])
+			edgeToComp[edges[k].Name] = c
+		}
+	}
+
+	outputG := make([]Graph, len(slices))
+	for c := range slices {
+		outputG[c] = Graph{Edges: NewEdges(slices[c]), encoding: g.encoding}
+	}
+
+	return outputG, edgeToComp, isolatedEdges
+}
@@ -0,0 +1,105 @@
+//go:build componentsref
+// +build componentsref
+
+package lib
+
+// components_reference.go computes the connected components of a graph w.r.t. a separator by a plain breadth-first
+// search over the edges, as a reference for the union-find implementation in components.go
+
+import "github.com/cem-okulmus/disjoint"
+
+// components implements GetComponents by searching the edges and special edges reachable via vertices outside of sep.
+// The vertices map is not used.
+func (g Graph) components(sep Edges, vertices map[int]*disjoint.Element) ([]Graph, map[int]int, []Edge) {
+	sepVertices := sep.VertexSet()
+	edges := g.Edges.Slice()
+
+	// the edges come first, followed by the special edges
+	free := make([][]int, len(edges)+len(g.Special))
+	for i := range edges {
+		free[i] = freeVertices(edges[i].Vertices, sepVertices)
+	}
+	for i := range g.Special {
+		free[len(edges)+i] = freeVertices(g.Special[i].Vertices(), sepVertices)
+	}
+
+	containing := make(map[int][]int)
+	for i := range free {
+		for _, v := range free[i] {
+			containing[v] = append(containing[v], i)
+		}
+	}
+
+	comp := make([]int, len(free))
+	for i := range comp {
+		comp[i] = -1
+	}
+	numComps := 0
+	for i := range free {
+		if comp[i] >= 0 || len(free[i]) == 0 {
+			continue
+		}
+		comp[i] = numComps
+		queue := []int{i}
+		for len(queue) > 0 {
+			j := queue[0]
+			queue = queue[1:]
+			for _, v := range free[j] {
+				for _, l := range containing[v] {
+					if comp[l] < 0 {
+						comp[l] = numComps
+						queue = append(queue, l)
+					}
+				}
+			}
+		}
+		numComps++
+	}
+
+	compEdges := make([][]Edge, numComps)
+	compSpecial := make([][]SpecialEdge, numComps)
+	var isolatedEdges []Edge
+	var isolatedSp []SpecialEdge
+	edgeToComp := make(map[int]int)
+
+	for i := range edges {
+		if comp[i] < 0 {
+			isolatedEdges = append(isolatedEdges, edges[i])
+			continue
+		}
+		compEdges[comp[i]] = append(compEdges[comp[i]], edges[i])
+		edgeToComp[edges[i].Name] = comp[i]
+	}
+	for i := range g.Special {
+		if c := comp[len(edges)+i]; c >= 0 {
+			compSpecial[c] = append(compSpecial[c], g.Special[i])
+		} else {
+			isolatedSp = append(isolatedSp, g.Special[i])
+		}
+	}
+
+	var outputG []Graph
+	for c := 0; c < numComps; c++ {
+		if compEdges[c] == nil {
+			compEdges[c] = []Edge{} // only special edges
+		}
+		outputG = append(outputG, Graph{Edges: NewEdges(compEdges[c]), Special: compSpecial[c], encoding: g.encoding})
+	}
+	for i := range isolatedSp {
+		outputG = append(outputG, Graph{Edges: NewEdges([]Edge{}), Special: []SpecialEdge{isolatedSp[i]},
+			encoding: g.encoding})
+	}
+
+	return outputG, edgeToComp, isolatedEdges
+}
+
+// freeVertices returns the vertices not in sep
+func freeVertices(vertices []int, sep VertexSet) []int {
+	var output []int
+	for _, v := range vertices {
+		if !sep.Has(v) {
+			output = append(output, v)
+		}
+	}
+	return output
+}
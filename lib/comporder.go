@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A ComponentOrder decides in which order the components of a separator are decomposed. For infeasible widths, this
+// materially affects how fast a separator gets rejected.
+type ComponentOrder int
+
+// The supported component orders
+const (
+	AsFound       ComponentOrder = iota // keep the order produced by GetComponents
+	LargestFirst                        // fails fast, as large components are the most likely to be rejected
+	SmallestFirst                       // finds easy wins early
+)
+
+var componentOrders = map[string]ComponentOrder{
+	"found":    AsFound,
+	"largest":  LargestFirst,
+	"smallest": SmallestFirst,
+}
+
+// ComponentOrders returns the names of all component orders, in alphabetical order
+func ComponentOrders() []string {
+	var output []string
+
+	for name := range componentOrders {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+
+	return output
+}
+
+// ParseComponentOrder returns the component order of the given name
+func ParseComponentOrder(name string) (ComponentOrder, error) {
+	order, ok := componentOrders[name]
+	if !ok {
+		return AsFound, fmt.Errorf("unknown component order %q, supported are: %v", name, ComponentOrders())
+	}
+	return order, nil
+}
+
+// Sort orders comps in place by their size, i.e. their number of edges and special edges
+func (o ComponentOrder) Sort(comps []Graph) {
+	switch o {
+	case LargestFirst:
+		sort.SliceStable(comps, func(i, j int) bool { return comps[i].Len() > comps[j].Len() })
+	case SmallestFirst:
+		sort.SliceStable(comps, func(i, j int) bool { return comps[i].Len() < comps[j].Len() })
+	}
+}
@@ -181,3 +181,41 @@ func (c *Cover) backtrack() bool {
 	}
 	return true
 }
+
+// MinCover returns a smallest subset of edges covering the vertices, preferring edges with more vertices. The search
+// is exhaustive, so it is only meant for the few edges of a single node. If the edges don't cover all vertices, they
+// are returned unchanged.
+func MinCover(vertices []int, edges Edges) Edges {
+	sorted := append([]Edge{}, edges.Slice()...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Vertices) > len(sorted[j].Vertices) })
+
+	for k := 0; k < len(sorted); k++ {
+		if cover, ok := coverWith(vertices, sorted, k, []Edge{}); ok {
+			return NewEdges(cover)
+		}
+	}
+
+	return edges
+}
+
+// coverWith extends chosen by at most k edges, such that all vertices are covered
+func coverWith(vertices []int, edges []Edge, k int, chosen []Edge) ([]Edge, bool) {
+	if len(vertices) == 0 {
+		return chosen, true
+	}
+	if k == 0 {
+		return nil, false
+	}
+
+	// the first vertex needs to be covered by one of the edges containing it
+	for _, e := range edges {
+		if !e.contains(vertices[0]) {
+			continue
+		}
+		if cover, ok := coverWith(Diff(vertices, e.Vertices), edges, k-1, append(chosen, e)); ok {
+			return cover, true
+		}
+	}
+
+	return nil, false
+}
@@ -2,7 +2,9 @@ package lib
 
 import (
 	"fmt"
-	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // A Decomp (short for Decomposition) consists of a labelled tree which
@@ -14,12 +16,19 @@ type Decomp struct {
 }
 
 func (d Decomp) String() string {
-	return d.Root.String()
+	return d.Root.stringIdent(0, d.Graph.Encoding())
+}
+
+// Found returns true unless the decomp is empty, i.e. the zero value returned by the algorithms if no decomposition
+// was found. Any other decomp has its graph or its root set, so only these are checked, which is much cheaper than
+// comparing against Decomp{} with reflect.DeepEqual, and unaffected by fields added later.
+func (d Decomp) Found() bool {
+	return !d.Graph.isZero() || !d.Root.isZero()
 }
 
 // RestoreSubedges replaces any ad-hoc subedge with actual edges occurring in the graph
 func (d *Decomp) RestoreSubedges() {
-	if reflect.DeepEqual(*d, Decomp{}) { // don't change the empty decomp
+	if !d.Found() { // don't change the empty decomp
 		return
 	}
 
@@ -28,76 +37,274 @@ func (d *Decomp) RestoreSubedges() {
 	d.Root = newRoot
 }
 
-// Correct checks if a decomp full fills the properties of a GHD when given a hypergraph g as input.
-// It also checks for the special condition of HDs, though it merely prints a warning if it is not satisfied,
-// the output is not affected by this additional check.
-func (d Decomp) Correct(g Graph) bool {
-	if reflect.DeepEqual(d, Decomp{}) { // empty Decomp is always false
-		return false
+// SetConnectors records for every node below the root its connector, the vertices it shares with its parent. These
+// are the attachment points of a subtree to the rest of the decomposition, as used by join planners. The connectors
+// are computed from the final tree, since rerooting and the restoration of preprocessing steps change the tree after
+// it was constructed.
+func (d *Decomp) SetConnectors() {
+	d.Root.Conn = nil // the root has no parent
+	d.Root.setConnectors()
+}
+
+func (n *Node) setConnectors() {
+	for i := range n.Children {
+		n.Children[i].Conn = Inter(n.Bag, n.Children[i].Bag)
+		n.Children[i].setConnectors()
+	}
+}
+
+// RerootAt returns the decomp rerooted at a node whose bag contains all the given vertices, such as the output
+// variables of a query, so that Yannakakis-style evaluation can produce the answers at the root. An error is returned
+// if no bag contains all of them. Connectors are not updated, see SetConnectors.
+func (d Decomp) RerootAt(vertices []int) (Decomp, error) {
+	if !d.Root.containsSubset(vertices) {
+		return d, fmt.Errorf("no bag contains all of the vertices %v", d.Graph.Encoding().PrintVertices(vertices))
 	}
 
+	d.Root = d.Root.RerootEdge(vertices)
+	return d, nil
+}
+
+// A Verdict records the outcome of each condition of a GHD checked by Verify, in a form that can be exported as JSON
+type Verdict struct {
+	SameGraph    bool     `json:"sameGraph"`    // the decomp is one of the given graph
+	BagsInCovers bool     `json:"bagsInCovers"` // every bag is a subset of the vertices of its cover
+	EdgesCovered bool     `json:"edgesCovered"` // every edge, and every isolated vertex, is contained in some bag
+	Connected    bool     `json:"connected"`    // the nodes containing a vertex form a connected subtree
+	Width        int      `json:"width"`
+	Problems     []string `json:"problems,omitempty"` // a description of the first violation of each condition
+}
+
+// Valid returns true if all conditions of a GHD are satisfied
+func (v Verdict) Valid() bool {
+	return v.SameGraph && v.BagsInCovers && v.EdgesCovered && v.Connected
+}
+
+// Verify checks each condition of a GHD for the given hypergraph g, independently of the others
+func (d Decomp) Verify(g Graph) Verdict {
+	output := Verdict{SameGraph: true, BagsInCovers: true, EdgesCovered: true, Connected: true}
+	enc := g.Encoding()
+
 	//must be a decomp of same graph
-	if !d.Graph.equal(g) {
+	if !d.Found() || !d.Graph.equal(g) {
+		output.SameGraph = false
 		if d.Graph.Edges.Len() > 0 {
-			fmt.Println("Decomp of different graph")
+			output.Problems = append(output.Problems, "Decomp of different graph")
 		} else {
-			fmt.Println("Empty Decomp")
+			output.Problems = append(output.Problems, "Empty Decomp")
 		}
-		return false
 	}
 
-	//Every bag must be subset of the lambda label
-	if !d.Root.bagSubsets() {
-		fmt.Printf("Bags not subsets of edge labels")
-		return false
-	}
+	//Every bag must be subset of the lambda label, except for isolated vertices, which no edge contains
+	d.Root.forEach(func(n *Node) bool {
+		bag := n.Bag
+		if len(g.Isolated) > 0 {
+			bag = Diff(bag, g.Isolated)
+		}
+		if !Subset(bag, n.Cover.Vertices()) {
+			output.BagsInCovers = false
+			output.Problems = append(output.Problems, "Bag "+enc.PrintVertices(n.Bag)+" not subset of edge label "+
+				n.Cover.stringEnc(enc))
+		}
+		return output.BagsInCovers
+	})
 
 	// Every edge has to be covered
-	for _, e := range d.Graph.Edges.Slice() {
-		if !d.Root.coversEdge(e) {
-			fmt.Println("Edge ", e, " isn't covered")
-			return false
-		}
+	if e, ok := d.Root.uncoveredEdge(g.Edges); ok {
+		output.EdgesCovered = false
+		output.Problems = append(output.Problems, "Edge "+e.stringEnc(enc)+" isn't covered")
+	} else if v, ok := d.Root.missingVertex(g.Isolated); ok {
+		output.EdgesCovered = false
+		output.Problems = append(output.Problems, "Isolated vertex "+enc.Name(v)+" isn't in any bag")
 	}
 
 	//connectedness
-	for _, i := range d.Graph.Edges.Vertices() {
-		nodeCheck, _ := d.Root.connected(i, false)
-		if !nodeCheck {
-			mutex.RLock()
-			fmt.Printf("Vertex %v doesn't span connected subtree\n", m[i])
-			mutex.RUnlock()
-			return false
+	disconnected := d.Root.disconnected()
+	for _, i := range append(append([]int{}, g.Edges.Vertices()...), g.Isolated...) {
+		if mem(disconnected, i) {
+			output.Connected = false
+			output.Problems = append(output.Problems, "Vertex "+enc.Name(i)+" doesn't span connected subtree")
+			break
 		}
 	}
 
+	output.Width = d.CheckWidth()
+	return output
+}
+
+// Correct checks if a decomp full fills the properties of a GHD when given a hypergraph g as input.
+// It also checks for the special condition of HDs, though it merely prints a warning if it is not satisfied,
+// the output is not affected by this additional check.
+func (d Decomp) Correct(g Graph) bool {
+	if !d.Found() { // empty Decomp is always false
+		return false
+	}
+
+	verdict := d.Verify(g)
+	if !verdict.Valid() {
+		fmt.Println(verdict.Problems[0])
+		return false
+	}
+
 	//special condition (optionally)
-	if !d.Root.noSCViolation() {
+	if !d.Root.noSCViolation(d.Graph.Encoding()) {
 		fmt.Println("SCV found!. Not a valid hypertree decomposition!")
 	}
 
 	return true
 }
 
-// CheckWidth returns the size of the largest bag of any node in a decomp
-func (d Decomp) CheckWidth() int {
-	var output = 0
-
-	current := []Node{d.Root}
-
-	// iterate over decomp in BFS
-	for len(current) > 0 {
-		children := []Node{}
-		for _, n := range current {
-			if n.Cover.Len() > output {
-				output = n.Cover.Len()
-			}
-			for _, c := range n.Children {
-				children = append(children, c) // build up the next level of the tree
-			}
+// CorrectTD checks if a decomp is a tree decomposition of the primal graph of g, i.e. every edge of g is contained
+// in some bag, and the nodes containing a vertex form a connected subtree. Covers are not considered.
+func (d Decomp) CorrectTD(g Graph) bool {
+	if !d.Found() {
+		return false
+	}
+	if e, ok := d.Root.uncoveredEdge(g.Edges); ok {
+		fmt.Println("Edge " + e.stringEnc(g.Encoding()) + " isn't contained in any bag")
+		return false
+	}
+	if v, ok := d.Root.missingVertex(g.Isolated); ok {
+		fmt.Println("Isolated vertex " + g.Encoding().Name(v) + " isn't contained in any bag")
+		return false
+	}
+	disconnected := d.Root.disconnected()
+	for _, v := range append(append([]int{}, g.Edges.Vertices()...), g.Isolated...) {
+		if mem(disconnected, v) {
+			fmt.Println("Vertex " + g.Encoding().Name(v) + " doesn't span connected subtree")
+			return false
+		}
+	}
+	return true
+}
+
+// AddIsolated adds a leaf below the root for each isolated vertex of the graph of the decomp missing from all bags,
+// whose bag contains just that vertex and whose cover is empty, so that every vertex of the graph is in some bag
+// without affecting the width. This is meant to be done only once the covers and bags are final.
+func (d Decomp) AddIsolated() Decomp {
+	if !d.Found() || len(d.Graph.Isolated) == 0 {
+		return d
+	}
+
+	missing := d.Root.missingVertices(d.Graph.Isolated)
+	if len(missing) == 0 {
+		return d
+	}
+	children := append([]Node{}, d.Root.Children...) // the slice may be shared with other decomps
+	for _, v := range missing {
+		children = append(children, Node{Bag: []int{v}, Cover: NewEdges([]Edge{})})
+	}
+	d.Root.Children = children
+
+	return d
+}
+
+// Canonical returns a copy of the decomp in a canonical form: the vertices of each bag and connector are sorted, the
+// edges of each cover are sorted by name, and the children of each node are sorted by their canonical keys. Two
+// decomps whose trees are the same up to the order of vertices, edges and children have the same canonical form.
+func (d Decomp) Canonical() Decomp {
+	if !d.Found() {
+		return d
+	}
+
+	d.Root, _ = d.Root.canonical()
+	return d
+}
+
+// CanonicalKey returns a string identifying the tree of the decomp up to the order of vertices, edges and children,
+// as a key for deduplicating decomps, e.g. in a map
+func (d Decomp) CanonicalKey() string {
+	_, key := d.Root.canonical()
+	return key
+}
+
+// Equivalent checks if the decomp and the other one are isomorphic as rooted trees, such that each node is mapped to
+// one with the same bag and cover, regardless of the order of their vertices, edges and children. Rerooting a decomp
+// thus generally results in one that is not equivalent, and neither the graphs nor fractional weights are compared.
+func (d Decomp) Equivalent(other Decomp) bool {
+	return d.CanonicalKey() == other.CanonicalKey()
+}
+
+// canonical returns a copy of the subtree in canonical form, see Decomp.Canonical, together with its canonical key
+func (n Node) canonical() (Node, string) {
+	n.Bag = sortedCopy(n.Bag)
+	if n.Conn != nil {
+		n.Conn = sortedCopy(n.Conn)
+	}
+
+	cover := append([]Edge{}, n.Cover.Slice()...)
+	sort.Slice(cover, func(i, j int) bool { return cover[i].Name < cover[j].Name })
+	n.Cover = NewEdges(cover)
+
+	children := make([]Node, len(n.Children))
+	keys := make([]string, len(n.Children))
+	order := make([]int, len(n.Children))
+	for i := range n.Children {
+		children[i], keys[i] = n.Children[i].canonical()
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+
+	n.Children = make([]Node, len(children))
+	sortedKeys := make([]string, len(children))
+	for i, j := range order {
+		n.Children[i], sortedKeys[i] = children[j], keys[j]
+	}
+	n.parPointer, n.vertices = nil, nil
+
+	var buffer strings.Builder
+	for i, v := range n.Bag {
+		if i > 0 {
+			buffer.WriteByte(',')
 		}
-		current = children
+		buffer.WriteString(strconv.Itoa(v))
 	}
+	buffer.WriteByte('|')
+	for i, e := range cover {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		buffer.WriteString(strconv.Itoa(e.Name))
+	}
+	buffer.WriteByte('(')
+	buffer.WriteString(strings.Join(sortedKeys, ";"))
+	buffer.WriteByte(')')
+
+	return n, buffer.String()
+}
 
+// sortedCopy returns a sorted copy of the slice, leaving it unchanged
+func sortedCopy(s []int) []int {
+	output := append([]int{}, s...)
+	sort.Ints(output)
 	return output
 }
+
+// TreeWidth returns the size of the largest bag of any node in a decomp, minus one
+func (d Decomp) TreeWidth() int {
+	output := 0
+	d.Root.forEach(func(n *Node) bool {
+		if len(n.Bag) > output {
+			output = len(n.Bag)
+		}
+		return true
+	})
+	return output - 1
+}
+
+// TrivialDecomp returns the decomp consisting of a single node, which covers all vertices of the graph with all of
+// its edges. Its width is the number of edges, so there is no need to search for any width at least that large.
+func TrivialDecomp(g Graph) Decomp {
+	return Decomp{Graph: g, Root: Node{Bag: g.Vertices(), Cover: g.Edges}}
+}
+
+// CheckWidth returns the size of the largest cover of any node in a decomp, the same as Width
+func (d Decomp) CheckWidth() int {
+	return d.Width()
+}
+
+// SpecialCondition returns true if no vertex left out of the bag of a node, though covered by its edges, appears in
+// the subtree rooted at it. A correct decomp satisfying this special condition is a hypertree decomposition.
+func (d Decomp) SpecialCondition() bool {
+	return d.Root.noSCViolation(d.Graph.Encoding())
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// A SubtreeDumper writes out each subgraph an algorithm managed to decompose, together with the subtree produced for
+// it, numbered in the order they are produced. Following these files shows step by step how the final decomposition
+// is assembled from the decompositions of the components.
+type SubtreeDumper struct {
+	Dir    string              // nothing is written if empty
+	Notify func(decomp Decomp) // if set, called with each subtree, possibly by several goroutines at once
+	count  int64
+}
+
+// Dump writes the subgraph and subtree of decomp into the directory of the dumper, once as text and once as GML.
+// Calling Dump on a nil dumper does nothing, so algorithms can call it unconditionally.
+func (s *SubtreeDumper) Dump(decomp Decomp) {
+	if s == nil {
+		return
+	}
+	i := atomic.AddInt64(&s.count, 1)
+	if s.Notify != nil {
+		s.Notify(decomp)
+	}
+	if s.Dir == "" {
+		return
+	}
+	decomp.Root = decomp.Root.copyTree() // GML output numbers the nodes, which must not affect the algorithm
+
+	text := fmt.Sprintf("Subgraph:\n%v\n\nSubtree:\n%v", decomp.Graph, decomp)
+	base := filepath.Join(s.Dir, fmt.Sprintf("subtree%05d", i))
+
+	if err := ioutil.WriteFile(base+".txt", []byte(text), 0644); err != nil {
+		fmt.Println("Couldn't dump subtree:", err)
+		return
+	}
+	if err := ioutil.WriteFile(base+".gml", []byte(decomp.ToGML()), 0644); err != nil {
+		fmt.Println("Couldn't dump subtree:", err)
+	}
+}
+
+// copyTree returns a copy of the subtree, not sharing any slice of children
+func (n Node) copyTree() Node {
+	children := make([]Node, len(n.Children))
+	for i := range n.Children {
+		children[i] = n.Children[i].copyTree()
+	}
+	n.Children = children
+
+	return n
+}
+
+// Count returns the number of subtrees dumped so far
+func (s *SubtreeDumper) Count() int {
+	return int(atomic.LoadInt64(&s.count))
+}
@@ -14,56 +14,35 @@ import (
 // An Edge (used here for hyperedge) consists of a collection of vertices and a name
 type Edge struct {
 	Name     int
-	Vertices []int // use integers for vertices
+	Vertices []int   // use integers for vertices
+	Weight   float64 // optional, e.g. the size of the relation, see GetWeight
 }
 
-// FullString always prints the list of vertices of an edge, even if the edge is named
+// FullString always prints the list of vertices of an edge, even if the edge is named. The integers are printed, as an
+// edge doesn't know the names of its graph, see Encoding for printing names.
 func (e Edge) FullString() string {
+	return e.fullStringEnc(NewEncoding())
+}
+
+func (e Edge) fullStringEnc(enc *Encoding) string {
 	var buffer bytes.Buffer
-	mutex.RLock()
-	defer mutex.RUnlock()
 	if e.Name > 0 {
-		buffer.WriteString(m[e.Name])
-	}
-	buffer.WriteString(" (")
-	for i, n := range e.Vertices {
-		var s string
-		if m == nil {
-			s = fmt.Sprintf("%v", n)
-		} else {
-			s = m[n]
-		}
-		buffer.WriteString(s)
-		if i != len(e.Vertices)-1 {
-			buffer.WriteString(", ")
-		}
+		buffer.WriteString(enc.Name(e.Name))
 	}
-	buffer.WriteString(")")
+	buffer.WriteString(" ")
+	buffer.WriteString(enc.PrintVertices(e.Vertices))
 	return buffer.String()
 }
 
 func (e Edge) String() string {
-	mutex.RLock()
-	defer mutex.RUnlock()
+	return e.stringEnc(NewEncoding())
+}
+
+func (e Edge) stringEnc(enc *Encoding) string {
 	if e.Name > 0 {
-		return m[e.Name]
+		return enc.Name(e.Name)
 	}
-	var buffer bytes.Buffer
-	buffer.WriteString("(")
-	for i, n := range e.Vertices {
-		var s string
-		if m == nil {
-			s = fmt.Sprintf("%v", n)
-		} else {
-			s = m[n]
-		}
-		buffer.WriteString(s)
-		if i != len(e.Vertices)-1 {
-			buffer.WriteString(", ")
-		}
-	}
-	buffer.WriteString(")")
-	return buffer.String()
+	return enc.PrintVertices(e.Vertices)
 }
 
 // Edges struct is a slice of Edge, defined for the use of the sort interface,
@@ -136,13 +115,17 @@ func (e Edges) FullString() string {
 	return buffer.String()
 }
 
+// String prints the integers of the edges, use Encoding.PrintEdges with the encoding of their graph to print names
 func (e Edges) String() string {
+	return e.stringEnc(NewEncoding())
+}
 
+func (e Edges) stringEnc(enc *Encoding) string {
 	var buffer bytes.Buffer
 	buffer.WriteString("{")
 
 	for i, e2 := range e.slice {
-		buffer.WriteString(e2.String())
+		buffer.WriteString(e2.stringEnc(enc))
 		if i != len(e.slice)-1 {
 			buffer.WriteString(", ")
 		}
@@ -171,6 +154,11 @@ func (e Edges) Len() int {
 	return len(e.slice)
 }
 
+// isZero returns true if the Edges struct is the zero value, rather than created by NewEdges
+func (e Edges) isZero() bool {
+	return e.slice == nil && e.hashMux == nil
+}
+
 // Swap as used for the sort interface
 func (e Edges) Swap(i, j int) {
 	e.slice[i], e.slice[j] = e.slice[j], e.slice[i]
@@ -327,8 +315,6 @@ func (e *Edges) Diff(other Edges) Edges {
 // FullStringInt always prints the list of vertices of an edge, even if the edge is named
 func (e Edge) FullStringInt() string {
 	var buffer bytes.Buffer
-	mutex.RLock()
-	defer mutex.RUnlock()
 	if e.Name > 0 {
 		buffer.WriteString("E" + strconv.Itoa(e.Name))
 	}
@@ -0,0 +1,251 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// An Encoding maps the integers used to represent vertices and edges to their names. Each parsed graph gets its own
+// encoding, so that several graphs can be parsed, decomposed and printed at the same time. An Encoding is safe for
+// concurrent use.
+type Encoding struct {
+	mux   sync.RWMutex
+	names map[int]string
+	ids   map[string]int // index from the names back to their integers
+	next  int            // the smallest integer not used so far
+}
+
+// NewEncoding returns an empty encoding, with 1 as the first integer handed out
+func NewEncoding() *Encoding {
+	return &Encoding{names: make(map[int]string), ids: make(map[string]int), next: 1}
+}
+
+// newEncodingOf returns an encoding of the given names, numbered from 1 in their order, which must be unique
+func newEncodingOf(names []string) *Encoding {
+	e := &Encoding{names: make(map[int]string, len(names)), ids: make(map[string]int, len(names)), next: 1}
+	for _, name := range names {
+		e.setName(e.next, name)
+		e.next++
+	}
+	return e
+}
+
+// setName binds i to name, keeping the index up to date. Empty names are not indexed.
+func (e *Encoding) setName(i int, name string) {
+	if old, ok := e.names[i]; ok && e.ids[old] == i {
+		delete(e.ids, old)
+	}
+	e.names[i] = name
+	if name != "" {
+		e.ids[name] = i
+	}
+}
+
+// GobEncode is used to serialise an encoding together with the graph using it
+func (e *Encoding) GobEncode() ([]byte, error) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+	if err := encoder.Encode(e.names); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(e.next); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode restores an encoding serialised with GobEncode
+func (e *Encoding) GobDecode(b []byte) error {
+	buf := bytes.NewBuffer(b)
+
+	decoder := gob.NewDecoder(buf)
+	if err := decoder.Decode(&e.names); err != nil {
+		return err
+	}
+	if e.names == nil {
+		e.names = make(map[int]string)
+	}
+	e.ids = make(map[string]int, len(e.names))
+	for k, v := range e.names {
+		if v != "" {
+			e.ids[v] = k
+		}
+	}
+
+	return decoder.Decode(&e.next)
+}
+
+// Add assigns a fresh integer to name and returns it
+func (e *Encoding) Add(name string) int {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	i := e.next
+	e.setName(i, name)
+	e.next++
+	return i
+}
+
+// Set assigns the given integer to name, used by formats which come with their own numbering
+func (e *Encoding) Set(i int, name string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.setName(i, name)
+	if i >= e.next {
+		e.next = i + 1
+	}
+}
+
+// AddUnique assigns a fresh integer to name, or to the first of name_2, name_3 and so on not taken yet, and returns
+// it. The names derive from name alone, so that they are the same on each run.
+func (e *Encoding) AddUnique(name string) int {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	unique := name
+	for n := 2; ; n++ {
+		if _, ok := e.ids[unique]; !ok {
+			break
+		}
+		unique = name + "_" + strconv.Itoa(n)
+	}
+
+	i := e.next
+	e.setName(i, unique)
+	e.next++
+	return i
+}
+
+// Reserve marks the next n integers as used, without naming them
+func (e *Encoding) Reserve(n int) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.next = e.next + n
+}
+
+// Len returns the smallest integer not used so far
+func (e *Encoding) Len() int {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	return e.next
+}
+
+// Name returns the name of an integer. Integers without a name, such as those added by Reserve, get one generated
+// from the integer, as in _12, so that no output shows a blank name. If the encoding holds no names at all, the
+// integer itself is returned.
+func (e *Encoding) Name(i int) string {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	return e.name(i)
+}
+
+func (e *Encoding) name(i int) string {
+	if len(e.names) == 0 {
+		return strconv.Itoa(i)
+	}
+	if name := e.names[i]; name != "" {
+		return name
+	}
+	return "_" + strconv.Itoa(i)
+}
+
+// ID returns the integer of a name, and false if the name is unknown. Names generated by Name are resolved as well.
+func (e *Encoding) ID(name string) (int, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	if i, ok := e.ids[name]; ok {
+		return i, true
+	}
+	if strings.HasPrefix(name, "_") {
+		if i, err := strconv.Atoi(name[1:]); err == nil && i > 0 && i < e.next && e.names[i] == "" {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Inverse returns a map from the names to their integers
+func (e *Encoding) Inverse() map[string]int {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	output := make(map[string]int, len(e.ids))
+	for k, v := range e.ids {
+		output[k] = v
+	}
+	return output
+}
+
+// Transparent overwrites all names with the integers themselves, in order to print out the exact underlying encoding
+func (e *Encoding) Transparent() {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	for i := 0; i < e.next; i++ {
+		e.setName(i, strconv.Itoa(i))
+	}
+}
+
+// PrintVertices will pretty print an int slice using the names of the encoding
+func (e *Encoding) PrintVertices(vertices []int) string {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString("(")
+	for i, v := range vertices {
+		buffer.WriteString(e.name(v))
+		if i != len(vertices)-1 {
+			buffer.WriteString(", ")
+		}
+	}
+	buffer.WriteString(")")
+
+	return buffer.String()
+}
+
+// PrintEdge will print the name of an edge using the encoding, or its vertices if it has no name
+func (e *Encoding) PrintEdge(edge Edge) string {
+	return edge.stringEnc(e)
+}
+
+// PrintFullEdge will print the name of an edge together with its vertices, using the encoding
+func (e *Encoding) PrintFullEdge(edge Edge) string {
+	return edge.fullStringEnc(e)
+}
+
+// PrintEdges will pretty print edges using the names of the encoding, and the vertices of edges without a name
+func (e *Encoding) PrintEdges(edges Edges) string {
+	return edges.stringEnc(e)
+}
+
+var nodeCounter int64 // source of the numbers identifying nodes of decompositions in GML and DOT output
+
+// nextNodeNum returns a fresh number for a node
+func nextNodeNum() int {
+	return int(atomic.AddInt64(&nodeCounter, 1))
+}
+
+// reserveNodeNums ensures that nextNodeNum never returns a number up to max, used when node numbers are parsed
+func reserveNodeNums(max int) {
+	for {
+		old := atomic.LoadInt64(&nodeCounter)
+		if old >= int64(max) || atomic.CompareAndSwapInt64(&nodeCounter, old, int64(max)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package lib
+
+// features.go computes structural metrics of a hypergraph, e.g. to be used as features when learning to predict
+// the width of an instance or which algorithm decomposes it fastest
+
+// Features is a vector of structural metrics of a hypergraph. All of them are cheap to compute compared to any
+// decomposition, the most expensive being the hinge tree.
+type Features struct {
+	Vertices   int     `json:"vertices"`   // number of vertices
+	Edges      int     `json:"edges"`      // number of edges
+	MaxArity   int     `json:"maxArity"`   // size of the largest edge
+	MeanArity  float64 `json:"meanArity"`  // average size of an edge
+	MaxDegree  int     `json:"maxDegree"`  // largest number of edges a single vertex occurs in
+	MeanDegree float64 `json:"meanDegree"` // average number of edges a vertex occurs in
+	// IntersectionSize is the largest number of vertices shared by two distinct edges. The width of a GHD is bounded
+	// by a function of it, and subedges are cheap to compute if it's small.
+	IntersectionSize int `json:"intersectionSize"`
+	// IntersectionDensity is the fraction of pairs of edges sharing some vertex, i.e. the density of the
+	// intersection graph
+	IntersectionDensity float64 `json:"intersectionDensity"`
+	// PrimalDensity is the fraction of pairs of vertices that occur together in some edge
+	PrimalDensity float64 `json:"primalDensity"`
+	// LargestClique is the size of the largest clique of the primal graph found greedily. Its vertices must share a
+	// bag in any decomposition.
+	LargestClique int  `json:"largestClique"`
+	Components    int  `json:"components"`   // number of connected components
+	Acyclic       bool `json:"acyclic"`      // true if the GYÖ reduction removes all edges, i.e. the width is 1
+	ReducedEdges  int  `json:"reducedEdges"` // number of edges left after the GYÖ reduction
+	LargestHinge  int  `json:"largestHinge"` // number of edges of the largest node in the hinge tree
+}
+
+// Features computes the structural metrics of the graph
+func (g Graph) Features() Features {
+	var output Features
+
+	edges := g.Edges.Slice()
+	occurrences := make(map[int][]int) // the positions of the edges each vertex occurs in
+	for i, e := range edges {
+		for _, v := range e.Vertices {
+			occurrences[v] = append(occurrences[v], i)
+		}
+		if len(e.Vertices) > output.MaxArity {
+			output.MaxArity = len(e.Vertices)
+		}
+		output.MeanArity += float64(len(e.Vertices))
+	}
+
+	output.Vertices = len(occurrences)
+	output.Edges = len(edges)
+	if output.Edges > 0 {
+		output.MeanArity /= float64(output.Edges)
+	}
+
+	for _, occ := range occurrences {
+		if len(occ) > output.MaxDegree {
+			output.MaxDegree = len(occ)
+		}
+		output.MeanDegree += float64(len(occ))
+	}
+	if output.Vertices > 0 {
+		output.MeanDegree /= float64(output.Vertices)
+	}
+
+	// count the shared vertices only for pairs of edges that do intersect
+	intersecting := 0
+	for i, e := range edges {
+		shared := make(map[int]int)
+		for _, v := range e.Vertices {
+			for _, j := range occurrences[v] {
+				if j > i {
+					shared[j]++
+				}
+			}
+		}
+		intersecting += len(shared)
+		for _, count := range shared {
+			if count > output.IntersectionSize {
+				output.IntersectionSize = count
+			}
+		}
+	}
+	if output.Edges > 1 {
+		output.IntersectionDensity = float64(2*intersecting) / float64(output.Edges*(output.Edges-1))
+	}
+
+	primal := g.primalGraph()
+	neighbours := 0
+	for _, n := range primal {
+		neighbours += len(n)
+	}
+	if output.Vertices > 1 {
+		output.PrimalDensity = float64(neighbours) / float64(output.Vertices*(output.Vertices-1))
+	}
+	for _, clique := range greedyCliques(primal) {
+		if len(clique) > output.LargestClique {
+			output.LargestClique = len(clique)
+		}
+	}
+
+	comps, _, _ := g.GetComponents(NewEdges(nil), nil)
+	output.Components = len(comps)
+
+	reduced, _ := g.GYÖReduct()
+	output.ReducedEdges = reduced.Edges.Len()
+	output.Acyclic = output.ReducedEdges == 0
+
+	if output.Edges > 0 {
+		output.LargestHinge = GetHingeTree(g).GetLargestGraph().Edges.Len()
+	}
+
+	return output
+}
+
+// DegreeDistribution returns for each degree the number of vertices occurring in exactly that many edges
+func (g Graph) DegreeDistribution() map[int]int {
+	degrees := make(map[int]int)
+	for _, e := range g.Edges.Slice() {
+		for _, v := range e.Vertices {
+			degrees[v]++
+		}
+	}
+
+	output := make(map[int]int)
+	for _, d := range degrees {
+		output[d]++
+	}
+	return output
+}
+
+// ArityDistribution returns for each size the number of edges of exactly that size
+func (g Graph) ArityDistribution() map[int]int {
+	output := make(map[int]int)
+	for _, e := range g.Edges.Slice() {
+		output[len(e.Vertices)]++
+	}
+	return output
+}
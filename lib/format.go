@@ -0,0 +1,200 @@
+package lib
+
+// format.go provides a registry of the supported input formats for hypergraphs, together with the parsers for
+// hypergraphs given as bipartite incidence lists or JSON objects
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// A FormatParser turns the string representation of a hypergraph into a Graph. The returned ParseGraph carries the
+// encoding of the vertex and edge names used in the input.
+type FormatParser func(s string) (Graph, ParseGraph)
+
+var formats = map[string]FormatParser{
+	"hyperbench": GetGraph,
+	"pace":       getGraphPACEEncoded,
+	"incidence":  GetGraphIncidence,
+	"json":       GetGraphJSON,
+	"datalog":    GetGraphDatalog,
+	"sql":        GetGraphSQL,
+	"xcsp":       getGraphXCSPEncoded,
+}
+
+// RegisterFormat adds a new input format to the registry, replacing any existing format of the same name
+func RegisterFormat(name string, parser FormatParser) {
+	formats[name] = parser
+}
+
+// Formats returns the names of all registered input formats, in alphabetical order
+func Formats() []string {
+	var output []string
+
+	for name := range formats {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+
+	return output
+}
+
+// GetGraphFormat parses a string into a graph, using the parser registered for the given format. Gzip-compressed
+// input is decompressed first.
+func GetGraphFormat(format string, s string) (Graph, ParseGraph, error) {
+	parser, ok := formats[format]
+	if !ok {
+		return Graph{}, ParseGraph{}, fmt.Errorf("unknown input format %q, supported are: %s", format,
+			strings.Join(Formats(), ", "))
+	}
+
+	s, err := gunzip(s)
+	if err != nil {
+		return Graph{}, ParseGraph{}, err
+	}
+
+	graph, pgraph := parser(s)
+	return graph, pgraph, nil
+}
+
+// getGraphPACEEncoded wraps GetGraphPACE, additionally returning the encoding of the generated names
+func getGraphPACEEncoded(s string) (Graph, ParseGraph) {
+	graph := GetGraphPACE(s)
+
+	pgraph := ParseGraph{Encoding: graph.Encoding().Inverse(), encoding: graph.Encoding()}
+
+	return graph, pgraph
+}
+
+// getGraphXCSPEncoded wraps GetGraphXCSP, additionally returning the encoding of the names
+func getGraphXCSPEncoded(s string) (Graph, ParseGraph) {
+	return getGraphXCSP(strings.NewReader(s))
+}
+
+// GetGraphJSON parses a string containing a JSON object into a graph, mapping each edge name to the list of names of
+// its vertices, such as {"R": ["x", "y"], "S": ["y", "z"]}. The edges are added in alphabetical order of their names.
+func GetGraphJSON(s string) (Graph, ParseGraph) {
+	var pgraph ParseGraph
+
+	var input map[string][]string
+	if err := json.Unmarshal([]byte(s), &input); err != nil {
+		fmt.Println("Couldn't parse input: ")
+		panic(err)
+	}
+
+	var names []string
+	for name := range input {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pgraph.Edges = append(pgraph.Edges, parseEdge{Name: name, Vertices: input[name]})
+	}
+
+	graph := pgraph.build()
+	return graph, pgraph
+}
+
+// GetGraphIncidence parses a string containing a bipartite incidence list into a graph. Each line consists of a
+// vertex and the name of an edge containing it, separated by whitespace or a comma. A line consisting of a vertex
+// alone declares it, so that vertices in no edge can be given. Lines starting with "%", "//" or "#" are treated as
+// comments.
+func GetGraphIncidence(s string) (Graph, ParseGraph) {
+	var pgraph ParseGraph
+
+	edgeIndex := make(map[string]int)
+	seen := make(map[[2]string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "%") || strings.HasPrefix(line, "//") ||
+			strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ',' || r == ';'
+		})
+		if len(fields) == 1 {
+			pgraph.Isolated = append(pgraph.Isolated, fields[0]) // dropped by build if in some edge after all
+			continue
+		}
+		if len(fields) != 2 {
+			log.Panicln("Incidence list malformed at line", lineNum, ": expected vertex and edge, got", line)
+		}
+
+		vertex, edge := fields[0], fields[1]
+		if _, ok := seen[[2]string{vertex, edge}]; ok {
+			continue // ignore repeated incidences
+		}
+		seen[[2]string{vertex, edge}] = Empty
+
+		i, ok := edgeIndex[edge]
+		if !ok {
+			i = len(pgraph.Edges)
+			edgeIndex[edge] = i
+			pgraph.Edges = append(pgraph.Edges, parseEdge{Name: edge})
+		}
+		pgraph.Edges[i].Vertices = append(pgraph.Edges[i].Vertices, vertex)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("Couldn't parse input: ")
+		panic(err)
+	}
+
+	graph := pgraph.build()
+	return graph, pgraph
+}
+
+// build encodes the parsed edges into a graph, using the same encoding scheme as for HyperBench: vertices first, then
+// edge names. Isolated vertices are encoded after the others, and ignored if they occur in some edge.
+func (p *ParseGraph) build() Graph {
+	var output Graph
+	var edges []Edge
+
+	encoding := NewEncoding()
+	p.Encoding = make(map[string]int)
+	p.encoding = encoding
+
+	for _, e := range p.Edges {
+		for _, n := range e.Vertices {
+			if _, ok := p.Encoding[n]; !ok {
+				p.Encoding[n] = encoding.Add(n)
+			}
+		}
+	}
+	for _, n := range p.Isolated {
+		if _, ok := p.Encoding[n]; !ok {
+			p.Encoding[n] = encoding.Add(n)
+			output.Isolated = append(output.Isolated, p.Encoding[n])
+		}
+	}
+	for _, e := range p.Edges {
+		if _, ok := p.Encoding[e.Name]; ok {
+			log.Panicln("Edge names not unique, not a valid hypergraph!")
+		}
+
+		p.Encoding[e.Name] = encoding.Add(e.Name)
+	}
+
+	edges = make([]Edge, 0, len(p.Edges))
+	for _, e := range p.Edges {
+		vertices := make([]int, 0, len(e.Vertices))
+		for _, n := range e.Vertices {
+			vertices = append(vertices, p.Encoding[n])
+		}
+		edges = append(edges, Edge{Name: p.Encoding[e.Name], Vertices: vertices})
+	}
+
+	encoding.Reserve(len(p.Edges))
+
+	output.Edges = NewEdges(edges)
+	output.encoding = encoding
+	return output
+}
@@ -0,0 +1,131 @@
+package lib
+
+import (
+	"math"
+)
+
+const simplexEps = 1e-9 // tolerance used to compare values in the simplex method
+
+// FractionalCover computes an optimal fractional edge cover of the vertices, i.e. weights for the edges, such that the
+// weights of the edges containing any one of the vertices sum up to at least 1, and the total weight is minimal. It
+// returns the total weight and the weight of each edge, in the order of edges.Slice(). If some vertex is not contained
+// in any edge, no cover exists and the returned weight is infinite.
+//
+// The dual LP, maximising the sum of weights y_v of the vertices such that the vertices of each edge sum up to at
+// most 1, is solved with the simplex method using Bland's rule. The weights of the edges can then be read off the
+// objective row, at the columns of the slack variables.
+func FractionalCover(vertices []int, edges Edges) (float64, []float64) {
+	vertices = RemoveDuplicates(append([]int{}, vertices...))
+	n := len(vertices)
+	m := edges.Len()
+
+	index := make(map[int]int, n)
+	for i, v := range vertices {
+		index[v] = i
+	}
+
+	// tableau with one row per edge, and the objective in the last row; columns are the vertex weights, the
+	// slack variables of each edge and the right hand side
+	width := n + m + 1
+	tab := make([][]float64, m+1)
+	basis := make([]int, m)
+	for i, e := range edges.Slice() {
+		row := make([]float64, width)
+		for _, v := range e.Vertices {
+			if j, ok := index[v]; ok {
+				row[j] = 1
+			}
+		}
+		row[n+i] = 1
+		row[width-1] = 1
+		tab[i] = row
+		basis[i] = n + i
+	}
+	tab[m] = make([]float64, width)
+	for j := 0; j < n; j++ {
+		tab[m][j] = -1
+	}
+
+	for {
+		// entering column: first one with negative reduced cost
+		col := -1
+		for j := 0; j < n+m; j++ {
+			if tab[m][j] < -simplexEps {
+				col = j
+				break
+			}
+		}
+		if col == -1 {
+			break // optimal
+		}
+
+		// leaving row: minimal ratio, ties broken by the smallest basic variable
+		row := -1
+		var best float64
+		for i := 0; i < m; i++ {
+			if tab[i][col] <= simplexEps {
+				continue
+			}
+			ratio := tab[i][width-1] / tab[i][col]
+			if row == -1 || ratio < best-simplexEps || (ratio < best+simplexEps && basis[i] < basis[row]) {
+				row = i
+				best = ratio
+			}
+		}
+		if row == -1 {
+			return math.Inf(1), nil // unbounded dual, so some vertex can't be covered
+		}
+
+		pivot(tab, row, col)
+		basis[row] = col
+	}
+
+	weights := make([]float64, m)
+	for i := range weights {
+		weights[i] = tab[m][n+i]
+	}
+
+	return tab[m][width-1], weights
+}
+
+// pivot performs a pivot step on the tableau, making col the basic variable of row
+func pivot(tab [][]float64, row int, col int) {
+	p := tab[row][col]
+	for j := range tab[row] {
+		tab[row][j] = tab[row][j] / p
+	}
+
+	for i := range tab {
+		if i == row || tab[i][col] == 0 {
+			continue
+		}
+		f := tab[i][col]
+		for j := range tab[i] {
+			tab[i][j] = tab[i][j] - f*tab[row][j]
+		}
+	}
+}
+
+// FractionalWidth returns the largest weight of the cover of any node in a decomp. Nodes without fractional weights
+// count with the number of edges in their cover.
+func (d Decomp) FractionalWidth() float64 {
+	return d.Root.fractionalWidth()
+}
+
+func (n Node) fractionalWidth() float64 {
+	var output float64
+
+	if n.Weights == nil {
+		output = float64(n.Cover.Len())
+	} else {
+		for _, w := range n.Weights {
+			output = output + w
+		}
+	}
+
+	for i := range n.Children {
+		output = math.Max(output, n.Children[i].fractionalWidth())
+	}
+
+	return output
+}
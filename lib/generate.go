@@ -0,0 +1,101 @@
+package lib
+
+// generate.go produces random hypergraphs, to study how the algorithms scale and to test them on instances beyond
+// the ones at hand
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// A GraphModel decides how the vertices of the edges of a random hypergraph are chosen
+type GraphModel int
+
+// The supported models of random hypergraphs
+const (
+	Uniform      GraphModel = iota // each vertex is equally likely to be chosen
+	Preferential                   // vertices are chosen with probability proportional to their degree plus one
+)
+
+var graphModels = map[string]GraphModel{
+	"uniform":      Uniform,
+	"preferential": Preferential,
+}
+
+// GraphModels returns the names of all models of random hypergraphs, in alphabetical order
+func GraphModels() []string {
+	var output []string
+
+	for name := range graphModels {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+
+	return output
+}
+
+// ParseGraphModel returns the model of random hypergraphs of the given name
+func ParseGraphModel(name string) (GraphModel, error) {
+	model, ok := graphModels[name]
+	if !ok {
+		return Uniform, fmt.Errorf("unknown graph model %q, supported are: %v", name, GraphModels())
+	}
+	return model, nil
+}
+
+// RandomGraph returns a hypergraph with the given number of edges, each consisting of arity distinct vertices out of
+// V1 to Vn, for n the given number of vertices, chosen according to the model. The edges are named E1 to Em. Vertices
+// which end up in no edge are not part of the graph. The same seed always produces the same graph.
+func RandomGraph(model GraphModel, vertices, edges, arity int, seed int64) (Graph, error) {
+	if vertices < 1 || edges < 1 || arity < 1 {
+		return Graph{}, errors.New("the number of vertices, edges and the arity must be positive")
+	}
+	if arity > vertices {
+		return Graph{}, fmt.Errorf("arity %d exceeds the number of vertices %d", arity, vertices)
+	}
+
+	r := rand.New(rand.NewSource(seed))
+
+	// vertices are numbered from 1 and edges follow them, matching the encoding of the names
+	var names []string
+	for v := 1; v <= vertices; v++ {
+		names = append(names, "V"+strconv.Itoa(v))
+	}
+	for e := 1; e <= edges; e++ {
+		names = append(names, "E"+strconv.Itoa(e))
+	}
+
+	// for the preferential model, each vertex occurs in the pool once, plus once per edge containing it
+	var pool []int
+	for v := 1; v <= vertices; v++ {
+		pool = append(pool, v)
+	}
+
+	var output []Edge
+	for e := 1; e <= edges; e++ {
+		chosen := make(map[int]bool)
+		var edge []int
+		for len(edge) < arity {
+			var v int
+			switch model {
+			case Preferential:
+				v = pool[r.Intn(len(pool))]
+			default:
+				v = r.Intn(vertices) + 1
+			}
+			if chosen[v] {
+				continue
+			}
+			chosen[v] = true
+			edge = append(edge, v)
+		}
+		sort.Ints(edge)
+		pool = append(pool, edge...)
+		output = append(output, Edge{Name: vertices + e, Vertices: edge})
+	}
+
+	return Graph{Edges: NewEdges(output), encoding: newEncodingOf(names)}, nil
+}
@@ -2,17 +2,110 @@ package lib
 
 import (
 	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log"
 
 	"github.com/cem-okulmus/disjoint"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
-// A Graph is a collection of (special) edges
+// A Graph is a collection of (special) edges. Edges may be empty, and vertices in no edge are listed as Isolated.
+// As no cover contains them, isolated vertices are left out of Vertices and of the search for decompositions, and
+// are only added to a bag once a decomposition was found, see AddIsolated.
 type Graph struct {
 	Edges    Edges
-	Special  []Edges
+	Special  []SpecialEdge
+	Isolated []int
 	vertices []int
+	encoding *Encoding // names of vertices and edges, set by the parsers
+}
+
+// graphGob holds the fields of a graph which are serialised
+type graphGob struct {
+	Edges    Edges
+	Special  []SpecialEdge
+	Isolated []int
+	Encoding *Encoding
+}
+
+// GobEncode serialises a graph, including its encoding
+func (g Graph) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := gob.NewEncoder(&buf)
+	if err := encoder.Encode(graphGob{Edges: g.Edges, Special: g.Special, Isolated: g.Isolated,
+		Encoding: g.encoding}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode restores a graph serialised with GobEncode
+func (g *Graph) GobDecode(b []byte) error {
+	var out graphGob
+
+	decoder := gob.NewDecoder(bytes.NewBuffer(b))
+	if err := decoder.Decode(&out); err != nil {
+		return err
+	}
+
+	*g = Graph{Edges: out.Edges, Special: out.Special, Isolated: out.Isolated, encoding: out.Encoding}
+	return nil
+}
+
+// isZero returns true if none of the fields of the graph are set
+func (g Graph) isZero() bool {
+	return g.Edges.isZero() && g.Special == nil && g.Isolated == nil && g.encoding == nil
+}
+
+// Encoding returns the names of the vertices and edges of the graph. For graphs not produced by a parser or derived
+// from a parsed graph, an empty encoding is returned, which prints the integers.
+func (g Graph) Encoding() *Encoding {
+	if g.encoding == nil {
+		return NewEncoding()
+	}
+	return g.encoding
+}
+
+// EdgeByName returns the edge of the graph with the given name, and false if there is none
+func (g Graph) EdgeByName(name string) (Edge, bool) {
+	id, ok := g.Encoding().ID(name)
+	if !ok {
+		return Edge{}, false
+	}
+
+	for _, e := range g.Edges.Slice() {
+		if e.Name == id {
+			return e, true
+		}
+	}
+
+	return Edge{}, false
+}
+
+// VertexByName returns the integer of the vertex with the given name, and false if the graph has no such vertex
+func (g *Graph) VertexByName(name string) (int, bool) {
+	id, ok := g.Encoding().ID(name)
+	if !ok || !mem(g.Vertices(), id) {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// WithEncoding returns a copy of the graph using the given encoding for the names of its vertices and edges
+func (g Graph) WithEncoding(e *Encoding) Graph {
+	g.encoding = e
+	return g
+}
+
+// WithEdges returns a graph of the given edges, derived from g and thus sharing its encoding. Graphs derived by
+// building a Graph literal instead are printed with the encoding of the last parsed graph, which may name the
+// edges wrongly once several graphs were parsed.
+func (g Graph) WithEdges(e Edges) Graph {
+	return Graph{Edges: e, encoding: g.encoding}
 }
 
 //  A DSD (short for Disjoint-Set-Datastructure) collects the information on the connected components of a graph
@@ -35,8 +128,9 @@ func (d *DSD) AddSepVertices(e Edge) {
 func (g Graph) String() string {
 	var buffer bytes.Buffer
 	buffer.WriteString("{")
+	enc := g.Encoding()
 	for i, e := range g.Edges.Slice() {
-		buffer.WriteString(e.String())
+		buffer.WriteString(e.stringEnc(enc))
 		if i != g.Edges.Len()-1 {
 			buffer.WriteString(", ")
 		}
@@ -46,7 +140,7 @@ func (g Graph) String() string {
 	if len(g.Special) > 0 {
 		buffer.WriteString(" & Special Edges [")
 		for i := range g.Special {
-			buffer.WriteString(g.Special[i].String())
+			buffer.WriteString(g.Special[i].stringEnc(enc))
 			if i != len(g.Special)-1 {
 				buffer.WriteString(", ")
 			}
@@ -58,10 +152,11 @@ func (g Graph) String() string {
 }
 
 func (g Graph) equal(other Graph) bool {
-	return cmp.Equal(g, other, cmpopts.IgnoreUnexported(g), cmp.Comparer(equalEdges))
+	return cmp.Equal(g, other, cmpopts.IgnoreUnexported(g), cmpopts.EquateEmpty(), cmp.Comparer(equalEdges),
+		cmp.Comparer(SpecialEdge.Equal))
 }
 
-// Vertices produces the union of all vertices from all edges of the graph
+// Vertices produces the union of all vertices from all edges of the graph, thus without the isolated ones
 func (g *Graph) Vertices() []int {
 	if len(g.vertices) > 0 {
 		return g.vertices
@@ -87,8 +182,11 @@ func (g Graph) Len() int {
 // Note that special edges are ignored here, since they should never be
 // considered when choosing a separator
 func GetSubset(edges Edges, s []int) Edges {
-	var output []Edge
+	output := make([]Edge, 0, len(s))
 	for _, i := range s {
+		if i < 0 || i >= edges.Len() {
+			log.Panicln("Index", i, "of subset", s, "not within the", edges.Len(), "edges")
+		}
 		output = append(output, edges.Slice()[i])
 	}
 	return NewEdges(output)
@@ -99,148 +197,34 @@ func (g Graph) GetSubset(s []int) Edges {
 	return GetSubset(g.Edges, s)
 }
 
-// GetComponents uses Disjoint Set data structure to compute connected components
+// GetComponents computes the connected components of the graph after removing the vertices of sep, in the order
+// their first edge appears in the graph, together with the component of each edge by name and the edges left without
+// any vertex outside of sep. The vertices map is scratch space for the union-find data structure, which callers
+// searching many separators should reuse; if nil, one is taken from ElementMap.
 func (g Graph) GetComponents(sep Edges, vertices map[int]*disjoint.Element) ([]Graph, map[int]int, []Edge) {
-	var outputG []Graph
-
-	// var vertices = make(map[int]*disjoint.Element, len(g.Vertices()))
-	var comps = make(map[*disjoint.Element][]Edge)
-	var compsSp = make(map[*disjoint.Element][]Edges)
-
-	balsepVert := sep.Vertices()
-	// var balSepCache
-
-	balSepCache := make([]bool, encode)
-	for _, v := range balsepVert {
-		balSepCache[v-1] = true
-	}
-
-	//  Set up the disjoint sets for each node
-	for _, i := range g.Vertices() {
-		if e, ok := vertices[i]; ok {
-			e.Reset()
-		} else {
-			vertices[i] = disjoint.NewElement()
-		}
-	}
-
-	// Merge together the connected components
-	for k := range g.Edges.Slice() {
-		for i := 0; i < len(g.Edges.Slice()[k].Vertices); i++ {
-			if balSepCache[g.Edges.Slice()[k].Vertices[i]-1] {
-				continue
-			}
-			for j := i + 1; j < len(g.Edges.Slice()[k].Vertices); j++ {
-				if balSepCache[g.Edges.Slice()[k].Vertices[j]-1] {
-					continue
-				}
-
-				disjoint.Union(vertices[g.Edges.Slice()[k].Vertices[i]], vertices[g.Edges.Slice()[k].Vertices[j]])
-				i = j - 1
-				break
-			}
-		}
-	}
-
-	for k := range g.Special {
-		for i := 0; i < len(g.Special[k].Vertices())-1; i++ {
-			if balSepCache[g.Special[k].Vertices()[i]-1] {
-				continue
-			}
-			for j := i + 1; j < len(g.Special[k].Vertices()); j++ {
-				if balSepCache[g.Special[k].Vertices()[j]-1] {
-					continue
-				}
-				disjoint.Union(vertices[g.Special[k].Vertices()[i]], vertices[g.Special[k].Vertices()[j]])
-				i = j - 1
-				break
-			}
-		}
-	}
-
-	var isolatedEdges []Edge
-
-	//sort each edge and special edge to a corresponding component
-	for i := range g.Edges.Slice() {
-		var vertexRep int
-		found := false
-		for _, v := range g.Edges.Slice()[i].Vertices {
-			if balSepCache[v-1] {
-				continue
-			}
-			vertexRep = v
-			found = true
-			break
-		}
-		if !found {
-			isolatedEdges = append(isolatedEdges, g.Edges.Slice()[i])
-			continue
-		}
-
-		slice, ok := comps[vertices[vertexRep].Find()]
-		if !ok {
-			newslice := make([]Edge, 0, g.Edges.Len())
-			comps[vertices[vertexRep].Find()] = newslice
-			slice = newslice
-		}
-
-		comps[vertices[vertexRep].Find()] = append(slice, g.Edges.Slice()[i])
-	}
-
-	var isolatedSp []Edges
-	for i := range g.Special {
-		var vertexRep int
-		found := false
-		for _, v := range g.Special[i].Vertices() {
-			if balSepCache[v-1] {
-				continue
-			}
-			vertexRep = v
-			found = true
-			break
-		}
-		if !found {
-			isolatedSp = append(isolatedSp, g.Special[i])
-			continue
-		}
-
-		slice, ok := compsSp[vertices[vertexRep].Find()]
-		if !ok {
-			newslice := make([]Edges, 0, len(g.Special))
-			compsSp[vertices[vertexRep].Find()] = newslice
-			slice = newslice
-		}
-
-		compsSp[vertices[vertexRep].Find()] = append(slice, g.Special[i])
-	}
-
-	edgeToComp := make(map[int]int)
-
-	// Store the components as graphs
-	for k := range comps {
-		slice := comps[k]
-		for i := range slice {
-			edgeToComp[slice[i].Name] = len(outputG)
-		}
-		g := Graph{Edges: NewEdges(slice), Special: compsSp[k]}
-		outputG = append(outputG, g)
+	if vertices == nil {
+		vertices = ElementMap()
+		defer ReleaseElementMap(vertices)
 	}
+	return g.components(sep, vertices)
+}
 
-	for k := range compsSp {
-		_, ok := comps[k]
-		if ok {
-			continue
-		}
-		g := Graph{Edges: NewEdges([]Edge{}), Special: compsSp[k]}
-		outputG = append(outputG, g)
+// ComponentsSplit returns the connected components of the graph as independent graphs, in the order their first edge
+// appears, so that each of them can be decomposed on its own. Edges without any vertex are added to the first
+// component, and isolated vertices are left out, see Decomp.AddIsolated.
+func (g Graph) ComponentsSplit() []Graph {
+	comps, _, empty := g.GetComponents(NewEdges(nil), nil)
+	if len(empty) == 0 {
+		return comps
 	}
-
-	for i := range isolatedSp {
-		g := Graph{Edges: NewEdges([]Edge{}), Special: []Edges{isolatedSp[i]}}
-		outputG = append(outputG, g)
+	if len(comps) == 0 {
+		return []Graph{g.WithEdges(NewEdges(empty))}
 	}
 
-	return outputG, edgeToComp, isolatedEdges
+	special := comps[0].Special
+	comps[0] = comps[0].WithEdges(NewEdges(append(append([]Edge{}, comps[0].Edges.Slice()...), empty...)))
+	comps[0].Special = special
+	return comps
 }
 
 func (d *DSD) Update(e Edge) {
@@ -300,7 +284,7 @@ func CutEdges(edges Edges, vertices []int) Edges {
 		inter := Inter(edges.Slice()[i].Vertices, vertices)
 		if len(inter) > 0 {
 			name := edges.Slice()[i].Name
-			output = append(output, Edge{Name: name, Vertices: inter})
+			output = append(output, Edge{Name: name, Vertices: inter, Weight: edges.Slice()[i].Weight})
 		}
 	}
 
@@ -322,7 +306,67 @@ func (g Graph) ComputeSubEdges(K int) Graph {
 		}
 	}
 
-	return Graph{Edges: removeDuplicateEdges(output)}
+	return Graph{Edges: removeDuplicateEdges(output), encoding: g.encoding}
+}
+
+// AddSubEdges adds the subedges relevant to a GHD of width K to the edges of g, as ComputeSubEdges does, but at most
+// limit of them, or all of them if limit is negative. Larger subedges are added first, starting with the intersections
+// of an edge with the union of up to K others, so that those most likely to be needed in a cover are kept. It also
+// returns whether all relevant subedges were added, in which case the search for separators over the edges of the
+// output is complete for GHDs of width K.
+func (g Graph) AddSubEdges(K int, limit int) (Graph, bool) {
+	output := append([]Edge{}, g.Edges.Slice()...)
+
+	present := make(map[string]bool) // vertex sets of edges of g, not added again
+	for _, e := range output {
+		present[fmt.Sprint(RemoveDuplicates(append([]int{}, e.Vertices...)))] = true
+	}
+
+	// the candidates are kept by their size, each set of vertices once
+	bySize := make(map[int][][]int)
+	seen := make(map[string]bool)
+	largest := 0
+	candidate := func(vertices []int) {
+		vertices = RemoveDuplicates(vertices)
+		k := fmt.Sprint(vertices)
+		if len(vertices) == 0 || seen[k] {
+			return
+		}
+		seen[k] = true
+		bySize[len(vertices)] = append(bySize[len(vertices)], vertices)
+		if len(vertices) > largest {
+			largest = len(vertices)
+		}
+	}
+
+	for _, e := range g.Edges.Slice() {
+		edgesWihoutE := diffEdges(g.Edges, e)
+		gen := getCombin(edgesWihoutE.Len(), K)
+		for gen.HasNext() {
+			subset := GetSubset(edgesWihoutE, gen.Combination)
+			candidate(Inter(e.Vertices, subset.Vertices()))
+			gen.Confirm()
+		}
+	}
+
+	// all subsets of the intersections are relevant as well, each reached by removing one vertex at a time
+	added := 0
+	for size := largest; size > 0; size-- {
+		for _, vertices := range bySize[size] {
+			if !present[fmt.Sprint(vertices)] {
+				if limit >= 0 && added == limit {
+					return Graph{Edges: NewEdges(output), encoding: g.encoding}, false
+				}
+				output = append(output, Edge{Vertices: vertices})
+				added++
+			}
+			for i := range vertices {
+				candidate(append(append([]int{}, vertices[:i]...), vertices[i+1:]...))
+			}
+		}
+	}
+
+	return Graph{Edges: NewEdges(output), encoding: g.encoding}, true
 }
 
 // GetBIP computes the BIP number of the graph
@@ -346,6 +390,42 @@ func (g Graph) GetBIP() int {
 	return output
 }
 
+// Primal returns the primal graph of g as a graph of binary edges, one for each pair of vertices sharing an edge of
+// g, in the order the pairs first appear. Vertices sharing no edge with any other get an edge of their own, so that
+// the vertices of both graphs are the same. The edges are named by negative numbers, distinct from all vertices.
+func (g Graph) Primal() Graph {
+	var output []Edge
+	seen := make(map[[2]int]bool)
+	paired := make(map[int]bool)
+
+	for _, e := range g.Edges.Slice() {
+		for i, v := range e.Vertices {
+			for _, w := range e.Vertices[i+1:] {
+				if v == w {
+					continue
+				}
+				pair := [2]int{v, w}
+				if w < v {
+					pair = [2]int{w, v}
+				}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+				paired[v], paired[w] = true, true
+				output = append(output, Edge{Name: -len(output) - 1, Vertices: []int{pair[0], pair[1]}})
+			}
+		}
+	}
+	for _, v := range g.Edges.Vertices() {
+		if !paired[v] {
+			output = append(output, Edge{Name: -len(output) - 1, Vertices: []int{v}})
+		}
+	}
+
+	return Graph{Edges: NewEdges(output), encoding: g.encoding}
+}
+
 //ToHyberBenchFormat transforms the graph structure to a string in HyperBench Format. This is only relevant for
 // generated instances with no existing string representation. Using this with a parsed graph is not the target use
 // case, only used for internal testing
@@ -379,11 +459,13 @@ func (g *Graph) MakeEdgesDistinct() []int {
 	tmp := []int{}
 	newEdges := []Edge{}
 
+	// each added vertex is named after its edge
+	enc := g.Encoding()
 	for _, e := range g.Edges.Slice() {
-		e.Vertices = append(e.Vertices, encode)
-		tmp = append(tmp, encode)
+		v := enc.AddUnique("_" + enc.Name(e.Name))
+		e.Vertices = append(e.Vertices, v)
+		tmp = append(tmp, v)
 		newEdges = append(newEdges, e)
-		encode++
 	}
 
 	g.Edges = NewEdges(newEdges)
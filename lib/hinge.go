@@ -6,7 +6,6 @@ package lib
 import (
 	"bytes"
 	"log"
-	"reflect"
 
 	"github.com/cem-okulmus/disjoint"
 )
@@ -54,7 +53,7 @@ func (h Hingetree) GetLargestGraph() Graph {
 func (h Hingetree) stringIdent(i int) string {
 	var buffer bytes.Buffer
 
-	if reflect.DeepEqual(h.decomp, Decomp{}) {
+	if !h.decomp.Found() {
 		buffer.WriteString("\n" + indent(i) + h.hinge.String() + "\n")
 	} else {
 		buffer.WriteString("\n" + indent(i) + h.decomp.String() + "\n")
@@ -125,7 +124,7 @@ func (h Hingetree) expandHingeTree(isUsed map[int]bool, parentE int) Hingetree {
 
 		for i := range hinges {
 			edges := hinges[i].Edges.Slice()
-			extendedHinge := Graph{Edges: NewEdges(append(edges, *e))}
+			extendedHinge := Graph{Edges: NewEdges(append(edges, *e)), encoding: h.hinge.encoding}
 			htrees = append(htrees, Hingetree{hinge: extendedHinge})
 		}
 
@@ -223,14 +222,14 @@ func (n Node) RerootEdge(edge []int) Node {
 func (h Hingetree) DecompHinge(alg AlgorithmH, g Graph) Decomp {
 	h.decomp = alg.FindDecompGraph(h.hinge)
 
-	if reflect.DeepEqual(h.decomp, Decomp{}) {
+	if !h.decomp.Found() {
 		return Decomp{}
 	}
 
 	// go recursively over children
 	for i := range h.children {
 		out := h.children[i].h.DecompHinge(alg, g)
-		if reflect.DeepEqual(out, Decomp{}) { // reject if subtree cannot be merged to GHD
+		if !out.Found() { // reject if subtree cannot be merged to GHD
 			return Decomp{}
 		}
 		//reroot child and parent to a connecting node:
@@ -0,0 +1,319 @@
+package lib
+
+// hyperbench.go implements a hand-written scanner for the HyperBench format, reading the input as a stream so that
+// very large hypergraphs can be parsed quickly and without holding the input in memory as a whole
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// hbPunct holds the punctuation allowed within identifiers after their first character
+const hbPunct = ".;_:!?\\/=[]'$<>-+~@*\""
+
+// hbScanner splits HyperBench input into the names of edges and vertices and the symbols ( ) , and the final "."
+type hbScanner struct {
+	reader *bufio.Reader
+	line   int
+	col    int
+	buf    []byte
+}
+
+// peek returns the next byte without consuming it, and false at the end of the input
+func (s *hbScanner) peek() (byte, bool) {
+	b, err := s.reader.Peek(1)
+	if err != nil {
+		if err != io.EOF {
+			panic(err)
+		}
+		return 0, false
+	}
+	return b[0], true
+}
+
+// peekSecond returns the byte after the next one, and false if there is none
+func (s *hbScanner) peekSecond() (byte, bool) {
+	b, err := s.reader.Peek(2)
+	if len(b) < 2 {
+		if err != nil && err != io.EOF {
+			panic(err)
+		}
+		return 0, false
+	}
+	return b[1], true
+}
+
+func (s *hbScanner) next() byte {
+	c, err := s.reader.ReadByte()
+	if err != nil {
+		panic(err)
+	}
+	if c == '\n' {
+		s.line++
+		s.col = 0
+	} else {
+		s.col++
+	}
+	return c
+}
+
+// fail reports malformed input at the current position
+func (s *hbScanner) fail(format string, args ...interface{}) {
+	fmt.Println("Couldn't parse input: ")
+	panic(fmt.Errorf("%d:%d: %s", s.line, s.col+1, fmt.Sprintf(format, args...)))
+}
+
+func (s *hbScanner) atEnd() bool {
+	_, ok := s.peek()
+	return !ok
+}
+
+// describe names the next byte for error messages
+func (s *hbScanner) describe() string {
+	c, ok := s.peek()
+	if !ok {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", c)
+}
+
+// skip consumes any whitespace and comments, which start with % or // and run to the end of the line
+func (s *hbScanner) skip() {
+	for {
+		c, ok := s.peek()
+		if !ok {
+			return
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			s.next()
+		case c == '%':
+			s.skipLine()
+		case c == '/':
+			if c2, ok := s.peekSecond(); !ok || c2 != '/' {
+				return
+			}
+			s.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (s *hbScanner) skipLine() {
+	for c, ok := s.peek(); ok && c != '\n'; c, ok = s.peek() {
+		s.next()
+	}
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// name reads the name of an edge or vertex: an identifier, a number or a quoted string, which keeps its quotes.
+// Identifiers start with a letter, digit or underscore, and may contain punctuation after that. Non-ASCII letters are
+// allowed as well. The name is only valid until the next call.
+func (s *hbScanner) name() ([]byte, bool) {
+	c, ok := s.peek()
+	if !ok {
+		return nil, false
+	}
+	s.buf = s.buf[:0]
+
+	switch {
+	case isAlnum(c) || c == '_':
+		for ok && (isAlnum(c) || strings.IndexByte(hbPunct, c) >= 0) {
+			s.buf = append(s.buf, s.next())
+			c, ok = s.peek()
+		}
+	case c == '"':
+		s.buf = append(s.buf, s.next())
+		for {
+			c, ok = s.peek()
+			if !ok {
+				s.fail("unterminated string")
+			}
+			s.buf = append(s.buf, s.next())
+			if c == '"' {
+				break
+			}
+			if c == '\\' {
+				if _, ok = s.peek(); !ok {
+					s.fail("unterminated string")
+				}
+				s.buf = append(s.buf, s.next())
+			}
+		}
+	case c == '-' || c == '+' || c == '.':
+		if c != '.' {
+			s.buf = append(s.buf, s.next())
+			if c, ok = s.peek(); !ok || !(isDigit(c) || c == '.') {
+				s.fail("expected number after %q", s.buf)
+			}
+		}
+		for ok && (isDigit(c) || c == '.') {
+			s.buf = append(s.buf, s.next())
+			c, ok = s.peek()
+		}
+	default:
+		return nil, false
+	}
+
+	return s.buf, true
+}
+
+// expect consumes the given symbol, after any whitespace
+func (s *hbScanner) expect(symbol byte) {
+	s.skip()
+	if c, ok := s.peek(); !ok || c != symbol {
+		s.fail("expected %q, got %s", symbol, s.describe())
+	}
+	s.next()
+}
+
+// consume consumes the given symbol if it comes next, after any whitespace, and returns true if it did
+func (s *hbScanner) consume(symbol byte) bool {
+	s.skip()
+	if c, ok := s.peek(); ok && c == symbol {
+		s.next()
+		return true
+	}
+	return false
+}
+
+// edges reads the edges of the input, each given by its name followed by the names of its vertices in parentheses.
+// Commas between edges and vertices are optional, and the input may end with a dot. The vertices are encoded as they
+// are read, numbered from 1 in the order of their first occurrence, and the edges are numbered after all vertices,
+// in the order they occur, as done by ParseGraph.build.
+func (s *hbScanner) edges(pgraph *ParseGraph) ([]Edge, []string) {
+	var edges []Edge
+	var names []string // the names of the vertices, followed by those of the edges
+	var scratch []int  // the vertices of the current edge
+
+	for {
+		s.skip()
+		c, ok := s.peek()
+		if !ok {
+			if len(edges) == 0 {
+				s.fail("no edges in input")
+			}
+			break
+		}
+		if c2, ok2 := s.peekSecond(); c == '.' && (!ok2 || !(isDigit(c2) || c2 == '.')) {
+			s.next()
+			if s.skip(); !s.atEnd() {
+				s.fail("expected end of input after \".\", got %s", s.describe())
+			}
+			break
+		}
+
+		name, ok := s.name()
+		if !ok {
+			s.fail("expected edge name, got %s", s.describe())
+		}
+		edge := parseEdge{Name: string(name)}
+
+		s.expect('(')
+		scratch = scratch[:0]
+		for !s.consume(')') {
+			vertex, ok := s.name()
+			if !ok {
+				s.fail("expected vertex or \")\" in edge %v, got %s", edge.Name, s.describe())
+			}
+			id, ok := pgraph.Encoding[string(vertex)]
+			if !ok {
+				names = append(names, string(vertex))
+				id = len(names)
+				pgraph.Encoding[names[id-1]] = id
+			}
+			scratch = append(scratch, id)
+			s.consume(',')
+		}
+
+		vertices := make([]int, len(scratch))
+		copy(vertices, scratch)
+		edge.Vertices = make([]string, len(scratch))
+		for i, id := range scratch {
+			edge.Vertices[i] = names[id-1] // each name is only allocated once
+		}
+		pgraph.Edges = append(pgraph.Edges, edge)
+		edges = append(edges, Edge{Vertices: vertices})
+		s.consume(',')
+	}
+
+	for i := range edges {
+		name := pgraph.Edges[i].Name
+		if _, ok := pgraph.Encoding[name]; ok {
+			log.Panicln("Edge names not unique, not a valid hypergraph!")
+		}
+		names = append(names, name)
+		edges[i].Name = len(names)
+		pgraph.Encoding[name] = len(names)
+	}
+
+	return edges, names
+}
+
+// GetGraph parses a string in HyperBench format into a graph
+func GetGraph(s string) (Graph, ParseGraph) {
+	return GetGraphReader(strings.NewReader(s))
+}
+
+// GetGraphReader parses a hypergraph in HyperBench format, read from reader. The input is scanned as a stream, so
+// that only the graph is kept in memory, with each distinct name once. Gzip-compressed input is decompressed on the
+// fly.
+func GetGraphReader(reader io.Reader) (Graph, ParseGraph) {
+	buffered := bufio.NewReaderSize(reader, 1<<16)
+	if magic, _ := buffered.Peek(2); bytes.Equal(magic, gzipMagic) {
+		unzipped, err := gzip.NewReader(buffered)
+		if err != nil {
+			fmt.Println("Couldn't parse input: ")
+			panic(err)
+		}
+		defer unzipped.Close()
+		buffered = bufio.NewReaderSize(unzipped, 1<<16)
+	}
+
+	scanner := hbScanner{reader: buffered, line: 1}
+	pgraph := ParseGraph{Encoding: make(map[string]int)}
+	edges, names := scanner.edges(&pgraph)
+
+	pgraph.encoding = newEncodingOf(names)
+	pgraph.encoding.Reserve(len(edges))
+
+	var output Graph
+	output.Edges = NewEdges(edges)
+	output.encoding = pgraph.encoding
+	return output, pgraph
+}
+
+// gzipMagic starts any gzip-compressed input
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gunzip decompresses s if it is gzip-compressed, and returns it unchanged otherwise
+func gunzip(s string) (string, error) {
+	if !strings.HasPrefix(s, string(gzipMagic)) {
+		return s, nil
+	}
+	reader, err := gzip.NewReader(strings.NewReader(s))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	output, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,241 @@
+package lib
+
+// improve.go lowers the width of decompositions by local search, such as those found by heuristics or by the exact
+// algorithms at a width larger than needed
+
+import (
+	"context"
+	"math/rand"
+)
+
+// maxSplitTries bounds the number of ways tried to split a node, once they are too many to try them all
+const maxSplitTries = 1 << 14
+
+// Improve tries to lower the width of the decomposition by local changes, until none of them helps any more or the
+// context is done. Each node of the largest width gets a smaller cover of its bag if the edges of the graph allow
+// it, or else is split into two adjacent nodes of smaller width, among which the neighbours of the node are
+// distributed. Nodes are merged again by Minimize in the end, where the width allows. The result is correct if d is,
+// and never wider. As for Minimize, the special condition of hypertree decompositions may be lost, and weights and
+// connectors are dropped.
+func (d Decomp) Improve(ctx context.Context) Decomp {
+	if !d.Found() {
+		return d
+	}
+
+	d.RestoreSubedges()
+	r := rand.New(rand.NewSource(1))
+
+	for ctx.Err() == nil {
+		width := d.Width()
+		if width <= 1 {
+			break
+		}
+		left, progress := d.Root.lower(ctx, r, d.Graph.Edges, width)
+		if left > 0 && !progress {
+			break // changes to the neighbours of the nodes left didn't help them either
+		}
+	}
+
+	return d.Minimize(d.Width())
+}
+
+// lower tries to bring each node of the given width below it, returning the number of nodes left at that width and
+// whether any node was changed
+func (n *Node) lower(ctx context.Context, r *rand.Rand, edges Edges, width int) (int, bool) {
+	type visit struct {
+		node   *Node
+		parent []int // the bag of the parent, nil for the root
+	}
+
+	left, progress := 0, false
+	stack := []visit{{node: n}}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := current.node
+
+		if node.Cover.Len() >= width {
+			if ctx.Err() != nil {
+				return left + 1, progress
+			}
+			if cover, ok := (VertexSepCheck{Edges: FilterVertices(edges, node.Bag), K: width - 1}).GetCover(
+				node.Bag); ok {
+				node.Cover = cover
+				progress = true
+			} else if node.split(ctx, r, edges, current.parent, width-1) {
+				progress = true
+			} else {
+				left++
+			}
+		}
+
+		for i := range node.Children {
+			stack = append(stack, visit{node: &node.Children[i], parent: node.Bag})
+		}
+	}
+
+	return left, progress
+}
+
+// split replaces the node by adjacent nodes, each with a bag coverable by at most k edges, and returns false if no
+// such split was found. The bags are made up of the vertices shared with the neighbours and of the edges within the
+// bag, so that each neighbour can be attached to one of the new nodes, and each edge stays covered. Splits into nodes
+// of smaller width are preferred, as they leave more room to split the nodes further. The parent is the bag of the
+// parent of the node, nil for the root.
+func (n *Node) split(ctx context.Context, r *rand.Rand, edges Edges, parent []int, k int) bool {
+	var top []int // the vertices shared with the parent, which must all end up in the upper node
+	var items [][]int
+	if parent != nil {
+		top = Inter(n.Bag, parent)
+		items = append(items, top)
+	}
+	for i := range n.Children {
+		items = append(items, Inter(n.Bag, n.Children[i].Bag))
+	}
+	for _, e := range edges.Slice() {
+		if Subset(e.Vertices, n.Bag) {
+			items = append(items, e.Vertices)
+		}
+	}
+	items = maximalSets(items)
+	if len(items) < 2 {
+		return false
+	}
+
+	for width := 1; width <= k; width++ {
+		if n.splitAt(ctx, r, edges, parent, top, items, width) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAt splits the node into two, with bags made up of the given items, such that both bags can be covered by at
+// most k edges. If there is no such split, a part of the bag which can be covered is split off, as large as possible,
+// and the rest is split further. Returns false, leaving the node unchanged, if this fails as well.
+func (n *Node) splitAt(ctx context.Context, r *rand.Rand, edges Edges, parent, top []int, items [][]int, k int) bool {
+	// the first item always goes into the first bag, each bit of the mask puts one of the others into the second
+	tries := 1<<uint(len(items)-1) - 1
+	exhaustive := len(items)-1 < 15
+	if !exhaustive {
+		tries = maxSplitTries
+	}
+
+	// the best split found with only one of the bags covered, and the bag left to split further
+	var peel struct {
+		first, second []int
+		cover         Edges
+		coverFirst    bool
+		rest          int
+	}
+
+	for try := 1; try <= tries; try++ {
+		if ctx.Err() != nil {
+			return false
+		}
+		var first, second []int
+		for i := range items {
+			inSecond := false
+			if i > 0 {
+				if exhaustive {
+					inSecond = try&(1<<uint(i-1)) != 0
+				} else {
+					inSecond = r.Intn(2) == 1
+				}
+			}
+			if inSecond {
+				second = append(second, items[i]...)
+			} else {
+				first = append(first, items[i]...)
+			}
+		}
+		first, second = RemoveDuplicates(first), RemoveDuplicates(second)
+		if len(second) == 0 || len(first) == len(n.Bag) || len(second) == len(n.Bag) {
+			continue
+		}
+
+		firstCover, firstOK := VertexSepCheck{Edges: FilterVertices(edges, first), K: k}.GetCover(first)
+		secondCover, secondOK := VertexSepCheck{Edges: FilterVertices(edges, second), K: k}.GetCover(second)
+		switch {
+		case firstOK && secondOK:
+			n.divide(top, first, firstCover, second, secondCover)
+			return true
+		case firstOK && (peel.rest == 0 || len(second) < peel.rest):
+			peel.first, peel.second, peel.cover, peel.coverFirst, peel.rest = first, second, firstCover, true,
+				len(second)
+		case secondOK && (peel.rest == 0 || len(first) < peel.rest):
+			peel.first, peel.second, peel.cover, peel.coverFirst, peel.rest = first, second, secondCover, false,
+				len(first)
+		}
+	}
+
+	if peel.rest == 0 {
+		return false
+	}
+
+	// the rest keeps the cover of the node for now, which covers all of its bag
+	original := *n
+	var rest *Node
+	if peel.coverFirst {
+		_, rest = n.divide(top, peel.first, peel.cover, peel.second, n.Cover)
+	} else {
+		rest, _ = n.divide(top, peel.first, n.Cover, peel.second, peel.cover)
+	}
+	restParent := parent
+	if rest != n {
+		restParent = n.Bag
+	}
+	if rest.split(ctx, r, edges, restParent, k) {
+		return true
+	}
+	*n = original
+	return false
+}
+
+// divide replaces the node by two adjacent nodes with the given bags and covers, which together hold all of its bag.
+// The node with the vertices in top takes the place of the original node, with the other node as its last child, and
+// each child is attached to a node whose bag contains all the vertices it shares with the original node. Returns
+// the nodes of the first and second bag.
+func (n *Node) divide(top []int, first []int, firstCover Edges, second []int, secondCover Edges) (*Node, *Node) {
+	upper := Node{Bag: first, Cover: firstCover}
+	lower := Node{Bag: second, Cover: secondCover}
+	swapped := !Subset(top, first)
+	if swapped {
+		upper, lower = lower, upper
+	}
+	for i := range n.Children {
+		if Subset(Inter(n.Bag, n.Children[i].Bag), lower.Bag) {
+			lower.Children = append(lower.Children, n.Children[i])
+		} else {
+			upper.Children = append(upper.Children, n.Children[i])
+		}
+	}
+	upper.Children = append(upper.Children, lower)
+	*n = upper
+
+	if swapped {
+		return &n.Children[len(n.Children)-1], n
+	}
+	return n, &n.Children[len(n.Children)-1]
+}
+
+// maximalSets returns the sets which are not contained in any other, keeping only the first of equal ones
+func maximalSets(sets [][]int) [][]int {
+	var output [][]int
+
+OUTER:
+	for i := range sets {
+		for j := range sets {
+			if i == j || !Subset(sets[i], sets[j]) {
+				continue
+			}
+			if j < i || !Subset(sets[j], sets[i]) { // contained in a larger set, or equal to an earlier one
+				continue OUTER
+			}
+		}
+		output = append(output, sets[i])
+	}
+
+	return output
+}
@@ -0,0 +1,196 @@
+package lib
+
+// infeasible.go checks cheap necessary conditions for the existence of a GHD of a given width, in order to reject
+// hopeless widths before any search is started
+
+import (
+	"fmt"
+	"sort"
+)
+
+// primalGraph returns for each vertex its neighbours, i.e. all vertices sharing some edge with it
+func (g Graph) primalGraph() map[int]map[int]bool {
+	output := make(map[int]map[int]bool)
+
+	for _, e := range g.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if _, ok := output[v]; !ok {
+				output[v] = make(map[int]bool)
+			}
+			for _, w := range e.Vertices {
+				if v != w {
+					output[v][w] = true
+				}
+			}
+		}
+	}
+
+	return output
+}
+
+// greedyCliques grows a clique of the primal graph from each vertex, adding neighbours of high degree first
+func greedyCliques(primal map[int]map[int]bool) [][]int {
+	var output [][]int
+	seen := make(map[string]struct{})
+
+	var vertices []int
+	for v := range primal {
+		vertices = append(vertices, v)
+	}
+	sort.Ints(vertices)
+
+	for _, v := range vertices {
+		var neighbours []int
+		for w := range primal[v] {
+			neighbours = append(neighbours, w)
+		}
+		sort.Slice(neighbours, func(i, j int) bool {
+			if len(primal[neighbours[i]]) != len(primal[neighbours[j]]) {
+				return len(primal[neighbours[i]]) > len(primal[neighbours[j]])
+			}
+			return neighbours[i] < neighbours[j]
+		})
+
+		clique := []int{v}
+	NEIGHBOURS:
+		for _, w := range neighbours {
+			for _, u := range clique {
+				if !primal[w][u] {
+					continue NEIGHBOURS
+				}
+			}
+			clique = append(clique, w)
+		}
+
+		sort.Ints(clique)
+		key := fmt.Sprint(clique)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = Empty
+		output = append(output, clique)
+	}
+
+	return output
+}
+
+// infeasibleBudget is the number of edges the search for a cover of a clique branches on in Infeasible, before the
+// clique is taken as coverable
+const infeasibleBudget = 4096
+
+// Infeasible reports whether the graph can't have a GHD of width K, based on cheap necessary conditions only. If so,
+// an explanation is returned. A result of false doesn't mean that a decomposition exists.
+//
+// The main condition checked is that the vertices of any clique in the primal graph must occur together in some bag
+// of every decomposition, and hence must be coverable by K edges. As finding a cover may take up to deg^K steps, the
+// search for each clique gives up after infeasibleBudget branches, so the check stays cheap.
+func (g Graph) Infeasible(K int) (bool, string) {
+	if K < 1 {
+		return true, "width must be at least 1"
+	}
+	if g.Edges.Len() <= K {
+		return false, "" // a single bag covered by all edges suffices
+	}
+
+	check := VertexSepCheck{Edges: g.Edges, K: K}
+	for _, clique := range greedyCliques(g.primalGraph()) {
+		if _, ok, complete := check.getCover(clique, infeasibleBudget); !ok && complete {
+			return true, fmt.Sprintf("the vertices %v pairwise share edges, so must occur in one bag, "+
+				"but can't be covered by %d edges", g.Encoding().PrintVertices(clique), K)
+		}
+	}
+
+	return false, ""
+}
+
+// exactCliqueLimit is the largest number of vertices for which LowerBound enumerates all maximal cliques of the
+// primal graph, instead of growing them greedily
+const exactCliqueLimit = 30
+
+// maximalCliques returns all maximal cliques of the primal graph, using the Bron–Kerbosch algorithm with pivoting
+func maximalCliques(primal map[int]map[int]bool) [][]int {
+	var output [][]int
+
+	var expand func(clique []int, candidates, excluded map[int]bool)
+	expand = func(clique []int, candidates, excluded map[int]bool) {
+		if len(candidates) == 0 && len(excluded) == 0 {
+			output = append(output, append([]int{}, clique...))
+			return
+		}
+
+		// only vertices not adjacent to the pivot need to be tried, choosing the one with most neighbours
+		pivot, most := -1, -1
+		for _, set := range []map[int]bool{candidates, excluded} {
+			for u := range set {
+				if count := len(primal[u]); count > most {
+					pivot, most = u, count
+				}
+			}
+		}
+
+		var tried []int
+		for v := range candidates {
+			if !primal[pivot][v] {
+				tried = append(tried, v)
+			}
+		}
+		sort.Ints(tried)
+
+		for _, v := range tried {
+			newCandidates, newExcluded := make(map[int]bool), make(map[int]bool)
+			for w := range primal[v] {
+				if candidates[w] {
+					newCandidates[w] = true
+				}
+				if excluded[w] {
+					newExcluded[w] = true
+				}
+			}
+			expand(append(clique, v), newCandidates, newExcluded)
+			delete(candidates, v)
+			excluded[v] = true
+		}
+	}
+
+	candidates := make(map[int]bool)
+	for v := range primal {
+		candidates[v] = true
+	}
+	expand([]int{}, candidates, make(map[int]bool))
+
+	return output
+}
+
+// LowerBound returns a lower bound on the generalized hypertree width of the graph. The vertices of a clique in the
+// primal graph must occur together in some bag, so the width is at least the number of edges needed to cover them.
+// For graphs of at most exactCliqueLimit vertices, all maximal cliques are considered, for larger ones only those
+// found greedily. Cyclic graphs, as detected by the GYÖ reduction, have width at least 2.
+func LowerBound(G Graph) int {
+	if G.Edges.Len() == 0 {
+		return 1
+	}
+
+	output := 1
+	if reduced, _ := G.GYÖReduct(); reduced.Edges.Len() > 0 {
+		output = 2
+	}
+
+	primal := G.primalGraph()
+	var cliques [][]int
+	if len(primal) <= exactCliqueLimit {
+		cliques = maximalCliques(primal)
+	} else {
+		cliques = greedyCliques(primal)
+	}
+
+	for _, clique := range cliques {
+		for output < G.Edges.Len() {
+			if _, ok := (VertexSepCheck{Edges: G.Edges, K: output}).GetCover(clique); ok {
+				break
+			}
+			output++
+		}
+	}
+
+	return output
+}
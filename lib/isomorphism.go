@@ -0,0 +1,291 @@
+package lib
+
+// isomorphism.go detects isomorphic subgraphs via colour refinement, where a given set of vertices stays fixed, and
+// allows to transfer decompositions between isomorphic subgraphs
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+)
+
+// hashInts computes an order-dependent hash of a slice of uint64
+func hashInts(values ...uint64) uint64 {
+	h := fnv.New64a()
+	bs := make([]byte, 8)
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(bs, v)
+		h.Write(bs)
+	}
+	return h.Sum64()
+}
+
+// isoEdges returns the vertex sets of all edges and special edges of g, special edges marked by a true flag
+func (g *Graph) isoEdges() ([][]int, []bool) {
+	var output [][]int
+	var special []bool
+
+	for _, e := range g.Edges.Slice() {
+		output = append(output, e.Vertices)
+		special = append(special, false)
+	}
+	for i := range g.Special {
+		output = append(output, g.Special[i].Vertices())
+		special = append(special, true)
+	}
+
+	return output, special
+}
+
+// initialColours assigns each fixed vertex its own colour, and the same colour to all other vertices
+func (g *Graph) initialColours(fixed map[int]bool) map[int]uint64 {
+	colours := make(map[int]uint64)
+
+	for _, v := range g.Vertices() {
+		if fixed[v] {
+			colours[v] = hashInts(1, uint64(v))
+		} else {
+			colours[v] = 0
+		}
+	}
+
+	return colours
+}
+
+func numColours(colours map[int]uint64) int {
+	distinct := make(map[uint64]struct{})
+	for _, c := range colours {
+		distinct[c] = Empty
+	}
+	return len(distinct)
+}
+
+// refine performs colour refinement until the number of colour classes stabilises
+func (g *Graph) refine(colours map[int]uint64) map[int]uint64 {
+	edges, special := g.isoEdges()
+	current := numColours(colours)
+
+	for {
+		incident := make(map[int][]uint64)
+
+		for i, e := range edges {
+			var members []uint64
+			for _, v := range e {
+				members = append(members, colours[v])
+			}
+			sort.Slice(members, func(a, b int) bool { return members[a] < members[b] })
+			if special[i] {
+				members = append(members, 1)
+			}
+			edgeColour := hashInts(members...)
+
+			for _, v := range e {
+				incident[v] = append(incident[v], edgeColour)
+			}
+		}
+
+		next := make(map[int]uint64)
+		for v, c := range colours {
+			sig := incident[v]
+			sort.Slice(sig, func(a, b int) bool { return sig[a] < sig[b] })
+			next[v] = hashInts(append([]uint64{c}, sig...)...)
+		}
+
+		nextNum := numColours(next)
+		colours = next
+		if nextNum == current {
+			return colours
+		}
+		current = nextNum
+	}
+}
+
+// IsoHash returns a hash of the graph which is invariant under renaming any vertex not in fixed
+func (g Graph) IsoHash(fixed []int) uint64 {
+	fixedMap := make(map[int]bool)
+	for _, v := range fixed {
+		fixedMap[v] = true
+	}
+
+	colours := g.refine(g.initialColours(fixedMap))
+
+	var values []uint64
+	for _, c := range colours {
+		values = append(values, c)
+	}
+	sort.Slice(values, func(a, b int) bool { return values[a] < values[b] })
+
+	return hashInts(append(values, uint64(g.Edges.Len()), uint64(len(g.Special)))...)
+}
+
+func vertexKey(vertices []int) string {
+	sorted := make([]int, len(vertices))
+	copy(sorted, vertices)
+	sort.Ints(sorted)
+
+	bs := make([]byte, binary.MaxVarintLen64*len(sorted))
+	pos := 0
+	for _, v := range sorted {
+		pos = pos + binary.PutVarint(bs[pos:], int64(v))
+	}
+	return string(bs[:pos])
+}
+
+// IsoMapping tries to find a vertex bijection from g to other which maps the edges and special edges of g onto
+// those of other, keeping all vertices in fixed unchanged. Returns nil if no such mapping could be found, which
+// may also happen for some isomorphic graphs with many automorphisms.
+func (g Graph) IsoMapping(other Graph, fixed []int) map[int]int {
+	if g.Edges.Len() != other.Edges.Len() || len(g.Special) != len(other.Special) ||
+		len(g.Vertices()) != len(other.Vertices()) {
+		return nil
+	}
+
+	fixedMap := make(map[int]bool)
+	for _, v := range fixed {
+		fixedMap[v] = true
+	}
+
+	coloursG := g.refine(g.initialColours(fixedMap))
+	coloursO := other.refine(other.initialColours(fixedMap))
+
+	for {
+		classesG := make(map[uint64][]int)
+		classesO := make(map[uint64][]int)
+		for v, c := range coloursG {
+			classesG[c] = append(classesG[c], v)
+		}
+		for v, c := range coloursO {
+			classesO[c] = append(classesO[c], v)
+		}
+
+		// pick the smallest non-singleton class, if any, to individualise a vertex in it
+		var target uint64
+		found := false
+		for c, vs := range classesG {
+			if len(vs) != len(classesO[c]) {
+				return nil
+			}
+			if len(vs) > 1 && (!found || c < target) {
+				target = c
+				found = true
+			}
+		}
+
+		if !found {
+			mapping := make(map[int]int)
+			for c, vs := range classesG {
+				mapping[vs[0]] = classesO[c][0]
+			}
+			if g.checkMapping(other, mapping, fixedMap) {
+				return mapping
+			}
+			return nil
+		}
+
+		sort.Ints(classesG[target])
+		sort.Ints(classesO[target])
+		coloursG[classesG[target][0]] = hashInts(2, target)
+		coloursO[classesO[target][0]] = hashInts(2, target)
+		coloursG = g.refine(coloursG)
+		coloursO = other.refine(coloursO)
+	}
+}
+
+// checkMapping verifies that the mapping keeps fixed vertices and maps all (special) edges of g onto other
+func (g Graph) checkMapping(other Graph, mapping map[int]int, fixed map[int]bool) bool {
+	for v, w := range mapping {
+		if fixed[v] && v != w {
+			return false
+		}
+	}
+
+	edgesO, specialO := other.isoEdges()
+	available := make(map[string]int)
+	for i := range edgesO {
+		key := vertexKey(edgesO[i])
+		if specialO[i] {
+			key = "s" + key
+		}
+		available[key]++
+	}
+
+	edgesG, specialG := g.isoEdges()
+	for i := range edgesG {
+		var image []int
+		for _, v := range edgesG[i] {
+			image = append(image, mapping[v])
+		}
+		key := vertexKey(image)
+		if specialG[i] {
+			key = "s" + key
+		}
+		if available[key] == 0 {
+			return false
+		}
+		available[key]--
+	}
+
+	return true
+}
+
+// Remap produces a copy of the subtree rooted at n, with all vertices renamed according to mapping (vertices not
+// in mapping are kept). Cover edges are replaced by the edges from edges with the renamed vertex set. Returns false
+// if some renamed cover edge can't be found in edges.
+func (n Node) Remap(mapping map[int]int, edges Edges) (Node, bool) {
+	lookup := make(map[string]Edge)
+	for _, e := range edges.Slice() {
+		lookup[vertexKey(e.Vertices)] = e
+	}
+
+	return n.remap(mapping, lookup)
+}
+
+func (n Node) remap(mapping map[int]int, lookup map[string]Edge) (Node, bool) {
+	rename := func(vertices []int) ([]int, bool) {
+		changed := false
+		output := make([]int, len(vertices))
+		for i, v := range vertices {
+			output[i] = v
+			if w, ok := mapping[v]; ok && w != v {
+				output[i] = w
+				changed = true
+			}
+		}
+		return output, changed
+	}
+
+	output := Node{Bag: n.Bag, Cover: n.Cover, Cost: n.Cost}
+
+	if bag, changed := rename(n.Bag); changed {
+		output.Bag = bag
+	}
+
+	var cover []Edge
+	coverChanged := false
+	for _, e := range n.Cover.Slice() {
+		vertices, changed := rename(e.Vertices)
+		if !changed {
+			cover = append(cover, e)
+			continue
+		}
+		coverChanged = true
+		image, ok := lookup[vertexKey(vertices)]
+		if !ok {
+			return Node{}, false
+		}
+		cover = append(cover, image)
+	}
+	if coverChanged {
+		output.Cover = NewEdges(cover)
+	}
+
+	for i := range n.Children {
+		child, ok := n.Children[i].remap(mapping, lookup)
+		if !ok {
+			return Node{}, false
+		}
+		output.Children = append(output.Children, child)
+	}
+
+	return output, true
+}
@@ -0,0 +1,69 @@
+package lib
+
+import "sync"
+
+// A Limiter bounds the number of goroutines spawned by all algorithms together, from the workers of the searches to
+// the recursive calls on components, and records the peak number of them running at once. Callers not granted a
+// goroutine do the work in their own one instead, so a limit never blocks. The zero value imposes no bound.
+type Limiter struct {
+	mux     sync.Mutex
+	max     int
+	running int
+	peak    int
+}
+
+// Goroutines is the limiter shared by all algorithms
+var Goroutines = &Limiter{}
+
+// SetMax sets the maximal number of goroutines running at once, a value ≤ 0 meaning no bound, and resets the peak
+func (l *Limiter) SetMax(n int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.max = n
+	l.peak = l.running
+}
+
+// TryAcquire grants up to n goroutines, as many as the bound allows, and returns their number. All of them must be
+// returned by Release once they are done.
+func (l *Limiter) TryAcquire(n int) int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.max > 0 && l.running+n > l.max {
+		n = l.max - l.running
+		if n < 0 {
+			n = 0
+		}
+	}
+	l.running += n
+	if l.running > l.peak {
+		l.peak = l.running
+	}
+
+	return n
+}
+
+// Release returns n goroutines granted by TryAcquire
+func (l *Limiter) Release(n int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.running -= n
+}
+
+// Running returns the number of goroutines currently granted
+func (l *Limiter) Running() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return l.running
+}
+
+// Peak returns the largest number of goroutines granted at once since the last call of SetMax
+func (l *Limiter) Peak() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return l.peak
+}
@@ -0,0 +1,185 @@
+package lib
+
+// logging.go provides leveled logs, tagged by the component of the solver producing them, so that parts of a search
+// can be debugged without drowning in the logs of all others
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// A LogLevel bounds which messages of a logger are written, from none at LogOff to all at LogDebug
+type LogLevel int32
+
+// The log levels, each including the messages of the ones before it
+const (
+	LogOff LogLevel = iota
+	LogError
+	LogWarn
+	LogInfo
+	LogDebug
+)
+
+var logLevelNames = []string{"off", "error", "warn", "info", "debug"}
+
+func (l LogLevel) String() string {
+	if l < LogOff || int(l) >= len(logLevelNames) {
+		return fmt.Sprintf("LogLevel(%d)", int32(l))
+	}
+	return logLevelNames[l]
+}
+
+// ParseLogLevel returns the level of the given name
+func ParseLogLevel(name string) (LogLevel, error) {
+	for i := range logLevelNames {
+		if logLevelNames[i] == name {
+			return LogLevel(i), nil
+		}
+	}
+	return LogOff, fmt.Errorf("unknown log level %q, supported are: %v", name, logLevelNames)
+}
+
+// A Logger writes the messages of one component up to its level. All loggers are off initially. Checking the level
+// costs a single atomic load, so calls whose arguments are expensive to compute, such as the string of a graph, are
+// meant to be guarded by Enabled, which makes them free when the logger is off.
+type Logger struct {
+	name  string
+	level int32
+}
+
+// The loggers of the components of the solver
+var (
+	LogSearch    = &Logger{name: "search"}    // separators found by the searches
+	LogCache     = &Logger{name: "cache"}     // hits and evictions of caches of failed separators
+	LogRecursion = &Logger{name: "recursion"} // subgraphs decomposed, and separators chosen or rejected for them
+	LogParser    = &Logger{name: "parser"}    // problems found in input files
+)
+
+var loggers = []*Logger{LogSearch, LogCache, LogRecursion, LogParser}
+
+var (
+	logMux    sync.Mutex
+	logOutput io.Writer = os.Stderr
+)
+
+// SetLogOutput sets the writer all loggers write to, os.Stderr by default
+func SetLogOutput(w io.Writer) {
+	logMux.Lock()
+	defer logMux.Unlock()
+
+	logOutput = w
+}
+
+// Name returns the name of the component the logger is for
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Level returns the current level of the logger
+func (l *Logger) Level() LogLevel {
+	return LogLevel(atomic.LoadInt32(&l.level))
+}
+
+// SetLevel sets the level of the logger, and may be called at any time
+func (l *Logger) SetLevel(level LogLevel) {
+	atomic.StoreInt32(&l.level, int32(level))
+}
+
+// Enabled returns true if messages of the given level are written
+func (l *Logger) Enabled(level LogLevel) bool {
+	return level != LogOff && LogLevel(atomic.LoadInt32(&l.level)) >= level
+}
+
+// Printf writes a message of the given level, prefixed by the component and level, if the logger is enabled for it
+func (l *Logger) Printf(level LogLevel, format string, v ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	msg := fmt.Sprintf(format, v...)
+
+	logMux.Lock()
+	defer logMux.Unlock()
+
+	fmt.Fprintf(logOutput, "%s %s: %s\n", l.name, level, strings.TrimSuffix(msg, "\n"))
+}
+
+// LogComponents returns the names of all components with a logger, in alphabetical order
+func LogComponents() []string {
+	var output []string
+	for _, l := range loggers {
+		output = append(output, l.name)
+	}
+	sort.Strings(output)
+	return output
+}
+
+// SetLogLevels sets the levels of the loggers by a comma-separated list of entries, each either of the form
+// component=level, or only a level for all components, with later entries taking precedence. E.g. "info,search=debug"
+// writes all messages up to info, and debug messages of the search as well. Components not mentioned keep their
+// level. Nothing is changed if the list has an error.
+func SetLogLevels(spec string) error {
+	levels := make(map[*Logger]LogLevel)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		targets := loggers
+		levelName := entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			name := strings.TrimSpace(entry[:i])
+			levelName = entry[i+1:]
+			targets = nil
+			for _, l := range loggers {
+				if l.name == name {
+					targets = []*Logger{l}
+				}
+			}
+			if targets == nil {
+				return fmt.Errorf("unknown log component %q, supported are: %v", name, LogComponents())
+			}
+		}
+
+		level, err := ParseLogLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return err
+		}
+		for _, l := range targets {
+			levels[l] = level
+		}
+	}
+
+	for l, level := range levels {
+		l.SetLevel(level)
+	}
+	return nil
+}
+
+// LogLevels returns the levels of all loggers not turned off, in the form accepted by SetLogLevels
+func LogLevels() string {
+	var entries []string
+	for _, l := range loggers {
+		if level := l.Level(); level != LogOff {
+			entries = append(entries, l.name+"="+level.String())
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// LoggingEnabled returns true if any logger writes messages of at least the given level
+func LoggingEnabled(level LogLevel) bool {
+	for _, l := range loggers {
+		if l.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package lib
+
+// memory.go provides approximate memory footprints of the central data structures, and a reporter to periodically
+// print them during a run
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	wordSize        = 8  // size of an int, pointer or uint64 in bytes
+	sliceHeaderSize = 24 // size of the header of a slice in bytes
+)
+
+// MemSize returns the approximate number of bytes used by an edge
+func (e Edge) MemSize() int {
+	return wordSize + sliceHeaderSize + wordSize*cap(e.Vertices)
+}
+
+// MemSize returns the approximate number of bytes used by an Edges struct, including its cached vertices
+func (e Edges) MemSize() int {
+	output := 2*sliceHeaderSize + 3*wordSize + wordSize*cap(e.vertices)
+
+	for i := range e.slice {
+		output = output + e.slice[i].MemSize()
+	}
+
+	return output
+}
+
+// MemSize returns the approximate number of bytes used by a special edge, including the edges it was created from
+func (s SpecialEdge) MemSize() int {
+	return s.Edges.MemSize() + 2*wordSize + sliceHeaderSize + wordSize*cap(s.vertices)
+}
+
+// MemSize returns the approximate number of bytes used by a graph, including its special edges
+func (g Graph) MemSize() int {
+	output := g.Edges.MemSize() + 2*sliceHeaderSize + wordSize*cap(g.vertices)
+
+	for i := range g.Special {
+		output = output + g.Special[i].MemSize()
+	}
+
+	return output
+}
+
+// MemSize returns the approximate number of bytes used by the subtree rooted at the node
+func (n Node) MemSize() int {
+	output := 3*wordSize + 4*sliceHeaderSize + wordSize*(cap(n.Bag)+cap(n.Conn)+cap(n.vertices)) +
+		n.Cover.MemSize()
+
+	for i := range n.Children {
+		output = output + n.Children[i].MemSize()
+	}
+
+	return output
+}
+
+// MemSize returns the approximate number of bytes used by a decomposition, not counting the graph
+func (d Decomp) MemSize() int {
+	return d.Root.MemSize()
+}
+
+// MemSize returns the approximate number of bytes used by the entries of a cache
+func (c *Cache) MemSize() int {
+	if c.shards == nil {
+		return 0
+	}
+	c.rlock()
+	defer c.runlock()
+
+	var output int
+	for i := range c.shards {
+		for _, v := range c.shards[i].cache {
+			output = output + v.memSize()
+		}
+		for _, v := range c.shards[i].plain {
+			output = output + plainMemSize(v)
+		}
+	}
+
+	return output
+}
+
+// memSize returns the approximate number of bytes used by the entry of a separator
+func (v *compCache) memSize() int {
+	return 2*wordSize + 2*sliceHeaderSize + wordSize*(cap(v.Succ)+2*cap(v.Fail))
+}
+
+// plainMemSize returns the approximate number of bytes used by the set of plain failures of a separator
+func plainMemSize(set map[uint64]int) int {
+	return 3*wordSize + 2*wordSize*len(set) // map entry plus an estimate for each set entry
+}
+
+// ParseBytes parses a size in bytes, given as a number optionally followed by one of the units B, KB, MB, GB or TB,
+// all of them taken as powers of 1024, e.g. "4GB" or "512 MB"
+func ParseBytes(s string) (int, error) {
+	units := []struct {
+		suffix string
+		factor float64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+	input := s
+	s = strings.ToUpper(strings.TrimSpace(s))
+	factor := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, factor = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.factor
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+	return int(n * factor), nil
+}
+
+type memSource struct {
+	name string
+	size func() int
+}
+
+// A MemReport collects a number of named data structures, whose approximate memory usage can be reported
+type MemReport struct {
+	sources []memSource
+	mux     sync.Mutex
+}
+
+// Add registers a new data structure, via a function computing its current size in bytes
+func (r *MemReport) Add(name string, size func() int) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.sources = append(r.sources, memSource{name: name, size: size})
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func (r *MemReport) String() string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	var buffer bytes.Buffer
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	buffer.WriteString(fmt.Sprintf("Memory: heap %s, sys %s, goroutines %d\n", formatBytes(stats.HeapAlloc),
+		formatBytes(stats.Sys), runtime.NumGoroutine()))
+	for _, s := range r.sources {
+		buffer.WriteString(fmt.Sprintf("  %-20s ~%s\n", s.name, formatBytes(uint64(s.size()))))
+	}
+
+	return buffer.String()
+}
+
+// Start prints the report to w in the given interval, until the returned stop function is called
+func (r *MemReport) Start(interval time.Duration, w io.Writer) func() {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprint(w, r.String())
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,78 @@
+package lib
+
+// metrics.go summarises the shape of a decomposition, so that it can be judged without reading the whole tree
+
+import "fmt"
+
+// Width returns the size of the largest cover of any node in the decomp
+func (d Decomp) Width() int {
+	output := 0
+	d.Root.forEach(func(n *Node) bool {
+		if n.Cover.Len() > output {
+			output = n.Cover.Len()
+		}
+		return true
+	})
+	return output
+}
+
+// Depth returns the number of edges on the longest path from the root of the decomp to a leaf, 0 for a single node
+func (d Decomp) Depth() int {
+	output := -1
+
+	current := []*Node{&d.Root}
+	for len(current) > 0 {
+		output++
+		var children []*Node
+		for _, n := range current {
+			for i := range n.Children {
+				children = append(children, &n.Children[i])
+			}
+		}
+		current = children
+	}
+
+	return output
+}
+
+// NumNodes returns the number of nodes of the decomp
+func (d Decomp) NumNodes() int {
+	output := 0
+	d.Root.forEach(func(n *Node) bool {
+		output++
+		return true
+	})
+	return output
+}
+
+// BagStats sums up the sizes of the bags of a decomp, counted in vertices
+type BagStats struct {
+	Min  int
+	Max  int
+	Mean float64
+}
+
+func (s BagStats) String() string {
+	return fmt.Sprintf("min %d, max %d, mean %.2f", s.Min, s.Max, s.Mean)
+}
+
+// BagStats returns the smallest, largest and mean size of the bags of the decomp
+func (d Decomp) BagStats() BagStats {
+	var output BagStats
+	sum, count := 0, 0
+
+	d.Root.forEach(func(n *Node) bool {
+		if count == 0 || len(n.Bag) < output.Min {
+			output.Min = len(n.Bag)
+		}
+		if len(n.Bag) > output.Max {
+			output.Max = len(n.Bag)
+		}
+		sum += len(n.Bag)
+		count++
+		return true
+	})
+	output.Mean = float64(sum) / float64(count)
+
+	return output
+}
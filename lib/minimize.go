@@ -0,0 +1,64 @@
+package lib
+
+// minimize.go post-processes decompositions, removing redundant nodes and edges while keeping them correct
+
+// Minimize returns an equivalent decomposition with fewer nodes and edges. Nodes whose bag is contained in that of a
+// neighbour are removed, adjacent nodes are merged whenever their bags can be covered by at most K of the edges
+// covering them, and each cover is shrunk to a smallest subset covering the bag. The width thus never exceeds K, unless
+// it did so before, and the special condition of hypertree decompositions may not be preserved. Weights and connectors are dropped, as
+// they no longer fit the changed covers and tree.
+func (d Decomp) Minimize(K int) Decomp {
+	if !d.Found() {
+		return d
+	}
+
+	d.RestoreSubedges()
+	d.Root = d.Root.minimize(K)
+
+	return d
+}
+
+// minimize works bottom-up, each node absorbing its children for as long as possible
+func (n Node) minimize(K int) Node {
+	output := Node{Bag: n.Bag, Cover: MinCover(n.Bag, n.Cover)}
+
+	pending := make([]Node, 0, len(n.Children))
+	for i := range n.Children {
+		pending = append(pending, n.Children[i].minimize(K))
+	}
+
+	// a child that is absorbed hands its own children over, which may then be absorbed in turn
+	for len(pending) > 0 {
+		c := pending[0]
+		pending = pending[1:]
+
+		switch {
+		case Subset(c.Bag, output.Bag):
+		case Subset(output.Bag, c.Bag):
+			output.Bag, output.Cover = c.Bag, c.Cover
+		default:
+			bag := RemoveDuplicates(append(append([]int{}, output.Bag...), c.Bag...))
+			cover, ok := coverWithin(bag, append(append([]Edge{}, output.Cover.Slice()...), c.Cover.Slice()...), K)
+			if !ok {
+				output.Children = append(output.Children, c)
+				continue
+			}
+			output.Bag, output.Cover = bag, cover
+		}
+
+		// the bag of output may have grown, so children kept earlier are checked again
+		pending = append(append(pending, c.Children...), output.Children...)
+		output.Children = nil
+	}
+
+	return output
+}
+
+// coverWithin returns a smallest set of at most K of the edges covering the vertices, if there is one
+func coverWithin(vertices []int, edges []Edge, K int) (Edges, bool) {
+	unique := removeDuplicateEdges(edges)
+	if cover := MinCover(vertices, unique); Subset(vertices, cover.Vertices()) && cover.Len() <= K {
+		return cover, true
+	}
+	return Edges{}, false
+}
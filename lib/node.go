@@ -14,18 +14,23 @@ type Node struct {
 	num        int
 	Bag        []int
 	Cover      Edges
+	Conn       []int           // the connector, i.e. the vertices shared with the parent node, see Decomp.SetConnectors
+	Weights    map[int]float64 // weights of the cover edges in a fractional decomposition, nil for integral covers
 	Cost       float64
 	Children   []Node
 	parPointer *Node
 	vertices   []int
 }
 
-func (n Node) printBag() string {
-	mutex.RLock()
-	defer mutex.RUnlock()
+// isZero returns true if the node has neither a bag, a cover nor children
+func (n Node) isZero() bool {
+	return n.Bag == nil && n.Cover.isZero() && n.Children == nil
+}
+
+func (n Node) printBag(enc *Encoding) string {
 	var buffer bytes.Buffer
 	for i, v := range n.Bag {
-		buffer.WriteString(m[v])
+		buffer.WriteString(enc.Name(v))
 		if i != len(n.Bag)-1 {
 			buffer.WriteString(", ")
 		}
@@ -44,26 +49,39 @@ func indent(i int) string {
 	return output
 }
 
-func (n Node) stringIdent(i int) string {
+func (n Node) stringIdent(i int, enc *Encoding) string {
 	var buffer bytes.Buffer
 
-	buffer.WriteString("\n" + indent(i) + "Bag: {" + n.printBag() + "}")
+	buffer.WriteString("\n" + indent(i) + "Bag: {" + n.printBag(enc) + "}")
 
 	buffer.WriteString("\n" + indent(i) + "Cover: {")
 	for i, e := range n.Cover.Slice() {
-		buffer.WriteString(e.String())
+		buffer.WriteString(e.stringEnc(enc))
 		if i != n.Cover.Len()-1 {
 			buffer.WriteString(", ")
 		}
 	}
 	buffer.WriteString("}\n")
+	if n.Weights != nil {
+		buffer.WriteString(indent(i) + "Weights: {")
+		for j, e := range n.Cover.Slice() {
+			buffer.WriteString(e.stringEnc(enc) + ": " + fmt.Sprintf("%.3f", n.Weights[e.Name]))
+			if j != n.Cover.Len()-1 {
+				buffer.WriteString(", ")
+			}
+		}
+		buffer.WriteString("}\n")
+	}
+	if len(n.Conn) > 0 {
+		buffer.WriteString(indent(i) + "Connector: " + enc.PrintVertices(n.Conn) + "\n")
+	}
 	if n.Cost != 0 {
 		buffer.WriteString(indent(i) + "Cost: " + fmt.Sprintf("%.2f", n.Cost) + "\n")
 	}
 	if len(n.Children) > 0 {
 		buffer.WriteString(indent(i) + "Children: " + strconv.Itoa(len(n.Children)) + "\n" + indent(i) + "[")
 		for _, c := range n.Children {
-			buffer.WriteString(c.stringIdent(i + 1))
+			buffer.WriteString(c.stringIdent(i+1, enc))
 		}
 		buffer.WriteString(indent(i) + "]\n")
 	}
@@ -72,66 +90,99 @@ func (n Node) stringIdent(i int) string {
 }
 
 func (n Node) String() string {
-	return n.stringIdent(0)
+	return n.stringIdent(0, NewEncoding())
 }
 
-func (n Node) contains(o Node) bool {
-	// every node contains itself
-	if reflect.DeepEqual(n, o) {
-		return true
-	}
-	// Check recursively if contained in children
-	for _, child := range n.Children {
-		if child.contains(o) {
-			return true
+// forEach calls f on every node of the subtree rooted at n in pre-order, until f returns false. An explicit stack is
+// used instead of recursion, as decompositions can be too deep for the stack of a goroutine. Returns false if f did.
+func (n *Node) forEach(f func(*Node) bool) bool {
+	stack := []*Node{n}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if !f(current) {
+			return false
+		}
+		for i := len(current.Children) - 1; i >= 0; i-- { // reversed, so the first child is visited first
+			stack = append(stack, &current.Children[i])
 		}
 	}
 
-	return false
+	return true
 }
 
 // bagSubsets checks if all bags are proper subsets of the union of their covers
 func (n Node) bagSubsets() bool {
-	if !Subset(n.Bag, n.Cover.Vertices()) {
-		// log.Println("Bag:", PrintVertices(n.Bag), "Cover: ", n.Cover)
-		return false
-	}
+	return n.forEach(func(c *Node) bool {
+		// log.Println("Bag:", PrintVertices(c.Bag), "Cover: ", c.Cover)
+		return Subset(c.Bag, c.Cover.Vertices())
+	})
+}
 
-	for _, c := range n.Children {
-		if !c.bagSubsets() {
-			return false
+// missingVertices returns the vertices not contained in any bag of the subtree
+func (n Node) missingVertices(vertices []int) []int {
+	var output []int
+	if len(vertices) == 0 {
+		return output
+	}
+	inBag := make(map[int]bool)
+	n.forEach(func(c *Node) bool {
+		for _, v := range c.Bag {
+			inBag[v] = true
+		}
+		return true
+	})
+	for _, v := range vertices {
+		if !inBag[v] {
+			output = append(output, v)
 		}
 	}
+	return output
+}
 
-	return true
+// missingVertex returns a vertex not contained in any bag of the subtree, if there is one
+func (n Node) missingVertex(vertices []int) (int, bool) {
+	if missing := n.missingVertices(vertices); len(missing) > 0 {
+		return missing[0], true
+	}
+	return 0, false
 }
 
-// checks if an edge appears as a subset in some bag in the subtree of n
-func (n Node) coversEdge(e Edge) bool {
-	// edge contained in current node
-	if Subset(e.Vertices, n.Bag) {
+// uncoveredEdge returns an edge not appearing as a subset of any bag in the subtree of n, and false if there is none
+func (n Node) uncoveredEdge(edges Edges) (Edge, bool) {
+	// index the bags by their vertices, so only bags containing the first vertex of an edge need to be checked
+	var bags [][]int
+	index := make(map[int][]int)
+	n.forEach(func(c *Node) bool {
+		for _, v := range c.Bag {
+			index[v] = append(index[v], len(bags))
+		}
+		bags = append(bags, c.Bag)
 		return true
-	}
+	})
 
-	// Check recursively if contained in children
-	for i := range n.Children {
-		if n.Children[i].coversEdge(e) {
-			return true
+OUTER:
+	for _, e := range edges.Slice() {
+		if len(e.Vertices) == 0 {
+			continue
 		}
+		for _, i := range index[e.Vertices[0]] {
+			if Subset(e.Vertices, bags[i]) {
+				continue OUTER
+			}
+		}
+		return e, true
 	}
 
-	return false
+	return Edge{}, false
 }
 
 // getNumber assigns some number to a node
 func (n *Node) getNumber() {
 	if n.num == 0 {
-		temp := "num : " + strconv.Itoa(n.num) + " node: " + n.Cover.String()
-		mutex.Lock()
-		n.num = encode
-		m[n.num] = temp
-		encode++
-		mutex.Unlock()
+		n.num = nextNodeNum()
 	}
 }
 
@@ -158,49 +209,64 @@ func (n *Node) getConGraph(withLoops bool) Edges {
 	return NewEdges(output)
 }
 
-// parent returns the parent of of in n, if it doesn't exist, nil is returned
-func (n *Node) parent(o Node) Node {
-	if n.parPointer != nil { // check for existing pointer
-		return *n.parPointer
+// pathTo returns the nodes on the path from n down to the first occurrence of o, or nil if o is not in the subtree.
+// The second slice holds for each node on the path the position of the next node among its children.
+func (n *Node) pathTo(o Node) ([]*Node, []int) {
+	if reflect.DeepEqual(*n, o) {
+		return []*Node{n}, []int{}
 	}
 
-	// Check recursively if contained in children
-	for i := range n.Children {
-		if reflect.DeepEqual(n.Children[i], o) {
-			return *n
-		} else if n.Children[i].contains(o) {
-			return n.Children[i].parent(o)
+	// the path so far, and for each node on it the next child to look at
+	path := []*Node{n}
+	next := []int{0}
+
+	for len(path) > 0 {
+		last := len(path) - 1
+		current := path[last]
+
+		if next[last] == len(current.Children) { // all children checked, go back up
+			path = path[:last]
+			next = next[:last]
+			continue
 		}
 
+		child := &current.Children[next[last]]
+		next[last]++
+		path = append(path, child)
+		if reflect.DeepEqual(*child, o) {
+			positions := make([]int, len(next))
+			for i := range next {
+				positions[i] = next[i] - 1
+			}
+			return path, positions
+		}
+		next = append(next, 0)
 	}
 
-	n.parPointer = &o // cache the result
-	return o
+	return nil, nil
 }
 
 // Reroot produces a new, isomorphic subtree, rerooting G at child
 func (n Node) Reroot(child Node) Node {
-	if !n.contains(child) {
+	path, positions := n.pathTo(child)
+	if path == nil {
 		log.Panicf("Can't reRoot: no child %+v in node %+v!\n", child, n)
 	}
-	if reflect.DeepEqual(n, child) {
-		return child
-	}
-	p := n.parent(child)
-	p = n.Reroot(p)
 
-	// remove child from children of parent
-	var newparentchildren []Node
-	for _, c := range p.Children {
-		if reflect.DeepEqual(c, child) {
-			continue
-		}
-		newparentchildren = append(newparentchildren, c)
+	// move down the path, turning each node into the last child of the next one
+	output := *path[0]
+	for i, next := range path[1:] {
+		// remove next from the children of the current root, the original children keep their positions
+		var newparentchildren []Node
+		newparentchildren = append(newparentchildren, output.Children[:positions[i]]...)
+		newparentchildren = append(newparentchildren, output.Children[positions[i]+1:]...)
+		output.Children = newparentchildren
+
+		newchildren := append(append([]Node{}, next.Children...), output)
+		output = Node{Bag: next.Bag, Cover: next.Cover, Weights: next.Weights, Children: newchildren}
 	}
-	p.Children = newparentchildren
-	newchildren := append(child.Children, p)
 
-	return Node{Bag: child.Bag, Cover: child.Cover, Children: newchildren}
+	return output
 }
 
 // Vertices recursively collects all vertices from the bag of this node, and the bags of all its children
@@ -210,26 +276,26 @@ func (n *Node) Vertices() []int {
 	}
 
 	var output []int
-	output = append(output, n.Bag...)
-
-	for _, c := range n.Children {
-		output = append(output, c.Vertices()...)
-	}
+	n.forEach(func(c *Node) bool {
+		output = append(output, c.Bag...)
+		return true
+	})
 
 	n.vertices = RemoveDuplicates(output)
 	return n.vertices
 }
 
-// specialCondition tests special condition violation on one node
-func (n Node) specialCondition() bool {
+// specialCondition tests special condition violation on one node, naming the violating vertex with enc
+func (n Node) specialCondition(enc *Encoding) bool {
 	hiddenVertices := Diff(n.Cover.Vertices(), n.Bag)
+	if len(hiddenVertices) == 0 {
+		return true
+	}
 	verticesRooted := n.Vertices()
 
 	for _, v := range hiddenVertices {
 		if mem(verticesRooted, v) {
-			mutex.RLock()
-			log.Println("Vertex ", m[v], " violates special condition")
-			mutex.RUnlock()
+			log.Println("Vertex ", enc.Name(v), " violates special condition")
 			return false
 		}
 	}
@@ -237,27 +303,48 @@ func (n Node) specialCondition() bool {
 	return true
 }
 
-// noSCViolation test special condition recursively on entire subtree rooted at node
-func (n Node) noSCViolation() bool {
-	if !n.specialCondition() {
-		return false
+// noSCViolation test special condition on entire subtree rooted at node
+func (n Node) noSCViolation(enc *Encoding) bool {
+	// specialCondition works on a copy of each node, so the tree isn't changed by caching the vertices
+	return n.forEach(func(c *Node) bool { return c.specialCondition(enc) })
+}
+
+// restoreEdges replaces any ad-hoc subedges with a fitting superedge from a given input set
+func (n *Node) restoreEdges(edges Edges) Node {
+	var output Node
+
+	// each original node is paired with its copy in the output, whose children still need to be filled in
+	type pair struct {
+		orig *Node
+		copy *Node
 	}
+	stack := []pair{{orig: n, copy: &output}}
 
-	for i := range n.Children {
-		if !n.Children[i].noSCViolation() {
-			return false
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		orig := current.orig
+		*current.copy = Node{Bag: orig.Bag, Cover: NewEdges(restoreCover(orig.Cover, edges)), Conn: orig.Conn,
+			Weights: orig.Weights, Cost: orig.Cost}
+
+		if len(orig.Children) > 0 {
+			current.copy.Children = make([]Node, len(orig.Children))
+			for i := range orig.Children {
+				stack = append(stack, pair{orig: &orig.Children[i], copy: &current.copy.Children[i]})
+			}
 		}
 	}
 
-	return true
+	return output
 }
 
-// restoreEdges replaces any ad-hoc subedges with a fitting superedge from a given input set
-func (n *Node) restoreEdges(edges Edges) Node {
+// restoreCover replaces the ad-hoc subedges in a cover by the first edge from edges containing them
+func restoreCover(cover Edges, edges Edges) []Edge {
 	var nuCover []Edge
 
 OUTER:
-	for _, e2 := range n.Cover.Slice() {
+	for _, e2 := range cover.Slice() {
 		if e2.Name != 0 {
 			nuCover = append(nuCover, e2)
 			continue
@@ -270,13 +357,7 @@ OUTER:
 		}
 	}
 
-	var nuChildern []Node
-
-	for i := range n.Children {
-		nuChildern = append(nuChildern, n.Children[i].restoreEdges(edges))
-	}
-
-	return Node{Bag: n.Bag, Cover: NewEdges(nuCover), Cost: n.Cost, Children: nuChildern}
+	return nuCover
 }
 
 // CombineNodes attaches subtree to n, via the connecting special edge
@@ -300,31 +381,30 @@ func (n *Node) CombineNodes(subtree Node, connecting Edges) *Node {
 	return nil
 }
 
-func (n Node) connected(v int, parentContainsV bool) (bool, bool) {
-	containsV := mem(n.Bag, v)
-	subtreeContainsV := containsV
-	numNeighboursContainingV := 0
-
-	if parentContainsV {
-		numNeighboursContainingV = 1
-	}
+// disconnected returns all vertices whose occurrences in the bags don't form a connected subtree. The nodes containing
+// a vertex form a forest, which is a single tree if and only if it has exactly one node more than it has edges.
+func (n Node) disconnected() []int {
+	nodes := make(map[int]int) // for each vertex the number of nodes containing it
+	edges := make(map[int]int) // for each vertex the number of edges between nodes containing it
 
-	for i := range n.Children {
-		connected, subtreeFlag := n.Children[i].connected(v, containsV)
-
-		if !connected { // stop if subtree rooted at child i already has a disconnected subtree
-			return false, false
+	n.forEach(func(c *Node) bool {
+		for _, v := range RemoveDuplicates(append([]int{}, c.Bag...)) {
+			nodes[v]++
 		}
-
-		if subtreeFlag {
-			numNeighboursContainingV++
-			subtreeContainsV = true
+		for i := range c.Children {
+			for _, v := range RemoveDuplicates(Inter(c.Bag, c.Children[i].Bag)) {
+				edges[v]++
+			}
 		}
-	}
+		return true
+	})
 
-	if !containsV && numNeighboursContainingV > 1 {
-		return false, false
+	var output []int
+	for v, count := range nodes {
+		if count-edges[v] > 1 {
+			output = append(output, v)
+		}
 	}
 
-	return true, subtreeContainsV
+	return output
 }
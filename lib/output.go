@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 )
 
@@ -52,7 +53,7 @@ func (d Decomp) ToGML() string {
 
 	buffer.WriteString("graph [\n\n  directed 0\n\n")
 	edges := d.Root.getConGraph(false).Slice()
-	buffer.WriteString(d.Root.toGML())
+	buffer.WriteString(d.Root.toGML(d.Graph.Encoding()))
 
 	for i := range edges {
 		buffer.WriteString(edges[i].toGML())
@@ -68,18 +69,49 @@ func (d Decomp) ToGML() string {
 	return result
 }
 
-func (n Node) toGML() string {
+func (n Node) toGML(enc *Encoding) string {
 
 	var buffer bytes.Buffer
 
 	current := "  node [\n    id " + fmt.Sprint(n.num) +
-		"\n    label \"" + n.Cover.String() + " " + PrintVertices(n.Bag) +
+		"\n    label \"" + n.Cover.stringEnc(enc) + " " + enc.PrintVertices(n.Bag) +
 		"\"\n    vgj [\n      labelPosition \"in\"\n      shape \"Rectangle\"\n    ]\n  ]\n\n"
 
 	buffer.WriteString(current)
 
 	for i := range n.Children {
-		buffer.WriteString(n.Children[i].toGML())
+		buffer.WriteString(n.Children[i].toGML(enc))
+	}
+
+	return buffer.String()
+}
+
+// ToDOT exports the decomp as a string, in the DOT format of Graphviz
+func (d Decomp) ToDOT() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("graph decomp {\n  node [shape=box];\n\n")
+	edges := d.Root.getConGraph(false).Slice()
+	buffer.WriteString(d.Root.toDOT(d.Graph.Encoding()))
+	buffer.WriteString("\n")
+
+	for i := range edges {
+		buffer.WriteString("  n" + fmt.Sprint(edges[i].Vertices[0]) + " -- n" + fmt.Sprint(edges[i].Vertices[1]) + ";\n")
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
+
+func (n Node) toDOT(enc *Encoding) string {
+	var buffer bytes.Buffer
+
+	label := n.Cover.stringEnc(enc) + "\\n" + enc.PrintVertices(n.Bag)
+	label = strings.ReplaceAll(label, "\"", "\\\"")
+	buffer.WriteString("  n" + fmt.Sprint(n.num) + " [label=\"" + label + "\"];\n")
+
+	for i := range n.Children {
+		buffer.WriteString(n.Children[i].toDOT(enc))
 	}
 
 	return buffer.String()
@@ -92,3 +124,131 @@ func (e Edge) toGML() string {
 	return "  edge [\n    source " + fmt.Sprint(e.Vertices[0]) +
 		"\n    target " + fmt.Sprint(e.Vertices[1]) + "\n  ]\n\n"
 }
+
+// ToHyperBench exports the graph as a string in HyperBench format. Special edges are not part of the format and are therefore skipped.
+func (g Graph) ToHyperBench() string {
+	var buffer bytes.Buffer
+
+	enc := g.Encoding()
+	for i, e := range g.Edges.Slice() {
+		buffer.WriteString(e.fullStringEnc(enc))
+		if i != g.Edges.Len()-1 {
+			buffer.WriteString(",\n")
+		}
+	}
+
+	buffer.WriteString(".\n")
+	return buffer.String()
+}
+
+// ToCertificate exports the decomposition together with its hypergraph in the certificate format read by
+// cmd/verifycert. The format is line-based, each line starting with a single letter:
+//
+//	c <text>                    comment
+//	g <vertices> <edges>        number of vertices and edges, once before any other line
+//	e <edge> <vertex>...        an edge of the hypergraph and its vertices
+//	n <node> <parent>           a node of the tree, parent 0 marks the root; parents come before their children
+//	b <node> <vertex>...        the bag of a node
+//	l <node> <edge>...          the cover (lambda label) of a node
+//
+// Vertices, edges and nodes are positive integers.
+func (d Decomp) ToCertificate() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("c certificate of a generalized hypertree decomposition\n")
+	buffer.WriteString(fmt.Sprintf("g %d %d\n", len(d.Graph.Edges.Vertices()), d.Graph.Edges.Len()))
+	for _, e := range d.Graph.Edges.Slice() {
+		buffer.WriteString(fmt.Sprint("e ", e.Name))
+		for _, v := range e.Vertices {
+			buffer.WriteString(fmt.Sprint(" ", v))
+		}
+		buffer.WriteString("\n")
+	}
+
+	counter := 0
+	var visit func(n Node, parent int)
+	visit = func(n Node, parent int) {
+		counter++
+		id := counter
+
+		buffer.WriteString(fmt.Sprintf("n %d %d\nb %d", id, parent, id))
+		for _, v := range n.Bag {
+			buffer.WriteString(fmt.Sprint(" ", v))
+		}
+		buffer.WriteString(fmt.Sprintf("\nl %d", id))
+		for _, e := range n.Cover.Slice() {
+			buffer.WriteString(fmt.Sprint(" ", e.Name))
+		}
+		buffer.WriteString("\n")
+
+		for i := range n.Children {
+			visit(n.Children[i], id)
+		}
+	}
+	visit(d.Root, 0)
+
+	return buffer.String()
+}
+
+// ToTD exports the bags of the decomp as a tree decomposition of the primal graph of its graph, in the .td format of
+// the PACE 2017 treewidth track. Vertices keep their numbers if the graph was parsed in PACE format, and are otherwise
+// numbered in their order of appearance in the graph, as by ToPACE, with a comment line naming each of them.
+func (d Decomp) ToTD() string {
+	var buffer bytes.Buffer
+	enc := d.Graph.Encoding()
+	vertices := d.Graph.Edges.Vertices()
+
+	numbers := make(map[int]int, len(vertices))
+	used := make(map[int]bool, len(vertices))
+	for _, v := range vertices {
+		n, err := strconv.Atoi(strings.TrimPrefix(enc.Name(v), "V"))
+		if err != nil || n <= 0 || n > len(vertices) || used[n] || !strings.HasPrefix(enc.Name(v), "V") {
+			numbers = nil
+			break
+		}
+		numbers[v] = n
+		used[n] = true
+	}
+	if numbers == nil {
+		numbers = make(map[int]int, len(vertices))
+		for _, e := range d.Graph.Edges.Slice() {
+			for _, v := range e.Vertices {
+				if _, ok := numbers[v]; !ok {
+					numbers[v] = len(numbers) + 1
+					buffer.WriteString(fmt.Sprintf("c vertex %d %s\n", numbers[v], enc.Name(v)))
+				}
+			}
+		}
+	}
+
+	var bags, edges bytes.Buffer
+	counter, largest := 0, 0
+	var visit func(n Node, parent int)
+	visit = func(n Node, parent int) {
+		counter++
+		id := counter
+
+		bags.WriteString(fmt.Sprint("b ", id))
+		for _, v := range n.Bag {
+			bags.WriteString(fmt.Sprint(" ", numbers[v]))
+		}
+		bags.WriteString("\n")
+		if len(n.Bag) > largest {
+			largest = len(n.Bag)
+		}
+		if parent > 0 {
+			edges.WriteString(fmt.Sprintf("%d %d\n", parent, id))
+		}
+
+		for i := range n.Children {
+			visit(n.Children[i], id)
+		}
+	}
+	visit(d.Root, 0)
+
+	buffer.WriteString(fmt.Sprintf("s td %d %d %d\n", counter, largest, len(vertices)))
+	buffer.Write(bags.Bytes())
+	buffer.Write(edges.Bytes())
+
+	return buffer.String()
+}
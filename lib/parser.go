@@ -1,13 +1,14 @@
 package lib
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"log"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -18,101 +19,24 @@ import (
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
-var m map[int]string // stores the encoding of vertices for last file parsed (bit of a hack)
-var mutex = sync.RWMutex{}
-var encode int // stores the encoding of the highest int used
-
 type parseEdge struct {
-	Name     string   ` @(Number|Ident|String)`
-	Vertices []string `"(" ( @(Number|Ident|String)  ","? )* ")"`
+	Name     string   `parser:" @(Number|Ident|String)"`
+	Vertices []string `parser:"\"(\" ( @(Number|Ident|String)  \",\"? )* \")\""`
 }
 
 // ParseGraph contains data used to parse a graph, potentially useful for testing
 type ParseGraph struct {
-	Edges    []parseEdge `( @@ ","?)* (".")?`
+	Edges    []parseEdge
+	Isolated []string // vertices declared without being part of any edge
 	Encoding map[string]int
+	Query    *Query    // the query the graph was built from, if any
+	encoding *Encoding // the encoding of the parsed graph, extended by GetEdge
 }
 
-// TransparentEncoding will overwrite the encoding map in order to print out the exact underlying integer encoding.
-// Needs to be called after GetGraph to be effective.
-func TransparentEncoding() {
-	for i := 0; i < encode; i++ {
-		m[i] = strconv.Itoa(i)
-	}
-}
-
-// GetGraph parses a string in HyperBench format into a graph
-func GetGraph(s string) (Graph, ParseGraph) {
-
-	graphLexer := lexer.Must(ebnf.New(`
-    Comment = ("%" | "//") { "\u0000"…"\uffff"-"\n" } .
-    Ident = (digit| alpha | "_") { Punct |  "_" | alpha | digit } .
-    String = "\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
-    Number = [ "-" | "+" ] ("." | digit) { "." | digit } .
-    Punct = "." | ";"  | "_" | ":" | "!" | "?" | "\\" | "/" | "=" | "[" | "]" | "'" | "$" | "<" | ">" | "-" | "+" | "~" | "@" | "*" | "\""  .
-    Parenthesis = "(" | ")"  | "," .
-    Whitespace = " " | "\t" | "\n" | "\r" .
-    alpha = "a"…"z" | "A"…"Z" .
-    digit = "0"…"9" .
-    any = "\u0000"…"\uffff" .
-    `))
-
-	var parser = participle.MustBuild(&ParseGraph{}, participle.UseLookahead(1), participle.Lexer(graphLexer),
-		participle.Elide("Comment", "Whitespace"))
-	var output Graph
-	var edges []Edge
-	pgraph := ParseGraph{}
-	err := parser.ParseString(s, &pgraph)
-	if err != nil {
-		fmt.Println("Couldn't parse input: ")
-		panic(err)
-	}
-	encoding := make(map[int]string)
-	encode = 1 // initialize to 1
-	pgraph.Encoding = make(map[string]int)
-
-	// first fix the encoding, starting with vertices
-	for _, e := range pgraph.Edges {
-		for _, n := range e.Vertices {
-			_, ok := pgraph.Encoding[n]
-			if !ok {
-				pgraph.Encoding[n] = encode
-				encoding[encode] = n
-				encode++
-			}
-		}
-
-	}
-	for _, e := range pgraph.Edges {
-		_, ok := pgraph.Encoding[e.Name]
-		if ok {
-			log.Panicln("Edge names not unique, not a valid hypergraph!")
-		}
-
-		pgraph.Encoding[e.Name] = encode
-		encoding[encode] = e.Name
-		encode++
-	}
-
-	// now create the edges
-	for _, e := range pgraph.Edges {
-		var outputEdges []int
-		for _, n := range e.Vertices {
-			i, ok := pgraph.Encoding[n]
-			if !ok {
-				log.Panicln("Vertex not properly encoded, something went wrong with the parsing process!")
-			}
-			outputEdges = append(outputEdges, i)
-
-		}
-		edges = append(edges, Edge{Name: pgraph.Encoding[e.Name], Vertices: outputEdges})
-	}
-
-	encode = encode + len(pgraph.Edges)
-
-	output.Edges = NewEdges(edges)
-	m = encoding
-	return output, pgraph
+// TransparentEncoding will overwrite the encoding of the graph in order to print out the exact underlying integer
+// encoding. This affects all graphs sharing the encoding, such as the components of the graph.
+func TransparentEncoding(g Graph) {
+	g.Encoding().Transparent()
 }
 
 // GetEdge can be used parse additional hyperedges. Useful for testing purposes
@@ -132,99 +56,123 @@ func (p *ParseGraph) GetEdge(input string) Edge {
 		participle.Elide("Comment", "Whitespace"))
 	pEdge := parseEdge{}
 	parser.ParseString(input, &pEdge)
+	encoding := p.encoding
+	if encoding == nil {
+		encoding = NewEncoding()
+	}
 	var vertices []int
 	for _, v := range pEdge.Vertices {
 		val, ok := p.Encoding[v]
 		if ok {
 			vertices = append(vertices, val)
 		} else {
-			p.Encoding[v] = encode
-			m[encode] = v
-			vertices = append(vertices, encode)
-			encode++
+			p.Encoding[v] = encoding.Add(v)
+			vertices = append(vertices, p.Encoding[v])
 		}
 	}
-	m[encode] = pEdge.Name
-	encode++
-	return Edge{Vertices: vertices, Name: encode - 1}
+	return Edge{Vertices: vertices, Name: encoding.Add(pEdge.Name)}
 }
 
 // Implement PACE 2019 format
 
-type parseEdgePACE struct {
-	Name     int   ` @Number`
-	Vertices []int ` ( @Number   )* "\n" `
-}
-
-type parseGraphPACEInfo struct {
-	Vertices int `"p htd" @(Number) `
-	Edges    int `@(Number) "\n"`
-}
-
-type parseGraphPACE struct {
-	Info  parseGraphPACEInfo `@@`
-	Edges []parseEdgePACE    `(@@) *`
-	m     map[int]int
-}
-
 // GetGraphPACE parses a string in PACE 2019 format into a graph
 func GetGraphPACE(s string) Graph {
+	return GetGraphPACEReader(strings.NewReader(s))
+}
 
-	graphLexer := lexer.Must(ebnf.New(`
-    Comment = ("c" | "//") { "\u0000"…"\uffff"-"\n" } Newline.
-    Begin = "p htd" .
-    Number = ("." | digit | "_"){"." | digit | stuff } .
-    Whitespace = " " | "\t" | "\r" .
-    stuff = ":" | "@" | ";" | "-" | "_" .
-    Punct = "!"…"/"  .
-    Newline = "\n" .
-
-    digit = "0"…"9" .`))
-
-	var parser = participle.MustBuild(&parseGraphPACE{}, participle.UseLookahead(1), participle.Lexer(graphLexer),
-		participle.Elide("Comment", "Whitespace"))
+// GetGraphPACEReader parses a hypergraph in PACE 2019 format, read from reader. The input is read line by line, so
+// that large instances never need to be held in memory as a whole. Edges are encoded before any vertex, in the order
+// they appear. Vertices up to the number given in the "p htd" line which occur in no edge are added as isolated
+// vertices.
+func GetGraphPACEReader(reader io.Reader) Graph {
 	var output Graph
 	var edges []Edge
-	pgraph := parseGraphPACE{}
-	err := parser.ParseString(s, &pgraph)
-	if err != nil {
-		fmt.Println("Couldn't parse input: ")
-		panic(err)
-	}
-	encode = 1 // initialize to 1
 
-	encoding := make(map[int]string)
-	pgraph.m = make(map[int]int)
+	encoding := NewEncoding()
+	edgeIDs := make(map[int]int)
+	vertexIDs := make(map[int]int)
 
-	for _, e := range pgraph.Edges {
-		encoding[encode] = "E" + strconv.Itoa(e.Name)
-		pgraph.m[e.Name] = encode
-		encode++
-	}
+	numEdges := -1 // not known until the "p htd" line was read
+	numVertices := 0
+
+	buffered := bufio.NewReader(reader)
+	lineNum := 0
+	for {
+		line, err := buffered.ReadString('\n')
+		if err != nil && err != io.EOF {
+			fmt.Println("Couldn't parse input: ")
+			panic(err)
+		}
+		if err == io.EOF && line == "" {
+			break
+		}
+		lineNum++
+
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0 || fields[0] == "c" || strings.HasPrefix(fields[0], "//"):
+			// skip empty lines and comments
+		case fields[0] == "p":
+			if len(fields) != 4 || fields[1] != "htd" || numEdges != -1 {
+				log.Panicln("PACE input malformed at line", lineNum, ": expected single \"p htd <vertices> <edges>\"")
+			}
+			numVertices = atoiPACE(fields[2], lineNum)
+			numEdges = atoiPACE(fields[3], lineNum)
+		default:
+			if numEdges == -1 {
+				log.Panicln("PACE input malformed at line", lineNum, ": edge before \"p htd\" line")
+			}
+			if len(edges) == numEdges {
+				log.Panicln("PACE input malformed at line", lineNum, ": more than", numEdges, "edges")
+			}
 
-	for _, e := range pgraph.Edges {
-		var outputEdges []int
-		for _, n := range e.Vertices {
-			i, ok := pgraph.m[n+pgraph.Info.Edges]
-			if ok {
-				outputEdges = append(outputEdges, i)
-			} else {
-				pgraph.m[n+pgraph.Info.Edges] = encode
-				encoding[encode] = "V" + strconv.Itoa(n)
-				outputEdges = append(outputEdges, encode)
-				encode++
+			name := atoiPACE(fields[0], lineNum)
+			edgeID := len(edges) + 1 // edges get the encodings 1 to numEdges
+			edgeIDs[name] = edgeID
+			encoding.Set(edgeID, "E"+strconv.Itoa(name))
+
+			var vertices []int
+			for _, f := range fields[1:] {
+				v := atoiPACE(f, lineNum)
+				id, ok := vertexIDs[v]
+				if !ok {
+					id = numEdges + len(vertexIDs) + 1
+					vertexIDs[v] = id
+					encoding.Set(id, "V"+strconv.Itoa(v))
+				}
+				vertices = append(vertices, id)
 			}
+			edges = append(edges, Edge{Name: edgeID, Vertices: vertices})
+		}
+
+		if err == io.EOF {
+			break
 		}
-		edges = append(edges, Edge{Name: pgraph.m[e.Name], Vertices: outputEdges})
 	}
 
-	m = encoding
+	for v := 1; v <= numVertices; v++ {
+		if _, ok := vertexIDs[v]; !ok {
+			id := numEdges + len(vertexIDs) + 1
+			vertexIDs[v] = id
+			encoding.Set(id, "V"+strconv.Itoa(v))
+			output.Isolated = append(output.Isolated, id)
+		}
+	}
 
 	output.Edges = NewEdges(edges)
+	output.encoding = encoding
 
 	return output
 }
 
+func atoiPACE(s string, lineNum int) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		log.Panicln("PACE input malformed at line", lineNum, ":", err)
+	}
+	return i
+}
+
 func extractEdge(edges []Edge, edge int) Edge {
 	for i := range edges {
 		if edges[i].Name == edge {
@@ -255,37 +203,70 @@ func (n Node) attachChild(target int, child Node) Node {
 	return Node{}
 }
 
+// DecompJson is the JSON representation of a decomp, as written by the json flag and Decomp.MarshalJSON. Vertices
+// and edges are referred to by their names in the input graph. An example, with all optional keys present:
+//
+//	{"Root": {"Bag": ["a", "b"], "Cover": ["e1"], "Weights": {"e1": 1}, "Children": [
+//	    {"Bag": ["b", "c"], "Cover": ["e2"], "Conn": ["b"], "Weights": {"e2": 1}, "Children": null}]}}
+//
+// The keys are kept stable, new information is only ever added as further optional keys.
 type DecompJson struct {
 	Root NodeJson
 }
 
+// NodeJson is the JSON representation of a node, see DecompJson
 type NodeJson struct {
-	Bag      []string
-	Cover    []string
+	Bag      []string           // names of the vertices in the bag
+	Cover    []string           // names of the edges in the cover
+	Conn     []string           `json:",omitempty"` // vertices shared with the parent, present once connectors are set
+	Weights  map[string]float64 `json:",omitempty"` // weights of the cover, only for fractional decompositions
 	Children []NodeJson
 }
 
+// MarshalJSON encodes the decomp in the format described by DecompJson
+func (d Decomp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.IntoJson())
+}
+
+// IntoJson converts the decomp into its JSON representation, using the names of its graph
 func (d Decomp) IntoJson() DecompJson {
 	var output DecompJson
 
-	output.Root = d.Root.IntoJson()
+	output.Root = d.Root.intoJson(d.Graph.Encoding())
 
 	return output
 }
 
+// IntoJson converts the node into its JSON representation, printing the integers of its vertices and edges, as a node
+// doesn't know its graph. Use Decomp.IntoJson for the names.
 func (n Node) IntoJson() NodeJson {
+	return n.intoJson(NewEncoding())
+}
+
+func (n Node) intoJson(enc *Encoding) NodeJson {
 	var output NodeJson
 
 	for _, i := range n.Bag {
-		output.Bag = append(output.Bag, m[i])
+		output.Bag = append(output.Bag, enc.Name(i))
 	}
 
 	for i := range n.Cover.Slice() {
-		output.Cover = append(output.Cover, m[n.Cover.Slice()[i].Name])
+		output.Cover = append(output.Cover, n.Cover.Slice()[i].stringEnc(enc)) // subedges are given by their vertices
+	}
+
+	for _, i := range n.Conn {
+		output.Conn = append(output.Conn, enc.Name(i))
+	}
+
+	if n.Weights != nil {
+		output.Weights = make(map[string]float64, len(n.Weights))
+		for e, w := range n.Weights {
+			output.Weights[enc.Name(e)] = w
+		}
 	}
 
 	for i := range n.Children {
-		output.Children = append(output.Children, n.Children[i].IntoJson())
+		output.Children = append(output.Children, n.Children[i].intoJson(enc))
 	}
 
 	return output
@@ -315,6 +296,17 @@ func (n NodeJson) IntoNode(graph Graph, encoding map[string]int) Node {
 	}
 	output.Cover = NewEdges(cover)
 
+	for i := range n.Conn {
+		output.Conn = append(output.Conn, encoding[n.Conn[i]])
+	}
+
+	if n.Weights != nil {
+		output.Weights = make(map[int]float64, len(n.Weights))
+		for e, w := range n.Weights {
+			output.Weights[encoding[e]] = w
+		}
+	}
+
 	for i := range n.Children {
 		output.Children = append(output.Children, n.Children[i].IntoNode(graph, encoding))
 	}
@@ -335,8 +327,9 @@ func GetDecomp(input []byte, graph Graph, encoding map[string]int) Decomp {
 	return jason.IntoDecomp(graph, encoding)
 }
 
+// WriteDecomp encodes the decomp as JSON, see DecompJson
 func WriteDecomp(input Decomp) []byte {
-	out, err := json.Marshal(input.IntoJson())
+	out, err := json.Marshal(input)
 
 	if err != nil {
 		fmt.Println("error:", err)
@@ -352,21 +345,21 @@ type arc struct {
 }
 
 type parseGMLValue struct {
-	FlatVal string       ` @(Ident | Number) | "\"" @(Number | Ident | Punct)* "\""    `
-	List    parseGMLList `| "[" @@ "]"`
+	FlatVal string       `parser:" @(Ident | Number) | \"\\\"\" @(Number | Ident | Punct)* \"\\\"\"    "`
+	List    parseGMLList `parser:"| \"[\" @@ \"]\""`
 }
 
 type parseGMLListEntry struct {
-	Key   string        ` @(Ident|Number) `
-	Value parseGMLValue ` @@ `
+	Key   string        `parser:" @(Ident|Number) "`
+	Value parseGMLValue `parser:" @@ "`
 }
 
 type parseGMLList struct {
-	Entries []parseGMLListEntry `( @@ )*`
+	Entries []parseGMLListEntry `parser:"( @@ )*"`
 }
 
 type parseGML struct {
-	GML parseGMLList `@@`
+	GML parseGMLList `parser:"@@"`
 }
 
 // GetDecompGML can parse an input string in GML format to produce a decomp
@@ -423,10 +416,10 @@ func GetDecompGML(input string, graph Graph, encoding map[string]int) Decomp {
 
 			// check for necessary fields, id and label
 			if _, ok := nodeLabels["id"]; !ok {
-				log.Println("Node without id present in GML file.")
+				LogParser.Printf(LogWarn, "Node without id present in GML file.")
 			}
 			if _, ok := nodeLabels["label"]; !ok {
-				log.Println("Node without label present in GML file.")
+				LogParser.Printf(LogWarn, "Node without label present in GML file.")
 			}
 
 			// extract edge cover and bag from label
@@ -456,7 +449,7 @@ func GetDecompGML(input string, graph Graph, encoding map[string]int) Decomp {
 
 			node.num, _ = strconv.Atoi(nodeLabels["id"])
 
-			encode = max(node.num, encode) + 1 // ensure encode will never collide with num values in parsed GMl
+			reserveNodeNums(node.num) // ensure fresh node numbers will never collide with num values in parsed GML
 
 			node.Bag = bag
 			node.Cover = NewEdges(cover)
@@ -478,10 +471,10 @@ func GetDecompGML(input string, graph Graph, encoding map[string]int) Decomp {
 
 			// check for necessary fields, id and label
 			if _, ok := arcLabels["source"]; !ok {
-				log.Println("Edge without source present in GML file.")
+				LogParser.Printf(LogWarn, "Edge without source present in GML file.")
 			}
 			if _, ok := arcLabels["target"]; !ok {
-				log.Println("Edge without target present in GML file.")
+				LogParser.Printf(LogWarn, "Edge without target present in GML file.")
 			}
 
 			Arc.Source, _ = strconv.Atoi(arcLabels["source"])
@@ -521,3 +514,107 @@ func GetDecompGML(input string, graph Graph, encoding map[string]int) Decomp {
 
 	return Decomp{Graph: graph, Root: nodes[IDtoIndex[root]]}
 }
+
+// GetDecompPACE parses a decomposition in the PACE 2019 htd output format (as produced by e.g. HtdLEO or
+// newdetkdecomp), consisting of a solution line "s htd ...", bag lines "b id v1 v2 ...", cover lines
+// "w id edge weight" and tree edges "id1 id2". Vertices and edges refer to the numbering of a graph parsed via
+// GetGraphPACE.
+func GetDecompPACE(input string, graph Graph, encoding map[string]int) Decomp {
+	nodes := make(map[int]*Node)
+	covers := make(map[int][]Edge)
+	adjacency := make(map[int][]int)
+	var order []int
+	maxID := 0
+
+	getNode := func(id int) *Node {
+		n, ok := nodes[id]
+		if !ok {
+			n = &Node{num: id}
+			nodes[id] = n
+			order = append(order, id)
+			maxID = max(maxID, id)
+		}
+		return n
+	}
+
+	atoi := func(s string) int {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			log.Panicln("decomp couldn't be parsed, not a number:", s)
+		}
+		return i
+	}
+
+	for _, line := range strings.Split(input, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "c", "s":
+			continue
+		case "b":
+			if len(fields) < 2 {
+				log.Panicln("decomp couldn't be parsed, malformed bag line:", line)
+			}
+			n := getNode(atoi(fields[1]))
+			for _, v := range fields[2:] {
+				n.Bag = append(n.Bag, encoding["V"+v])
+			}
+		case "w":
+			if len(fields) < 4 {
+				log.Panicln("decomp couldn't be parsed, malformed weight line:", line)
+			}
+			if fields[3] == "0" {
+				continue
+			}
+			id := atoi(fields[1])
+			getNode(id)
+			out := extractEdge(graph.Edges.Slice(), encoding["E"+fields[2]])
+			if reflect.DeepEqual(out, Edge{}) {
+				log.Panicln("Can't find edge ", fields[2])
+			}
+			covers[id] = append(covers[id], out)
+		default:
+			if len(fields) != 2 {
+				log.Panicln("decomp couldn't be parsed, malformed line:", line)
+			}
+			a, b := atoi(fields[0]), atoi(fields[1])
+			getNode(a)
+			getNode(b)
+			adjacency[a] = append(adjacency[a], b)
+			adjacency[b] = append(adjacency[b], a)
+		}
+	}
+
+	if len(order) == 0 {
+		return Decomp{}
+	}
+
+	for id, cover := range covers {
+		nodes[id].Cover = NewEdges(cover)
+	}
+
+	// build up tree from first bag as root, ignoring any repeated visits
+	visited := make(map[int]bool)
+	var build func(id int) Node
+	build = func(id int) Node {
+		visited[id] = true
+		n := *nodes[id]
+		for _, c := range adjacency[id] {
+			if !visited[c] {
+				n.Children = append(n.Children, build(c))
+			}
+		}
+		return n
+	}
+
+	root := build(order[0])
+	if len(visited) != len(order) {
+		log.Panicln("decomp couldn't be parsed, tree is not connected")
+	}
+
+	reserveNodeNums(maxID) // ensure fresh node numbers never collide with num values of parsed nodes
+
+	return Decomp{Graph: graph, Root: root}
+}
@@ -6,7 +6,9 @@ package lib
 
 import (
 	"fmt"
-	"math/big"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // A GYÖReduct (that's short for GYÖ (Graham - Yu - Özsoyoğlu) Reduction )
@@ -25,8 +27,6 @@ type edgeOp struct {
 func (edgeOp) isGYÖ() {}
 
 func (e edgeOp) String() string {
-	mutex.RLock()
-	defer mutex.RUnlock()
 	return fmt.Sprintf("(%v ⊆ %v)", e.subedge, e.parent)
 }
 
@@ -38,9 +38,7 @@ type vertOp struct {
 func (vertOp) isGYÖ() {}
 
 func (v vertOp) String() string {
-	mutex.RLock()
-	defer mutex.RUnlock()
-	return fmt.Sprintf("(%v ∈ %v)", m[v.vertex], v.edge)
+	return fmt.Sprintf("(%v ∈ %v)", v.vertex, v.edge)
 }
 
 // Performs one part of GYÖ reduct
@@ -62,7 +60,7 @@ OUTER:
 		output = append(output, e1)
 	}
 
-	return Graph{Edges: NewEdges(output)}, ops
+	return Graph{Edges: NewEdges(output), encoding: g.encoding}, ops
 }
 
 func (g Graph) removeVertices() (Graph, []GYÖReduct) {
@@ -92,10 +90,12 @@ func (g Graph) removeVertices() (Graph, []GYÖReduct) {
 
 	}
 
-	return Graph{Edges: NewEdges(edges)}, ops
+	return Graph{Edges: NewEdges(edges), encoding: g.encoding}, ops
 }
 
-// GYÖReduct performs the GYÖ reduction on the graph
+// GYÖReduct performs the GYÖ reduction on the graph. An ear, i.e. an edge whose vertices are either only found in
+// this edge or also contained in some other edge, is removed in two steps: first its isolated vertices, then the
+// remaining edge as it is now contained in the other one. An acyclic graph is thus reduced to the empty graph.
 func (g Graph) GYÖReduct() (Graph, []GYÖReduct) {
 	var ops []GYÖReduct
 
@@ -234,25 +234,58 @@ func (n Node) RestoreGYÖ(reducts []GYÖReduct) (Node, bool) {
 	return output, true
 }
 
+// DecompGYÖ computes a decomposition of the graph by decomposing its GYÖ reduct with the given algorithm, and then
+// restoring the reductions. The graph is assumed to have no special edges.
+func (g Graph) DecompGYÖ(alg AlgorithmH) Decomp {
+	reduced, ops := g.GYÖReduct()
+
+	var decomp Decomp
+	if reduced.Edges.Len() > 0 {
+		decomp = alg.FindDecompGraph(reduced)
+		if !decomp.Found() {
+			return Decomp{}
+		}
+	}
+
+	var result bool
+	decomp.Root, result = decomp.Root.RestoreGYÖ(ops)
+	if !result {
+		return Decomp{}
+	}
+	decomp.Graph = g
+
+	return decomp
+}
+
 /*
 Type Collapse
 */
 
-func (g Graph) getType(vertex int) *big.Int {
-	output := new(big.Int)
+// types returns the type of each vertex, i.e. the indices of the edges containing it, as a string usable as key
+func (g Graph) types() map[int]string {
+	edges := make(map[int][]int)
+	for i, e := range g.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if l := edges[v]; len(l) == 0 || l[len(l)-1] != i { // a vertex may be listed twice in an edge
+				edges[v] = append(l, i)
+			}
+		}
+	}
 
-	for i := range g.Edges.Slice() {
-		if mem(g.Edges.Slice()[i].Vertices, vertex) {
-			output.SetBit(output, i, 1)
+	output := make(map[int]string, len(edges))
+	for v, l := range edges {
+		var buffer strings.Builder
+		for _, i := range l {
+			buffer.WriteString(strconv.Itoa(i))
+			buffer.WriteByte(',')
 		}
+		output[v] = buffer.String()
 	}
 	return output
 }
 
 // TypeCollapse performs type collapse on the graph, the mapping that's also output can be used
 // to restore the original hypergraph.
-//
-// Possible optimization: When computing the distances, use the matrix to speed up type detection
 func (g Graph) TypeCollapse() (Graph, map[int][]int, int) {
 	count := 0
 
@@ -261,9 +294,10 @@ func (g Graph) TypeCollapse() (Graph, map[int][]int, int) {
 
 	// identify vertices to replace
 	encountered := make(map[string]int)
+	types := g.types()
 
 	for _, v := range g.Vertices() {
-		typeString := g.getType(v).String()
+		typeString := types[v]
 
 		if _, ok := encountered[typeString]; ok {
 			// already seen this type before
@@ -287,7 +321,61 @@ func (g Graph) TypeCollapse() (Graph, map[int][]int, int) {
 		newEdges = append(newEdges, Edge{Name: e.Name, Vertices: RemoveDuplicates(vertices)})
 	}
 
-	return Graph{Edges: NewEdges(newEdges)}, restorationMap, count
+	return Graph{Edges: NewEdges(newEdges), encoding: g.encoding}, restorationMap, count
+}
+
+// A TwinReduct records the twins removed from a graph by RemoveTwins: for each remaining vertex, the vertices
+// contained in exactly the same edges, and for each remaining edge, the edges with exactly the same vertices
+type TwinReduct struct {
+	Vertices map[int][]int
+	Edges    map[int][]int
+}
+
+// Count returns the number of vertices and of edges removed
+func (r TwinReduct) Count() (int, int) {
+	vertices, edges := 0, 0
+	for _, l := range r.Vertices {
+		vertices += len(l)
+	}
+	for _, l := range r.Edges {
+		edges += len(l)
+	}
+	return vertices, edges
+}
+
+// RemoveTwins removes duplicate edges, keeping the first of each set of edges with the same vertices, and then
+// collapses twin vertices, contained in the same edges, into one. Neither changes the width of the graph, but on
+// structured instances, such as CSPs with many constraints over the same scope, the graph may shrink a lot. The
+// graph is assumed to have no special edges. Decompositions of the result are turned into decompositions of g by
+// RestoreTwins.
+func (g Graph) RemoveTwins() (Graph, TwinReduct) {
+	output := TwinReduct{Edges: make(map[int][]int)}
+
+	kept := make(map[string]int) // the edge kept for each vertex set
+	var edges []Edge
+	for _, e := range g.Edges.Slice() {
+		vertices := RemoveDuplicates(append([]int{}, e.Vertices...))
+		sort.Ints(vertices)
+		key := fmt.Sprint(vertices)
+
+		if name, ok := kept[key]; ok {
+			output.Edges[name] = append(output.Edges[name], e.Name)
+			continue
+		}
+		kept[key] = e.Name
+		edges = append(edges, e)
+	}
+
+	reduced, vertices, _ := g.WithEdges(NewEdges(edges)).TypeCollapse()
+	output.Vertices = vertices
+
+	return reduced, output
+}
+
+// RestoreTwins turns a decomposition of the graph returned by RemoveTwins into one of the original graph. Only the
+// collapsed vertices need to be added back, as any bag containing an edge kept also contains its duplicates.
+func (n Node) RestoreTwins(r TwinReduct) (Node, bool) {
+	return n.RestoreTypes(r.Vertices)
 }
 
 func (e Edges) addVertex(target int, oldVertices []int) Edges {
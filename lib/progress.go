@@ -0,0 +1,124 @@
+package lib
+
+// progress.go counts the work done by the searches and recursive calls of all algorithms, so that long runs can
+// report how far they got
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// flushInterval is the number of candidates a search checks before adding them to the counts
+const flushInterval = 1024
+
+// SearchStats counts the separator candidates checked per generator, the recursive calls currently running per depth,
+// and the lookups in caches. It is safe for concurrent use.
+type SearchStats struct {
+	mux      sync.Mutex
+	consumed []int64     // candidates checked, by the position of their generator within the search
+	depths   map[int]int // recursive calls currently running, by their depth
+	lookups  int64       // accessed atomically, as are hits
+	hits     int64
+}
+
+// Stats is shared by all algorithms
+var Stats = &SearchStats{}
+
+// Consume adds n candidates checked by the generator at the given position of a search
+func (s *SearchStats) Consume(generator int, n int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for len(s.consumed) <= generator {
+		s.consumed = append(s.consumed, 0)
+	}
+	s.consumed[generator] += int64(n)
+}
+
+// Enter records the start of a recursive call at the given depth, which must be ended by Leave
+func (s *SearchStats) Enter(depth int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.depths == nil {
+		s.depths = make(map[int]int)
+	}
+	s.depths[depth]++
+}
+
+// Leave records the end of a recursive call at the given depth
+func (s *SearchStats) Leave(depth int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.depths[depth]--
+	if s.depths[depth] == 0 {
+		delete(s.depths, depth)
+	}
+}
+
+// CacheLookup records a lookup in a cache, and whether it was a hit
+func (s *SearchStats) CacheLookup(hit bool) {
+	atomic.AddInt64(&s.lookups, 1)
+	if hit {
+		atomic.AddInt64(&s.hits, 1)
+	}
+}
+
+// CacheLookups returns the number of lookups in caches so far, and how many of them were hits
+func (s *SearchStats) CacheLookups() (lookups, hits int64) {
+	return atomic.LoadInt64(&s.lookups), atomic.LoadInt64(&s.hits)
+}
+
+// Consumed returns the number of candidates checked so far, by the position of their generator within the search
+func (s *SearchStats) Consumed() []int64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return append([]int64{}, s.consumed...)
+}
+
+// Reset sets all counts back to zero, except for the recursive calls currently running
+func (s *SearchStats) Reset() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.consumed = nil
+	atomic.StoreInt64(&s.lookups, 0)
+	atomic.StoreInt64(&s.hits, 0)
+}
+
+func (s *SearchStats) String() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var buffer bytes.Buffer
+
+	var total int64
+	for _, n := range s.consumed {
+		total += n
+	}
+	buffer.WriteString(fmt.Sprintf("Separators checked: %d, by generator: %v\n", total, s.consumed))
+
+	var depths []int
+	for d := range s.depths {
+		depths = append(depths, d)
+	}
+	sort.Ints(depths)
+	buffer.WriteString("Recursive calls running by depth:")
+	for _, d := range depths {
+		buffer.WriteString(fmt.Sprintf(" %d:%d", d, s.depths[d]))
+	}
+	buffer.WriteString("\n")
+
+	lookups, hits := atomic.LoadInt64(&s.lookups), atomic.LoadInt64(&s.hits)
+	if lookups > 0 {
+		buffer.WriteString(fmt.Sprintf("Cache: %d of %d lookups hit (%.1f%%)\n", hits, lookups,
+			100*float64(hits)/float64(lookups)))
+	}
+
+	return buffer.String()
+}
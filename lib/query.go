@@ -0,0 +1,450 @@
+package lib
+
+// query.go builds the hypergraph of a conjunctive query, given either in a Datalog-like syntax or as SQL join query,
+// and maps the bags of a decomposition back to the atoms of the query
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// An Atom of a conjunctive query, together with the name of the edge it becomes in the hypergraph
+type Atom struct {
+	Edge     string
+	Relation string
+	Args     []string // the variables of the atom, i.e. the vertices of its edge
+}
+
+func (a Atom) String() string {
+	return a.Relation + "(" + strings.Join(a.Args, ", ") + ")"
+}
+
+// A Query is a conjunctive query, each of whose atoms becomes an edge of its hypergraph. Atoms without any variables
+// don't restrict the decompositions and are left out.
+type Query struct {
+	Atoms []Atom
+}
+
+// newQuery names the edges of the atoms after their relations, unless they are named already, numbering repeated
+// names as in r, r_2, r_3, so that they are unique and don't clash with any variable
+func newQuery(atoms []Atom) Query {
+	used := make(map[string]bool)
+	for _, a := range atoms {
+		for _, v := range a.Args {
+			used[v] = true
+		}
+	}
+
+	var output Query
+	for _, a := range atoms {
+		if len(a.Args) == 0 {
+			continue
+		}
+		base := a.Edge
+		if base == "" {
+			base = a.Relation
+		}
+		a.Edge = base
+		for i := 2; used[a.Edge]; i++ {
+			a.Edge = fmt.Sprint(base, "_", i)
+		}
+		used[a.Edge] = true
+		output.Atoms = append(output.Atoms, a)
+	}
+
+	return output
+}
+
+// Graph returns the hypergraph of the query. The returned ParseGraph refers back to the query.
+func (q Query) Graph() (Graph, ParseGraph) {
+	if len(q.Atoms) == 0 {
+		log.Panicln("Query has no atoms with variables, no hypergraph to decompose!")
+	}
+
+	pgraph := ParseGraph{Query: &q}
+	for _, a := range q.Atoms {
+		pgraph.Edges = append(pgraph.Edges, parseEdge{Name: a.Edge, Vertices: a.Args})
+	}
+
+	graph := pgraph.build()
+	return graph, pgraph
+}
+
+// GetGraphDatalog parses a conjunctive query in Datalog-like syntax into its hypergraph, see ParseDatalog
+func GetGraphDatalog(s string) (Graph, ParseGraph) {
+	q, err := ParseDatalog(s)
+	if err != nil {
+		log.Panicln("Couldn't parse query:", err)
+	}
+	return q.Graph()
+}
+
+// GetGraphSQL parses a SQL join query into its hypergraph, see ParseSQL
+func GetGraphSQL(s string) (Graph, ParseGraph) {
+	q, err := ParseSQL(s)
+	if err != nil {
+		log.Panicln("Couldn't parse query:", err)
+	}
+	return q.Graph()
+}
+
+// BagAtoms describes the decomp in terms of the query, listing the atoms covering each bag, with children indented
+// below their parents
+func (q Query) BagAtoms(d Decomp) string {
+	atoms := make(map[string]Atom)
+	for _, a := range q.Atoms {
+		atoms[a.Edge] = a
+	}
+
+	d.RestoreSubedges()
+	enc := d.Graph.Encoding()
+
+	var buffer bytes.Buffer
+	var write func(n Node, depth int)
+	write = func(n Node, depth int) {
+		var cover []string
+		for _, e := range n.Cover.Slice() {
+			name := enc.Name(e.Name)
+			if a, ok := atoms[name]; ok {
+				cover = append(cover, a.String())
+			} else {
+				cover = append(cover, name)
+			}
+		}
+		buffer.WriteString(indent(depth) + "{" + n.printBag(enc) + "}: " + strings.Join(cover, ", ") + "\n")
+
+		for i := range n.Children {
+			write(n.Children[i], depth+1)
+		}
+	}
+	write(d.Root, 0)
+
+	return buffer.String()
+}
+
+// datalogAtom matches an atom such as r(X, Y, 'c')
+var datalogAtom = regexp.MustCompile(`^\s*([A-Za-z_][\w.]*)\s*\(([^()]*)\)\s*$`)
+
+// ParseDatalog parses a conjunctive query in a Datalog-like syntax, such as q(X) :- r(X,Y), s(Y,Z). The head is
+// optional. Arguments starting with an uppercase letter or an underscore are variables, others are constants, and
+// each anonymous variable _ is distinct from all others.
+func ParseDatalog(s string) (Query, error) {
+	body := strings.TrimSuffix(strings.TrimSpace(s), ".")
+	if i := strings.Index(body, ":-"); i >= 0 {
+		body = body[i+2:]
+	}
+
+	var atoms []Atom
+	anonymous := 0
+	for _, part := range splitTopLevel(body) {
+		match := datalogAtom.FindStringSubmatch(part)
+		if match == nil {
+			return Query{}, fmt.Errorf("malformed atom %q", strings.TrimSpace(part))
+		}
+
+		atom := Atom{Relation: match[1]}
+		for _, arg := range splitTopLevel(match[2]) {
+			arg = strings.TrimSpace(arg)
+			switch {
+			case arg == "":
+				return Query{}, fmt.Errorf("empty argument in atom %q", strings.TrimSpace(part))
+			case arg == "_":
+				anonymous++
+				atom.Args = append(atom.Args, fmt.Sprint("_", anonymous))
+			case arg != "" && (arg[0] == '_' || unicode.IsUpper(rune(arg[0]))):
+				atom.Args = append(atom.Args, arg)
+			}
+		}
+		atoms = append(atoms, atom)
+	}
+
+	return newQuery(atoms), nil
+}
+
+// splitTopLevel splits s at all commas outside of parentheses and quotes
+func splitTopLevel(s string) []string {
+	var output []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			output = append(output, s[start:i])
+			start = i + 1
+		}
+	}
+	if strings.TrimSpace(s[start:]) != "" || len(output) > 0 {
+		output = append(output, s[start:])
+	}
+	return output
+}
+
+// sqlToken matches the tokens of the SQL subset understood by ParseSQL
+var sqlToken = regexp.MustCompile(`\s*('(?:[^']|'')*'|"[^"]*"|[A-Za-z_][\w$]*|\d+(?:\.\d+)?|<>|<=|>=|!=|\S)`)
+
+// sqlKeywords are the words that can appear among the columns of a select list or condition without being one
+var sqlKeywords = map[string]bool{"and": true, "or": true, "not": true, "is": true, "null": true, "true": true,
+	"false": true, "as": true, "distinct": true, "like": true, "between": true, "in": true}
+
+// ParseSQL parses a join query in a restricted subset of SQL, of the form
+//
+//	SELECT ... FROM r AS a, s b JOIN t ON b.y = t.y WHERE a.x = b.x AND a.z > 3
+//
+// Each table in the FROM clause becomes an atom, named after its alias if it has one. Columns are qualified by a table
+// or alias, unless only one table is given. Columns are identified by the equalities between them, each class of
+// columns forming a variable named after its first column in alphabetical order. Only conjunctions are supported in
+// conditions, and all columns mentioned anywhere in the query are variables of their atoms.
+func ParseSQL(s string) (Query, error) {
+	var tokens []string
+	for _, match := range sqlToken.FindAllStringSubmatch(strings.TrimSpace(s), -1) {
+		tokens = append(tokens, match[1])
+	}
+	if len(tokens) > 0 && tokens[len(tokens)-1] == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	keyword := func(i int, words ...string) bool {
+		if i >= len(tokens) {
+			return false
+		}
+		for _, w := range words {
+			if strings.EqualFold(tokens[i], w) {
+				return true
+			}
+		}
+		return false
+	}
+	isName := func(i int) bool {
+		return i < len(tokens) && (unicode.IsLetter(rune(tokens[i][0])) || tokens[i][0] == '_' ||
+			tokens[i][0] == '"') && !keyword(i, "select", "from", "where", "join", "inner", "cross", "on", "and",
+			"as", "group", "order", "limit", "having")
+	}
+	unquote := func(name string) string {
+		return strings.Trim(name, `"`)
+	}
+
+	if !keyword(0, "select") {
+		return Query{}, fmt.Errorf("query must start with SELECT")
+	}
+	i := 1
+	for i < len(tokens) && !keyword(i, "from") {
+		i++
+	}
+	selectList := tokens[1:i]
+	if i == len(tokens) {
+		return Query{}, fmt.Errorf("query has no FROM clause")
+	}
+	i++
+
+	// the tables, and the tokens of all conditions
+	var tables, aliases []string
+	var conditions []string
+	for i < len(tokens) {
+		if !isName(i) {
+			return Query{}, fmt.Errorf("expected table name, got %q", tokens[i])
+		}
+		table := unquote(tokens[i])
+		alias := table
+		i++
+		if keyword(i, "as") {
+			i++
+			if !isName(i) {
+				return Query{}, fmt.Errorf("expected alias after AS")
+			}
+		}
+		if isName(i) {
+			alias = unquote(tokens[i])
+			i++
+		}
+		tables, aliases = append(tables, table), append(aliases, alias)
+
+		if keyword(i, "on") {
+			i++
+			for i < len(tokens) && tokens[i] != "," && !keyword(i, "join", "inner", "cross", "where", "group",
+				"order", "limit") {
+				conditions = append(conditions, tokens[i])
+				i++
+			}
+			conditions = append(conditions, "and")
+		}
+
+		switch {
+		case i == len(tokens):
+		case tokens[i] == ",":
+			i++
+		case keyword(i, "join"):
+			i++
+		case keyword(i, "inner", "cross") && keyword(i+1, "join"):
+			i += 2
+		case keyword(i, "where"):
+			i++
+			for i < len(tokens) && !keyword(i, "group", "order", "limit") {
+				conditions = append(conditions, tokens[i])
+				i++
+			}
+			i = len(tokens) // the rest doesn't affect the joins
+		case keyword(i, "group", "order", "limit"):
+			i = len(tokens) // the rest doesn't affect the joins
+		default:
+			return Query{}, fmt.Errorf("unexpected %q in FROM clause", tokens[i])
+		}
+	}
+	if len(tables) == 0 {
+		return Query{}, fmt.Errorf("query has no tables")
+	}
+
+	index := make(map[string]int)
+	for j, alias := range aliases {
+		if _, ok := index[strings.ToLower(alias)]; ok {
+			return Query{}, fmt.Errorf("table or alias %q used twice", alias)
+		}
+		index[strings.ToLower(alias)] = j
+	}
+
+	// column resolves the column at position j of toks, returning its qualified name and the length of its tokens
+	column := func(toks []string, j int) (string, int, error) {
+		if j+2 < len(toks) && toks[j+1] == "." && (unicode.IsLetter(rune(toks[j+2][0])) || toks[j+2][0] == '_' ||
+			toks[j+2][0] == '"') {
+			t, ok := index[strings.ToLower(unquote(toks[j]))]
+			if !ok {
+				return "", 0, fmt.Errorf("unknown table or alias %q", toks[j])
+			}
+			return aliases[t] + "." + unquote(toks[j+2]), 3, nil
+		}
+		if len(aliases) > 1 {
+			return "", 0, fmt.Errorf("column %q must be qualified by a table", toks[j])
+		}
+		return aliases[0] + "." + unquote(toks[j]), 1, nil
+	}
+	isColumn := func(toks []string, j int) bool {
+		c := toks[j]
+		return (unicode.IsLetter(rune(c[0])) || c[0] == '_' || c[0] == '"') && !sqlKeywords[strings.ToLower(c)] &&
+			!(j+1 < len(toks) && toks[j+1] == "(") // a function
+	}
+
+	// the classes of columns equal to each other, kept as union-find
+	parent := make(map[string]string)
+	var find func(c string) string
+	find = func(c string) string {
+		if parent[c] != c {
+			parent[c] = find(parent[c])
+		}
+		return parent[c]
+	}
+	columns := make([][]string, len(tables)) // of each table, in order of appearance
+	addColumn := func(c string) {
+		if _, ok := parent[c]; ok {
+			return
+		}
+		parent[c] = c
+		t := index[strings.ToLower(c[:strings.LastIndex(c, ".")])]
+		columns[t] = append(columns[t], c)
+	}
+
+	for j := 0; j < len(selectList); j++ {
+		if selectList[j] == "*" || !isColumn(selectList, j) || (j > 0 && strings.EqualFold(selectList[j-1], "as")) {
+			continue
+		}
+		if j+2 < len(selectList) && selectList[j+1] == "." && selectList[j+2] == "*" {
+			j += 2
+			continue
+		}
+		c, n, err := column(selectList, j)
+		if err != nil {
+			return Query{}, err
+		}
+		addColumn(c)
+		j += n - 1
+	}
+
+	// conditions are conjunctions of comparisons, only equalities between two columns join them
+	for _, cond := range splitConjunction(conditions) {
+		var found []string
+		consumed := 0 // tokens taken up by columns
+		for j := 0; j < len(cond); j++ {
+			if strings.EqualFold(cond[j], "or") {
+				return Query{}, fmt.Errorf("only conjunctions of conditions are supported")
+			}
+			if !isColumn(cond, j) {
+				continue
+			}
+			c, n, err := column(cond, j)
+			if err != nil {
+				return Query{}, err
+			}
+			addColumn(c)
+			found = append(found, c)
+			consumed += n
+			j += n - 1
+		}
+		if len(found) == 2 && consumed+1 == len(cond) && containsToken(cond, "=") {
+			parent[find(found[0])] = find(found[1])
+		}
+	}
+
+	// each class is named after its first column
+	names := make(map[string]string)
+	for c := range parent {
+		if r := find(c); names[r] == "" || c < names[r] {
+			names[r] = c
+		}
+	}
+
+	var atoms []Atom
+	for t := range tables {
+		atom := Atom{Edge: aliases[t], Relation: tables[t]}
+		seen := make(map[string]bool)
+		for _, c := range columns[t] {
+			if v := names[find(c)]; !seen[v] {
+				seen[v] = true
+				atom.Args = append(atom.Args, v)
+			}
+		}
+		atoms = append(atoms, atom)
+	}
+
+	return newQuery(atoms), nil
+}
+
+// splitConjunction splits the tokens of a condition at each AND, dropping parentheses around the parts
+func splitConjunction(tokens []string) [][]string {
+	var output [][]string
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && !strings.EqualFold(tokens[i], "and") {
+			continue
+		}
+		part := tokens[start:i]
+		for len(part) >= 2 && part[0] == "(" && part[len(part)-1] == ")" {
+			part = part[1 : len(part)-1]
+		}
+		if len(part) > 0 {
+			output = append(output, part)
+		}
+		start = i + 1
+	}
+	return output
+}
+
+func containsToken(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+package lib
+
+// recursion.go records the recursive calls of the algorithms as a tree, e.g. to see where the time of a search went
+// on a hard instance
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRecursionLimit is the number of calls a RecursionTrace records if no limit is set
+const DefaultRecursionLimit = 1000000
+
+// Outcomes of the calls in a RecursionTrace
+const (
+	RecursionOpen     = "open"     // still running when the trace was written
+	RecursionAccepted = "accepted" // returned a decomposition
+	RecursionRejected = "rejected" // returned none
+)
+
+// A RecursionTrace records each recursive call of an algorithm: the size of the subgraph it was made on, the
+// vertices it shares with the rest of the graph, the separators tried and the one chosen, how long it took and
+// whether it succeeded. Unlike a SearchTrace, calls on the same subgraph are recorded separately, so the result is
+// the tree of calls, and only counts of the separators tried are kept, which makes it small enough for large
+// instances. Recording stops after Limit calls.
+type RecursionTrace struct {
+	Limit     int             `json:"-"`
+	Calls     []RecursionCall `json:"calls"`
+	Truncated bool            `json:"truncated"` // true if calls were dropped because of the limit
+	start     time.Time
+	mux       sync.Mutex
+}
+
+// A RecursionCall is a single call of the recursion. Its ID is its index in the trace plus one, so that the parent
+// of the first call can be 0.
+type RecursionCall struct {
+	ID        int           `json:"id"`
+	Parent    int           `json:"parent"` // 0 for calls made on the whole graph
+	Edges     int           `json:"edges"`
+	Special   int           `json:"special"`
+	Vertices  int           `json:"vertices"`
+	Conn      []string      `json:"conn"`      // vertices shared with the separator of the parent
+	Tried     int           `json:"tried"`     // separators tried
+	Separator []string      `json:"separator"` // the cover of the root of the decomposition returned
+	Outcome   string        `json:"outcome"`
+	Start     time.Duration `json:"start"` // since the first call
+	Duration  time.Duration `json:"duration"`
+}
+
+// Enter records a call on H made by the call with the given id, 0 at the top level, with conn the vertices H shares
+// with the separator of its parent. It returns the id of the new call, to be passed on to Try, Leave and the calls
+// made by it, and -1 once the limit has been reached. All methods do nothing on a nil trace, so algorithms can call
+// them unconditionally.
+func (t *RecursionTrace) Enter(parent int, H Graph, conn []int) int {
+	if t == nil {
+		return 0
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	limit := t.Limit
+	if limit <= 0 {
+		limit = DefaultRecursionLimit
+	}
+	if parent < 0 || len(t.Calls) >= limit {
+		t.Truncated = t.Truncated || parent >= 0
+		return -1
+	}
+	if len(t.Calls) == 0 {
+		t.start = time.Now()
+	}
+
+	call := RecursionCall{
+		ID:       len(t.Calls) + 1,
+		Parent:   parent,
+		Edges:    H.Edges.Len(),
+		Special:  H.NumSpecial(),
+		Vertices: len(H.Vertices()),
+		Outcome:  RecursionOpen,
+		Start:    time.Since(t.start),
+	}
+	for _, v := range conn {
+		call.Conn = append(call.Conn, H.Encoding().Name(v))
+	}
+	t.Calls = append(t.Calls, call)
+
+	return call.ID
+}
+
+// Try records that a separator was tried by the call of the given id
+func (t *RecursionTrace) Try(id int) {
+	if t == nil || id <= 0 {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.Calls[id-1].Tried++
+}
+
+// Leave records that the call of the given id returned decomp, empty if it failed
+func (t *RecursionTrace) Leave(id int, decomp Decomp) {
+	if t == nil || id <= 0 {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	call := &t.Calls[id-1]
+	call.Duration = time.Since(t.start) - call.Start
+	if !decomp.Found() {
+		call.Outcome = RecursionRejected
+		return
+	}
+	call.Outcome = RecursionAccepted
+	for _, e := range decomp.Root.Cover.Slice() {
+		call.Separator = append(call.Separator, decomp.Graph.Encoding().Name(e.Name))
+	}
+}
+
+// Len returns the number of calls recorded so far
+func (t *RecursionTrace) Len() int {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return len(t.Calls)
+}
+
+// ToJSON exports the recorded calls as JSON
+func (t *RecursionTrace) ToJSON() ([]byte, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// GobEncode exports the recorded calls in the compact binary format of encoding/gob
+func (t *RecursionTrace) GobEncode() ([]byte, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(recursionGob{Calls: t.Calls, Truncated: t.Truncated})
+	return buffer.Bytes(), err
+}
+
+// GobDecode reads calls exported by GobEncode
+func (t *RecursionTrace) GobDecode(data []byte) error {
+	var decoded recursionGob
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return err
+	}
+	t.Calls, t.Truncated = decoded.Calls, decoded.Truncated
+	return nil
+}
+
+type recursionGob struct {
+	Calls     []RecursionCall
+	Truncated bool
+}
+
+// ReadRecursionTrace reads a trace exported as JSON or via gob, telling them apart by the first byte
+func ReadRecursionTrace(r io.Reader) (*RecursionTrace, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var output RecursionTrace
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &output)
+	} else {
+		err = output.GobDecode(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range output.Calls {
+		if output.Calls[i].ID != i+1 || output.Calls[i].Parent < 0 || output.Calls[i].Parent >= output.Calls[i].ID {
+			return nil, fmt.Errorf("call %d out of order in the trace", output.Calls[i].ID)
+		}
+	}
+	return &output, nil
+}
+
+// ToDOT exports the tree of calls in the DOT format of Graphviz. Each call is labelled by the size of its subgraph,
+// its separator and its duration, accepted calls drawn in green and rejected ones in red.
+func (t *RecursionTrace) ToDOT() string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	var buffer bytes.Buffer
+	buffer.WriteString("digraph recursion {\n")
+
+	for _, c := range t.Calls {
+		color := "black"
+		switch c.Outcome {
+		case RecursionAccepted:
+			color = "green"
+		case RecursionRejected:
+			color = "red"
+		}
+		label := fmt.Sprintf("%d edges, %d special\\nconn {%s}\\ntried %d, chose {%s}\\n%v", c.Edges, c.Special,
+			strings.Join(c.Conn, ", "), c.Tried, strings.Join(c.Separator, ", "), c.Duration)
+		buffer.WriteString(fmt.Sprintf("  c%d [label=\"%s\", shape=box, color=%s];\n", c.ID,
+			strings.ReplaceAll(label, "\"", "\\\""), color))
+		if c.Parent > 0 {
+			buffer.WriteString(fmt.Sprintf("  c%d -> c%d;\n", c.Parent, c.ID))
+		}
+	}
+	if t.Truncated {
+		buffer.WriteString("  truncated [label=\"trace truncated\", shape=plaintext];\n")
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
+
+// ToFolded exports the calls as folded stacks, one line per call with the path of calls leading to it and the time
+// spent in the call itself in microseconds, the input format of flamegraph.pl and similar tools. Calls are named by
+// the size of their subgraph and their outcome.
+func (t *RecursionTrace) ToFolded() string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	self := make([]time.Duration, len(t.Calls))
+	for i, c := range t.Calls {
+		self[i] += c.Duration
+		if c.Parent > 0 {
+			self[c.Parent-1] -= c.Duration // children may run in parallel, so this may drop below zero
+		}
+	}
+
+	stacks := make([]string, len(t.Calls))
+	var buffer bytes.Buffer
+	for i, c := range t.Calls {
+		frame := fmt.Sprintf("%d edges %s", c.Edges, c.Outcome)
+		if c.Parent > 0 {
+			stacks[i] = stacks[c.Parent-1] + ";" + frame
+		} else {
+			stacks[i] = frame
+		}
+		if us := self[i].Microseconds(); us > 0 {
+			buffer.WriteString(fmt.Sprintf("%s %d\n", stacks[i], us))
+		}
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,137 @@
+package lib
+
+// scratch.go reuses the scratch space needed to check candidate separators and compute their components, which
+// searches over many separators would otherwise allocate anew for each one, putting heavy load on the garbage
+// collector. Only memory which never leaves the call using it is reused, so the pools are invisible to callers.
+
+import (
+	"log"
+	"sync"
+
+	"github.com/cem-okulmus/disjoint"
+)
+
+// pooling decides whether scratch space is taken from the pools, see SetPooling
+var pooling = true
+
+// SetPooling enables or disables the reuse of scratch space, which is enabled by default. Disabled, all scratch space
+// is allocated anew, as a baseline to measure the pools against or to rule them out when looking for a bug. It must
+// not be called while a search is running.
+func SetPooling(enabled bool) {
+	pooling = enabled
+}
+
+// elementMaps holds maps to be used as scratch space for the union-find data structure of GetComponents
+var elementMaps = sync.Pool{New: func() interface{} { return make(map[int]*disjoint.Element) }}
+
+// ElementMap returns a map to be passed as scratch space to GetComponents and the predicates, which reuses the
+// elements left by earlier calls. Once no longer used, the map should be handed back via ReleaseElementMap.
+func ElementMap() map[int]*disjoint.Element {
+	if !pooling {
+		return make(map[int]*disjoint.Element)
+	}
+	return elementMaps.Get().(map[int]*disjoint.Element)
+}
+
+// ReleaseElementMap hands a map obtained from ElementMap back for reuse
+func ReleaseElementMap(m map[int]*disjoint.Element) {
+	if pooling {
+		elementMaps.Put(m)
+	}
+}
+
+// componentScratch holds the buffers used within a single computation of components
+type componentScratch struct {
+	sep   VertexSet           // the vertices of the separator
+	reps  []*disjoint.Element // a free vertex of each edge, nil if there is none
+	comps []int               // the component of each edge, by index, -1 if there is none
+	sizes []int               // the number of edges in each component
+}
+
+var componentScratches = sync.Pool{New: func() interface{} { return new(componentScratch) }}
+
+// getComponentScratch returns scratch space for n edges and the given separator, with all buffers cleared
+func getComponentScratch(n int, sep Edges) *componentScratch {
+	var s *componentScratch
+	if pooling {
+		s = componentScratches.Get().(*componentScratch)
+	} else {
+		s = new(componentScratch)
+	}
+
+	s.sep = s.sep[:0]
+	for _, e := range sep.Slice() {
+		for _, v := range e.Vertices {
+			s.sep.Add(v)
+		}
+	}
+
+	if cap(s.reps) < n {
+		s.reps = make([]*disjoint.Element, n)
+		s.comps = make([]int, n)
+	}
+	s.reps, s.comps = s.reps[:n], s.comps[:n]
+	for i := range s.reps {
+		s.reps[i] = nil
+	}
+	s.sizes = s.sizes[:0]
+
+	return s
+}
+
+// release hands the scratch space back for reuse, dropping the references to elements it still holds
+func (s *componentScratch) release() {
+	if !pooling {
+		return
+	}
+	for i := range s.reps {
+		s.reps[i] = nil
+	}
+	componentScratches.Put(s)
+}
+
+// resetElements prepares the union-find elements of all vertices of g, without listing the vertices first
+func resetElements(g Graph, vertices map[int]*disjoint.Element) {
+	reset := func(list []int) {
+		for _, v := range list {
+			if e, ok := vertices[v]; ok {
+				e.Reset()
+			} else {
+				vertices[v] = disjoint.NewElement()
+			}
+		}
+	}
+	if len(g.vertices) > 0 {
+		reset(g.vertices)
+		return
+	}
+	for _, e := range g.Edges.Slice() {
+		reset(e.Vertices)
+	}
+	for i := range g.Special {
+		reset(g.Special[i].vertices)
+	}
+}
+
+// subsetScratch holds the memory reused for the candidates checked by a worker of the search
+type subsetScratch struct {
+	slice []Edge
+	mux   sync.Mutex
+}
+
+// subset is the same as GetSubset, but reuses the memory of the previous candidate, so that the result is only valid
+// until the next call
+func (s *subsetScratch) subset(edges Edges, indices []int) Edges {
+	if !pooling {
+		return GetSubset(edges, indices)
+	}
+
+	s.slice = s.slice[:0]
+	for _, i := range indices {
+		if i < 0 || i >= edges.Len() {
+			log.Panicln("Index", i, "of subset", indices, "not within the", edges.Len(), "edges")
+		}
+		s.slice = append(s.slice, edges.Slice()[i])
+	}
+	return Edges{slice: s.slice, hashMux: &s.mux}
+}
@@ -3,6 +3,8 @@ package lib
 // search.go implements a parallel search over a set of edges with a given predicate to look for
 
 import (
+	"context"
+	"fmt"
 	"runtime"
 	"sync"
 
@@ -23,11 +25,13 @@ type SearchGenerator interface {
 
 // A Generator is black-box view for any kind of generation of items to look at in linear order, and it provides some helpful methods for the search
 type Generator interface {
-	HasNext() bool    // check if generator still has new elements
-	GetNext() []int   // the slice of int represents some choice of edges, with an underlying order
-	Confirm()         // confirm that the current selection has been checked *and* sent to central goroutine
-	Found()           // used to cache the check result
-	CheckFound() bool // used by search to see if previous run already performed the check
+	HasNext() bool              // check if generator still has new elements
+	GetNext() []int             // the slice of int represents some choice of edges, with an underlying order
+	Confirm()                   // confirm that the current selection has been checked *and* sent to central goroutine
+	Found()                     // used to cache the check result
+	CheckFound() bool           // used by search to see if previous run already performed the check
+	Save() []byte               // encode the state of the generation, while no search is using it
+	Restore(state []byte) error // continue the generation from a saved state, if it was saved by a matching generator
 }
 
 type ParallelSearch struct {
@@ -37,9 +41,17 @@ type ParallelSearch struct {
 	Result          []int
 	Generators      []Generator
 	ExhaustedSearch bool
+	Ctx             context.Context // if set, the search ends as soon as it is done
+	Sequential      bool            // if set, the generators are consumed one after another in the calling goroutine
 }
 
-type ParallelSearchGen struct{}
+// ParallelSearchGen sets up a ParallelSearch. If Ctx is set, all searches end once it is done, so that the algorithms
+// using them return without a decomposition. If Sequential is set, the searches don't race and always return the
+// first separator in the order of the generators, so that results are reproducible.
+type ParallelSearchGen struct {
+	Ctx        context.Context
+	Sequential bool
+}
 
 func (p ParallelSearchGen) GetSearch(H *Graph, Edges *Edges, BalFactor int, Gens []Generator) Search {
 	return &ParallelSearch{
@@ -49,9 +61,42 @@ func (p ParallelSearchGen) GetSearch(H *Graph, Edges *Edges, BalFactor int, Gens
 		Result:          []int{},
 		Generators:      Gens,
 		ExhaustedSearch: false,
+		Ctx:             p.Ctx,
+		Sequential:      p.Sequential,
 	}
 }
 
+// Context returns the context of the searches set up by the generator
+func (p ParallelSearchGen) Context() context.Context {
+	if p.Ctx == nil {
+		return context.Background()
+	}
+	return p.Ctx
+}
+
+// SearchContext returns the context used by the searches of a SearchGenerator, for algorithms which need to check
+// for cancellation outside of a search. Generators without a context never get cancelled.
+func SearchContext(gen SearchGenerator) context.Context {
+	if c, ok := gen.(interface{ Context() context.Context }); ok {
+		return c.Context()
+	}
+	return context.Background()
+}
+
+// WithContext returns a SearchGenerator like gen, whose searches end once ctx is done. It is meant for the searches
+// below the root, so a CheckpointGen, which only keeps the state of the searches at the root, is replaced by the
+// generator it wraps. Generators other than ParallelSearchGen are returned unchanged.
+func WithContext(gen SearchGenerator, ctx context.Context) SearchGenerator {
+	switch g := gen.(type) {
+	case ParallelSearchGen:
+		g.Ctx = ctx
+		return g
+	case *CheckpointGen:
+		return WithContext(g.SearchGenerator, ctx)
+	}
+	return gen
+}
+
 // SearchEnded returns true if search is completed
 func (s *ParallelSearch) SearchEnded() bool {
 	return s.ExhaustedSearch
@@ -67,16 +112,31 @@ type Predicate interface {
 	Check(H *Graph, sep *Edges, balancedFactor int, Vertices map[int]*disjoint.Element) bool
 }
 
+// An IncrementalPredicate can also check candidates using a ComponentTracker, which each worker of a search keeps
+// across the candidates it checks, given by their indices into the edges of the search
+type IncrementalPredicate interface {
+	Predicate
+	CheckTracked(t *ComponentTracker, combination []int, sep *Edges, balFactor int) bool
+}
+
 // FindNext starts the search and stops if some separator which satisfies the predicate
 // is found, or if the entire search space has been exhausted
 func (s *ParallelSearch) FindNext(pred Predicate) {
-	defer func() {
-		if r := recover(); r != nil {
-			return
-		}
-	}()
-
 	s.Result = []int{} // reset result
+	if len(s.Generators) == 0 || s.Edges == nil || s.Edges.Len() == 0 {
+		s.ExhaustedSearch = true // no separators to choose from
+		return
+	}
+
+	ctx := s.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Err() != nil {
+		s.ExhaustedSearch = true // cancelled before the search started
+		return
+	}
+
 	var numProc int
 	if runtime.GOMAXPROCS(-1) > len(s.Generators) {
 		numProc = len(s.Generators)
@@ -85,69 +145,141 @@ func (s *ParallelSearch) FindNext(pred Predicate) {
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(numProc)
-	finished := false
+
+	// without any goroutine granted, all generators are searched in this one, until it's cancelled
+	workers := 0
+	if !s.Sequential {
+		workers = Goroutines.TryAcquire(numProc)
+	}
+	if workers == 0 {
+		found := make(chan []int, 1)
+		wg.Add(1)
+		s.worker(0, 1, found, ctx.Done(), &wg, pred)
+		select {
+		case s.Result = <-found:
+		default:
+			s.ExhaustedSearch = true
+		}
+		return
+	}
+	defer Goroutines.Release(workers)
+
+	wg.Add(workers)
 	// SEARCH:
 	found := make(chan []int)
-	wait := make(chan bool)
-	//start workers
-	for i := 0; i < numProc; i++ {
-		go s.worker(i, found, &wg, &finished, pred)
+	done := make(chan struct{})      // closed once a result has been received, to stop the other workers
+	exhausted := make(chan struct{}) // closed once all workers have returned
+	//start workers, sharing the generators if there are more of them
+	for i := 0; i < workers; i++ {
+		go s.worker(i, workers, found, done, &wg, pred)
 	}
 
 	go func() {
 		wg.Wait()
-		wait <- true
+		close(exhausted)
 	}()
 
 	select {
 	case s.Result = <-found:
-		close(found) //to terminate other workers waiting on found
+		close(done)
+		wg.Wait()
+	case <-exhausted:
+		s.ExhaustedSearch = true
+	case <-ctx.Done():
+		close(done)
 		wg.Wait()
-	case <-wait:
 		s.ExhaustedSearch = true
 	}
 
 }
 
-// a worker that actually runs the search within a single goroutine
-func (s ParallelSearch) worker(workernum int, found chan []int, wg *sync.WaitGroup, finished *bool, pred Predicate) {
-	defer func() {
-		if r := recover(); r != nil {
-			// log.Printf("Worker %d 'forced' to quit, reason: %v", workernum, r)
+// a worker that actually runs the search within a single goroutine, going through the generators from the first one
+// with the given step one after another
+func (s ParallelSearch) worker(first, step int, found chan []int, done <-chan struct{}, wg *sync.WaitGroup,
+	pred Predicate) {
+	defer wg.Done()
+	Vertices := ElementMap()
+	defer ReleaseElementMap(Vertices)
+	var tracker *ComponentTracker
+	if _, ok := pred.(IncrementalPredicate); ok {
+		tracker = NewComponentTracker(s.H, s.Edges)
+	}
+
+	var scratch subsetScratch
+
+	for i := first; i < len(s.Generators); i += step {
+		if s.searchGenerator(i, found, done, pred, Vertices, tracker, &scratch) {
 			return
 		}
-	}()
-	defer wg.Done()
-	var Vertices = make(map[int]*disjoint.Element)
+	}
+}
 
-	gen := s.Generators[workernum]
+// searchGenerator checks the candidates of the i-th generator, and returns true once one was sent to found or the
+// search is done
+func (s ParallelSearch) searchGenerator(i int, found chan []int, done <-chan struct{}, pred Predicate,
+	Vertices map[int]*disjoint.Element, tracker *ComponentTracker, scratch *subsetScratch) bool {
+	gen := s.Generators[i]
+	checkedCount := 0
+	defer func() { Stats.Consume(i, checkedCount) }()
 
 	for gen.HasNext() {
-		if *finished {
-			// log.Printf("Worker %d told to quit", workernum)
-			return
+		select {
+		case <-done:
+			return true
+		default:
 		}
 		// j := make([]int, len(gen.Combination))
 		// copy(gen.Combination, j)
 		j := gen.GetNext()
 
-		sep := GetSubset(*s.Edges, j)
-		if pred.Check(s.H, &sep, s.BalFactor, Vertices) {
+		// a candidate found in a previous run, but not yet sent to the central goroutine, need not be checked again
+		checked := gen.CheckFound()
+		if !checked {
+			sep := scratch.subset(*s.Edges, j)
+			if tracker != nil {
+				checked = pred.(IncrementalPredicate).CheckTracked(tracker, j, &sep, s.BalFactor)
+			} else {
+				checked = pred.Check(s.H, &sep, s.BalFactor, Vertices)
+			}
+
+			checkedCount++
+			if checkedCount == flushInterval {
+				Stats.Consume(i, checkedCount)
+				checkedCount = 0
+			}
+		}
+		if checked {
 			gen.Found() // cache result
-			found <- j
-			// log.Println("Worker", workernum, "won, found: ", j)
-			gen.Confirm()
-			*finished = true
-			return
+			select {
+			case found <- j:
+				if LogSearch.Enabled(LogDebug) {
+					LogSearch.Printf(LogDebug, "Generator %d found separator %v", i, j)
+				}
+				gen.Confirm()
+			case <-done:
+				// another worker won, the candidate stays unconfirmed and is returned by the next call
+			}
+			return true
 		}
 		gen.Confirm()
 	}
+	return false
 }
 
 // BalancedCheck looks for Balanced Separators
 type BalancedCheck struct{}
 
+// CheckBalFactor returns an error unless the balance factor is at least 2, as a separator is only balanced if no
+// component has more than (b-1)/b of the edges, for factor b, which none can satisfy below that. Larger factors admit
+// larger components, so that more separators are balanced and fewer candidates need to be checked, but the recursion
+// depth grows, as the size of the components only drops to (b-1)/b at each level
+func CheckBalFactor(balFactor int) error {
+	if balFactor < 2 {
+		return fmt.Errorf("balance factor %v must be at least 2", balFactor)
+	}
+	return nil
+}
+
 // Check performs the needed computation to ensure whether sep is a Balanced Separator
 func (b BalancedCheck) Check(H *Graph, sep *Edges, balFactor int, Vertices map[int]*disjoint.Element) bool {
 
@@ -162,14 +294,14 @@ func (b BalancedCheck) Check(H *Graph, sep *Edges, balFactor int, Vertices map[i
 		}
 	}
 
-	// Make sure that "special seps can never be used as separators"
-	for i := range H.Special {
-		if IntHash(H.Special[i].Vertices()) == IntHash(sep.Vertices()) {
-			return false
-		}
-	}
+	return !H.IsSpecial(*sep)
+}
 
-	return true
+// CheckTracked is the same as Check, with the components maintained by the tracker for the candidate given by the
+// indices of its edges
+func (b BalancedCheck) CheckTracked(t *ComponentTracker, combination []int, sep *Edges, balFactor int) bool {
+	t.Set(combination)
+	return t.Balanced(balFactor) && !t.H.IsSpecial(*sep)
 }
 
 // CheckOut does the same as Check, except it also passes on the components found, if output is true
@@ -186,12 +318,92 @@ func (b BalancedCheck) CheckOut(H *Graph, sep *Edges, balFactor int, Vertices ma
 		}
 	}
 
-	// Make sure that "special seps can never be used as separators"
-	for i := range H.Special {
-		if IntHash(H.Special[i].Vertices()) == IntHash(sep.Vertices()) {
-			return false, []Graph{}, []Edge{}
-		}
+	if H.IsSpecial(*sep) {
+		return false, []Graph{}, []Edge{}
 	}
 
 	return true, comps, isolated
 }
+
+// VertexSepCheck looks for balanced separators given as sets of vertices, which must also be coverable by at most K
+// of the given edges
+type VertexSepCheck struct {
+	Edges Edges
+	K     int
+}
+
+// Check performs the needed computation to ensure whether the vertices of sep form a coverable Balanced Separator
+func (v VertexSepCheck) Check(H *Graph, sep *Edges, balFactor int, Vertices map[int]*disjoint.Element) bool {
+	if !(BalancedCheck{}).Check(H, sep, balFactor, Vertices) {
+		return false
+	}
+
+	_, ok := v.GetCover(sep.Vertices())
+	return ok
+}
+
+// GetCover returns at most K edges covering the given vertices, and false if there are none
+func (v VertexSepCheck) GetCover(vertices []int) (Edges, bool) {
+	cover, ok, _ := v.getCover(vertices, 0)
+	return cover, ok
+}
+
+// getCover looks for a cover like GetCover, but gives up once budget edges were branched on, if positive. The last
+// result tells if the search was complete, so that the lack of a cover is certain.
+func (v VertexSepCheck) getCover(vertices []int, budget int) (Edges, bool, bool) {
+	covered := make(map[int]int)
+	for _, x := range vertices {
+		covered[x] = 0
+	}
+	var chosen []Edge
+	branches := 0
+
+	// branch on the edges containing the first uncovered vertex
+	var search func() bool
+	search = func() bool {
+		uncovered := -1
+		for _, x := range vertices {
+			if covered[x] == 0 {
+				uncovered = x
+				break
+			}
+		}
+		if uncovered == -1 {
+			return true
+		}
+		if len(chosen) == v.K {
+			return false
+		}
+
+		for _, e := range v.Edges.Slice() {
+			if !mem(e.Vertices, uncovered) {
+				continue
+			}
+			if budget > 0 && branches == budget {
+				return false
+			}
+			branches++
+			chosen = append(chosen, e)
+			for _, x := range e.Vertices {
+				if _, ok := covered[x]; ok {
+					covered[x]++
+				}
+			}
+			if search() {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+			for _, x := range e.Vertices {
+				if _, ok := covered[x]; ok {
+					covered[x]--
+				}
+			}
+		}
+		return false
+	}
+
+	if !search() {
+		return Edges{}, false, budget <= 0 || branches < budget
+	}
+	return NewEdges(chosen), true, true
+}
@@ -0,0 +1,161 @@
+package lib
+
+// separators.go exposes the search for balanced separators on its own, for tools which need them apart from any
+// decomposition
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// A SeparatorIterator enumerates the balanced separators of a graph, using the same parallel search as the
+// algorithms. Unless the search is sequential, the order of the separators is not fixed.
+type SeparatorIterator struct {
+	graph      Graph
+	k          int
+	balFactor  int
+	ctx        context.Context
+	sequential bool
+	generators []Generator
+	search     Search
+	current    Edges
+	count      int
+
+	interval time.Duration // how often save is called, if set
+	save     func(state []byte)
+	lastSave time.Time
+}
+
+// separatorState is the state of a SeparatorIterator, as encoded by Save
+type separatorState struct {
+	Edges      int
+	K          int
+	BalFactor  int
+	Count      int
+	Generators []jsoniter.RawMessage
+}
+
+// BalancedSeparators returns an iterator over all sets of at most K edges of H which are balanced separators for the
+// given factor, each returned once. If ctx is done, the iteration ends early. With sequential set, the search runs in
+// the calling goroutine and the separators are returned in the order of their combinations.
+func BalancedSeparators(ctx context.Context, H Graph, K int, balFactor int, sequential bool) *SeparatorIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	it := &SeparatorIterator{graph: H, k: K, balFactor: balFactor, ctx: ctx, sequential: sequential}
+	it.generators = SplitCombin(H.Edges.Len(), K, runtime.GOMAXPROCS(-1), false)
+	it.search = it.getSearch(ctx)
+
+	return it
+}
+
+// getSearch sets up a search over the current generators, which ends once ctx is done
+func (it *SeparatorIterator) getSearch(ctx context.Context) Search {
+	gen := ParallelSearchGen{Ctx: ctx, Sequential: it.sequential}
+	return gen.GetSearch(&it.graph, &it.graph.Edges, it.balFactor, it.generators)
+}
+
+// Checkpoint makes the iterator call save with its state, as encoded by Save, whenever the given interval has passed
+// since the last call, which must be positive, even while it's still searching for the next separator. The state is
+// saved before the search continues, so that an iteration resumed from it returns again any separators returned after
+// it was saved.
+func (it *SeparatorIterator) Checkpoint(interval time.Duration, save func(state []byte)) {
+	it.interval = interval
+	it.save = save
+	it.lastSave = time.Now()
+}
+
+// Next advances to the next balanced separator, and returns false once there are none left
+func (it *SeparatorIterator) Next() bool {
+	if it.search.SearchEnded() {
+		return false
+	}
+	if it.save == nil {
+		it.search.FindNext(BalancedCheck{})
+	}
+
+	for it.save != nil {
+		if time.Since(it.lastSave) >= it.interval {
+			it.save(it.Save())
+			it.lastSave = time.Now()
+		}
+
+		// pause the search once the next state is due, which leaves each generator at a candidate it can resume from
+		ctx, cancel := context.WithDeadline(it.ctx, it.lastSave.Add(it.interval))
+		it.search = it.getSearch(ctx)
+		it.search.FindNext(BalancedCheck{})
+		paused := ctx.Err() != nil && it.ctx.Err() == nil
+		cancel()
+
+		if !it.search.SearchEnded() || !paused {
+			break
+		}
+	}
+	if it.search.SearchEnded() {
+		it.current = Edges{}
+		return false
+	}
+
+	it.current = GetSubset(it.graph.Edges, it.search.GetResult())
+	it.count++
+	return true
+}
+
+// Separator returns the separator found by the last call to Next
+func (it *SeparatorIterator) Separator() Edges {
+	return it.current
+}
+
+// Count returns the number of separators found so far
+func (it *SeparatorIterator) Count() int {
+	return it.count
+}
+
+// Save encodes the state of the iteration, such that Restore continues with the separators not returned yet. It must
+// not be called during a call to Next.
+func (it *SeparatorIterator) Save() []byte {
+	state := separatorState{Edges: it.graph.Edges.Len(), K: it.k, BalFactor: it.balFactor, Count: it.count}
+	for _, gen := range it.generators {
+		state.Generators = append(state.Generators, gen.Save())
+	}
+
+	output, err := json.Marshal(state)
+	if err != nil {
+		panic(err)
+	}
+	return output
+}
+
+// Restore continues the iteration from a state encoded by Save, which must come from an iteration over the
+// separators of a graph with as many edges, for the same width and balance factor. The search is split as it was
+// when saved, no matter how many CPUs there are now.
+func (it *SeparatorIterator) Restore(state []byte) error {
+	var saved separatorState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+	if saved.Edges != it.graph.Edges.Len() || saved.K != it.k || saved.BalFactor != it.balFactor {
+		return fmt.Errorf("state for %v edges, width %v and balance factor %v doesn't match the iteration",
+			saved.Edges, saved.K, saved.BalFactor)
+	}
+
+	generators := SplitCombin(it.graph.Edges.Len(), it.k, len(saved.Generators), false)
+	if len(generators) != len(saved.Generators) {
+		return fmt.Errorf("state with %v generators doesn't match the iteration", len(saved.Generators))
+	}
+	for i := range generators {
+		if err := generators[i].Restore(saved.Generators[i]); err != nil {
+			return err
+		}
+	}
+
+	it.generators = generators
+	it.search = it.getSearch(it.ctx)
+	it.count = saved.Count
+	it.current = Edges{}
+	return nil
+}
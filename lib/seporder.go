@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A SeparatorOrder decides in which order the searches try the edges for separators. SplitCombin enumerates
+// combinations in lexicographic order of the edges, while the heuristic orders rank the edges by a score first, so
+// that separators made of promising edges are tried early. The random order starts each search at a random edge
+// instead, wrapping around to the first edges of the graph at the end.
+type SeparatorOrder int
+
+// The supported separator orders
+const (
+	Lexicographic SeparatorOrder = iota // the order of the edges in the graph
+	ByCoverage                          // edges with the most vertices of the subgraph first
+	ByDegree                            // edges intersecting the most edges of the subgraph first
+	RandomOffset                        // the order of the edges, rotated by a random offset for each search
+)
+
+var separatorOrders = map[string]SeparatorOrder{
+	"none":     Lexicographic,
+	"coverage": ByCoverage,
+	"degree":   ByDegree,
+	"random":   RandomOffset,
+}
+
+// offsets is the source of the offsets of RandomOffset, shared by all searches
+var offsets = struct {
+	sync.Mutex
+	r *rand.Rand
+}{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// SeedRandomOffsets makes the offsets of RandomOffset reproducible, as far as the searches draw them in a fixed order
+func SeedRandomOffsets(seed int64) {
+	offsets.Lock()
+	defer offsets.Unlock()
+	offsets.r = rand.New(rand.NewSource(seed))
+}
+
+// randomOffset returns a random offset into n edges
+func randomOffset(n int) int {
+	offsets.Lock()
+	defer offsets.Unlock()
+	return offsets.r.Intn(n)
+}
+
+// SeparatorOrders returns the names of all separator orders, in alphabetical order
+func SeparatorOrders() []string {
+	var output []string
+
+	for name := range separatorOrders {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+
+	return output
+}
+
+// ParseSeparatorOrder returns the separator order of the given name
+func ParseSeparatorOrder(name string) (SeparatorOrder, error) {
+	order, ok := separatorOrders[name]
+	if !ok {
+		return Lexicographic, fmt.Errorf("unknown separator order %q, supported are: %v", name, SeparatorOrders())
+	}
+	return order, nil
+}
+
+// Generators returns generators for the combinations of at most k of the edges, split as by SplitCombin, which
+// produce the combinations in the given order when searching for separators of H
+func (o SeparatorOrder) Generators(H Graph, edges Edges, k int, split int, unextended bool) []Generator {
+	output := SplitCombin(edges.Len(), k, split, unextended)
+	if o == Lexicographic || len(output) == 0 {
+		return output
+	}
+
+	ranking := make([]int, edges.Len())
+	if o == RandomOffset {
+		offset := randomOffset(len(ranking))
+		for i := range ranking {
+			ranking[i] = (i + offset) % len(ranking)
+		}
+	} else {
+		scores := o.scores(H, edges)
+		for i := range ranking {
+			ranking[i] = i
+		}
+		sort.SliceStable(ranking, func(i, j int) bool { return scores[ranking[i]] > scores[ranking[j]] })
+	}
+
+	for i := range output {
+		output[i] = rankedGenerator{Generator: output[i], ranking: ranking}
+	}
+	return output
+}
+
+// scores computes the score of each edge w.r.t. H
+func (o SeparatorOrder) scores(H Graph, edges Edges) []int {
+	output := make([]int, edges.Len())
+
+	switch o {
+	case ByCoverage:
+		vertices := H.VertexSet()
+		for i, e := range edges.Slice() {
+			for _, v := range e.Vertices {
+				if vertices.Has(v) {
+					output[i]++
+				}
+			}
+		}
+	case ByDegree:
+		containing := make(map[int][]int) // the edges of H containing each vertex
+		for j, e := range H.Edges.Slice() {
+			for _, v := range e.Vertices {
+				containing[v] = append(containing[v], j)
+			}
+		}
+		seen := make([]int, H.Edges.Len()) // the last edge each edge of H was counted for, plus one
+		for i, e := range edges.Slice() {
+			for _, v := range e.Vertices {
+				for _, j := range containing[v] {
+					if seen[j] != i+1 {
+						seen[j] = i + 1
+						output[i]++
+					}
+				}
+			}
+		}
+	}
+
+	return output
+}
+
+// A rankedGenerator maps the combinations of another generator, taken as positions in a ranking, to the edges there
+type rankedGenerator struct {
+	Generator
+	ranking []int
+}
+
+// GetNext returns the edges at the positions of the next combination
+func (r rankedGenerator) GetNext() []int {
+	positions := r.Generator.GetNext()
+	output := make([]int, len(positions))
+	for i, p := range positions {
+		output[i] = r.ranking[p]
+	}
+	return output
+}
@@ -0,0 +1,158 @@
+package lib
+
+// special.go implements special edges, which stand in for the separators chosen further up in the search
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// A SpecialEdge is a separator used further up in the search, added to the components below it to keep them
+// connected to it. It is not an edge of the graph and may never be used as a separator itself. Two special edges are
+// equal if they have the same vertices, no matter which edges they were created from.
+type SpecialEdge struct {
+	ID       int   // distinct for each special edge created by NewSpecialEdge, and kept by copies
+	Edges    Edges // the separator, which also covers the special edge in a decomposition
+	vertices []int // sorted and without duplicates
+	hash     uint64
+}
+
+// specialIDs counts the special edges created so far
+var specialIDs int64
+
+// NewSpecialEdge turns a separator into a special edge. The edges are copied, so the separator may be changed
+// afterwards.
+func NewSpecialEdge(sep Edges) SpecialEdge {
+	slice := make([]Edge, sep.Len())
+	copy(slice, sep.Slice())
+
+	return newSpecialEdge(int(atomic.AddInt64(&specialIDs, 1)), NewEdges(slice))
+}
+
+// newSpecialEdge computes the vertices and hash of a special edge with the given identity
+func newSpecialEdge(id int, edges Edges) SpecialEdge {
+	vertices := edges.Vertices() // cached in edges as well, so that covers made of them compare equal to the separator
+
+	return SpecialEdge{ID: id, Edges: edges, vertices: vertices, hash: vertexHash(vertices)}
+}
+
+// vertexHash computes a hash of a sorted list of vertices without duplicates
+func vertexHash(vertices []int) uint64 {
+	h := fnv.New64a()
+	var bs [8]byte
+	for _, v := range vertices {
+		binary.LittleEndian.PutUint64(bs[:], uint64(v))
+		h.Write(bs[:])
+	}
+	return h.Sum64()
+}
+
+// Vertices returns the vertices of the special edge, in increasing order. The slice must not be changed.
+func (s SpecialEdge) Vertices() []int {
+	return s.vertices
+}
+
+// VertexSet returns the vertices of the special edge as a set
+func (s SpecialEdge) VertexSet() VertexSet {
+	return NewVertexSet(s.vertices)
+}
+
+// Hash returns the hash of the vertices of the special edge, computed once when it was created
+func (s SpecialEdge) Hash() uint64 {
+	return s.hash
+}
+
+// Equal checks whether two special edges have the same vertices. The hashes only serve to rule out most unequal
+// pairs quickly, the vertices are compared in any case.
+func (s SpecialEdge) Equal(other SpecialEdge) bool {
+	if s.hash != other.hash || len(s.vertices) != len(other.vertices) {
+		return false
+	}
+	for i := range s.vertices {
+		if s.vertices[i] != other.vertices[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasVertices checks whether the special edge has exactly the given vertices, sorted and without duplicates, whose
+// hash is passed along so that it is computed only once when checking many special edges
+func (s SpecialEdge) hasVertices(vertices []int, hash uint64) bool {
+	return s.Equal(SpecialEdge{vertices: vertices, hash: hash})
+}
+
+// String returns the edges of the special edge
+func (s SpecialEdge) String() string {
+	return s.Edges.String()
+}
+
+func (s SpecialEdge) stringEnc(enc *Encoding) string {
+	return s.Edges.stringEnc(enc)
+}
+
+// specialGob holds the fields of a special edge which are serialised, the others being derived from them
+type specialGob struct {
+	ID    int
+	Edges Edges
+}
+
+// GobEncode serialises a special edge
+func (s SpecialEdge) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(specialGob{ID: s.ID, Edges: s.Edges}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode restores a special edge serialised with GobEncode, keeping its identity
+func (s *SpecialEdge) GobDecode(b []byte) error {
+	var out specialGob
+	if err := gob.NewDecoder(bytes.NewBuffer(b)).Decode(&out); err != nil {
+		return err
+	}
+	*s = newSpecialEdge(out.ID, out.Edges)
+	return nil
+}
+
+// NumSpecial returns the number of special edges of the graph
+func (g Graph) NumSpecial() int {
+	return len(g.Special)
+}
+
+// AddSpecial adds special edges to the graph. The special edges of g are copied first, so that graphs sharing them,
+// such as the components of the same separator, are not affected.
+func (g *Graph) AddSpecial(special ...SpecialEdge) {
+	output := make([]SpecialEdge, 0, len(g.Special)+len(special))
+	output = append(output, g.Special...)
+	g.Special = append(output, special...)
+	g.vertices = nil
+}
+
+// IsSpecial checks whether the vertices of the separator are exactly those of one of the special edges of the graph
+func (g Graph) IsSpecial(sep Edges) bool {
+	if len(g.Special) == 0 {
+		return false
+	}
+	vertices := sep.Vertices()
+	hash := vertexHash(vertices)
+	for i := range g.Special {
+		if g.Special[i].hasVertices(vertices, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// SpecialVertices returns the union of the vertices of all special edges of the graph, in increasing order
+func (g Graph) SpecialVertices() []int {
+	var output []int
+	for i := range g.Special {
+		output = append(output, g.Special[i].vertices...)
+	}
+	return RemoveDuplicates(output)
+}
@@ -0,0 +1,217 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultTraceLimit is the number of nodes a SearchTrace records if no limit is set
+const DefaultTraceLimit = 10000
+
+// Statuses of the nodes in a SearchTrace
+const (
+	TraceOpen     = "open"     // tried, but no result known (yet)
+	TraceAccepted = "accepted" // decomposed, or used as separator of a decomposition
+	TraceRejected = "rejected" // couldn't be decomposed, or didn't lead to a decomposition
+)
+
+// A SearchTrace records the separators an algorithm explored: for each subgraph the separators tried on it, and for
+// each separator the components it produced. Subgraphs are identified by their edges, so the same component reached
+// via different separators is recorded only once, and the result is a lattice rather than a tree. As the number of
+// separators grows quickly, this is only meant for small instances, and recording stops after Limit nodes.
+type SearchTrace struct {
+	Limit     int         `json:"-"`
+	Nodes     []TraceNode `json:"nodes"`
+	Links     []TraceLink `json:"links"`
+	Truncated bool        `json:"truncated"` // true if nodes were dropped because of the limit
+	index     map[string]int
+	links     map[TraceLink]struct{}
+	mux       sync.Mutex
+}
+
+// A TraceNode is either a subgraph or a separator tried on a subgraph
+type TraceNode struct {
+	ID     int    `json:"id"`
+	Kind   string `json:"kind"` // "subgraph" or "separator"
+	Label  string `json:"label"`
+	Status string `json:"status"`
+}
+
+// A TraceLink leads from a subgraph to a separator tried on it, or from a separator to one of its components
+type TraceLink struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func subgraphKey(H Graph) string {
+	return fmt.Sprint("g", H.Edges.Hash())
+}
+
+func separatorKey(H Graph, sep Edges) string {
+	return fmt.Sprint("s", H.Edges.Hash(), "/", sep.Hash())
+}
+
+// node returns the id of the node under key, adding it if needed. Returns -1 if the limit has been reached.
+func (t *SearchTrace) node(key, kind string, label func() string) int {
+	if i, ok := t.index[key]; ok {
+		return i
+	}
+	limit := t.Limit
+	if limit <= 0 {
+		limit = DefaultTraceLimit
+	}
+	if len(t.Nodes) >= limit {
+		t.Truncated = true
+		return -1
+	}
+	if t.index == nil {
+		t.index = make(map[string]int)
+		t.links = make(map[TraceLink]struct{})
+	}
+
+	i := len(t.Nodes)
+	t.index[key] = i
+	t.Nodes = append(t.Nodes, TraceNode{ID: i, Kind: kind, Label: label(), Status: TraceOpen})
+	return i
+}
+
+func (t *SearchTrace) link(from, to int) {
+	if from < 0 || to < 0 {
+		return
+	}
+	l := TraceLink{From: from, To: to}
+	if _, ok := t.links[l]; ok {
+		return
+	}
+	t.links[l] = Empty
+	t.Links = append(t.Links, l)
+}
+
+func (t *SearchTrace) subgraph(H Graph) int {
+	return t.node(subgraphKey(H), "subgraph", func() string { return H.Edges.stringEnc(H.Encoding()) })
+}
+
+func (t *SearchTrace) separator(H Graph, sep Edges) int {
+	return t.node(separatorKey(H, sep), "separator", func() string { return sep.stringEnc(H.Encoding()) })
+}
+
+func (t *SearchTrace) setStatus(key, status string) {
+	if i, ok := t.index[key]; ok {
+		t.Nodes[i].Status = status
+	}
+}
+
+// Try records that sep is tried as separator of H, producing the components comps.
+// All methods do nothing on a nil trace, so algorithms can call them unconditionally.
+func (t *SearchTrace) Try(H Graph, sep Edges, comps []Graph) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	s := t.separator(H, sep)
+	t.link(t.subgraph(H), s)
+	for i := range comps {
+		t.link(s, t.subgraph(comps[i]))
+	}
+}
+
+// Accept records that sep led to a decomposition of H, and thus all of its components were decomposed
+func (t *SearchTrace) Accept(H Graph, sep Edges) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.setStatus(subgraphKey(H), TraceAccepted)
+	i, ok := t.index[separatorKey(H, sep)]
+	if !ok {
+		return
+	}
+	t.Nodes[i].Status = TraceAccepted
+	for _, l := range t.Links {
+		if l.From == i {
+			t.Nodes[l.To].Status = TraceAccepted
+		}
+	}
+}
+
+// Reject records that sep didn't lead to a decomposition of H
+func (t *SearchTrace) Reject(H Graph, sep Edges) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.setStatus(separatorKey(H, sep), TraceRejected)
+}
+
+// Fail records that no separator led to a decomposition of H. H is added if needed, to also show subgraphs where
+// no separator could be tried at all.
+func (t *SearchTrace) Fail(H Graph) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if i := t.subgraph(H); i >= 0 {
+		t.Nodes[i].Status = TraceRejected
+	}
+}
+
+// ToJSON exports the recorded nodes and links as JSON
+func (t *SearchTrace) ToJSON() ([]byte, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// ToDOT exports the recorded lattice in the DOT format of Graphviz. Subgraphs are drawn as boxes and separators as
+// ellipses, accepted nodes in green and rejected ones in red.
+func (t *SearchTrace) ToDOT() string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	var buffer bytes.Buffer
+	buffer.WriteString("digraph search {\n")
+
+	for _, n := range t.Nodes {
+		shape := "ellipse"
+		if n.Kind == "subgraph" {
+			shape = "box"
+		}
+		color := "black"
+		switch n.Status {
+		case TraceAccepted:
+			color = "green"
+		case TraceRejected:
+			color = "red"
+		}
+		label := strings.ReplaceAll(n.Label, "\"", "\\\"")
+		buffer.WriteString(fmt.Sprintf("  n%d [label=\"%s\", shape=%s, color=%s];\n", n.ID, label, shape, color))
+	}
+	for _, l := range t.Links {
+		buffer.WriteString(fmt.Sprintf("  n%d -> n%d;\n", l.From, l.To))
+	}
+	if t.Truncated {
+		buffer.WriteString("  truncated [label=\"trace truncated\", shape=plaintext];\n")
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
+
+// Len returns the number of nodes recorded so far
+func (t *SearchTrace) Len() int {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return len(t.Nodes)
+}
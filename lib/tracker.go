@@ -0,0 +1,227 @@
+package lib
+
+// tracker.go maintains the components of a graph while a separator is grown and shrunk one edge at a time, as between
+// consecutive candidates of a search, which mostly share all but their last edges
+
+// A ComponentTracker maintains the components of a graph w.r.t. a separator chosen from a fixed set of edges. The
+// components for each prefix of the separator are kept on a stack: adding an edge only splits the components
+// containing any of its vertices, and removing the last edge just drops the top of the stack. Components are counted
+// as GetComponents returns them, including special edges covered by the separator as components of their own, but
+// without edges covered by it. A tracker must not be used by several goroutines at once.
+type ComponentTracker struct {
+	H        *Graph
+	Edges    *Edges  // the edges the separator is chosen from
+	items    [][]int // the vertices of the edges of H, followed by those of its special edges
+	numEdges int     // the number of edges of H
+	stack    []trackerLevel
+
+	containing map[int][]int // the items containing each vertex
+	member     []int         // the items split at the current stamp
+	seen       []int         // the items reached at the current stamp
+	stamp      int
+	width      int   // the length of a VertexSet holding any vertex of H
+	queue      []int // scratch space for split
+	added      []int // scratch space for Push
+}
+
+// a trackerLevel holds the components w.r.t. some prefix of the separator. The components split at this level take
+// their items and vertices from the level's own buffers, which are reused once the level is pushed again after a pop,
+// as by then no other level refers to them anymore.
+type trackerLevel struct {
+	edge            int // the index of the last edge of the prefix, -1 for the empty one
+	sep             VertexSet
+	comps           []trackedComp
+	isolatedSpecial int      // special edges covered by the prefix
+	items           []int    // the buffer for the items of the components split at this level
+	words           []uint64 // the buffer for the vertices of the components split at this level
+}
+
+type trackedComp struct {
+	items    []int     // indices into the items of the tracker
+	vertices VertexSet // the vertices of the component not in the separator
+}
+
+// NewComponentTracker returns a tracker for the components of H, starting with an empty separator
+func NewComponentTracker(H *Graph, edges *Edges) *ComponentTracker {
+	t := &ComponentTracker{H: H, Edges: edges, numEdges: H.Edges.Len()}
+
+	all := make([]int, 0, H.Len())
+	for _, e := range H.Edges.Slice() {
+		all = append(all, len(t.items))
+		t.items = append(t.items, e.Vertices)
+	}
+	for i := range H.Special {
+		all = append(all, len(t.items))
+		t.items = append(t.items, H.Special[i].Vertices())
+	}
+
+	t.containing = make(map[int][]int)
+	for i := range t.items {
+		for _, v := range t.items[i] {
+			t.containing[v] = append(t.containing[v], i)
+			if v/64+1 > t.width {
+				t.width = v/64 + 1
+			}
+		}
+	}
+	t.member = make([]int, len(t.items))
+	t.seen = make([]int, len(t.items))
+
+	bottom := trackerLevel{edge: -1}
+	bottom.isolatedSpecial = t.split(all, nil, &bottom)
+	t.stack = []trackerLevel{bottom}
+
+	return t
+}
+
+// Push adds the edge with the given index to the separator
+func (t *ComponentTracker) Push(edge int) {
+	n := len(t.stack)
+	var next trackerLevel
+	if pooling && n < cap(t.stack) {
+		next = t.stack[:n+1][n] // a level popped earlier, whose buffers can be reused
+	}
+	top := t.stack[n-1]
+	vertices := t.Edges.Slice()[edge].Vertices
+
+	next.edge, next.isolatedSpecial = edge, top.isolatedSpecial
+	next.sep = append(next.sep[:0], top.sep...)
+	next.comps, next.items, next.words = next.comps[:0], next.items[:0], next.words[:0]
+	added := t.added[:0] // the vertices newly in the separator
+	for _, v := range vertices {
+		if !next.sep.Has(v) {
+			next.sep.Add(v)
+			added = append(added, v)
+		}
+	}
+	t.added = added
+
+	for _, c := range top.comps {
+		affected := false
+		for _, v := range added {
+			if c.vertices.Has(v) {
+				affected = true
+				break
+			}
+		}
+		if !affected {
+			next.comps = append(next.comps, c)
+			continue
+		}
+		next.isolatedSpecial += t.split(c.items, next.sep, &next)
+	}
+
+	t.stack = append(t.stack, next)
+}
+
+// Pop removes the edge added last from the separator
+func (t *ComponentTracker) Pop() {
+	if len(t.stack) == 1 {
+		return
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// Set changes the separator to the edges with the given indices, keeping the longest prefix it shares with the
+// current separator
+func (t *ComponentTracker) Set(combination []int) {
+	common := 0
+	for common < len(combination) && common+1 < len(t.stack) && t.stack[common+1].edge == combination[common] {
+		common++
+	}
+	t.stack = t.stack[:common+1]
+	for _, edge := range combination[common:] {
+		t.Push(edge)
+	}
+}
+
+// Sizes returns the number of edges and special edges in each component w.r.t. the current separator
+func (t *ComponentTracker) Sizes() []int {
+	top := t.stack[len(t.stack)-1]
+	output := make([]int, 0, len(top.comps)+top.isolatedSpecial)
+	for _, c := range top.comps {
+		output = append(output, len(c.items))
+	}
+	for i := 0; i < top.isolatedSpecial; i++ {
+		output = append(output, 1)
+	}
+	return output
+}
+
+// Balanced returns true if no component w.r.t. the current separator is too large, as checked by BalancedCheck
+func (t *ComponentTracker) Balanced(balFactor int) bool {
+	limit := (t.H.Len() * (balFactor - 1)) / balFactor
+	top := t.stack[len(t.stack)-1]
+
+	if top.isolatedSpecial > 0 && limit < 1 {
+		return false
+	}
+	for _, c := range top.comps {
+		if len(c.items) > limit {
+			return false
+		}
+	}
+	return true
+}
+
+// split computes the components formed by the given items w.r.t. sep, in time linear in their size, and adds them to
+// the components of the level. It returns the number of special edges among the items covered by sep, while covered
+// edges are dropped.
+func (t *ComponentTracker) split(items []int, sep VertexSet, level *trackerLevel) int {
+	t.stamp++
+	for _, i := range items {
+		t.member[i] = t.stamp
+	}
+
+	isolated := 0
+	for _, i := range items {
+		if t.seen[i] == t.stamp {
+			continue
+		}
+
+		start, wordStart := len(level.items), len(level.words)
+		for k := 0; k < t.width; k++ {
+			level.words = append(level.words, 0)
+		}
+		// the set is as long as needed for all vertices, so it never grows beyond its part of the buffer
+		compVertices := VertexSet(level.words[wordStart:len(level.words):len(level.words)])
+
+		queue := append(t.queue[:0], i)
+		t.seen[i] = t.stamp
+		for head := 0; head < len(queue); head++ {
+			j := queue[head]
+			free := false
+			for _, v := range t.items[j] {
+				if sep.Has(v) {
+					continue
+				}
+				free = true
+				if compVertices.Has(v) {
+					continue
+				}
+				compVertices.Add(v)
+				for _, l := range t.containing[v] {
+					if t.member[l] == t.stamp && t.seen[l] != t.stamp {
+						t.seen[l] = t.stamp
+						queue = append(queue, l)
+					}
+				}
+			}
+			if free {
+				level.items = append(level.items, j)
+			} else if j >= t.numEdges {
+				isolated++
+			}
+		}
+		t.queue = queue
+
+		if len(level.items) > start {
+			end := len(level.items)
+			level.comps = append(level.comps, trackedComp{items: level.items[start:end:end], vertices: compVertices})
+		} else {
+			level.words = level.words[:wordStart]
+		}
+	}
+
+	return isolated
+}
@@ -0,0 +1,138 @@
+package lib
+
+import (
+	"math/bits"
+)
+
+// A VertexSet is a set of vertices, stored as a bitset indexed by the integers representing them. As the vertices of
+// a graph are numbered consecutively by its encoding, this is much faster than working on slices for dense instances.
+type VertexSet []uint64
+
+// NewVertexSet returns the set of the given vertices
+func NewVertexSet(vertices []int) VertexSet {
+	var output VertexSet
+	for _, v := range vertices {
+		output.Add(v)
+	}
+	return output
+}
+
+// VertexSet returns the set of the vertices of g, with room for all integers used by its encoding
+func (g Graph) VertexSet() VertexSet {
+	output := make(VertexSet, 0, g.Encoding().Len()/64+1)
+	for _, v := range g.Vertices() {
+		output.Add(v)
+	}
+	return output
+}
+
+// VertexSet returns the set of the vertices of all edges
+func (e Edges) VertexSet() VertexSet {
+	return NewVertexSet(e.Vertices())
+}
+
+// Add adds v to the set, growing it if needed
+func (s *VertexSet) Add(v int) {
+	i := v / 64
+	for len(*s) <= i {
+		*s = append(*s, 0)
+	}
+	(*s)[i] |= 1 << uint(v%64)
+}
+
+// Remove removes v from the set
+func (s VertexSet) Remove(v int) {
+	if i := v / 64; v >= 0 && i < len(s) {
+		s[i] &^= 1 << uint(v%64)
+	}
+}
+
+// Has returns true if v is in the set
+func (s VertexSet) Has(v int) bool {
+	i := v / 64
+	return v >= 0 && i < len(s) && s[i]&(1<<uint(v%64)) != 0
+}
+
+// Len returns the number of vertices in the set
+func (s VertexSet) Len() int {
+	output := 0
+	for _, w := range s {
+		output += bits.OnesCount64(w)
+	}
+	return output
+}
+
+// Slice returns the vertices of the set in increasing order
+func (s VertexSet) Slice() []int {
+	output := make([]int, 0, s.Len())
+	for i, w := range s {
+		for w != 0 {
+			j := bits.TrailingZeros64(w)
+			output = append(output, i*64+j)
+			w &^= 1 << uint(j)
+		}
+	}
+	return output
+}
+
+// Union returns the set of vertices in s or o
+func (s VertexSet) Union(o VertexSet) VertexSet {
+	if len(s) < len(o) {
+		s, o = o, s
+	}
+	output := append(VertexSet{}, s...)
+	for i := range o {
+		output[i] |= o[i]
+	}
+	return output
+}
+
+// Inter returns the set of vertices in both s and o
+func (s VertexSet) Inter(o VertexSet) VertexSet {
+	if len(s) > len(o) {
+		s, o = o, s
+	}
+	output := make(VertexSet, len(s))
+	for i := range s {
+		output[i] = s[i] & o[i]
+	}
+	return output
+}
+
+// Diff returns the set of vertices in s but not in o
+func (s VertexSet) Diff(o VertexSet) VertexSet {
+	output := append(VertexSet{}, s...)
+	for i := 0; i < len(output) && i < len(o); i++ {
+		output[i] &^= o[i]
+	}
+	return output
+}
+
+// Subset returns true if all vertices of s are in o
+func (s VertexSet) Subset(o VertexSet) bool {
+	for i := range s {
+		w := s[i]
+		if i < len(o) {
+			w &^= o[i]
+		}
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects returns true if s and o have some vertex in common
+func (s VertexSet) Intersects(o VertexSet) bool {
+	for i := 0; i < len(s) && i < len(o); i++ {
+		if s[i]&o[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal returns true if s and o contain the same vertices
+func (s VertexSet) Equal(o VertexSet) bool {
+	return s.Subset(o) && o.Subset(s)
+}
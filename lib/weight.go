@@ -0,0 +1,131 @@
+package lib
+
+// weight.go supports weighted edges, e.g. the sizes of the relations of a query, and measures decompositions by the
+// largest total weight of a cover instead of its number of edges
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/cem-okulmus/disjoint"
+)
+
+// GetWeight returns the weight of the edge, where edges without a weight count as 1
+func (e Edge) GetWeight() float64 {
+	if e.Weight > 0 {
+		return e.Weight
+	}
+	return 1
+}
+
+// Weight returns the total weight of the edges
+func (e Edges) Weight() float64 {
+	var output float64
+	for _, e2 := range e.slice {
+		output += e2.GetWeight()
+	}
+	return output
+}
+
+// ReadWeights reads the weights of edges from CSV lines of the form "edge name,weight"
+func ReadWeights(r io.Reader) (map[string]float64, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[string]float64)
+	for _, record := range records {
+		weight, err := strconv.ParseFloat(record[1], 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("weight of edge %v must be a positive number, not %q", record[0], record[1])
+		}
+		output[record[0]] = weight
+	}
+	return output, nil
+}
+
+// SetWeights returns a copy of the graph, with the weights of the edges set by their names. Edges not mentioned keep
+// their weight.
+func (g Graph) SetWeights(weights map[string]float64) (Graph, error) {
+	enc := g.Encoding()
+	edges := append([]Edge{}, g.Edges.Slice()...)
+
+	for name, weight := range weights {
+		id, ok := enc.ID(name)
+		found := false
+		for i := range edges {
+			if ok && edges[i].Name == id {
+				edges[i].Weight = weight
+				found = true
+			}
+		}
+		if !found {
+			return g, fmt.Errorf("edge %v of the weights not found in the graph", name)
+		}
+	}
+
+	return g.WithEdges(NewEdges(edges)), nil
+}
+
+// coverWeight returns the weight of an edge used in a cover, which may be a subedge without a name of its own. Those
+// weigh as much as the lightest edge of the graph containing them.
+func (g Graph) coverWeight(e Edge, byName map[int]Edge) float64 {
+	if orig, ok := byName[e.Name]; ok && e.Name > 0 {
+		return orig.GetWeight()
+	}
+
+	output := -1.0
+	for _, orig := range g.Edges.Slice() {
+		if Subset(e.Vertices, orig.Vertices) && (output < 0 || orig.GetWeight() < output) {
+			output = orig.GetWeight()
+		}
+	}
+	if output < 0 {
+		return e.GetWeight()
+	}
+	return output
+}
+
+// WeightedWidth returns the largest total weight of the cover of some node, using the weights of the edges of the
+// decomposed graph
+func (d Decomp) WeightedWidth() float64 {
+	byName := make(map[int]Edge)
+	for _, e := range d.Graph.Edges.Slice() {
+		byName[e.Name] = e
+	}
+
+	var output float64
+	current := []Node{d.Root}
+	for len(current) > 0 {
+		var children []Node
+		for _, n := range current {
+			var weight float64
+			for _, e := range n.Cover.Slice() {
+				weight += d.Graph.coverWeight(e, byName)
+			}
+			if weight > output {
+				output = weight
+			}
+			children = append(children, n.Children...)
+		}
+		current = children
+	}
+
+	return output
+}
+
+// WeightCheck restricts another predicate to separators of total weight at most MaxWeight
+type WeightCheck struct {
+	Inner     Predicate
+	MaxWeight float64
+}
+
+// Check performs the check of the inner predicate, for separators light enough
+func (w WeightCheck) Check(H *Graph, sep *Edges, balFactor int, Vertices map[int]*disjoint.Element) bool {
+	return sep.Weight() <= w.MaxWeight && w.Inner.Check(H, sep, balFactor, Vertices)
+}
@@ -0,0 +1,254 @@
+package lib
+
+// xcsp.go derives the constraint hypergraph of a CSP given as XCSP3 instance, with one edge per constraint scope
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// xcspElement is any element of an XCSP3 instance, kept as a generic tree
+type xcspElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr    `xml:",any,attr"`
+	Text     string        `xml:",chardata"`
+	Children []xcspElement `xml:",any"`
+}
+
+func (e xcspElement) attr(name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// allText returns the text of the element and all its descendants, separated by spaces
+func (e xcspElement) allText() string {
+	var buffer strings.Builder
+	buffer.WriteString(e.Text)
+	for _, c := range e.Children {
+		buffer.WriteString(" ")
+		buffer.WriteString(c.allText())
+	}
+	return buffer.String()
+}
+
+// xcspRef matches a reference to a variable, or to some cells of an array, such as x, y[2][], or z[0..3]
+var xcspRef = regexp.MustCompile(`[A-Za-z_][\w]*((?:\[[^\]]*\])*)`)
+
+// xcspScopes resolves the references to variables in constraints, given the variables and arrays declared
+type xcspScopes struct {
+	vars   map[string]bool
+	arrays map[string][]int // the size of each dimension
+}
+
+// scope returns the variables referenced in s, once each and in order of their first reference
+func (x xcspScopes) scope(s string) []string {
+	var output []string
+	seen := make(map[string]bool)
+	add := func(v string) {
+		if !seen[v] {
+			seen[v] = true
+			output = append(output, v)
+		}
+	}
+
+	for _, match := range xcspRef.FindAllStringSubmatchIndex(s, -1) {
+		if match[0] > 0 && s[match[0]-1] == '%' { // a parameter of a group
+			continue
+		}
+		ref, indices := s[match[0]:match[1]], s[match[2]:match[3]]
+		id := ref[:len(ref)-len(indices)]
+
+		if x.vars[id] {
+			add(id)
+			continue
+		}
+		dims, ok := x.arrays[id]
+		if !ok {
+			continue // a function or operator of an expression
+		}
+
+		var ranges [][2]int
+		if indices != "" {
+			for _, index := range strings.Split(strings.Trim(indices, "[]"), "][") {
+				ranges = append(ranges, xcspRange(index, dims[min(len(ranges), len(dims)-1)]))
+			}
+		}
+		for len(ranges) < len(dims) {
+			ranges = append(ranges, [2]int{0, dims[len(ranges)] - 1})
+		}
+		forEachCell(ranges, func(cell []int) {
+			var name strings.Builder
+			name.WriteString(id)
+			for _, i := range cell {
+				name.WriteString("[" + strconv.Itoa(i) + "]")
+			}
+			add(name.String())
+		})
+	}
+
+	return output
+}
+
+// xcspRange parses the index of an array dimension of the given size, which is either empty, a single index or a
+// range such as 2..5
+func xcspRange(index string, size int) [2]int {
+	index = strings.TrimSpace(index)
+	if index == "" {
+		return [2]int{0, size - 1}
+	}
+	bounds := strings.SplitN(index, "..", 2)
+	low, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		log.Panicln("XCSP input malformed: invalid index", index)
+	}
+	high := low
+	if len(bounds) == 2 {
+		if high, err = strconv.Atoi(bounds[1]); err != nil {
+			log.Panicln("XCSP input malformed: invalid index", index)
+		}
+	}
+	return [2]int{low, high}
+}
+
+// forEachCell calls f with the indices of each cell in the given ranges of dimensions, in lexicographic order
+func forEachCell(ranges [][2]int, f func(cell []int)) {
+	cell := make([]int, len(ranges))
+	var rec func(d int)
+	rec = func(d int) {
+		if d == len(ranges) {
+			f(cell)
+			return
+		}
+		for i := ranges[d][0]; i <= ranges[d][1]; i++ {
+			cell[d] = i
+			rec(d + 1)
+		}
+	}
+	rec(0)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// GetGraphXCSP derives the constraint hypergraph of an XCSP3 instance, with one vertex for each variable referenced
+// in a constraint, and one edge for the scope of each constraint. The cells of arrays become variables of their
+// own, named as in y[1][2]. Constraints of blocks are added one by one, and a group adds one constraint for each of
+// its arguments. Edges are named after the id of their constraint if it has one, and numbered otherwise, such as c3.
+// Constraints without any variables are left out.
+func GetGraphXCSP(reader io.Reader) Graph {
+	graph, _ := getGraphXCSP(reader)
+	return graph
+}
+
+func getGraphXCSP(reader io.Reader) (Graph, ParseGraph) {
+	var instance xcspElement
+	if err := xml.NewDecoder(reader).Decode(&instance); err != nil {
+		fmt.Println("Couldn't parse input: ")
+		panic(err)
+	}
+	if instance.XMLName.Local != "instance" {
+		log.Panicln("XCSP input malformed: expected instance, got", instance.XMLName.Local)
+	}
+
+	scopes := xcspScopes{vars: make(map[string]bool), arrays: make(map[string][]int)}
+	for _, section := range instance.Children {
+		if section.XMLName.Local != "variables" {
+			continue
+		}
+		for _, v := range section.Children {
+			switch v.XMLName.Local {
+			case "var":
+				scopes.vars[v.attr("id")] = true
+			case "array":
+				var dims []int
+				for _, size := range strings.Split(strings.Trim(v.attr("size"), "[]"), "][") {
+					n, err := strconv.Atoi(strings.TrimSpace(size))
+					if err != nil || n <= 0 {
+						log.Panicln("XCSP input malformed: invalid size", v.attr("size"), "of array", v.attr("id"))
+					}
+					dims = append(dims, n)
+				}
+				scopes.arrays[v.attr("id")] = dims
+			}
+		}
+	}
+
+	var pgraph ParseGraph
+	add := func(name string, scope []string) {
+		if len(scope) > 0 {
+			pgraph.Edges = append(pgraph.Edges, parseEdge{Name: name, Vertices: scope})
+		}
+	}
+
+	var constraints func(parent xcspElement)
+	constraints = func(parent xcspElement) {
+		for _, c := range parent.Children {
+			switch c.XMLName.Local {
+			case "block":
+				constraints(c)
+			case "group":
+				var template string
+				var args []string
+				for _, child := range c.Children {
+					if child.XMLName.Local == "args" {
+						args = append(args, child.allText())
+					} else {
+						template += " " + child.allText()
+					}
+				}
+				for _, a := range args {
+					add(c.attr("id"), scopes.scope(template+" "+a))
+				}
+			case "annotations":
+			default:
+				add(c.attr("id"), scopes.scope(c.allText()))
+			}
+		}
+	}
+	for _, section := range instance.Children {
+		if section.XMLName.Local == "constraints" {
+			constraints(section)
+		}
+	}
+	if len(pgraph.Edges) == 0 {
+		log.Panicln("XCSP input has no constraints over any variables, no hypergraph to decompose!")
+	}
+
+	// name the edges uniquely, apart from the variables
+	used := make(map[string]bool)
+	for _, e := range pgraph.Edges {
+		for _, v := range e.Vertices {
+			used[v] = true
+		}
+	}
+	counters := make(map[string]int)
+	for i := range pgraph.Edges {
+		base, separator := pgraph.Edges[i].Name, "_"
+		if base == "" {
+			base, separator = "c", ""
+		}
+		name := pgraph.Edges[i].Name
+		for name == "" || used[name] {
+			counters[base]++
+			name = fmt.Sprint(base, separator, counters[base])
+		}
+		used[name] = true
+		pgraph.Edges[i].Name = name
+	}
+
+	graph := pgraph.build()
+	return graph, pgraph
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// progress tracks the state of a running search, so that it can be reported on request (see watchSignals)
+type progress struct {
+	start  time.Time
+	solver string
+	width  int32 // the width currently searched for, updated by the exact and approx modes
+	mem    *lib.MemReport
+}
+
+// SetWidth records the width currently searched for
+func (p *progress) SetWidth(K int) {
+	atomic.StoreInt32(&p.width, int32(K))
+}
+
+func (p *progress) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("Progress after %v: %v at width %v, %v of %v goroutines busy, "+
+		"%v spawned in total (peak %v)\n", time.Since(p.start).Round(time.Millisecond), p.solver,
+		atomic.LoadInt32(&p.width), algo.Busy(), algo.Procs(), lib.Goroutines.Running(), lib.Goroutines.Peak()))
+	buffer.WriteString(lib.Stats.String())
+	if p.mem != nil {
+		buffer.WriteString(p.mem.String())
+	}
+
+	return buffer.String()
+}
+
+// Start prints the progress to w in the given interval, until the returned stop function is called
+func (p *progress) Start(interval time.Duration, w io.Writer) func() {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprint(w, p.String())
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// selfCheck compares the result of an algorithm with the width computed by brute force, for graphs small enough,
+// and panics on any contradiction. Decompositions found must never be narrower than the width. If complete is set,
+// the algorithm is meant to find a GHD of every width not below it, and of exactly it in exact mode.
+func selfCheck(graph lib.Graph, decomp lib.Decomp, width int, exact, complete bool) {
+	ghw, ok := lib.BruteForceWidth(graph)
+	if !ok {
+		fmt.Println("Self-check skipped, the graph has more than", lib.BruteForceLimit, "vertices")
+		return
+	}
+	fmt.Println("Self-check: generalized hypertree width by brute force is", ghw)
+
+	found := decomp.Found()
+	switch {
+	case found && decomp.CheckWidth() < ghw:
+		log.Panicln("Self-check failed: decomposition of width", decomp.CheckWidth(), "found")
+	case found && exact && complete && decomp.CheckWidth() != ghw:
+		log.Panicln("Self-check failed: exact width", decomp.CheckWidth(), "computed")
+	case !found && !exact && complete && width >= ghw:
+		log.Panicln("Self-check failed: no decomposition of width", width, "found")
+	}
+	fmt.Println("Self-check passed")
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// The seps subcommand enumerates the balanced separators of a graph with at most k edges, and prints each as the set
+// of its edge names on a line of its own, as soon as it's found:
+//
+//	BalancedGo seps -graph query.hg -k 3 [-balfactor 2] [-count] [-checkpoint state.json]
+//
+// With a checkpoint file, the state of the search is written to it periodically and once the search stops, and a
+// search started with an existing checkpoint file resumes from it, so that it can be continued after a crash or
+// preemption. Separators found after the last checkpoint are printed again when resuming.
+//
+// With -listen, the search is distributed over workers on other machines instead, see distributed.go.
+//
+// The exit code is 0 once the search is done, and 2 if the input or the checkpoint couldn't be read.
+
+// seps runs the seps subcommand on the given arguments, and returns the exit code
+func seps(args []string) int {
+	flagSet := flag.NewFlagSet("seps", flag.ContinueOnError)
+	graphPath := flagSet.String("graph", "", "The hypergraph to look for balanced separators in")
+	format := flagSet.String("format", "hyperbench", "Input format of the hypergraph, one of: "+
+		strings.Join(lib.Formats(), ", "))
+	width := flagSet.Int("k", 0, "The largest number of edges in a separator")
+	balFactor := flagSet.Int("balfactor", 2, "Components of the graph minus a separator have at most "+
+		"(b-1)/b of its edges, for balance factor b of at least 2")
+	count := flagSet.Bool("count", false, "Only print the number of separators, not the separators themselves")
+	sequential := flagSet.Bool("seq", false, "Search sequentially, printing the separators in a fixed order")
+	timeout := flagSet.Int("timeout", 0, "If positive, stop the search after this many seconds")
+	checkpoint := flagSet.String("checkpoint", "", "Save the state of the search into the given file, and resume "+
+		"from it if it exists")
+	checkpointInterval := flagSet.Duration("checkpointInterval", time.Minute, "Used in combination with "+
+		"\"checkpoint\": how often the state is saved")
+	listen := flagSet.String("listen", "", "Coordinate the search on workers connecting to the given address, such "+
+		"as :7070, instead of searching locally")
+	units := flagSet.Int("units", 64, "Used in combination with \"listen\": number of units the search is split "+
+		"into at the start")
+	reportInterval := flagSet.Duration("reportInterval", 5*time.Second, "Used in combination with \"listen\": how "+
+		"often the workers report")
+
+	if err := flagSet.Parse(args); err != nil || *graphPath == "" || *width <= 0 ||
+		lib.CheckBalFactor(*balFactor) != nil || *checkpointInterval <= 0 ||
+		*listen != "" && (*sequential || *checkpoint != "" || *units <= 0 || *reportInterval <= 0) {
+		fmt.Fprintln(os.Stderr, "Usage: BalancedGo seps -graph <graph> -k <k> [-balfactor <b>] [-count] "+
+			"[-checkpoint <file> | -listen <address>]")
+		flagSet.SetOutput(os.Stderr)
+		flagSet.PrintDefaults()
+		return 2
+	}
+
+	dat, err := ioutil.ReadFile(*graphPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var graph lib.Graph
+	func() {
+		defer func() {
+			if r := recover(); r != nil { // the parsers panic on malformed input
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		graph, _, err = lib.GetGraphFormat(*format, string(dat))
+	}()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't read the hypergraph:", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*timeout)*time.Second)
+		defer cancel()
+	}
+
+	if *listen != "" {
+		total := 0
+		c := newCoordinator(graph, *width, *balFactor, *units, *reportInterval, func(sep []int) {
+			total++
+			if !*count {
+				fmt.Println(lib.GetSubset(graph.Edges, sep))
+			}
+		})
+		if err := coordinate(ctx, *listen, c); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		if *count {
+			fmt.Println(total)
+		}
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "Search timed out after", total, "separators")
+		}
+		return 0
+	}
+
+	it := lib.BalancedSeparators(ctx, graph, *width, *balFactor, *sequential)
+	if *checkpoint != "" {
+		if state, err := ioutil.ReadFile(*checkpoint); err == nil {
+			if err = it.Restore(state); err != nil {
+				fmt.Fprintln(os.Stderr, "Couldn't resume from checkpoint:", err)
+				return 2
+			}
+		} else if !os.IsNotExist(err) {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		it.Checkpoint(*checkpointInterval, func(state []byte) { saveCheckpoint(*checkpoint, state) })
+	}
+
+	for it.Next() {
+		if !*count {
+			fmt.Println(it.Separator())
+		}
+	}
+	if *count {
+		fmt.Println(it.Count())
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Search timed out after", it.Count(), "separators")
+	}
+	if *checkpoint != "" {
+		saveCheckpoint(*checkpoint, it.Save())
+	}
+
+	return 0
+}
+
+// saveCheckpoint replaces the checkpoint file with the given state, such that it's never left partially written
+func saveCheckpoint(path string, state []byte) {
+	temp := path + ".tmp"
+	if err := ioutil.WriteFile(temp, state, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't save checkpoint:", err)
+		return
+	}
+	if err := os.Rename(temp, path); err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't save checkpoint:", err)
+	}
+}
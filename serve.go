@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// maxRequestSize bounds the size of the body of a request to the server, in bytes
+const maxRequestSize = 64 << 20
+
+// A serveRequest asks the server to decompose a hypergraph. The graph is either a string in the given format, or a
+// JSON object mapping each edge name to its vertices, as parsed by lib.GetGraphJSON.
+type serveRequest struct {
+	Graph     jsoniter.RawMessage `json:"graph"`
+	Format    string              `json:"format"`    // format of the graph if given as string, hyperbench by default
+	Width     int                 `json:"width"`     // the width to decompose the graph with
+	Algorithm string              `json:"algorithm"` // one of algorithms.Names(), det by default
+	Timeout   string              `json:"timeout"`   // such as 30s, bounded by the timeout of the server
+	BalFactor int                 `json:"balfactor"` // balance factor of the separators, that of the server by default
+}
+
+// A serveResponse reports the outcome of a request, together with the decomposition if one was found
+type serveResponse struct {
+	Algorithm string              `json:"algorithm"`
+	Width     int                 `json:"width"`
+	Found     bool                `json:"found"`
+	Correct   bool                `json:"correct"`
+	Result    int                 `json:"resultWidth"` // the width of the decomposition found, if any
+	TimedOut  bool                `json:"timedOut"`
+	Millis    float64             `json:"ms"`
+	Decomp    jsoniter.RawMessage `json:"decomp,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// A decompServer answers requests to decompose hypergraphs over HTTP, running at most as many decompositions at the
+// same time as there are slots in jobs
+type decompServer struct {
+	jobs      chan struct{} // nil if there is no limit
+	balFactor int
+	timeout   time.Duration // upper bound on the time spent per request, none if not positive
+}
+
+// serve starts an HTTP server listening on addr, answering POST requests to /decompose, see serveRequest. At most
+// maxJobs decompositions are run at the same time, no limit applying if not positive, and further requests are
+// turned away with status 503. Each decomposition is cancelled once the client disconnects or the timeout passes.
+func serve(addr string, maxJobs, balFactor int, timeout time.Duration) error {
+	server := &decompServer{balFactor: balFactor, timeout: timeout}
+	if maxJobs > 0 {
+		server.jobs = make(chan struct{}, maxJobs)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/decompose", server.handleDecompose)
+
+	fmt.Println("Serving decompositions on", addr+"/decompose")
+	return http.ListenAndServe(addr, mux)
+}
+
+func (s *decompServer) handleDecompose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		s.reply(w, http.StatusMethodNotAllowed, serveResponse{Error: "only POST is supported"})
+		return
+	}
+
+	if s.jobs != nil {
+		select {
+		case s.jobs <- struct{}{}:
+			defer func() { <-s.jobs }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			s.reply(w, http.StatusServiceUnavailable, serveResponse{Error: "too many concurrent jobs"})
+			return
+		}
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		s.reply(w, http.StatusBadRequest, serveResponse{Error: err.Error()})
+		return
+	}
+	var req serveRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.reply(w, http.StatusBadRequest, serveResponse{Error: "malformed request: " + err.Error()})
+		return
+	}
+
+	status, output := s.decompose(r.Context(), req)
+	s.reply(w, status, output)
+}
+
+// decompose runs the request, bounded by ctx, and returns the HTTP status to reply with. A panic of the algorithm is
+// only answered with status 500 if it happens on the goroutine of the request, one in the goroutines of its searches or
+// components still ends the server.
+func (s *decompServer) decompose(ctx context.Context, req serveRequest) (status int, output serveResponse) {
+	if req.Algorithm == "" {
+		req.Algorithm = "det"
+	}
+	if req.BalFactor <= 0 {
+		req.BalFactor = s.balFactor
+	}
+	output.Algorithm, output.Width = req.Algorithm, req.Width
+
+	if req.Width <= 0 {
+		output.Error = "width must be positive"
+		return http.StatusBadRequest, output
+	}
+	if err := lib.CheckBalFactor(req.BalFactor); err != nil {
+		output.Error = err.Error()
+		return http.StatusBadRequest, output
+	}
+	timeout := s.timeout
+	if req.Timeout != "" {
+		requested, err := time.ParseDuration(req.Timeout)
+		if err != nil || requested <= 0 {
+			output.Error = fmt.Sprintf("invalid timeout %q", req.Timeout)
+			return http.StatusBadRequest, output
+		}
+		if timeout <= 0 || requested < timeout {
+			timeout = requested
+		}
+	}
+
+	graph, err := parseServeGraph(req)
+	if err != nil {
+		output.Error = err.Error()
+		return http.StatusBadRequest, output
+	}
+	solver, err := benchSolver(req.Algorithm, graph, req.Width, req.BalFactor)
+	if err != nil {
+		output.Error = err.Error()
+		return http.StatusBadRequest, output
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	solver.SetGenerator(lib.ParallelSearchGen{Ctx: ctx})
+
+	defer func() {
+		if r := recover(); r != nil {
+			output.Error = fmt.Sprint("decomposition failed: ", r)
+			status = http.StatusInternalServerError
+		}
+	}()
+
+	start := time.Now()
+	decomp := solver.FindDecomp()
+	output.Millis = float64(time.Since(start)) / float64(time.Millisecond)
+	output.TimedOut = ctx.Err() != nil
+
+	if decomp.Found() {
+		decomp.Graph = graph
+		decomp.RestoreSubedges()
+		output.Found = true
+		output.Correct = decomp.Correct(graph)
+		output.Result = decomp.CheckWidth()
+		output.Decomp = lib.WriteDecomp(decomp)
+	}
+
+	return http.StatusOK, output
+}
+
+// parseServeGraph parses the graph of the request, turning a panic of the parser into an error
+func parseServeGraph(req serveRequest) (graph lib.Graph, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed graph: %v", r)
+		}
+	}()
+
+	raw := bytes.TrimSpace(req.Graph)
+	if len(raw) == 0 {
+		return lib.Graph{}, fmt.Errorf("no graph given")
+	}
+	if raw[0] == '{' {
+		graph, _ = lib.GetGraphJSON(string(raw))
+		return graph, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return lib.Graph{}, fmt.Errorf("graph must be a string or a JSON object")
+	}
+	if req.Format == "" {
+		req.Format = "hyperbench"
+	}
+	graph, _, err = lib.GetGraphFormat(req.Format, s)
+	return graph, err
+}
+
+func (s *decompServer) reply(w http.ResponseWriter, status int, output serveResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(output); err != nil {
+		fmt.Println("Couldn't write response: ", err)
+	}
+}
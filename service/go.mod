@@ -0,0 +1,25 @@
+module github.com/cem-okulmus/BalancedGo/service
+
+go 1.19
+
+require (
+	github.com/cem-okulmus/BalancedGo v1.6.13
+	google.golang.org/grpc v1.60.1
+	google.golang.org/protobuf v1.31.0
+)
+
+require (
+	github.com/alecthomas/participle v0.3.0 // indirect
+	github.com/cem-okulmus/disjoint v1.1.2 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/go-cmp v0.5.9 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	golang.org/x/net v0.16.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20231002182017-d307bd883b97 // indirect
+)
+
+replace github.com/cem-okulmus/BalancedGo => ../
@@ -0,0 +1,51 @@
+// The Decomposer service offers the algorithms of BalancedGo over gRPC, see decomposer.proto for its definition:
+//
+//	go run . -addr :9090 -maxJobs 4
+//
+// The code in pb is generated from the definition with
+//
+//	protoc --go_out=. --go_opt=module=github.com/cem-okulmus/BalancedGo/service \
+//		--go-grpc_out=. --go-grpc_opt=module=github.com/cem-okulmus/BalancedGo/service decomposer.proto
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/BalancedGo/service/pb"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "the address to listen on")
+	maxJobs := flag.Int("maxJobs", 0, "maximal number of decompositions run at the same time, further requests are "+
+		"turned away (no limit if 0)")
+	balFactor := flag.Int("balfactor", 2, "the balance factor used unless a request sets its own")
+	timeout := flag.Duration("timeout", 0, "upper bound on the time spent per request (e.g. 10m), none if 0")
+
+	flag.Parse()
+	if err := lib.CheckBalFactor(*balFactor); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Println("Couldn't listen: ", err)
+		os.Exit(1)
+	}
+
+	server := grpc.NewServer()
+	pb.RegisterDecomposerServer(server, newDecomposer(*maxJobs, *balFactor, *timeout))
+
+	fmt.Println("Serving decompositions on", listener.Addr())
+	start := time.Now()
+	if err := server.Serve(listener); err != nil {
+		fmt.Println("Server stopped after", time.Since(start), ": ", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,1148 @@
+// The Decomposer service computes hypergraph decompositions with BalancedGo, for embedding the solver into
+// long-running services such as query planners. Graphs are given as text in any of the input formats of BalancedGo,
+// decompositions are returned in its JSON format.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: decomposer.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DecomposeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Graph           string `protobuf:"bytes,1,opt,name=graph,proto3" json:"graph,omitempty"`
+	Format          string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`                                             // input format of the graph, hyperbench by default
+	Width           int32  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`                                              // the width to decompose the graph with, or the largest width tried if exact is set
+	Exact           bool   `protobuf:"varint,4,opt,name=exact,proto3" json:"exact,omitempty"`                                              // try all widths from 1 up to width in order, so that the result is of the smallest
+	Algorithm       string `protobuf:"bytes,5,opt,name=algorithm,proto3" json:"algorithm,omitempty"`                                       // one of local, global, det, balDet, vertex or greedy, det by default
+	TimeoutMs       int64  `protobuf:"varint,6,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`                     // bounded by the timeout of the server, none if not positive
+	BalFactor       int32  `protobuf:"varint,7,opt,name=bal_factor,json=balFactor,proto3" json:"bal_factor,omitempty"`                     // balance factor of the separators, that of the server by default
+	StatsIntervalMs int64  `protobuf:"varint,8,opt,name=stats_interval_ms,json=statsIntervalMs,proto3" json:"stats_interval_ms,omitempty"` // time between cache statistics, 1s by default
+}
+
+func (x *DecomposeRequest) Reset() {
+	*x = DecomposeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DecomposeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecomposeRequest) ProtoMessage() {}
+
+func (x *DecomposeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecomposeRequest.ProtoReflect.Descriptor instead.
+func (*DecomposeRequest) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DecomposeRequest) GetGraph() string {
+	if x != nil {
+		return x.Graph
+	}
+	return ""
+}
+
+func (x *DecomposeRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *DecomposeRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *DecomposeRequest) GetExact() bool {
+	if x != nil {
+		return x.Exact
+	}
+	return false
+}
+
+func (x *DecomposeRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *DecomposeRequest) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *DecomposeRequest) GetBalFactor() int32 {
+	if x != nil {
+		return x.BalFactor
+	}
+	return 0
+}
+
+func (x *DecomposeRequest) GetStatsIntervalMs() int64 {
+	if x != nil {
+		return x.StatsIntervalMs
+	}
+	return 0
+}
+
+type DecomposeEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*DecomposeEvent_Bounds
+	//	*DecomposeEvent_Subproblem
+	//	*DecomposeEvent_Cache
+	//	*DecomposeEvent_Result
+	Event isDecomposeEvent_Event `protobuf_oneof:"event"`
+}
+
+func (x *DecomposeEvent) Reset() {
+	*x = DecomposeEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DecomposeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecomposeEvent) ProtoMessage() {}
+
+func (x *DecomposeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecomposeEvent.ProtoReflect.Descriptor instead.
+func (*DecomposeEvent) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *DecomposeEvent) GetEvent() isDecomposeEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *DecomposeEvent) GetBounds() *Bounds {
+	if x, ok := x.GetEvent().(*DecomposeEvent_Bounds); ok {
+		return x.Bounds
+	}
+	return nil
+}
+
+func (x *DecomposeEvent) GetSubproblem() *Subproblem {
+	if x, ok := x.GetEvent().(*DecomposeEvent_Subproblem); ok {
+		return x.Subproblem
+	}
+	return nil
+}
+
+func (x *DecomposeEvent) GetCache() *CacheStats {
+	if x, ok := x.GetEvent().(*DecomposeEvent_Cache); ok {
+		return x.Cache
+	}
+	return nil
+}
+
+func (x *DecomposeEvent) GetResult() *Result {
+	if x, ok := x.GetEvent().(*DecomposeEvent_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+type isDecomposeEvent_Event interface {
+	isDecomposeEvent_Event()
+}
+
+type DecomposeEvent_Bounds struct {
+	Bounds *Bounds `protobuf:"bytes,1,opt,name=bounds,proto3,oneof"`
+}
+
+type DecomposeEvent_Subproblem struct {
+	Subproblem *Subproblem `protobuf:"bytes,2,opt,name=subproblem,proto3,oneof"`
+}
+
+type DecomposeEvent_Cache struct {
+	Cache *CacheStats `protobuf:"bytes,3,opt,name=cache,proto3,oneof"`
+}
+
+type DecomposeEvent_Result struct {
+	Result *Result `protobuf:"bytes,4,opt,name=result,proto3,oneof"`
+}
+
+func (*DecomposeEvent_Bounds) isDecomposeEvent_Event() {}
+
+func (*DecomposeEvent_Subproblem) isDecomposeEvent_Event() {}
+
+func (*DecomposeEvent_Cache) isDecomposeEvent_Event() {}
+
+func (*DecomposeEvent_Result) isDecomposeEvent_Event() {}
+
+// Bounds on the width computed by the algorithm. The lower bound is only raised by algorithms that are complete,
+// i.e. local, global, det and balDet, when they fail at some width.
+type Bounds struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lower int32 `protobuf:"varint,1,opt,name=lower,proto3" json:"lower,omitempty"`
+	Upper int32 `protobuf:"varint,2,opt,name=upper,proto3" json:"upper,omitempty"` // 0 if no decomposition has been found yet
+}
+
+func (x *Bounds) Reset() {
+	*x = Bounds{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Bounds) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bounds) ProtoMessage() {}
+
+func (x *Bounds) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bounds.ProtoReflect.Descriptor instead.
+func (*Bounds) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Bounds) GetLower() int32 {
+	if x != nil {
+		return x.Lower
+	}
+	return 0
+}
+
+func (x *Bounds) GetUpper() int32 {
+	if x != nil {
+		return x.Upper
+	}
+	return 0
+}
+
+// A subgraph decomposed during the search, reported by local, global and det only
+type Subproblem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Width    int32 `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"` // the width searched for
+	Edges    int32 `protobuf:"varint,2,opt,name=edges,proto3" json:"edges,omitempty"` // of the subgraph, including special edges
+	Vertices int32 `protobuf:"varint,3,opt,name=vertices,proto3" json:"vertices,omitempty"`
+	Count    int64 `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"` // subgraphs decomposed so far at this width
+}
+
+func (x *Subproblem) Reset() {
+	*x = Subproblem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Subproblem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subproblem) ProtoMessage() {}
+
+func (x *Subproblem) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subproblem.ProtoReflect.Descriptor instead.
+func (*Subproblem) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Subproblem) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Subproblem) GetEdges() int32 {
+	if x != nil {
+		return x.Edges
+	}
+	return 0
+}
+
+func (x *Subproblem) GetVertices() int32 {
+	if x != nil {
+		return x.Vertices
+	}
+	return 0
+}
+
+func (x *Subproblem) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type CacheStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lookups           int64 `protobuf:"varint,1,opt,name=lookups,proto3" json:"lookups,omitempty"` // lookups in caches by all jobs of the server
+	Hits              int64 `protobuf:"varint,2,opt,name=hits,proto3" json:"hits,omitempty"`
+	SeparatorsChecked int64 `protobuf:"varint,3,opt,name=separators_checked,json=separatorsChecked,proto3" json:"separators_checked,omitempty"`
+}
+
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStats) ProtoMessage() {}
+
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CacheStats) GetLookups() int64 {
+	if x != nil {
+		return x.Lookups
+	}
+	return 0
+}
+
+func (x *CacheStats) GetHits() int64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetSeparatorsChecked() int64 {
+	if x != nil {
+		return x.SeparatorsChecked
+	}
+	return 0
+}
+
+type Result struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Found    bool    `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Correct  bool    `protobuf:"varint,2,opt,name=correct,proto3" json:"correct,omitempty"`
+	Width    int32   `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"` // of the decomposition found, if any
+	TimedOut bool    `protobuf:"varint,4,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	Ms       float64 `protobuf:"fixed64,5,opt,name=ms,proto3" json:"ms,omitempty"`
+	Decomp   string  `protobuf:"bytes,6,opt,name=decomp,proto3" json:"decomp,omitempty"` // as JSON
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Result) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *Result) GetCorrect() bool {
+	if x != nil {
+		return x.Correct
+	}
+	return false
+}
+
+func (x *Result) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Result) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+func (x *Result) GetMs() float64 {
+	if x != nil {
+		return x.Ms
+	}
+	return 0
+}
+
+func (x *Result) GetDecomp() string {
+	if x != nil {
+		return x.Decomp
+	}
+	return ""
+}
+
+type VerifyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Graph        string `protobuf:"bytes,1,opt,name=graph,proto3" json:"graph,omitempty"`
+	Format       string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"` // input format of the graph, hyperbench by default
+	Decomp       string `protobuf:"bytes,3,opt,name=decomp,proto3" json:"decomp,omitempty"`
+	DecompFormat string `protobuf:"bytes,4,opt,name=decomp_format,json=decompFormat,proto3" json:"decomp_format,omitempty"` // one of json, gml or htd, json by default
+	MaxWidth     int32  `protobuf:"varint,5,opt,name=max_width,json=maxWidth,proto3" json:"max_width,omitempty"`            // if positive, the decomposition is only valid if its width is at most this
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyRequest) GetGraph() string {
+	if x != nil {
+		return x.Graph
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetDecomp() string {
+	if x != nil {
+		return x.Decomp
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetDecompFormat() string {
+	if x != nil {
+		return x.DecompFormat
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetMaxWidth() int32 {
+	if x != nil {
+		return x.MaxWidth
+	}
+	return 0
+}
+
+type VerifyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid        bool     `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	SameGraph    bool     `protobuf:"varint,2,opt,name=same_graph,json=sameGraph,proto3" json:"same_graph,omitempty"`
+	BagsInCovers bool     `protobuf:"varint,3,opt,name=bags_in_covers,json=bagsInCovers,proto3" json:"bags_in_covers,omitempty"`
+	EdgesCovered bool     `protobuf:"varint,4,opt,name=edges_covered,json=edgesCovered,proto3" json:"edges_covered,omitempty"`
+	Connected    bool     `protobuf:"varint,5,opt,name=connected,proto3" json:"connected,omitempty"`
+	Width        int32    `protobuf:"varint,6,opt,name=width,proto3" json:"width,omitempty"`
+	Problems     []string `protobuf:"bytes,7,rep,name=problems,proto3" json:"problems,omitempty"`
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerifyResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetSameGraph() bool {
+	if x != nil {
+		return x.SameGraph
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetBagsInCovers() bool {
+	if x != nil {
+		return x.BagsInCovers
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetEdgesCovered() bool {
+	if x != nil {
+		return x.EdgesCovered
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *VerifyResponse) GetProblems() []string {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{8}
+}
+
+type StatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Running           int32  `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`                // jobs running at the moment
+	MaxJobs           int32  `protobuf:"varint,2,opt,name=max_jobs,json=maxJobs,proto3" json:"max_jobs,omitempty"` // 0 if there is no limit
+	Served            int64  `protobuf:"varint,3,opt,name=served,proto3" json:"served,omitempty"`                  // jobs finished so far
+	SeparatorsChecked int64  `protobuf:"varint,4,opt,name=separators_checked,json=separatorsChecked,proto3" json:"separators_checked,omitempty"`
+	CacheLookups      int64  `protobuf:"varint,5,opt,name=cache_lookups,json=cacheLookups,proto3" json:"cache_lookups,omitempty"`
+	CacheHits         int64  `protobuf:"varint,6,opt,name=cache_hits,json=cacheHits,proto3" json:"cache_hits,omitempty"`
+	Goroutines        int32  `protobuf:"varint,7,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	HeapBytes         uint64 `protobuf:"varint,8,opt,name=heap_bytes,json=heapBytes,proto3" json:"heap_bytes,omitempty"`
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_decomposer_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_decomposer_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_decomposer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StatsResponse) GetRunning() int32 {
+	if x != nil {
+		return x.Running
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetMaxJobs() int32 {
+	if x != nil {
+		return x.MaxJobs
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetServed() int64 {
+	if x != nil {
+		return x.Served
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetSeparatorsChecked() int64 {
+	if x != nil {
+		return x.SeparatorsChecked
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetCacheLookups() int64 {
+	if x != nil {
+		return x.CacheLookups
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetCacheHits() int64 {
+	if x != nil {
+		return x.CacheHits
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetGoroutines() int32 {
+	if x != nil {
+		return x.Goroutines
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetHeapBytes() uint64 {
+	if x != nil {
+		return x.HeapBytes
+	}
+	return 0
+}
+
+var File_decomposer_proto protoreflect.FileDescriptor
+
+var file_decomposer_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x64, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0a, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x67, 0x6f, 0x22, 0xf4,
+	0x01, 0x0a, 0x10, 0x44, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x61, 0x70, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x61, 0x70, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x78, 0x61, 0x63, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x78, 0x61, 0x63, 0x74, 0x12, 0x1c, 0x0a,
+	0x09, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61,
+	0x6c, 0x5f, 0x66, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09,
+	0x62, 0x61, 0x6c, 0x46, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x73, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x49, 0x6e, 0x74, 0x65, 0x72,
+	0x76, 0x61, 0x6c, 0x4d, 0x73, 0x22, 0xdf, 0x01, 0x0a, 0x0e, 0x44, 0x65, 0x63, 0x6f, 0x6d, 0x70,
+	0x6f, 0x73, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2c, 0x0a, 0x06, 0x62, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x67, 0x6f, 0x2e, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x48, 0x00, 0x52, 0x06,
+	0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x38, 0x0a, 0x0a, 0x73, 0x75, 0x62, 0x70, 0x72, 0x6f,
+	0x62, 0x6c, 0x65, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x62, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x64, 0x67, 0x6f, 0x2e, 0x53, 0x75, 0x62, 0x70, 0x72, 0x6f, 0x62, 0x6c,
+	0x65, 0x6d, 0x48, 0x00, 0x52, 0x0a, 0x73, 0x75, 0x62, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
+	0x12, 0x2e, 0x0a, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x67, 0x6f, 0x2e, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x48, 0x00, 0x52, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65,
+	0x12, 0x2c, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x67, 0x6f, 0x2e, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x07,
+	0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x34, 0x0a, 0x06, 0x42, 0x6f, 0x75, 0x6e, 0x64,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x77, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x6c, 0x6f, 0x77, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x70, 0x70, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x75, 0x70, 0x70, 0x65, 0x72, 0x22, 0x6a, 0x0a,
+	0x0a, 0x53, 0x75, 0x62, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x77,
+	0x69, 0x64, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74,
+	0x68, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x64, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x65, 0x64, 0x67, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x72, 0x74, 0x69,
+	0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x76, 0x65, 0x72, 0x74, 0x69,
+	0x63, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x69, 0x0a, 0x0a, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x6f, 0x6b, 0x75,
+	0x70, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x68, 0x69, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x73, 0x65, 0x70, 0x61, 0x72, 0x61, 0x74,
+	0x6f, 0x72, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x11, 0x73, 0x65, 0x70, 0x61, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x65, 0x64, 0x22, 0x93, 0x01, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05,
+	0x66, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x63, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x5f, 0x6f,
+	0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x4f,
+	0x75, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x02,
+	0x6d, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x22, 0x97, 0x01, 0x0a, 0x0d, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x67, 0x72, 0x61, 0x70, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x61,
+	0x70, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65,
+	0x63, 0x6f, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x63, 0x6f,
+	0x6d, 0x70, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x5f, 0x66, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65, 0x63, 0x6f, 0x6d,
+	0x70, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x77,
+	0x69, 0x64, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x57,
+	0x69, 0x64, 0x74, 0x68, 0x22, 0xe0, 0x01, 0x0a, 0x0e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x61, 0x6d, 0x65, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x73, 0x61, 0x6d, 0x65, 0x47, 0x72, 0x61, 0x70, 0x68, 0x12, 0x24, 0x0a, 0x0e,
+	0x62, 0x61, 0x67, 0x73, 0x5f, 0x69, 0x6e, 0x5f, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x62, 0x61, 0x67, 0x73, 0x49, 0x6e, 0x43, 0x6f, 0x76, 0x65,
+	0x72, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x64, 0x67, 0x65, 0x73, 0x5f, 0x63, 0x6f, 0x76, 0x65,
+	0x72, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x65, 0x64, 0x67, 0x65, 0x73,
+	0x43, 0x6f, 0x76, 0x65, 0x72, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x70,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x22, 0x0e, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x8e, 0x02, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75, 0x6e,
+	0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x72, 0x75, 0x6e, 0x6e,
+	0x69, 0x6e, 0x67, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x6a, 0x6f, 0x62, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x4a, 0x6f, 0x62, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x73, 0x65, 0x70, 0x61, 0x72, 0x61,
+	0x74, 0x6f, 0x72, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x11, 0x73, 0x65, 0x70, 0x61, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x6c,
+	0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x63, 0x61,
+	0x63, 0x68, 0x65, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x61,
+	0x63, 0x68, 0x65, 0x5f, 0x68, 0x69, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x63, 0x61, 0x63, 0x68, 0x65, 0x48, 0x69, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x6f, 0x72,
+	0x6f, 0x75, 0x74, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x67,
+	0x6f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x65, 0x61,
+	0x70, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x68,
+	0x65, 0x61, 0x70, 0x42, 0x79, 0x74, 0x65, 0x73, 0x32, 0xd4, 0x01, 0x0a, 0x0a, 0x44, 0x65, 0x63,
+	0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x12, 0x47, 0x0a, 0x09, 0x44, 0x65, 0x63, 0x6f, 0x6d,
+	0x70, 0x6f, 0x73, 0x65, 0x12, 0x1c, 0x2e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x67,
+	0x6f, 0x2e, 0x44, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x67, 0x6f, 0x2e,
+	0x44, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x73, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01,
+	0x12, 0x3f, 0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x19, 0x2e, 0x62, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x64, 0x67, 0x6f, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64,
+	0x67, 0x6f, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x3c, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x2e, 0x62, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x64, 0x67, 0x6f, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x67,
+	0x6f, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
+	0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x65,
+	0x6d, 0x2d, 0x6f, 0x6b, 0x75, 0x6c, 0x6d, 0x75, 0x73, 0x2f, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63,
+	0x65, 0x64, 0x47, 0x6f, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_decomposer_proto_rawDescOnce sync.Once
+	file_decomposer_proto_rawDescData = file_decomposer_proto_rawDesc
+)
+
+func file_decomposer_proto_rawDescGZIP() []byte {
+	file_decomposer_proto_rawDescOnce.Do(func() {
+		file_decomposer_proto_rawDescData = protoimpl.X.CompressGZIP(file_decomposer_proto_rawDescData)
+	})
+	return file_decomposer_proto_rawDescData
+}
+
+var file_decomposer_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_decomposer_proto_goTypes = []interface{}{
+	(*DecomposeRequest)(nil), // 0: balancedgo.DecomposeRequest
+	(*DecomposeEvent)(nil),   // 1: balancedgo.DecomposeEvent
+	(*Bounds)(nil),           // 2: balancedgo.Bounds
+	(*Subproblem)(nil),       // 3: balancedgo.Subproblem
+	(*CacheStats)(nil),       // 4: balancedgo.CacheStats
+	(*Result)(nil),           // 5: balancedgo.Result
+	(*VerifyRequest)(nil),    // 6: balancedgo.VerifyRequest
+	(*VerifyResponse)(nil),   // 7: balancedgo.VerifyResponse
+	(*StatsRequest)(nil),     // 8: balancedgo.StatsRequest
+	(*StatsResponse)(nil),    // 9: balancedgo.StatsResponse
+}
+var file_decomposer_proto_depIdxs = []int32{
+	2, // 0: balancedgo.DecomposeEvent.bounds:type_name -> balancedgo.Bounds
+	3, // 1: balancedgo.DecomposeEvent.subproblem:type_name -> balancedgo.Subproblem
+	4, // 2: balancedgo.DecomposeEvent.cache:type_name -> balancedgo.CacheStats
+	5, // 3: balancedgo.DecomposeEvent.result:type_name -> balancedgo.Result
+	0, // 4: balancedgo.Decomposer.Decompose:input_type -> balancedgo.DecomposeRequest
+	6, // 5: balancedgo.Decomposer.Verify:input_type -> balancedgo.VerifyRequest
+	8, // 6: balancedgo.Decomposer.Stats:input_type -> balancedgo.StatsRequest
+	1, // 7: balancedgo.Decomposer.Decompose:output_type -> balancedgo.DecomposeEvent
+	7, // 8: balancedgo.Decomposer.Verify:output_type -> balancedgo.VerifyResponse
+	9, // 9: balancedgo.Decomposer.Stats:output_type -> balancedgo.StatsResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_decomposer_proto_init() }
+func file_decomposer_proto_init() {
+	if File_decomposer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_decomposer_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecomposeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecomposeEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Bounds); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Subproblem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CacheStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Result); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_decomposer_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_decomposer_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*DecomposeEvent_Bounds)(nil),
+		(*DecomposeEvent_Subproblem)(nil),
+		(*DecomposeEvent_Cache)(nil),
+		(*DecomposeEvent_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_decomposer_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_decomposer_proto_goTypes,
+		DependencyIndexes: file_decomposer_proto_depIdxs,
+		MessageInfos:      file_decomposer_proto_msgTypes,
+	}.Build()
+	File_decomposer_proto = out.File
+	file_decomposer_proto_rawDesc = nil
+	file_decomposer_proto_goTypes = nil
+	file_decomposer_proto_depIdxs = nil
+}
@@ -0,0 +1,221 @@
+// The Decomposer service computes hypergraph decompositions with BalancedGo, for embedding the solver into
+// long-running services such as query planners. Graphs are given as text in any of the input formats of BalancedGo,
+// decompositions are returned in its JSON format.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: decomposer.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Decomposer_Decompose_FullMethodName = "/balancedgo.Decomposer/Decompose"
+	Decomposer_Verify_FullMethodName    = "/balancedgo.Decomposer/Verify"
+	Decomposer_Stats_FullMethodName     = "/balancedgo.Decomposer/Stats"
+)
+
+// DecomposerClient is the client API for Decomposer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DecomposerClient interface {
+	// Decompose streams events on the progress of the search, ending with the result
+	Decompose(ctx context.Context, in *DecomposeRequest, opts ...grpc.CallOption) (Decomposer_DecomposeClient, error)
+	// Verify checks a decomposition against a graph, without running any algorithm
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	// Stats reports on the jobs run by the server, and the work done by the searches of all of them
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+}
+
+type decomposerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDecomposerClient(cc grpc.ClientConnInterface) DecomposerClient {
+	return &decomposerClient{cc}
+}
+
+func (c *decomposerClient) Decompose(ctx context.Context, in *DecomposeRequest, opts ...grpc.CallOption) (Decomposer_DecomposeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Decomposer_ServiceDesc.Streams[0], Decomposer_Decompose_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &decomposerDecomposeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Decomposer_DecomposeClient interface {
+	Recv() (*DecomposeEvent, error)
+	grpc.ClientStream
+}
+
+type decomposerDecomposeClient struct {
+	grpc.ClientStream
+}
+
+func (x *decomposerDecomposeClient) Recv() (*DecomposeEvent, error) {
+	m := new(DecomposeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *decomposerClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, Decomposer_Verify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *decomposerClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, Decomposer_Stats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecomposerServer is the server API for Decomposer service.
+// All implementations must embed UnimplementedDecomposerServer
+// for forward compatibility
+type DecomposerServer interface {
+	// Decompose streams events on the progress of the search, ending with the result
+	Decompose(*DecomposeRequest, Decomposer_DecomposeServer) error
+	// Verify checks a decomposition against a graph, without running any algorithm
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	// Stats reports on the jobs run by the server, and the work done by the searches of all of them
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	mustEmbedUnimplementedDecomposerServer()
+}
+
+// UnimplementedDecomposerServer must be embedded to have forward compatible implementations.
+type UnimplementedDecomposerServer struct {
+}
+
+func (UnimplementedDecomposerServer) Decompose(*DecomposeRequest, Decomposer_DecomposeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Decompose not implemented")
+}
+func (UnimplementedDecomposerServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedDecomposerServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedDecomposerServer) mustEmbedUnimplementedDecomposerServer() {}
+
+// UnsafeDecomposerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DecomposerServer will
+// result in compilation errors.
+type UnsafeDecomposerServer interface {
+	mustEmbedUnimplementedDecomposerServer()
+}
+
+func RegisterDecomposerServer(s grpc.ServiceRegistrar, srv DecomposerServer) {
+	s.RegisterService(&Decomposer_ServiceDesc, srv)
+}
+
+func _Decomposer_Decompose_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DecomposeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DecomposerServer).Decompose(m, &decomposerDecomposeServer{stream})
+}
+
+type Decomposer_DecomposeServer interface {
+	Send(*DecomposeEvent) error
+	grpc.ServerStream
+}
+
+type decomposerDecomposeServer struct {
+	grpc.ServerStream
+}
+
+func (x *decomposerDecomposeServer) Send(m *DecomposeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Decomposer_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DecomposerServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Decomposer_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DecomposerServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Decomposer_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DecomposerServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Decomposer_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DecomposerServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Decomposer_ServiceDesc is the grpc.ServiceDesc for Decomposer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Decomposer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "balancedgo.Decomposer",
+	HandlerType: (*DecomposerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Verify",
+			Handler:    _Decomposer_Verify_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Decomposer_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Decompose",
+			Handler:       _Decomposer_Decompose_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "decomposer.proto",
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/BalancedGo/service/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// complete lists the algorithms that find a decomposition whenever one of the given width exists, so that a failure
+// raises the lower bound
+var complete = map[string]bool{"local": true, "global": true, "det": true, "balDet": true}
+
+// decomposer implements the Decomposer service, running at most as many decompositions at the same time as there
+// are slots in jobs
+type decomposer struct {
+	pb.UnimplementedDecomposerServer
+	jobs      chan struct{} // nil if there is no limit
+	balFactor int
+	timeout   time.Duration // upper bound on the time spent per request, none if not positive
+	running   int32         // accessed atomically, as is served
+	served    int64
+}
+
+func newDecomposer(maxJobs, balFactor int, timeout time.Duration) *decomposer {
+	output := &decomposer{balFactor: balFactor, timeout: timeout}
+	if maxJobs > 0 {
+		output.jobs = make(chan struct{}, maxJobs)
+	}
+	return output
+}
+
+// solver sets up the named algorithm for the graph at width K, reporting subtrees to the dumper if it supports that
+func solver(name string, graph lib.Graph, K, balFactor int, dumper *lib.SubtreeDumper) (algo.Algorithm, error) {
+	switch name {
+	case "local":
+		return &algo.BalSepLocal{K: K, Graph: graph, BalFactor: balFactor, Dumper: dumper}, nil
+	case "global":
+		return &algo.BalSepGlobal{K: K, Graph: graph.ComputeSubEdges(K), BalFactor: balFactor, Dumper: dumper}, nil
+	case "det":
+		return &algo.DetKDecomp{K: K, Graph: graph, BalFactor: balFactor, SubEdge: true, Dumper: dumper}, nil
+	case "balDet":
+		return &algo.BalSepHybrid{K: K, Graph: graph, BalFactor: balFactor, Depth: 1}, nil
+	case "vertex":
+		return &algo.BalSepVertex{K: K, Graph: graph, BalFactor: balFactor}, nil
+	case "greedy":
+		return &algo.GreedyDecomp{K: K, Graph: graph}, nil
+	}
+	return nil, fmt.Errorf("unknown algorithm %q, expected one of: local, global, det, balDet, vertex, greedy", name)
+}
+
+// parseGraph parses the graph in the given format, turning a panic of the parser into an error
+func parseGraph(s, format string) (graph lib.Graph, pgraph lib.ParseGraph, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed graph: %v", r)
+		}
+	}()
+
+	if format == "" {
+		format = "hyperbench"
+	}
+	return lib.GetGraphFormat(format, s)
+}
+
+// acquire takes a slot for a job, and returns the function to release it again
+func (d *decomposer) acquire() (func(), error) {
+	if d.jobs != nil {
+		select {
+		case d.jobs <- struct{}{}:
+		default:
+			return nil, status.Error(codes.ResourceExhausted, "too many concurrent jobs")
+		}
+	}
+	atomic.AddInt32(&d.running, 1)
+
+	return func() {
+		atomic.AddInt32(&d.running, -1)
+		atomic.AddInt64(&d.served, 1)
+		if d.jobs != nil {
+			<-d.jobs
+		}
+	}, nil
+}
+
+// Decompose runs the request, streaming the bounds whenever they improve, each subgraph decomposed and the cache
+// statistics in regular intervals, followed by the result. The search is cancelled once the client goes away or the
+// timeout passes.
+func (d *decomposer) Decompose(req *pb.DecomposeRequest, stream pb.Decomposer_DecomposeServer) error {
+	release, err := d.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if req.Width <= 0 {
+		return status.Error(codes.InvalidArgument, "width must be positive")
+	}
+	algorithm := req.Algorithm
+	if algorithm == "" {
+		algorithm = "det"
+	}
+	balFactor := int(req.BalFactor)
+	if balFactor <= 0 {
+		balFactor = d.balFactor
+	}
+	if err := lib.CheckBalFactor(balFactor); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	graph, _, err := parseGraph(req.Graph, req.Format)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := solver(algorithm, graph, int(req.Width), balFactor, nil); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := stream.Context()
+	timeout := d.timeout
+	if requested := time.Duration(req.TimeoutMs) * time.Millisecond; requested > 0 &&
+		(timeout <= 0 || requested < timeout) {
+		timeout = requested
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	interval := time.Duration(req.StatsIntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	// events are sent by this goroutine only, as a stream must not be used by several at once. The channel is never
+	// closed, since subtrees may still be reported by goroutines of a cancelled search.
+	events := make(chan *pb.DecomposeEvent, 64)
+	done := make(chan *pb.Result, 1)
+	failed := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				failed <- status.Error(codes.Internal, fmt.Sprint("decomposition failed: ", r))
+			}
+		}()
+		done <- d.search(ctx, graph, algorithm, req, balFactor, events)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := stream.Send(cacheEvent()); err != nil {
+				return err
+			}
+		case err := <-failed:
+			return err
+		case result := <-done:
+			for len(events) > 0 { // report all subtrees and bounds before the result
+				if err := stream.Send(<-events); err != nil {
+					return err
+				}
+			}
+			if err := stream.Send(cacheEvent()); err != nil {
+				return err
+			}
+			return stream.Send(&pb.DecomposeEvent{Event: &pb.DecomposeEvent_Result{Result: result}})
+		}
+	}
+}
+
+// search runs the algorithm at the requested width, or at each width from 1 up to it if the request is exact,
+// until a decomposition is found or ctx is done. Bounds are sent to events, as are as many subtrees as fit.
+func (d *decomposer) search(ctx context.Context, graph lib.Graph, algorithm string, req *pb.DecomposeRequest,
+	balFactor int, events chan *pb.DecomposeEvent) *pb.Result {
+	low := int(req.Width)
+	if req.Exact {
+		low = 1
+	}
+
+	lower := int32(1)
+	output := &pb.Result{}
+	start := time.Now()
+	for K := low; K <= int(req.Width) && ctx.Err() == nil; K++ {
+		var count int64
+		width := int32(K)
+		dumper := &lib.SubtreeDumper{Notify: func(decomp lib.Decomp) {
+			event := &pb.Subproblem{Width: width, Edges: int32(decomp.Graph.Len()),
+				Vertices: int32(len(decomp.Graph.Vertices())), Count: atomic.AddInt64(&count, 1)}
+			select {
+			case events <- &pb.DecomposeEvent{Event: &pb.DecomposeEvent_Subproblem{Subproblem: event}}:
+			default: // dropped, the count of the next one covers it
+			}
+		}}
+
+		s, _ := solver(algorithm, graph, K, balFactor, dumper)
+		s.SetGenerator(lib.ParallelSearchGen{Ctx: ctx})
+		decomp := s.FindDecomp()
+
+		if decomp.Found() {
+			decomp.Graph = graph
+			output.Found = true
+			output.Correct = decomp.Correct(graph)
+			output.Width = int32(decomp.CheckWidth())
+			output.Decomp = string(lib.WriteDecomp(decomp))
+
+			sendBounds(ctx, events, &pb.Bounds{Lower: lower, Upper: output.Width})
+			break
+		}
+		if ctx.Err() == nil && complete[algorithm] {
+			lower = width + 1
+			sendBounds(ctx, events, &pb.Bounds{Lower: lower})
+		}
+	}
+
+	output.Ms = float64(time.Since(start)) / float64(time.Millisecond)
+	output.TimedOut = ctx.Err() != nil
+	return output
+}
+
+// sendBounds adds the bounds to events, unless ctx is done, in which case nobody receives them anymore
+func sendBounds(ctx context.Context, events chan *pb.DecomposeEvent, bounds *pb.Bounds) {
+	select {
+	case events <- &pb.DecomposeEvent{Event: &pb.DecomposeEvent_Bounds{Bounds: bounds}}:
+	case <-ctx.Done():
+	}
+}
+
+// cacheEvent reports the statistics shared by all searches
+func cacheEvent() *pb.DecomposeEvent {
+	lookups, hits := lib.Stats.CacheLookups()
+	event := &pb.CacheStats{Lookups: lookups, Hits: hits, SeparatorsChecked: separatorsChecked()}
+
+	return &pb.DecomposeEvent{Event: &pb.DecomposeEvent_Cache{Cache: event}}
+}
+
+func separatorsChecked() int64 {
+	var output int64
+	for _, n := range lib.Stats.Consumed() {
+		output += n
+	}
+	return output
+}
+
+// Verify checks the decomposition against the graph, each condition of a GHD independently of the others
+func (d *decomposer) Verify(ctx context.Context, req *pb.VerifyRequest) (output *pb.VerifyResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil { // the parsers panic on malformed input
+			output, err = nil, status.Error(codes.InvalidArgument, fmt.Sprint("malformed decomposition: ", r))
+		}
+	}()
+
+	graph, pgraph, err := parseGraph(req.Graph, req.Format)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var decomp lib.Decomp
+	switch req.DecompFormat {
+	case "", "json":
+		decomp = lib.GetDecomp([]byte(req.Decomp), graph, pgraph.Encoding)
+	case "gml":
+		decomp = lib.GetDecompGML(req.Decomp, graph, pgraph.Encoding)
+	case "htd":
+		decomp = lib.GetDecompPACE(req.Decomp, graph, pgraph.Encoding)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown decomposition format %q, expected one of: "+
+			"json, gml, htd", req.DecompFormat)
+	}
+
+	verdict := decomp.Verify(graph)
+	output = &pb.VerifyResponse{Valid: verdict.Valid(), SameGraph: verdict.SameGraph,
+		BagsInCovers: verdict.BagsInCovers, EdgesCovered: verdict.EdgesCovered, Connected: verdict.Connected,
+		Width: int32(verdict.Width), Problems: verdict.Problems}
+	if req.MaxWidth > 0 && output.Width > req.MaxWidth {
+		output.Valid = false
+		output.Problems = append(output.Problems, fmt.Sprint("Width ", output.Width, " exceeds ", req.MaxWidth))
+	}
+
+	return output, nil
+}
+
+// Stats reports the jobs of the server, and the work done by all searches since it started
+func (d *decomposer) Stats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	lookups, hits := lib.Stats.CacheLookups()
+
+	return &pb.StatsResponse{
+		Running:           atomic.LoadInt32(&d.running),
+		MaxJobs:           int32(cap(d.jobs)),
+		Served:            atomic.LoadInt64(&d.served),
+		SeparatorsChecked: separatorsChecked(),
+		CacheLookups:      lookups,
+		CacheHits:         hits,
+		Goroutines:        int32(runtime.NumGoroutine()),
+		HeapBytes:         mem.HeapAlloc,
+	}, nil
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// watchSignals prints the progress on stderr when receiving SIGUSR1, and toggles the extensive logs on SIGUSR2,
+// until the returned stop function is called. This allows to inspect long-running searches without restarting them.
+// Logs turned on again keep the levels chosen by the log flag, or are all at level info if none were chosen.
+func watchSignals(p *progress, verbose bool) func() {
+	levels := lib.LogLevels()
+	if levels == "" {
+		levels = lib.LogInfo.String()
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case s := <-ch:
+				if s == syscall.SIGUSR1 {
+					fmt.Fprint(os.Stderr, p.String())
+					continue
+				}
+				verbose = !verbose
+				if verbose {
+					lib.SetLogLevels(levels)
+				} else {
+					lib.SetLogLevels(lib.LogOff.String())
+				}
+				logActive(verbose)
+				fmt.Fprintln(os.Stderr, "Extensive logs turned on:", verbose)
+			case <-done:
+				signal.Stop(ch)
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package main
+
+// watchSignals does nothing on Windows, which has no user-defined signals
+func watchSignals(p *progress, verbose bool) func() {
+	return func() {}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// formatDistribution prints a distribution as "value: count" pairs, in increasing order of the values
+func formatDistribution(dist map[int]int) string {
+	var values []int
+	for v := range dist {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+
+	var pairs []string
+	for _, v := range values {
+		pairs = append(pairs, fmt.Sprint(v, ": ", dist[v]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// printStats prints statistics of the hypergraph, to triage instances before deciding which algorithm and width
+// to try
+func printStats(g lib.Graph) {
+	features := g.Features()
+
+	fmt.Println("Edges: ", features.Edges)
+	fmt.Println("Vertices: ", features.Vertices)
+	fmt.Printf("Arity: max %v, mean %.2f\n", features.MaxArity, features.MeanArity)
+	fmt.Println("Arity distribution (arity: edges): ", formatDistribution(g.ArityDistribution()))
+	fmt.Printf("Degree: max %v, mean %.2f\n", features.MaxDegree, features.MeanDegree)
+	fmt.Println("Degree distribution (degree: vertices): ", formatDistribution(g.DegreeDistribution()))
+	fmt.Printf("Intersection graph density: %.4f\n", features.IntersectionDensity)
+	fmt.Println("Largest intersection of two edges: ", features.IntersectionSize)
+	fmt.Println("Connected components: ", features.Components)
+	fmt.Println("Acyclic: ", features.Acyclic)
+	fmt.Println("Lower bound on width: ", lib.LowerBound(g))
+}
@@ -6,7 +6,6 @@ import (
 	"log"
 	"math/rand"
 	"os"
-	"reflect"
 	"testing"
 	"time"
 
@@ -97,6 +96,15 @@ func TestAlgo(t *testing.T) {
 	}
 	algoTestsGHD = append(algoTestsGHD, balDet)
 
+	sizedBalDet := &algo.BalSepHybrid{
+		K:         width,
+		Graph:     graph,
+		BalFactor: BalFactor,
+		Depth:     algo.UnboundedDepth,
+		Size:      4,
+	}
+	algoTestsGHD = append(algoTestsGHD, sizedBalDet)
+
 	seqBalDet := &algo.BalSepHybridSeq{
 		K:         width,
 		Graph:     graph,
@@ -106,6 +114,16 @@ func TestAlgo(t *testing.T) {
 
 	algoTestsGHD = append(algoTestsGHD, seqBalDet)
 
+	largestSeqBalDet := &algo.BalSepHybridSeq{
+		K:         width,
+		Graph:     graph,
+		BalFactor: BalFactor,
+		Depth:     1,
+		Order:     lib.LargestFirst,
+	}
+
+	algoTestsGHD = append(algoTestsGHD, largestSeqBalDet)
+
 	det := &algo.DetKDecomp{
 		K:         width,
 		Graph:     graph,
@@ -140,6 +158,24 @@ func TestAlgo(t *testing.T) {
 
 	algoTestsGHD = append(algoTestsGHD, local)
 
+	localDeferred := &algo.BalSepLocal{
+		K:             width,
+		Graph:         graph,
+		BalFactor:     BalFactor,
+		DeferSubedges: true,
+	}
+
+	algoTestsGHD = append(algoTestsGHD, localDeferred)
+
+	smallestLocal := &algo.BalSepLocal{
+		K:         width,
+		Graph:     graph,
+		BalFactor: BalFactor,
+		Order:     lib.SmallestFirst,
+	}
+
+	algoTestsGHD = append(algoTestsGHD, smallestLocal)
+
 	// test out all algorithms
 
 	first := true
@@ -154,7 +190,7 @@ func TestAlgo(t *testing.T) {
 
 		out = solve(algorithm, graph, &hinget)
 
-		if !reflect.DeepEqual(out, lib.Decomp{}) || (len(ops) > 0 && graph.Edges.Len() == 0) {
+		if out.Found() || (len(ops) > 0 && graph.Edges.Len() == 0) {
 			var result bool
 			out.Root, result = out.Root.RestoreGYÖ(ops)
 			if !result {
@@ -167,7 +203,7 @@ func TestAlgo(t *testing.T) {
 				log.Panicln("Type Collapse reduction failed")
 			}
 		}
-		if !reflect.DeepEqual(out, lib.Decomp{}) {
+		if out.Found() {
 			out.Graph = graphInitial
 		}
 
@@ -207,7 +243,7 @@ func TestAlgo(t *testing.T) {
 
 		out = solve(algorithm, graph, &hinget)
 
-		if !reflect.DeepEqual(out, lib.Decomp{}) || (len(ops) > 0 && graph.Edges.Len() == 0) {
+		if out.Found() || (len(ops) > 0 && graph.Edges.Len() == 0) {
 			var result bool
 			out.Root, result = out.Root.RestoreGYÖ(ops)
 			if !result {
@@ -220,7 +256,7 @@ func TestAlgo(t *testing.T) {
 				log.Panicln("Type Collapse reduction failed")
 			}
 		}
-		if !reflect.DeepEqual(out, lib.Decomp{}) {
+		if out.Found() {
 			out.Graph = graphInitial
 		}
 
@@ -252,7 +288,7 @@ func TestAlgo(t *testing.T) {
 
 	//produce a GML
 	gml := out.ToGML()
-	if !reflect.DeepEqual(out, lib.Decomp{}) {
+	if out.Found() {
 		lib.GetDecompGML(gml, graphInitial, encoding)
 	}
 
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/disjoint"
+)
+
+// components returns the number of vertices and edges of each component of the graph minus the vertices of sep,
+// computed by a plain search over the vertices
+func components(graph lib.Graph, sep lib.Edges) (vertices []int, edges []int) {
+	covered := sep.VertexSet()
+	comp := make(map[int]int) // the component of each vertex outside sep, by index
+	neighbours := func(v int) []int {
+		var output []int
+		for _, e := range graph.Edges.Slice() {
+			for _, w := range e.Vertices {
+				if w == v {
+					output = append(output, e.Vertices...)
+					break
+				}
+			}
+		}
+		return output
+	}
+
+	for _, v := range graph.Vertices() {
+		if _, ok := comp[v]; ok || covered.Has(v) {
+			continue
+		}
+		id := len(vertices)
+		vertices = append(vertices, 0)
+		stack := []int{v}
+		comp[v] = id
+		for len(stack) > 0 {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			vertices[id]++
+			for _, x := range neighbours(w) {
+				if _, ok := comp[x]; !ok && !covered.Has(x) {
+					comp[x] = id
+					stack = append(stack, x)
+				}
+			}
+		}
+	}
+
+	edges = make([]int, len(vertices))
+	for _, e := range graph.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if !covered.Has(v) {
+				edges[comp[v]]++
+				break
+			}
+		}
+	}
+	return vertices, edges
+}
+
+// TestBalanceMeasures checks the alternative balance predicates against a direct computation of the components, and
+// that the algorithms using them still produce correct decompositions
+func TestBalanceMeasures(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		graph, _ := getRandomGraph(10)
+		sep := getRandomSep(graph, 3)
+		balFactor := 2 + i%3
+		vertices, edges := components(graph, sep)
+
+		total := 0
+		for _, n := range vertices {
+			total += n
+		}
+		want := true
+		for j := range vertices {
+			want = want && vertices[j] <= total*(balFactor-1)/balFactor && edges[j]+1 < graph.Len()
+		}
+		scratch := make(map[int]*disjoint.Element)
+		if got := (lib.VertexBalancedCheck{}).Check(&graph, &sep, balFactor, scratch); got != want {
+			t.Errorf("Vertex balance of %v in %v is %v, expected %v", sep, graph, got, want)
+		}
+
+		for _, max := range []int{1, 2, 3} {
+			bound := lib.ComponentBound{Inner: lib.BalancedCheck{}, MaxComps: max}
+			want := len(vertices) <= max && (lib.BalancedCheck{}).Check(&graph, &sep, balFactor, scratch)
+			if got := bound.Check(&graph, &sep, balFactor, scratch); got != want {
+				t.Errorf("Separator %v with %v components in %v passed bound %v: %v", sep, len(vertices), graph,
+					max, got)
+			}
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+		k := i%3 + 1
+		for _, name := range lib.BalanceMeasures() {
+			measure, err := lib.ParseBalanceMeasure(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, maxComps := range []int{0, 2} {
+				solvers := []algo.Algorithm{
+					&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2, Balance: measure.Predicate(maxComps)},
+					&algo.BalSepGlobal{K: k, Graph: graph.ComputeSubEdges(k), BalFactor: 2,
+						Balance: measure.Predicate(maxComps)},
+					&algo.BalSepHybrid{K: k, Graph: graph, BalFactor: 2, Depth: 1,
+						Balance: measure.Predicate(maxComps)},
+				}
+				for _, solver := range solvers {
+					solver.SetGenerator(lib.ParallelSearchGen{})
+					decomp := solver.FindDecomp()
+					if !decomp.Found() {
+						continue // the search may miss decompositions with measures other than edges
+					}
+					decomp.Graph = graph
+					if !decomp.Correct(graph) {
+						t.Errorf("%v with balance %v and at most %v components produced %v for %v", solver.Name(),
+							name, maxComps, decomp, graph)
+					}
+				}
+			}
+		}
+	}
+
+	if _, err := lib.ParseBalanceMeasure("weight"); err == nil {
+		t.Errorf("Unknown balance measure parsed")
+	}
+}
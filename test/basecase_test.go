@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestBaseCaseSpecial checks that a subgraph consisting only of special edges, which can be covered by K edges, is
+// decomposed by a single node with a leaf for each special edge
+func TestBaseCaseSpecial(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,a).")
+	var special []lib.SpecialEdge
+	for _, e := range graph.Edges.Slice() {
+		special = append(special, lib.NewSpecialEdge(lib.NewEdges([]lib.Edge{e})))
+	}
+	H := lib.Graph{Edges: lib.NewEdges([]lib.Edge{}), Special: special}
+
+	solvers := []algo.Algorithm{
+		&algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2},
+		&algo.BalSepGlobal{K: 2, Graph: graph, BalFactor: 2},
+		&algo.BalSepHybrid{K: 2, Graph: graph, BalFactor: 2, Depth: 1},
+		&algo.BalSepHybridSeq{K: 2, Graph: graph, BalFactor: 2, Depth: 1},
+	}
+
+	for _, solver := range solvers {
+		solver.SetGenerator(lib.ParallelSearchGen{})
+		decomp := solver.FindDecompGraph(H)
+
+		root := decomp.Root
+		if root.Cover.Len() > 2 || len(root.Bag) != 4 || !lib.Subset(root.Bag, root.Cover.Vertices()) {
+			t.Errorf("%v: root doesn't cover all special edges: %v", solver.Name(), decomp)
+			continue
+		}
+		if len(root.Children) != len(special) {
+			t.Errorf("%v: not a leaf for each special edge: %v", solver.Name(), decomp)
+		}
+		for _, c := range root.Children {
+			if len(c.Children) > 0 || c.Cover.Len() != 1 {
+				t.Errorf("%v: leaf isn't a special edge: %v", solver.Name(), c)
+			}
+		}
+	}
+}
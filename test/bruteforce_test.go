@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// getDenseGraph produces a random graph with small edges over few vertices, which unlike those of getRandomGraph
+// are rarely acyclic
+func getDenseGraph(r *rand.Rand, vertices, edges int) lib.Graph {
+	var out []string
+	for i := 0; i < edges; i++ {
+		var edge []string
+		for j := 0; j < r.Intn(2)+2; j++ {
+			edge = append(edge, fmt.Sprint("v", r.Intn(vertices)))
+		}
+		out = append(out, fmt.Sprintf("E%v(%v)", i, strings.Join(edge, ",")))
+	}
+
+	graph, _ := lib.GetGraph(strings.Join(out, ",\n") + ".")
+	return graph
+}
+
+// TestBruteForce uses the exhaustive computation of the width on small random graphs as ground truth for the
+// algorithms computing GHDs: each must find a decomposition of exactly that width, and none below it
+func TestBruteForce(t *testing.T) {
+	triangle, _ := lib.GetGraph("R(x,y),\nS(y,z),\nT(z,x).")
+	if width, ok := lib.BruteForceWidth(triangle); !ok || width != 2 {
+		t.Errorf("Width of triangle: %v (computed: %v), expected 2", width, ok)
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 10; i++ {
+		graph := getDenseGraph(r, 8, 10)
+		ghw, _ := lib.BruteForceWidth(graph)
+
+		for _, k := range []int{ghw - 1, ghw} {
+			if k < 1 {
+				continue
+			}
+			solvers := []algo.Algorithm{
+				&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2},
+				&algo.BalSepGlobal{K: k, Graph: graph.ComputeSubEdges(k), BalFactor: 2},
+				&algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2, SubEdge: true},
+			}
+			for _, solver := range solvers {
+				solver.SetGenerator(lib.ParallelSearchGen{})
+				decomp := solver.FindDecomp()
+				decomp.Graph = graph
+				if found := decomp.Correct(graph); found != (k == ghw) {
+					t.Errorf("%v at width %v on %v of width %v: found %v", solver.Name(), k, graph, ghw, found)
+				}
+			}
+		}
+	}
+}
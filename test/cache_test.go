@@ -3,10 +3,15 @@ package tests
 // this is intended to provide some basic unit tests for lib.Cache
 
 import (
+	"bytes"
+	"fmt"
 	"math/rand"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
 	"github.com/cem-okulmus/BalancedGo/lib"
 	"github.com/cem-okulmus/disjoint"
 )
@@ -38,7 +43,7 @@ func getRandomGraph(size int) (lib.Graph, map[string]int) {
 	card := r.Intn(size) + 1
 
 	var edges []lib.Edge
-	var SpEdges []lib.Edges
+	var SpEdges []lib.SpecialEdge
 
 	for i := 0; i < card; i++ {
 		edges = append(edges, getRandomEdge(size))
@@ -126,3 +131,245 @@ func TestCache(t *testing.T) {
 	}
 
 }
+
+// TestCacheSaveLoad checks that saved cache entries are found again after loading them, and that DetK gives the same
+// answers with a loaded cache, rejecting caches saved for another graph
+func TestCacheSaveLoad(t *testing.T) {
+	randomGraph, _ := getRandomGraph(100)
+	randomSep := getRandomSep(randomGraph, 10)
+	comps, _, _ := randomGraph.GetComponents(randomSep, make(map[int]*disjoint.Element))
+	if len(comps) == 0 {
+		return
+	}
+
+	var cache lib.Cache
+	cache.Init()
+	cache.AddNegative(randomSep, comps[0])
+	special := comps[0]
+	special.Special = []lib.SpecialEdge{lib.NewSpecialEdge(randomSep)}
+	cache.AddNegative(randomSep, special)
+
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var loaded lib.Cache
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.CheckNegative(randomSep, comps[:1]) || !loaded.CheckNegative(randomSep, []lib.Graph{special}) {
+		t.Errorf("Negative entries lost when saving the cache")
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+
+		det := &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}
+		expected := det.FindDecomp().Correct(graph)
+
+		var saved bytes.Buffer
+		if err := det.SaveCache(&saved); err != nil {
+			t.Fatal(err)
+		}
+		dat := saved.Bytes()
+
+		for k := 1; k <= 2; k++ {
+			other := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			if err := other.LoadCache(bytes.NewReader(dat)); err != nil {
+				t.Fatal(err)
+			}
+			check := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			if k == 2 && expected != other.FindDecomp().Correct(graph) {
+				t.Errorf("Different result with loaded cache for %v", graph)
+			}
+			if k == 1 && check.FindDecomp().Correct(graph) != other.FindDecomp().Correct(graph) {
+				t.Errorf("Different result for smaller width with loaded cache for %v", graph)
+			}
+		}
+
+		different := graph
+		different.Edges = lib.NewEdges(append([]lib.Edge{getRandomEdge(8)}, graph.Edges.Slice()...))
+		other := &algo.DetKDecomp{K: 2, Graph: different, BalFactor: 2}
+		if err := other.LoadCache(bytes.NewReader(dat)); err == nil {
+			t.Errorf("Cache for %v accepted for %v", graph, different)
+		}
+	}
+}
+
+// TestCacheWidths checks that failures are kept when moving to smaller widths, dropped when moving to larger ones,
+// and that DetK gives the same answers when reusing them
+func TestCacheWidths(t *testing.T) {
+	randomGraph, _ := getRandomGraph(100)
+	randomSep := getRandomSep(randomGraph, 10)
+	comps, _, _ := randomGraph.GetComponents(randomSep, make(map[int]*disjoint.Element))
+	if len(comps) == 0 {
+		return
+	}
+	special := comps[0]
+	special.Special = []lib.SpecialEdge{lib.NewSpecialEdge(randomSep)}
+	both := []lib.Graph{comps[0], special}
+
+	var cache lib.Cache
+	cache.SetWidth(3)
+	cache.AddNegative(randomSep, comps[0])
+	cache.AddNegative(randomSep, special)
+
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dat := buf.Bytes()
+
+	cache.SetWidth(2)
+	if !cache.CheckNegative(randomSep, both[:1]) || !cache.CheckNegative(randomSep, both[1:]) {
+		t.Errorf("Failures dropped when moving to a smaller width")
+	}
+	cache.SetWidth(4)
+	if cache.CheckNegative(randomSep, both) {
+		t.Errorf("Failures kept when moving to a larger width")
+	}
+
+	var loaded lib.Cache
+	loaded.SetWidth(4)
+	if err := loaded.Load(bytes.NewReader(dat)); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CheckNegative(randomSep, both) {
+		t.Errorf("Failures loaded which don't hold for a larger width")
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+
+		reused := &algo.DetKDecomp{K: 3, Graph: graph, BalFactor: 2}
+		reused.FindDecomp()
+		for k := 2; k >= 1; k-- {
+			reused.SetWidth(k)
+			fresh := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			if reused.FindDecomp().Correct(graph) != fresh.FindDecomp().Correct(graph) {
+				t.Errorf("Different result at width %v when reusing the cache for %v", k, graph)
+			}
+		}
+	}
+}
+
+// TestCacheLimit checks that a cache with a limit stays within it by evicting the least recently used separators,
+// and that DetK gives the same answers with a cache too small to hold more than a few of them
+func TestCacheLimit(t *testing.T) {
+	if size, err := lib.ParseBytes("4GB"); err != nil || size != 4<<30 {
+		t.Errorf("Parsed 4GB as %v, %v", size, err)
+	}
+	if size, err := lib.ParseBytes("512 kb"); err != nil || size != 512<<10 {
+		t.Errorf("Parsed 512 kb as %v, %v", size, err)
+	}
+	if _, err := lib.ParseBytes("much"); err == nil {
+		t.Errorf("Parsed an invalid size")
+	}
+
+	var edges []string
+	for i := 0; i < 300; i++ {
+		edges = append(edges, fmt.Sprintf("e%v(x%v,x%v)", i, i, i+1))
+	}
+	graph, _ := lib.GetGraph(strings.Join(edges, ",") + ".")
+	var seps []lib.Edges
+	var comps [][]lib.Graph
+	for i := 1; i < graph.Edges.Len()-1; i++ { // inner edges, each splitting the path in two
+		sep := lib.GetSubset(graph.Edges, []int{i})
+		c, _, _ := graph.GetComponents(sep, nil)
+		seps = append(seps, sep)
+		comps = append(comps, c)
+	}
+
+	var cache lib.Cache
+	cache.Init()
+	cache.AddNegative(seps[0], comps[0][0])
+	entry := cache.MemSize()
+	cache.SetLimit(200 * entry)
+
+	for i := 1; i < len(seps); i++ {
+		if !cache.CheckNegative(seps[0], comps[0]) { // keeps the first separator in use
+			t.Fatalf("Recently used separator evicted after adding %v others", i-1)
+		}
+		cache.AddNegative(seps[i], comps[i][0])
+		if !cache.CheckNegative(seps[i], comps[i]) {
+			t.Fatalf("Separator evicted right after adding it")
+		}
+		if cache.MemSize() > cache.Limit() {
+			t.Errorf("Cache of %v bytes exceeds its limit of %v", cache.MemSize(), cache.Limit())
+		}
+	}
+	if cache.Evictions() == 0 || cache.Evictions()+cache.Len() != len(seps) {
+		t.Errorf("%v separators evicted, %v kept of %v", cache.Evictions(), cache.Len(), len(seps))
+	}
+
+	cache.SetLimit(0)
+	for i := range seps {
+		cache.AddNegative(seps[i], comps[i][0])
+	}
+	if cache.Len() != len(seps) {
+		t.Errorf("Cache without limit holds %v separators, expected %v", cache.Len(), len(seps))
+	}
+	special := comps[0][0]
+	special.AddSpecial(lib.NewSpecialEdge(seps[0]))
+	cache.AddNegative(seps[0], special) // kept apart from the failure without special edges
+	if cache.Len() != len(seps) {
+		t.Errorf("Separator with failures with and without special edges counted %v times", cache.Len()-len(seps)+1)
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+
+		for k := 1; k <= 2; k++ {
+			limited := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			limited.SetCacheLimit(100)
+			fresh := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			if limited.FindDecomp().Correct(graph) != fresh.FindDecomp().Correct(graph) {
+				t.Errorf("Different result at width %v with a limited cache for %v", k, graph)
+			}
+		}
+	}
+}
+
+// TestCacheConcurrent checks that entries added from many goroutines at once, spread over all shards of the cache,
+// are all found afterwards, also by copies of the cache
+func TestCacheConcurrent(t *testing.T) {
+	var edges []string
+	for i := 0; i < 200; i++ {
+		edges = append(edges, fmt.Sprintf("e%v(x%v,x%v)", i, i, i+1))
+	}
+	graph, _ := lib.GetGraph(strings.Join(edges, ",") + ".")
+
+	var cache lib.Cache
+	var cacheCopy lib.Cache
+	cache.CopyRef(&cacheCopy)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < graph.Edges.Len(); i = i + 8 {
+				sep := lib.GetSubset(graph.Edges, []int{i})
+				comps, _, _ := graph.GetComponents(sep, nil)
+				for _, c := range comps {
+					cacheCopy.CheckNegative(sep, comps)
+					cache.AddNegative(sep, c)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for i := 0; i < graph.Edges.Len(); i++ {
+		sep := lib.GetSubset(graph.Edges, []int{i})
+		comps, _, _ := graph.GetComponents(sep, nil)
+		for _, c := range comps {
+			if !cacheCopy.CheckNegative(sep, []lib.Graph{c}) {
+				t.Errorf("Failure of separator %v lost", sep)
+			}
+		}
+	}
+	if cache.Len() != graph.Edges.Len() {
+		t.Errorf("Cache holds %v separators, expected %v", cache.Len(), graph.Edges.Len())
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// bagsOf returns the bags of all nodes of a decomposition
+func bagsOf(n lib.Node) [][]int {
+	output := [][]int{n.Bag}
+	for _, c := range n.Children {
+		output = append(output, bagsOf(c)...)
+	}
+	return output
+}
+
+// TestCandidateDecomp checks that restricting the bags to those of a decomposition found by another algorithm yields
+// a correct decomposition with bags within them, of at most the same width, and that none is found for candidates which can't
+// form one
+func TestCandidateDecomp(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 20; i++ {
+		graph := getDenseGraph(r, 10, 8+i%8)
+
+		var found lib.Decomp
+		for k := 1; k <= graph.Edges.Len(); k++ {
+			det := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			if found = det.FindDecomp(); found.Correct(graph) {
+				break
+			}
+		}
+		greedy := algo.GreedyDecomp{}.Decompose(graph)
+
+		for _, source := range []lib.Decomp{found, greedy} {
+			width := source.CheckWidth()
+			candidates := bagsOf(source.Root)
+			r.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+			decomp := algo.CandidateDecomp{K: width}.FindDecompRestricted(graph, candidates)
+			if !decomp.Correct(graph) || decomp.CheckWidth() > width {
+				t.Fatalf("No decomposition of width %v found for %v with the bags of %v", width, graph, source)
+			}
+
+			for _, bag := range bagsOf(decomp.Root) {
+				within := false
+				for _, c := range candidates {
+					within = within || lib.Subset(bag, c)
+				}
+				if !within {
+					t.Errorf("Bag %v not within any of the candidates %v", bag, candidates)
+				}
+			}
+		}
+	}
+
+	cycle, _ := lib.GetGraph("a(x,y),b(y,z),c(z,w),d(w,x).")
+	x, _ := cycle.VertexByName("x")
+	y, _ := cycle.VertexByName("y")
+	z, _ := cycle.VertexByName("z")
+	w, _ := cycle.VertexByName("w")
+	path := [][]int{{x, y}, {y, z}, {z, w}}
+	if decomp := (algo.CandidateDecomp{K: 2}).FindDecompRestricted(cycle, path); !reflect.DeepEqual(decomp,
+		lib.Decomp{}) {
+		t.Errorf("Decomposition of cycle found with the bags of a path: %v", decomp)
+	}
+	triangles := [][]int{{x, y, z}, {x, z, w}}
+	if decomp := (algo.CandidateDecomp{K: 1}).FindDecompRestricted(cycle, triangles); !reflect.DeepEqual(decomp,
+		lib.Decomp{}) {
+		t.Errorf("Decomposition of cycle found with bags too wide for width 1: %v", decomp)
+	}
+	if decomp := (algo.CandidateDecomp{K: 2}).FindDecompRestricted(cycle, triangles); !decomp.Correct(cycle) {
+		t.Errorf("No decomposition of cycle found with triangles")
+	}
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// shuffled returns a copy of the subtree with the vertices of each bag, the edges of each cover and the children of
+// each node in random order
+func shuffled(r *rand.Rand, n lib.Node) lib.Node {
+	bag := append([]int{}, n.Bag...)
+	r.Shuffle(len(bag), func(i, j int) { bag[i], bag[j] = bag[j], bag[i] })
+	cover := append([]lib.Edge{}, n.Cover.Slice()...)
+	r.Shuffle(len(cover), func(i, j int) { cover[i], cover[j] = cover[j], cover[i] })
+
+	children := make([]lib.Node, len(n.Children))
+	for i := range n.Children {
+		children[i] = shuffled(r, n.Children[i])
+	}
+	r.Shuffle(len(children), func(i, j int) { children[i], children[j] = children[j], children[i] })
+
+	return lib.Node{Bag: bag, Cover: lib.NewEdges(cover), Children: children}
+}
+
+// TestCanonical checks that decompositions differing only in the order of vertices, edges and children have the same
+// canonical form and are equivalent, while changing a bag or rerooting makes them differ
+func TestCanonical(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 20; i++ {
+		graph := getDenseGraph(r, 10, 8+i%8)
+		decomp := algo.GreedyDecomp{}.Decompose(graph)
+		other := lib.Decomp{Graph: graph, Root: shuffled(r, decomp.Root)}
+
+		if !decomp.Equivalent(other) || !other.Equivalent(decomp) {
+			t.Fatalf("Shuffled decomp not equivalent:\n%v\n%v", decomp, other)
+		}
+		if decomp.Canonical().String() != other.Canonical().String() {
+			t.Errorf("Different canonical forms:\n%v\n%v", decomp.Canonical(), other.Canonical())
+		}
+		if canonical := other.Canonical(); !canonical.Correct(graph) || !canonical.Equivalent(decomp) {
+			t.Errorf("Canonical form not correct or not equivalent: %v", canonical)
+		}
+
+		changed := lib.Decomp{Graph: graph, Root: shuffled(r, decomp.Root)}
+		changed.Root.Bag = changed.Root.Bag[1:]
+		if decomp.Equivalent(changed) {
+			t.Errorf("Decomp with a changed bag still equivalent: %v", changed)
+		}
+		if len(decomp.Root.Children) > 0 && len(decomp.Root.Bag) != len(decomp.Root.Children[0].Bag) {
+			rerooted := lib.Decomp{Graph: graph, Root: decomp.Root.Reroot(decomp.Root.Children[0])}
+			if decomp.Equivalent(rerooted) {
+				t.Errorf("Rerooted decomp still equivalent: %v", rerooted)
+			}
+		}
+	}
+}
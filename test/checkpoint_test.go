@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestCheckpointResume checks that a decomposition resumed from any state saved along the way finds a decomposition
+// exactly if the uninterrupted one does. Dense graphs are used, so that some separators are rejected at the root.
+func TestCheckpointResume(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		graph := getDenseGraph(r, 10, 14)
+		for k := 1; k <= 3; k++ {
+			var states [][]byte
+			gen := lib.NewCheckpointGen(lib.ParallelSearchGen{Sequential: true}, graph, "global")
+			gen.Interval = 1 // save each state
+			gen.Save = func(state []byte) { states = append(states, state) }
+
+			global := &algo.BalSepGlobal{K: k, Graph: graph, BalFactor: 2}
+			global.SetGenerator(gen)
+			expected := global.FindDecomp().Found()
+			gen.Flush()
+
+			for j, state := range states {
+				resumed := lib.NewCheckpointGen(lib.ParallelSearchGen{Sequential: true}, graph, "global")
+				if err := resumed.Restore(state); err != nil {
+					t.Fatalf("width %d: can't restore state %d: %v", k, j, err)
+				}
+				global := &algo.BalSepGlobal{K: k, Graph: graph, BalFactor: 2}
+				global.SetGenerator(resumed)
+				decomp := global.FindDecomp()
+				if decomp.Found() != expected {
+					t.Fatalf("width %d: found %v resuming from state %d, expected %v on %v", k, decomp.Found(), j,
+						expected, graph)
+				}
+				if expected && !decomp.Correct(graph) {
+					t.Errorf("width %d: decomp resumed from state %d not correct: %v", k, j, decomp)
+				}
+			}
+		}
+	}
+}
+
+// TestCheckpointCancelled checks that the separator searched when the context is done isn't taken as rejected
+func TestCheckpointCancelled(t *testing.T) {
+	graph, _ := getRandomGraph(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var state []byte
+	gen := lib.NewCheckpointGen(lib.ParallelSearchGen{Ctx: ctx}, graph, "global")
+	gen.Save = func(s []byte) { state = s }
+	global := &algo.BalSepGlobal{K: 3, Graph: graph, BalFactor: 2}
+	global.SetGenerator(gen)
+	global.FindDecomp() // only small graphs can be decomposed without any search
+	gen.Flush()
+
+	resumed := lib.NewCheckpointGen(lib.ParallelSearchGen{}, graph, "global")
+	if err := resumed.Restore(state); err != nil {
+		t.Fatal(err)
+	}
+	global.SetGenerator(resumed)
+	decomp := global.FindDecomp()
+
+	det := &algo.DetKDecomp{K: 3, Graph: graph, BalFactor: 2}
+	det.SetGenerator(lib.ParallelSearchGen{})
+	if expected := det.FindDecomp().Found(); decomp.Found() != expected {
+		t.Errorf("found %v after resuming a cancelled search, expected %v", decomp.Found(), expected)
+	}
+}
+
+// TestCheckpointMismatch checks that a state is only restored for the same graph and setting
+func TestCheckpointMismatch(t *testing.T) {
+	graph, _ := getRandomGraph(10)
+	gen := lib.NewCheckpointGen(lib.ParallelSearchGen{}, graph, "global")
+	state := gen.State()
+
+	if err := lib.NewCheckpointGen(lib.ParallelSearchGen{}, graph, "local").Restore(state); err == nil {
+		t.Error("state restored for another setting")
+	}
+	other, _ := getRandomGraph(12)
+	if err := lib.NewCheckpointGen(lib.ParallelSearchGen{}, other, "global").Restore(state); err == nil {
+		t.Error("state restored for another graph")
+	}
+	if err := lib.NewCheckpointGen(lib.ParallelSearchGen{}, graph, "global").Restore(state); err != nil {
+		t.Error(err)
+	}
+}
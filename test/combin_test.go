@@ -98,3 +98,99 @@ func TestCombin(t *testing.T) {
 	}
 
 }
+
+// TestCombinSaveRestore ensures that a generator restored from a saved state continues with the same combinations,
+// and that states of other generators are rejected
+func TestCombinSaveRestore(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for x := 0; x < 20; x++ {
+		n, k, split := r.Intn(15)+1, r.Intn(4)+1, r.Intn(4)+1
+		gens := lib.SplitCombin(n, k, split, x%2 == 0)
+
+		for i, gen := range gens {
+			var all [][]int
+			for gen.HasNext() {
+				all = append(all, append([]int{}, gen.GetNext()...))
+				gen.Confirm()
+			}
+
+			cut := r.Intn(len(all) + 1)
+			first := lib.SplitCombin(n, k, split, x%2 == 0)[i]
+			for j := 0; j < cut; j++ {
+				first.HasNext()
+				first.Confirm()
+			}
+			if cut < len(all) && r.Intn(2) == 0 {
+				first.HasNext() // a candidate not yet confirmed must be returned again
+			}
+
+			restored := lib.SplitCombin(n, k, split, x%2 == 0)[i]
+			if err := restored.Restore(first.Save()); err != nil {
+				t.Fatalf("Restoring %v failed: %v", first, err)
+			}
+			var rest [][]int
+			for restored.HasNext() {
+				rest = append(rest, append([]int{}, restored.GetNext()...))
+				restored.Confirm()
+			}
+			if !reflect.DeepEqual(rest, all[cut:]) && !(len(rest) == 0 && cut == len(all)) {
+				t.Errorf("Restored generator returned %v, expected %v", rest, all[cut:])
+			}
+
+			if err := lib.SplitCombin(n+1, k, split, x%2 == 0)[0].Restore(first.Save()); err == nil {
+				t.Errorf("State of %v restored for another number of elements", first)
+			}
+		}
+	}
+}
+
+// TestCombinSplit ensures that splitting iterators, at any point, divides their remaining combinations among them
+func TestCombinSplit(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for x := 0; x < 50; x++ {
+		n, k, split := r.Intn(12)+1, r.Intn(4)+1, r.Intn(3)+1
+		unextended := x%2 == 0
+		gen := lib.SplitCombin(n, k, split, unextended)[0].(*lib.CombinationIterator)
+
+		var all []string
+		reference := lib.SplitCombin(n, k, split, unextended)[0]
+		for reference.HasNext() {
+			all = append(all, fmt.Sprint(reference.GetNext()))
+			reference.Confirm()
+		}
+
+		cut := r.Intn(len(all) + 1)
+		for i := 0; i < cut; i++ {
+			gen.HasNext()
+			gen.Confirm()
+		}
+		if cut < len(all) && r.Intn(2) == 0 {
+			gen.HasNext() // a candidate not yet confirmed stays with gen
+		}
+
+		gens := []*lib.CombinationIterator{gen}
+		for i := r.Intn(4); i > 0; i-- {
+			if other := gens[r.Intn(len(gens))].Split(); other != nil {
+				gens = append(gens, other)
+			}
+		}
+
+		found := make(map[string]int)
+		for _, g := range gens {
+			for g.HasNext() {
+				found[fmt.Sprint(g.GetNext())]++
+				g.Confirm()
+			}
+		}
+		if len(found) != len(all)-cut {
+			t.Errorf("Split into %v iterators, found %v of %v combinations", len(gens), len(found), len(all)-cut)
+		}
+		for _, c := range all[cut:] {
+			if found[c] != 1 {
+				t.Errorf("Combination %v found %v times after splitting into %v", c, found[c], len(gens))
+			}
+		}
+	}
+}
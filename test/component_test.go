@@ -3,9 +3,11 @@ package tests
 import (
 	"math/rand"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
 	"github.com/cem-okulmus/BalancedGo/lib"
 	"github.com/cem-okulmus/disjoint"
 )
@@ -105,3 +107,201 @@ func TestComponents(t *testing.T) {
 	}
 
 }
+
+// TestComponentsPlain compares the components of graphs without special edges to those of the general case, using
+// a special edge covered by the separator, which ends up as a component of its own
+func TestComponentsPlain(t *testing.T) {
+	for x := 0; x < 50; x++ {
+		graph, _ := getRandomGraph(20)
+		sep := getRandomSep(graph, 3)
+		var Vertices = make(map[int]*disjoint.Element)
+
+		comps, edgeToComp, isolated := graph.GetComponents(sep, Vertices)
+
+		special := lib.Graph{Edges: graph.Edges, Special: []lib.SpecialEdge{lib.NewSpecialEdge(sep)}}
+		compsSp, edgeToCompSp, isolatedSp := special.GetComponents(sep, Vertices)
+
+		if len(comps)+1 != len(compsSp) || len(isolated) != len(isolatedSp) {
+			t.Fatalf("Number of components differs: %v, %v", comps, compsSp)
+		}
+		for e, i := range edgeToComp {
+			for f, j := range edgeToComp {
+				if (i == j) != (edgeToCompSp[e] == edgeToCompSp[f]) {
+					t.Fatalf("Edges %v and %v not split in the same way: %v, %v", e, f, comps, compsSp)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkComponents measures the computation of components of graphs with and without special edges, and the
+// memory it allocates with and without reusing scratch space
+func BenchmarkComponents(b *testing.B) {
+	defer lib.SetPooling(true)
+
+	graph, _ := getRandomGraph(100)
+	sep := getRandomSep(graph, 5)
+	special := lib.Graph{Edges: graph.Edges, Special: []lib.SpecialEdge{lib.NewSpecialEdge(sep)}}
+	var Vertices = make(map[int]*disjoint.Element)
+
+	for _, pooling := range []bool{true, false} {
+		lib.SetPooling(pooling)
+		suffix := ""
+		if !pooling {
+			suffix = "-unpooled"
+		}
+
+		b.Run("plain"+suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				graph.GetComponents(sep, Vertices)
+			}
+		})
+		b.Run("special"+suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				special.GetComponents(sep, Vertices)
+			}
+		})
+		b.Run("scratch"+suffix, func(b *testing.B) { // a fresh scratch map each time, as done by the predicates
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				graph.GetComponents(sep, nil)
+			}
+		})
+	}
+}
+
+// BenchmarkGetSubset measures the selection of separators from the edges of a graph
+func BenchmarkGetSubset(b *testing.B) {
+	graph, _ := getRandomGraph(100)
+	subset := []int{0, graph.Edges.Len() / 2, graph.Edges.Len() - 1}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lib.GetSubset(graph.Edges, subset)
+	}
+}
+
+// BenchmarkDecompAllocs measures the memory allocated by a whole search, with and without reusing scratch space
+func BenchmarkDecompAllocs(b *testing.B) {
+	defer lib.SetPooling(true)
+	grid := gridGraph(4)
+
+	for _, pooling := range []bool{true, false} {
+		lib.SetPooling(pooling)
+		name := "pooled"
+		if !pooling {
+			name = "unpooled"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				solver := &algo.BalSepLocal{K: 3, Graph: grid, BalFactor: 2}
+				solver.SetGenerator(lib.ParallelSearchGen{})
+				solver.FindDecomp()
+			}
+		})
+	}
+}
+
+// TestComponentOrder checks that components are sorted by size as requested, and that the names of the orders parse
+func TestComponentOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(20)
+		sep := getRandomSep(graph, 3)
+		comps, _, _ := graph.GetComponents(sep, make(map[int]*disjoint.Element))
+
+		lib.LargestFirst.Sort(comps)
+		for j := 1; j < len(comps); j++ {
+			if comps[j-1].Len() < comps[j].Len() {
+				t.Errorf("Components not sorted largest first: %v", comps)
+			}
+		}
+		lib.SmallestFirst.Sort(comps)
+		for j := 1; j < len(comps); j++ {
+			if comps[j-1].Len() > comps[j].Len() {
+				t.Errorf("Components not sorted smallest first: %v", comps)
+			}
+		}
+	}
+
+	for _, name := range lib.ComponentOrders() {
+		if _, err := lib.ParseComponentOrder(name); err != nil {
+			t.Errorf("Order %v not parsed: %v", name, err)
+		}
+	}
+	if _, err := lib.ParseComponentOrder("random"); err == nil {
+		t.Errorf("Unknown order parsed")
+	}
+}
+
+// TestComponentsScratch ensures that without a scratch map given, GetComponents produces the same components, also
+// when called from many goroutines at once sharing the pooled scratch space, and with pooling disabled
+func TestComponentsScratch(t *testing.T) {
+	defer lib.SetPooling(true)
+
+	graph, _ := getRandomGraph(20)
+	seps := make([]lib.Edges, 20)
+	want := make([][]lib.Graph, len(seps))
+	for i := range seps {
+		seps[i] = getRandomSep(graph, 3)
+		want[i], _, _ = graph.GetComponents(seps[i], make(map[int]*disjoint.Element))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range seps {
+				if got, _, _ := graph.GetComponents(seps[i], nil); !reflect.DeepEqual(got, want[i]) {
+					t.Errorf("Components w.r.t. %v with pooled scratch map: %v, expected %v", seps[i], got, want[i])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	lib.SetPooling(false)
+	for i := range seps {
+		if got, _, _ := graph.GetComponents(seps[i], nil); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Components w.r.t. %v without pooling: %v, expected %v", seps[i], got, want[i])
+		}
+	}
+}
+
+// TestComponentsSplit checks that the components of a disconnected graph are decomposed independently, and that the
+// joined decomposition is correct for the whole graph, while a failing component makes the whole search fail
+func TestComponentsSplit(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,a),\nf1(x,y),\nf2(y,z),\nf3(z,x),\ng1(p,q),\nh().")
+	comps := graph.ComponentsSplit()
+	if len(comps) != 3 || comps[0].Edges.Len() != 4 {
+		t.Fatalf("split into %v", comps)
+	}
+	for i := range comps {
+		if !connected(comps[i]) {
+			t.Errorf("component %v not connected", comps[i])
+		}
+	}
+
+	for k := 1; k <= 2; k++ {
+		split := &algo.ComponentSplit{K: k, Graph: graph, Inner: &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2}}
+		split.SetGenerator(lib.ParallelSearchGen{})
+		decomp := split.FindDecomp()
+		if found := decomp.Found(); found != (k == 2) {
+			t.Fatalf("decomposition of width %d found: %v", k, found)
+		}
+		if k == 2 && (!decomp.Correct(graph) || len(decomp.Root.Children) != 3 || decomp.CheckWidth() != 2) {
+			t.Errorf("joined decomposition not correct: %v", decomp)
+		}
+	}
+
+	split := &algo.ComponentSplit{K: 1, Inner: &algo.DetKDecomp{K: 1, BalFactor: 2}}
+	split.SetGenerator(lib.ParallelSearchGen{})
+	decomp := split.DecompComponents(comps[2:])
+	if !decomp.Correct(decomp.Graph) || decomp.Graph.Edges.Len() != 1 {
+		t.Errorf("selected component not decomposed: %v", decomp)
+	}
+}
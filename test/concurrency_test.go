@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestConcurrentRuns checks that an algorithm gives the same answers when run concurrently on one instance, and that
+// clones with a different width don't affect the original
+func TestConcurrentRuns(t *testing.T) {
+	graph, _ := getRandomGraph(8)
+
+	solvers := []algo.Algorithm{
+		&algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2},
+		&algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2},
+		&algo.SmallWidth{K: 2, Graph: graph, Fallback: &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}},
+	}
+
+	for _, solver := range solvers {
+		solver.SetGenerator(lib.ParallelSearchGen{})
+		expected := solver.FindDecomp().Correct(graph)
+
+		clone := solver.Clone()
+		clone.SetWidth(1)
+		expectedClone := clone.FindDecomp().Correct(graph)
+
+		var wg sync.WaitGroup
+		results := make([]bool, 6)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i%2 == 0 {
+					results[i] = solver.FindDecomp().Correct(graph)
+				} else {
+					results[i] = clone.FindDecomp().Correct(graph)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range results {
+			if i%2 == 0 && results[i] != expected {
+				t.Errorf("%v: concurrent run differs, %v instead of %v", solver.Name(), results[i], expected)
+			}
+			if i%2 == 1 && results[i] != expectedClone {
+				t.Errorf("%v: concurrent run of clone differs, %v instead of %v", solver.Name(), results[i],
+					expectedClone)
+			}
+		}
+	}
+}
+
+// TestSeparateEncodings checks that two parsed graphs keep their own names, and can be decomposed at the same time
+func TestSeparateEncodings(t *testing.T) {
+	graph1, _ := lib.GetGraph("e1(a,b,c),\ne2(c,d),\ne3(d,e,a).")
+	graph2, _ := lib.GetGraph("f1(x,y),\nf2(y,z),\nf3(z,x).")
+
+	if graph1.String() != "{e1, e2, e3}" || graph2.String() != "{f1, f2, f3}" {
+		t.Fatalf("Graphs not printed with their own names: %v, %v", graph1, graph2)
+	}
+
+	var wg sync.WaitGroup
+	decomps := make([]lib.Decomp, 2)
+	for i, graph := range []lib.Graph{graph1, graph2} {
+		wg.Add(1)
+		go func(i int, graph lib.Graph) {
+			defer wg.Done()
+			solver := &algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2}
+			solver.SetGenerator(lib.ParallelSearchGen{})
+			decomps[i] = solver.FindDecomp()
+		}(i, graph)
+	}
+	wg.Wait()
+
+	if !decomps[0].Correct(graph1) || !decomps[1].Correct(graph2) {
+		t.Fatalf("Concurrent decompositions not correct: %v, %v", decomps[0], decomps[1])
+	}
+	if strings.Contains(decomps[0].String(), "f1") || strings.Contains(decomps[1].String(), "e1") {
+		t.Errorf("Decompositions printed with wrong names: %v, %v", decomps[0], decomps[1])
+	}
+}
+
+// TestCancelledSearch checks that algorithms return no decomposition when the context of their search is done
+func TestCancelledSearch(t *testing.T) {
+	graph, _ := getRandomGraph(8)
+	for graph.Edges.Len() <= 2 { // smaller graphs are decomposed without any search
+		graph, _ = getRandomGraph(8)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	solvers := []algo.Algorithm{
+		&algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2},
+		&algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2},
+		&algo.BalSepGlobal{K: 2, Graph: graph, BalFactor: 2},
+	}
+
+	for _, solver := range solvers {
+		solver.SetGenerator(lib.ParallelSearchGen{Ctx: ctx})
+		decomp := solver.FindDecomp()
+
+		if decomp.Found() {
+			t.Errorf("%v found a decomposition after being cancelled: %v", solver.Name(), decomp)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func checkConnectors(t *testing.T, n lib.Node) {
+	for _, c := range n.Children {
+		if !reflect.DeepEqual(c.Conn, lib.Inter(n.Bag, c.Bag)) {
+			t.Errorf("Connector %v doesn't match bags %v and %v", c.Conn, n.Bag, c.Bag)
+		}
+		checkConnectors(t, c)
+	}
+}
+
+// TestConnectors checks that connectors are set for each node and survive the JSON export
+func TestConnectors(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, encoding := getRandomGraph(6)
+
+		det := &algo.DetKDecomp{K: 3, Graph: graph, BalFactor: 2}
+		decomp := det.FindDecomp()
+		if !decomp.Correct(graph) {
+			continue
+		}
+		decomp.SetConnectors()
+
+		if len(decomp.Root.Conn) > 0 {
+			t.Errorf("Root has connector %v", decomp.Root.Conn)
+		}
+		checkConnectors(t, decomp.Root)
+
+		parsed := lib.GetDecomp(lib.WriteDecomp(decomp), graph, encoding)
+		if !reflect.DeepEqual(parsed.Root.IntoJson(), decomp.Root.IntoJson()) {
+			t.Errorf("Connectors lost in JSON export: %v, %v", decomp, parsed)
+		}
+	}
+}
+
+// TestDOT checks that the DOT export contains one line per node and one per tree edge
+func TestDOT(t *testing.T) {
+	graph, _, err := lib.GetGraphFormat("hyperbench", "e1(a,b,c),\ne2(c,d),\ne3(d,e,a),\ne4(e,f).")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	det := &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}
+	decomp := det.FindDecomp()
+	if !decomp.Correct(graph) {
+		t.Fatal("No decomposition found")
+	}
+
+	var count func(n lib.Node) int
+	count = func(n lib.Node) int {
+		out := 1
+		for _, c := range n.Children {
+			out = out + count(c)
+		}
+		return out
+	}
+	nodes := count(decomp.Root)
+
+	dot := decomp.ToDOT()
+	if strings.Count(dot, "[label=") != nodes || strings.Count(dot, " -- ") != nodes-1 {
+		t.Errorf("DOT output doesn't match decomposition with %v nodes: %v", nodes, dot)
+	}
+}
@@ -52,3 +52,31 @@ func TestCover2(t *testing.T) {
 		gen.NextSubset()
 	}
 }
+
+// TestMinCover checks that MinCover finds a cover of the vertices, and that no cover with fewer edges exists
+func TestMinCover(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		graph, _ := getRandomGraph(8)
+		vertices := graph.Edges.Vertices()
+
+		cover := lib.MinCover(vertices, graph.Edges)
+		if !lib.Subset(vertices, cover.Vertices()) {
+			t.Fatalf("Cover %v doesn't cover %v", cover, graph)
+		}
+
+		// check all smaller subsets of edges
+		n := graph.Edges.Len()
+		for s := 0; s < 1<<uint(n); s++ {
+			var subset []lib.Edge
+			for j := 0; j < n; j++ {
+				if s&(1<<uint(j)) > 0 {
+					subset = append(subset, graph.Edges.Slice()[j])
+				}
+			}
+			smaller := lib.NewEdges(subset)
+			if len(subset) < cover.Len() && lib.Subset(vertices, smaller.Vertices()) {
+				t.Fatalf("Found cover %v smaller than %v for %v", smaller, cover, graph)
+			}
+		}
+	}
+}
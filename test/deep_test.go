@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/debug"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestDeepDecomp checks that validating and rerooting a very deep decomposition works with a small stack
+func TestDeepDecomp(t *testing.T) {
+	const depth = 20000
+
+	var buffer bytes.Buffer
+	for i := 0; i < depth; i++ {
+		buffer.WriteString(fmt.Sprintf("e%d(v%d,v%d)", i, i, i+1))
+		if i < depth-1 {
+			buffer.WriteString(",\n")
+		}
+	}
+	buffer.WriteString(".")
+	graph, _ := lib.GetGraph(buffer.String())
+
+	// a path of nodes, each covering one edge
+	edges := graph.Edges.Slice()
+	leaf := lib.Node{Bag: edges[depth-1].Vertices, Cover: lib.NewEdges([]lib.Edge{edges[depth-1]})}
+	root := leaf
+	for i := depth - 2; i >= 0; i-- {
+		root = lib.Node{Bag: edges[i].Vertices, Cover: lib.NewEdges([]lib.Edge{edges[i]}), Children: []lib.Node{root}}
+	}
+	decomp := lib.Decomp{Graph: graph, Root: root}
+
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20)) // far too small for recursing along the path
+
+	if !decomp.Correct(graph) {
+		t.Fatal("Deep decomposition not correct")
+	}
+
+	decomp.RestoreSubedges()
+	decomp.Root = decomp.Root.Reroot(leaf)
+	if !decomp.Correct(graph) {
+		t.Error("Deep decomposition not correct after rerooting")
+	}
+
+	// the old root is now at the bottom of the path
+	last := decomp.Root
+	for len(last.Children) > 0 {
+		last = last.Children[len(last.Children)-1]
+	}
+	if !lib.Subset(last.Bag, edges[0].Vertices) {
+		t.Errorf("Last node after rerooting has bag %v, expected the old root", last.Bag)
+	}
+}
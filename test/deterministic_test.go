@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/disjoint"
+)
+
+// TestDeterministic checks that components are returned in the order of their first edges, and that with sequential
+// searches and recursive calls, repeated runs of the algorithms produce the very same decomposition, even if the
+// search space is split among several generators
+func TestDeterministic(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	algo.Sequential()
+	defer algo.SetProcs(0)
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+		sep := lib.NewEdges([]lib.Edge{graph.Edges.Slice()[r.Intn(graph.Edges.Len())]})
+
+		comps, _, _ := graph.GetComponents(sep, make(map[int]*disjoint.Element))
+		position := make(map[int]int)
+		for j, e := range graph.Edges.Slice() {
+			position[e.Name] = j
+		}
+		for j := 1; j < len(comps); j++ {
+			if position[comps[j-1].Edges.Slice()[0].Name] > position[comps[j].Edges.Slice()[0].Name] {
+				t.Errorf("Components of %v w.r.t. %v not in the order of their first edges: %v", graph, sep, comps)
+			}
+		}
+
+		k := r.Intn(2) + 1
+		solvers := []algo.Algorithm{
+			&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2},
+			&algo.BalSepGlobal{K: k, Graph: graph.ComputeSubEdges(k), BalFactor: 2},
+			&algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2},
+		}
+		for _, solver := range solvers {
+			var first string
+			for run := 0; run < 3; run++ {
+				clone := solver.Clone()
+				clone.SetGenerator(lib.ParallelSearchGen{Sequential: true})
+				decomp := clone.FindDecomp().String()
+
+				if run == 0 {
+					first = decomp
+				} else if decomp != first {
+					t.Errorf("%v on %v at width %v produced different decompositions:\n%v\n%v", solver.Name(), graph,
+						k, first, decomp)
+				}
+			}
+		}
+	}
+}
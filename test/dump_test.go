@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestDumpSubtrees checks that dumping subtrees doesn't affect the decompositions, and that a file is written for
+// each subtree
+func TestDumpSubtrees(t *testing.T) {
+	cycle, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e),\ne5(e,f),\ne6(f,g),\ne7(g,h),\ne8(h,a).")
+
+	dir, err := ioutil.TempDir("", "subtrees")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	solvers := []algo.Algorithm{
+		&algo.DetKDecomp{K: 2, Graph: cycle, BalFactor: 2, Dumper: &lib.SubtreeDumper{Dir: dir}},
+		&algo.BalSepLocal{K: 2, Graph: cycle, BalFactor: 2, Dumper: &lib.SubtreeDumper{Dir: dir}},
+		&algo.BalSepGlobal{K: 2, Graph: cycle.ComputeSubEdges(2), BalFactor: 2, Dumper: &lib.SubtreeDumper{Dir: dir}},
+	}
+
+	for _, solver := range solvers {
+		solver.SetGenerator(lib.ParallelSearchGen{})
+		decomp := solver.FindDecomp()
+		decomp.Graph = cycle
+		if !decomp.Correct(cycle) {
+			t.Errorf("%v found no correct decomposition when dumping subtrees: %v", solver.Name(), decomp)
+		}
+	}
+
+	dumper := solvers[0].(*algo.DetKDecomp).Dumper
+	if dumper.Count() == 0 {
+		t.Fatal("No subtrees dumped")
+	}
+	for i := 1; i <= dumper.Count(); i++ {
+		for _, ext := range []string{".txt", ".gml"} {
+			path := filepath.Join(dir, fmt.Sprintf("subtree%05d%s", i, ext))
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("Subtree %v not written: %v", path, err)
+			}
+		}
+	}
+}
+
+// TestDumpNotify checks that a dumper without directory reports each subtree to its callback, ending with the full
+// decomposition
+func TestDumpNotify(t *testing.T) {
+	cycle, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e),\ne5(e,f),\ne6(f,g),\ne7(g,h),\ne8(h,a).")
+
+	var mux sync.Mutex
+	var subtrees []lib.Decomp
+	dumper := &lib.SubtreeDumper{Notify: func(decomp lib.Decomp) {
+		mux.Lock()
+		defer mux.Unlock()
+		subtrees = append(subtrees, decomp)
+	}}
+
+	solver := &algo.DetKDecomp{K: 2, Graph: cycle, BalFactor: 2, Dumper: dumper}
+	solver.SetGenerator(lib.ParallelSearchGen{})
+	decomp := solver.FindDecomp()
+	decomp.Graph = cycle
+	if !decomp.Correct(cycle) {
+		t.Fatalf("No correct decomposition found: %v", decomp)
+	}
+
+	if len(subtrees) == 0 || len(subtrees) != dumper.Count() {
+		t.Fatalf("%v subtrees reported, %v dumped", len(subtrees), dumper.Count())
+	}
+	if last := subtrees[len(subtrees)-1]; last.Graph.Edges.Len() != cycle.Edges.Len() {
+		t.Errorf("Last subtree not of the whole graph: %v", last.Graph)
+	}
+}
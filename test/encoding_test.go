@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/disjoint"
 )
 
 func TestEncoding(t *testing.T) {
@@ -42,3 +44,84 @@ func TestEncoding(t *testing.T) {
 	}
 
 }
+
+// TestDerivedNames checks that graphs and edges derived from a parsed graph keep the names of its edges, even after
+// another graph reusing the same integers for different names was parsed
+func TestDerivedNames(t *testing.T) {
+	graph, _ := lib.GetGraph("R(x,y),\nS(y,z),\nT(z,x),\nU(z,w).")
+	lib.GetGraph("A(p,q),\nB(q,r),\nC(r,p),\nD(r,s).")
+
+	named := func(g lib.Graph) bool {
+		for _, e := range g.Edges.Slice() {
+			if e.Name == 0 {
+				continue // subedges have no name of their own
+			}
+			if orig, ok := graph.EdgeByName(g.Encoding().Name(e.Name)); !ok || !lib.Subset(e.Vertices, orig.Vertices) {
+				return false
+			}
+		}
+		return true
+	}
+
+	sep, _ := graph.EdgeByName("U")
+	comps, _, _ := graph.GetComponents(lib.NewEdges([]lib.Edge{sep}), make(map[int]*disjoint.Element))
+	reduced, _, _ := graph.TypeCollapse()
+	vertices := sep.Vertices
+
+	derived := append(comps,
+		graph.ComputeSubEdges(2),
+		reduced,
+		graph.WithEdges(graph.GetSubset([]int{0, 3})),
+		graph.WithEdges(lib.FilterVertices(graph.Edges, vertices)),
+		graph.WithEdges(lib.FilterVerticesStrict(graph.Edges, vertices)),
+		graph.WithEdges(lib.CutEdges(graph.Edges, vertices)),
+	)
+	for _, g := range derived {
+		if !named(g) || !strings.ContainsAny(g.String(), "RSTU") {
+			t.Errorf("Derived graph %v doesn't use the names of %v", g, graph)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Subset with index out of range didn't panic")
+		}
+	}()
+	graph.GetSubset([]int{4})
+}
+
+// TestGeneratedNames checks that vertices and edges without a name of their own still print with a name, which is
+// the same on each run
+func TestGeneratedNames(t *testing.T) {
+	graph, _ := lib.GetGraph("R(x,y),\nS(y,_R),\nT(_R,x).")
+	enc := graph.Encoding()
+
+	added := graph.MakeEdgesDistinct()
+	var names []string
+	for _, v := range added {
+		names = append(names, enc.Name(v))
+	}
+	if fmt.Sprint(names) != "[_R_2 _S _T]" {
+		t.Errorf("Vertices added to make edges distinct named %v", names)
+	}
+
+	reserved := enc.Len()
+	enc.Reserve(1)
+	if name := enc.Name(reserved); name != fmt.Sprint("_", reserved) {
+		t.Errorf("Unnamed integer %v printed as %q", reserved, name)
+	}
+	if i, ok := enc.ID(enc.Name(reserved)); !ok || i != reserved {
+		t.Errorf("Generated name %v resolved to %v, %v", enc.Name(reserved), i, ok)
+	}
+	if _, ok := enc.ID(fmt.Sprint("_", reserved+1)); ok {
+		t.Errorf("Name of an unused integer resolved")
+	}
+
+	r, _ := graph.EdgeByName("R")
+	subedge := lib.Edge{Vertices: r.Vertices[:1]}
+	node := lib.Node{Bag: subedge.Vertices, Cover: lib.NewEdges([]lib.Edge{r, subedge})}
+	decomp := lib.Decomp{Graph: graph, Root: node}
+	if cover := decomp.IntoJson().Root.Cover; fmt.Sprint(cover) != "[R (x)]" {
+		t.Errorf("Cover with a subedge printed as %v", cover)
+	}
+}
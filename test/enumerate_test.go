@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestEnumerate checks that all decompositions enumerated are correct and distinct, that there are some exactly if
+// DetK finds one, and that the limit is respected
+func TestEnumerate(t *testing.T) {
+	path, _ := lib.GetGraph("e1(a,b),\ne2(b,c).")
+	det := &algo.DetKDecomp{K: 1, Graph: path, BalFactor: 2}
+	det.SetGenerator(lib.ParallelSearchGen{})
+	if decomps := det.Enumerate(10); len(decomps) != 2 {
+		t.Errorf("expected both decompositions of a path of two edges, got %v", decomps)
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+		for k := 1; k <= 3; k++ {
+			det := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			det.SetGenerator(lib.ParallelSearchGen{})
+			found := det.FindDecomp().Found()
+
+			enumerator := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			enumerator.SetGenerator(lib.ParallelSearchGen{})
+			decomps := enumerator.Enumerate(20)
+			if found != (len(decomps) > 0) || len(decomps) > 20 {
+				t.Fatalf("width %d: enumerated %d decompositions, DetK found one: %v", k, len(decomps), found)
+			}
+
+			seen := make(map[string]bool)
+			for _, decomp := range decomps {
+				if !decomp.Correct(graph) || decomp.CheckWidth() > k {
+					t.Errorf("width %d: enumerated decomposition not correct: %v", k, decomp)
+				}
+				if seen[decomp.CanonicalKey()] {
+					t.Errorf("width %d: decomposition enumerated twice: %v", k, decomp)
+				}
+				seen[decomp.CanonicalKey()] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/eval"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// mapStorage keeps relations in memory, with columns in the order of the vertices of each edge
+type mapStorage map[string][]eval.Tuple
+
+func (m mapStorage) Load(name string, attributes []string) ([]eval.Tuple, error) {
+	return m[name], nil
+}
+
+// TestEvaluate checks the answers of a cyclic query evaluated along a decomposition against a naive nested loop
+func TestEvaluate(t *testing.T) {
+	graph, pgraph, err := lib.GetGraphFormat("hyperbench", "R(x,y),\nS(y,z),\nT(z,x),\nU(x,w).")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := mapStorage{
+		"R": {{"1", "2"}, {"2", "3"}, {"1", "3"}},
+		"S": {{"2", "3"}, {"3", "1"}, {"3", "4"}},
+		"T": {{"3", "1"}, {"1", "1"}, {"4", "2"}},
+		"U": {{"1", "a"}, {"1", "b"}, {"2", "c"}, {"5", "d"}},
+	}
+
+	expected := 0
+	for _, r := range storage["R"] {
+		for _, s := range storage["S"] {
+			for _, tt := range storage["T"] {
+				for _, u := range storage["U"] {
+					if r[1] == s[0] && s[1] == tt[0] && tt[1] == r[0] && u[0] == r[0] {
+						expected++
+					}
+				}
+			}
+		}
+	}
+
+	det := &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}
+	decomp := det.FindDecomp()
+	if !decomp.Correct(graph) {
+		t.Fatal("No decomposition found")
+	}
+
+	evaluator := eval.Evaluator{Graph: graph, Encoding: pgraph.Encoding, Storage: storage}
+	count, err := evaluator.Count(decomp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != expected {
+		t.Errorf("Wrong number of answers: %v, expected %v", count, expected)
+	}
+
+	first := 0
+	err = evaluator.Evaluate(decomp, func(eval.Tuple) bool {
+		first++
+		return false
+	})
+	if err != nil || first != 1 {
+		t.Errorf("Evaluation didn't stop after first answer")
+	}
+}
+
+// TestPlan runs the plan of a decomposition step by step on the relations, and checks that it produces the same
+// answers as the evaluator
+func TestPlan(t *testing.T) {
+	graph, pgraph, err := lib.GetGraphFormat("hyperbench", "R(x,y),\nS(y,z),\nT(z,x),\nU(x,w),\nV(v).")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := mapStorage{
+		"R": {{"1", "2"}, {"2", "3"}, {"1", "3"}},
+		"S": {{"2", "3"}, {"3", "1"}, {"3", "4"}},
+		"T": {{"3", "1"}, {"1", "1"}, {"4", "2"}},
+		"U": {{"1", "a"}, {"1", "b"}, {"2", "c"}, {"5", "d"}},
+		"V": {{"p"}, {"q"}},
+	}
+
+	det := &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}
+	decomp := det.FindDecomp()
+	if !decomp.Correct(graph) {
+		t.Fatal("No decomposition found")
+	}
+
+	evaluator := eval.Evaluator{Graph: graph, Encoding: pgraph.Encoding, Storage: storage}
+	expected, err := evaluator.Count(decomp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := evaluator.Plan(decomp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vertices := func(names []string) []int {
+		var output []int
+		for _, n := range names {
+			output = append(output, pgraph.Encoding[n])
+		}
+		return output
+	}
+	load := func(r eval.PlanRelation) eval.Relation {
+		return eval.Relation{Attributes: vertices(r.Attributes), Tuples: storage[r.Name]}
+	}
+
+	if len(plan.Bags) == 0 || plan.Bags[0].Parent != -1 {
+		t.Fatalf("Plan doesn't start at the root: %v", plan)
+	}
+	bags := make([]eval.Relation, len(plan.Bags))
+	for i, bag := range plan.Bags {
+		if i > 0 && (bag.Parent < 0 || bag.Parent >= i) {
+			t.Fatalf("Bag %v comes before its parent in %v", i, plan)
+		}
+		r := eval.Relation{Tuples: []eval.Tuple{{}}}
+		for _, c := range bag.Cover {
+			r = r.Join(load(c))
+		}
+		r = r.Project(vertices(bag.Attributes))
+		for _, f := range bag.Filters {
+			r = r.Semijoin(load(f))
+		}
+		bags[i] = r
+	}
+	for _, s := range plan.Steps {
+		switch s.Op {
+		case "semijoin":
+			bags[s.Target] = bags[s.Target].Semijoin(bags[s.Source])
+		case "join":
+			bags[s.Target] = bags[s.Target].Join(bags[s.Source])
+		default:
+			t.Fatalf("Unknown operation %v in plan", s.Op)
+		}
+	}
+
+	answers := bags[0].Project(vertices(plan.Attributes))
+	if len(answers.Tuples) != expected {
+		t.Errorf("Plan produced %v answers, expected %v", len(answers.Tuples), expected)
+	}
+	sql := plan.SQL() // a CTE for each bag and step
+	if !strings.HasPrefix(sql, "WITH") || strings.Count(sql, " AS (") != len(plan.Bags)+len(plan.Steps) {
+		t.Errorf("Unexpected SQL for plan: %v", sql)
+	}
+}
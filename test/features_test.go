@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestFeatures checks the features and distributions of small graphs computed by hand, and that acyclicity agrees
+// with the existence of a decomposition of width 1 on random graphs
+func TestFeatures(t *testing.T) {
+	triangle, _ := lib.GetGraph("R(x,y),\nS(y,z),\nT(z,x),\nU(v,w).")
+	got := triangle.Features()
+	want := lib.Features{
+		Vertices:            5,
+		Edges:               4,
+		MaxArity:            2,
+		MeanArity:           2,
+		MaxDegree:           2,
+		MeanDegree:          8.0 / 5,
+		IntersectionSize:    1,
+		IntersectionDensity: 3.0 / 6,
+		PrimalDensity:       8.0 / 20,
+		LargestClique:       3,
+		Components:          2,
+		Acyclic:             false,
+		ReducedEdges:        3,
+		LargestHinge:        3,
+	}
+	if got != want {
+		t.Errorf("Features of triangle: %+v, expected %+v", got, want)
+	}
+
+	if got := triangle.DegreeDistribution(); !reflect.DeepEqual(got, map[int]int{1: 2, 2: 3}) {
+		t.Errorf("Degree distribution of triangle: %v", got)
+	}
+	if got := triangle.ArityDistribution(); !reflect.DeepEqual(got, map[int]int{2: 4}) {
+		t.Errorf("Arity distribution of triangle: %v", got)
+	}
+
+	path, _ := lib.GetGraph("R(a,b,c),\nS(b,c,d),\nT(d,e).")
+	if got := path.Features(); !got.Acyclic || got.IntersectionSize != 2 || got.Components != 1 {
+		t.Errorf("Features of path: %+v", got)
+	}
+
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(6)
+		features := graph.Features()
+
+		det := &algo.DetKDecomp{K: 1, Graph: graph, BalFactor: 2}
+		if found := det.FindDecomp().Correct(graph); found != features.Acyclic {
+			t.Errorf("Graph %v reported as acyclic: %v, but width 1 decomposition found: %v", graph,
+				features.Acyclic, found)
+		}
+		if features.LargestClique > features.Vertices || features.LargestHinge > features.Edges {
+			t.Errorf("Features out of bounds for %v: %+v", graph, features)
+		}
+	}
+}
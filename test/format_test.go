@@ -0,0 +1,287 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestIncidence checks that a hypergraph given as incidence list parses to the same graph as in HyperBench format
+func TestIncidence(t *testing.T) {
+	hyperBench := "e1(a,b,c),\ne2(c,d),\ne3(d,e,a)."
+	incidence := "% comment\na e1\nb e1\nc,e1\nc e2\nd e2\nd e3\n\ne e3\na e3\na e3\n"
+
+	graph1, _, err := lib.GetGraphFormat("hyperbench", hyperBench)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph1String := graph1.Edges.FullString()
+
+	graph2, _, err := lib.GetGraphFormat("incidence", incidence)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph2String := graph2.Edges.FullString()
+
+	if graph1String != graph2String {
+		t.Errorf("Incidence list parsed differently: %v, %v", graph1String, graph2String)
+	}
+
+	if _, _, err := lib.GetGraphFormat("unknown", incidence); err == nil {
+		t.Errorf("Unknown format not rejected")
+	}
+}
+
+// TestGraphJSON checks that a hypergraph given as JSON object parses to the same graph as in HyperBench format
+func TestGraphJSON(t *testing.T) {
+	hyperBench := "e1(a,b,c),\ne2(c,d),\ne3(d,e,a)."
+	jsonGraph := `{"e3": ["d", "e", "a"], "e1": ["a", "b", "c"], "e2": ["c", "d"]}`
+
+	graph1, _, err := lib.GetGraphFormat("hyperbench", hyperBench)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph2, pgraph, err := lib.GetGraphFormat("json", jsonGraph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if graph1.Edges.FullString() != graph2.Edges.FullString() {
+		t.Errorf("JSON graph parsed differently: %v, %v", graph1.Edges.FullString(), graph2.Edges.FullString())
+	}
+	if _, ok := pgraph.Encoding["e2"]; !ok {
+		t.Errorf("Edge name missing from the encoding")
+	}
+}
+
+// TestGraphXCSP checks that the constraint hypergraph of an XCSP3 instance has one edge per constraint scope, with
+// arrays expanded into their cells, and groups and blocks into their constraints
+func TestGraphXCSP(t *testing.T) {
+	instance := `<instance format="XCSP3" type="CSP">
+  <variables>
+    <var id="x"> 0..5 </var>
+    <var id="unused"> 0 1 </var>
+    <array id="y" size="[2][3]"> 0..9 </array>
+  </variables>
+  <constraints>
+    <intension id="sum"> eq(add(x,y[0][0]),y[1][2]) </intension>
+    <allDifferent> y[0][] </allDifferent>
+    <extension>
+      <list> x y[1][0..1] </list>
+      <supports> (0,1,2)(1,2,3) </supports>
+    </extension>
+    <block>
+      <group id="g">
+        <intension> lt(%0,%1) </intension>
+        <args> x y[0][1] </args>
+        <args> y[0][1] y[1][1] </args>
+      </group>
+      <sum>
+        <list> y[1][] </list>
+        <condition> (le,x) </condition>
+      </sum>
+    </block>
+  </constraints>
+</instance>`
+
+	graph, _, err := lib.GetGraphFormat("xcsp", instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := lib.GetGraph(`sum(x,"y[0][0]","y[1][2]"),
+		c1("y[0][0]","y[0][1]","y[0][2]"),
+		c2(x,"y[1][0]","y[1][1]"),
+		g(x,"y[0][1]"),
+		g_1("y[0][1]","y[1][1]"),
+		c3("y[1][0]","y[1][1]","y[1][2]",x).`)
+	if graph.String() != expected.String() {
+		t.Errorf("XCSP instance parsed as %v, expected %v", graph, expected)
+	}
+
+	parsed := lib.GetGraphXCSP(strings.NewReader(instance))
+	if parsed.Edges.Len() != 6 {
+		t.Errorf("Expected 6 edges, got %v", parsed)
+	}
+}
+
+// TestPACEStreaming checks that a graph survives the round trip through the PACE format, including comments and
+// edges too long for a line-based scanner with default buffer size
+func TestPACEStreaming(t *testing.T) {
+	graph, _ := getRandomGraph(20)
+	pace := "c a comment\n\n" + graph.ToPACE()
+
+	parsed := lib.GetGraphPACE(pace)
+	if parsed.Edges.Len() != graph.Edges.Len() || len(parsed.Vertices()) != len(graph.Vertices()) {
+		t.Errorf("PACE round trip changed graph: %v, %v", graph, parsed)
+	}
+	for i := range graph.Edges.Slice() {
+		if len(parsed.Edges.Slice()[i].Vertices) != len(graph.Edges.Slice()[i].Vertices) {
+			t.Errorf("Edge %v parsed as %v", graph.Edges.Slice()[i], parsed.Edges.Slice()[i])
+		}
+	}
+
+	var buffer bytes.Buffer
+	n := 20000
+	buffer.WriteString(fmt.Sprintf("p htd %d 2\n1", n))
+	for v := 1; v <= n; v++ {
+		buffer.WriteString(fmt.Sprintf(" %d", v))
+	}
+	buffer.WriteString("\n2 1 2") // no final newline
+
+	parsed = lib.GetGraphPACEReader(&buffer)
+	if parsed.Edges.Len() != 2 || len(parsed.Edges.Slice()[0].Vertices) != n || len(parsed.Vertices()) != n {
+		t.Errorf("Long PACE edge not parsed correctly")
+	}
+}
+
+// TestNameLookup checks that edges and vertices can be found by their names, in either input format
+func TestNameLookup(t *testing.T) {
+	graph, _, err := lib.GetGraphFormat("hyperbench", "e1(a,b,c),\ne2(c,d),\ne3(d,e,a).")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := graph.EdgeByName("e2")
+	if !ok || graph.Encoding().PrintEdge(e) != "e2" || graph.Encoding().PrintVertices(e.Vertices) != "(c, d)" {
+		t.Errorf("Edge e2 not found by name: %v, %v", e, ok)
+	}
+	v, ok := graph.VertexByName("d")
+	if !ok || graph.Encoding().Name(v) != "d" {
+		t.Errorf("Vertex d not found by name: %v, %v", v, ok)
+	}
+	if _, ok := graph.EdgeByName("a"); ok {
+		t.Error("Vertex a found as edge")
+	}
+	if _, ok := graph.VertexByName("e1"); ok {
+		t.Error("Edge e1 found as vertex")
+	}
+
+	pace, _, err := lib.GetGraphFormat("pace", "p htd 4 2\n1 1 2\n2 2 3 4\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := pace.EdgeByName("E2"); !ok || len(e.Vertices) != 3 {
+		t.Errorf("Edge E2 not found by name: %v, %v", e, ok)
+	}
+	if _, ok := pace.VertexByName("V4"); !ok {
+		t.Error("Vertex V4 not found by name")
+	}
+}
+
+// TestMarshalJSON checks that decomps are encoded with the names of vertices and edges, using the documented keys
+func TestMarshalJSON(t *testing.T) {
+	graph, encoding := lib.GetGraph("e1(a,b,c),\ne2(c,d),\ne3(d,e,a),\ne4(e,f).")
+	decomp := (&algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}).FindDecomp()
+	if !decomp.Correct(graph) {
+		t.Fatal("No decomposition found")
+	}
+
+	out, err := json.Marshal(decomp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tree struct {
+		Root struct {
+			Bag      []string
+			Cover    []string
+			Children []json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(out, &tree); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Root.Bag) != len(decomp.Root.Bag) || len(tree.Root.Cover) != decomp.Root.Cover.Len() ||
+		len(tree.Root.Children) != len(decomp.Root.Children) {
+		t.Errorf("Root not encoded as expected: %s", out)
+	}
+	for _, name := range tree.Root.Cover {
+		if _, ok := graph.EdgeByName(name); !ok {
+			t.Errorf("Cover contains unknown edge %v: %s", name, out)
+		}
+	}
+
+	parsed := lib.GetDecomp(out, graph, encoding.Encoding)
+	if !parsed.Correct(graph) {
+		t.Errorf("Decomp not correct after parsing %s", out)
+	}
+}
+
+// TestHyperBenchSyntax checks the parser of the HyperBench format on the variants of its syntax, and that malformed
+// input is rejected
+func TestHyperBenchSyntax(t *testing.T) {
+	inputs := map[string]string{
+		"e1(a,b),e2(b,c).": "e1 (a, b), e2 (b, c)",
+		"e1(a b)\ne2(b,c)": "e1 (a, b), e2 (b, c)",
+		"% comment\ne1(a,b), // another\n e2(\"x y\",c).": `e1 (a, b), e2 ("x y", c)`,
+		"e1(a.b,c-d),e2(x.,-5,+3.2,.5).":                  "e1 (a.b, c-d), e2 (x., -5, +3.2, .5)",
+		"1(2,3),\n2b(3,4).\n% trailing comment\n":         "1 (2, 3), 2b (3, 4)",
+		`e1(a,"q\"r"),e2(_x,y!?)`:                         `e1 (a, "q\"r"), e2 (_x, y!?)`,
+	}
+	for input, expected := range inputs {
+		graph, _ := lib.GetGraph(input)
+		var edges []string
+		for _, e := range graph.Edges.Slice() {
+			edges = append(edges, graph.Encoding().PrintFullEdge(e))
+		}
+		if got := strings.Join(edges, ", "); got != expected {
+			t.Errorf("Parsed %q as %v, expected %v", input, got, expected)
+		}
+	}
+
+	for _, input := range []string{"", "% only a comment", "e1(a,b). e2(c)", "e1(a,(b)", "-e(a)", "e1(a,b", "e1(a),e1(b)",
+		"a(a,b)"} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Malformed input %q accepted", input)
+				}
+			}()
+			lib.GetGraph(input)
+		}()
+	}
+}
+
+// TestGzipInput checks that gzip-compressed input parses to the same graph as the uncompressed one
+func TestGzipInput(t *testing.T) {
+	graph, _ := getRandomGraph(20)
+	input := graph.ToHyperBench()
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(input))
+	writer.Close()
+
+	parsed, _, err := lib.GetGraphFormat("hyperbench", compressed.String())
+	if err != nil || parsed.ToHyperBench() != input {
+		t.Errorf("Compressed input parsed as %v, expected %v (error: %v)", parsed, input, err)
+	}
+	if parsed, _ := lib.GetGraphReader(&compressed); compressed.Len() > 0 || parsed.Edges.Len() == 0 {
+		t.Errorf("Compressed input not read from reader")
+	}
+}
+
+// BenchmarkGetGraph measures the parsing of a large hypergraph in HyperBench format
+func BenchmarkGetGraph(b *testing.B) {
+	var buffer strings.Builder
+	for i := 0; i < 100000; i++ {
+		if i > 0 {
+			buffer.WriteString(",\n")
+		}
+		buffer.WriteString(fmt.Sprintf("E%d(v%d,v%d,v%d)", i, i%20000, (i*7)%20000, (i*13)%20000))
+	}
+	buffer.WriteString(".")
+	input := buffer.String()
+
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lib.GetGraph(input)
+	}
+}
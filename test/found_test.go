@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestFound checks that Found tells the empty decomp apart from all others, agreeing with a comparison against the
+// zero value by reflect.DeepEqual on the outputs of the algorithms
+func TestFound(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c).")
+	if (lib.Decomp{}).Found() {
+		t.Errorf("empty decomp found")
+	}
+	if !(lib.Decomp{Graph: graph}).Found() || !(lib.Decomp{Root: lib.Node{Bag: []int{}}}).Found() {
+		t.Errorf("decomp with only a graph or a root not found")
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+		for k := 1; k <= 3; k++ {
+			solvers := []algo.Algorithm{
+				&algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2},
+				&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2},
+				&algo.GreedyDecomp{K: k, Graph: graph},
+			}
+			for _, solver := range solvers {
+				solver.SetGenerator(lib.ParallelSearchGen{})
+				decomp := solver.FindDecomp()
+				if decomp.Found() == reflect.DeepEqual(decomp, lib.Decomp{}) {
+					t.Fatalf("%v, width %d: Found() is %v for %v", solver.Name(), k, decomp.Found(), decomp)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestFractionalCover checks the simplex method on a triangle, and that random covers are valid
+func TestFractionalCover(t *testing.T) {
+	triangle, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,a).")
+	weight, _ := lib.FractionalCover(triangle.Edges.Vertices(), triangle.Edges)
+	if math.Abs(weight-1.5) > 1e-6 {
+		t.Errorf("Fractional cover of triangle has weight %v instead of 1.5", weight)
+	}
+
+	for i := 0; i < 50; i++ {
+		graph, _ := getRandomGraph(8)
+		vertices := graph.Edges.Vertices()
+
+		weight, weights := lib.FractionalCover(vertices, graph.Edges)
+		if weight > float64(graph.Edges.Len())+1e-6 {
+			t.Fatalf("Weight %v larger than number of edges of %v", weight, graph)
+		}
+
+		sum := 0.0
+		covered := make(map[int]float64)
+		for j, e := range graph.Edges.Slice() {
+			sum = sum + weights[j]
+			for _, v := range lib.RemoveDuplicates(append([]int{}, e.Vertices...)) {
+				covered[v] = covered[v] + weights[j]
+			}
+		}
+		if math.Abs(sum-weight) > 1e-6 {
+			t.Errorf("Weights %v don't sum up to %v", weights, weight)
+		}
+		for _, v := range vertices {
+			if covered[v] < 1-1e-6 {
+				t.Errorf("Vertex %v not covered by weights %v of %v", v, weights, graph)
+			}
+		}
+	}
+}
+
+// TestFractional checks that fractional decompositions are correct, and no wider than the integral ones
+func TestFractional(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(6)
+
+		solver := &algo.Fractional{K: 2, Graph: graph, Inner: &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}}
+		decomp := solver.FindDecomp()
+		if !decomp.Correct(graph) {
+			continue
+		}
+
+		if decomp.FractionalWidth() > 2+1e-6 {
+			t.Errorf("Fractional width %v larger than 2: %v", decomp.FractionalWidth(), decomp)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// fuzzGraph decodes a small hypergraph from arbitrary bytes: the lowest three bits of each byte pick one of eight
+// vertices, and a byte with the highest bit set ends the current edge. Edges beyond the eighth and vertices beyond
+// the third of an edge are ignored, so that every algorithm finishes quickly. Since every byte string decodes to a
+// graph, the fuzzer can shrink failing inputs byte by byte.
+func fuzzGraph(data []byte) (lib.Graph, bool) {
+	var edges []string
+	var edge []string
+	seen := make(map[string]bool)
+
+	end := func() {
+		if len(edge) > 0 && len(edges) < 8 {
+			edges = append(edges, fmt.Sprintf("E%d(%s)", len(edges)+1, strings.Join(edge, ",")))
+		}
+		edge = nil
+		seen = make(map[string]bool)
+	}
+	for _, b := range data {
+		v := fmt.Sprint("V", b&7)
+		if !seen[v] && len(edge) < 3 {
+			seen[v] = true
+			edge = append(edge, v)
+		}
+		if b&0x80 != 0 {
+			end()
+		}
+	}
+	end()
+
+	if len(edges) == 0 {
+		return lib.Graph{}, false
+	}
+	graph, _ := lib.GetGraph(strings.Join(edges, ",\n") + ".")
+	return graph, true
+}
+
+// FuzzDecomp runs the algorithms on small hypergraphs and checks the invariants of their output: each decomposition
+// found must cover all edges, keep the nodes containing a vertex connected, have each bag within the vertices of its
+// cover and be of width at most k. Moreover, the algorithms computing GHDs must agree on whether one exists, and
+// must find one whenever an HD exists or BalSepSplit finds one.
+//
+// Without -fuzz, only the seeds below and the regression cases in testdata/fuzz/FuzzDecomp are checked. Run
+//
+//	go test ./test -run FuzzDecomp -fuzz FuzzDecomp -fuzztime 5m
+//
+// to search for failing inputs, which are minimised and stored in testdata/fuzz/FuzzDecomp, and from then on
+// checked as regression cases by each run of the tests.
+func FuzzDecomp(f *testing.F) {
+	f.Add([]byte{0, 0x81, 1, 0x82, 2, 0x80}, uint8(1))                   // triangle
+	f.Add([]byte{0, 0x81, 1, 0x82, 2, 0x83, 3, 0x84, 4, 0x80}, uint8(1)) // cycle of length five
+	f.Add([]byte{0, 0x81, 1, 0x82, 2, 0x83, 3, 0x84, 4, 0x80}, uint8(2))
+	f.Add([]byte{0, 1, 0x82, 2, 3, 0x84, 4, 5, 0x86, 6, 7, 0x80, 1, 3, 5, 0x87}, uint8(2))
+	f.Add([]byte{0, 0x81, 0, 0x82, 0, 0x83, 1, 0x82, 1, 0x83, 2, 0x83}, uint8(2)) // the complete graph on four vertices
+
+	f.Fuzz(func(t *testing.T, data []byte, width uint8) {
+		graph, ok := fuzzGraph(data)
+		if !ok {
+			return
+		}
+		k := int(width)%3 + 1
+
+		check := func(solver algo.Algorithm) bool {
+			solver.SetGenerator(lib.ParallelSearchGen{})
+			decomp := solver.FindDecomp()
+			if !decomp.Found() {
+				return false
+			}
+			decomp.Graph = graph
+			verdict := decomp.Verify(graph)
+			if !verdict.Valid() {
+				t.Errorf("%v at width %v produced an invalid decomposition of %v: %v\n%v", solver.Name(), k,
+					graph, verdict.Problems, decomp)
+			}
+			if verdict.Width > k {
+				t.Errorf("%v at width %v produced a decomposition of width %v for %v:\n%v", solver.Name(), k,
+					verdict.Width, graph, decomp)
+			}
+			return true
+		}
+
+		hd := check(&algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2})
+
+		solvers := []algo.Algorithm{
+			&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2},
+			&algo.BalSepGlobal{K: k, Graph: graph.ComputeSubEdges(k), BalFactor: 2},
+			&algo.BalSepHybrid{K: k, Graph: graph, BalFactor: 2, Depth: 1},
+			&algo.BalSepHybridSeq{K: k, Graph: graph, BalFactor: 2, Depth: 1},
+			&algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2, SubEdge: true},
+		}
+		var found []bool
+		for _, solver := range solvers {
+			found = append(found, check(solver))
+		}
+		// the split without backtracking may fail, but only where no GHD exists or some other separator is needed
+		if check(&algo.BalSepSplit{K: k, Graph: graph, BalFactor: 2}) && !found[0] {
+			t.Errorf("BalSep Split found a decomposition of width %v for %v, which %v didn't", k, graph,
+				solvers[0].Name())
+		}
+		for i := range solvers {
+			if found[i] != found[0] {
+				t.Errorf("%v and %v disagree at width %v on %v: %v and %v", solvers[0].Name(), solvers[i].Name(), k,
+					graph, found[0], found[i])
+			}
+			if hd && !found[i] {
+				t.Errorf("%v found no GHD of width %v for %v, which has an HD of that width", solvers[i].Name(), k,
+					graph)
+			}
+		}
+	})
+}
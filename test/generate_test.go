@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestRandomGraph checks that random hypergraphs have the requested shape, read back as the same graph, and only
+// depend on the seed
+func TestRandomGraph(t *testing.T) {
+	for _, name := range lib.GraphModels() {
+		model, err := lib.ParseGraphModel(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for seed := int64(0); seed < 10; seed++ {
+			graph, err := lib.RandomGraph(model, 20, 15, 4, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if graph.Edges.Len() != 15 || len(graph.Vertices()) > 20 {
+				t.Errorf("Model %v produced %v edges and %v vertices", name, graph.Edges.Len(),
+					len(graph.Vertices()))
+			}
+			for _, e := range graph.Edges.Slice() {
+				if len(e.Vertices) != 4 || len(lib.RemoveDuplicates(append([]int{}, e.Vertices...))) != 4 {
+					t.Errorf("Model %v produced edge %v without 4 distinct vertices", name, e)
+				}
+			}
+
+			out := graph.ToHyperBench()
+			again, _ := lib.RandomGraph(model, 20, 15, 4, seed)
+			if again.ToHyperBench() != out {
+				t.Errorf("Model %v produced different graphs for seed %v", name, seed)
+			}
+			parsed, _ := lib.GetGraph(out)
+			if parsed.ToHyperBench() != out {
+				t.Errorf("Model %v produced %v, read back as %v", name, out, parsed.ToHyperBench())
+			}
+		}
+	}
+
+	if _, err := lib.RandomGraph(lib.Uniform, 3, 5, 4, 1); err == nil {
+		t.Errorf("Arity larger than the number of vertices accepted")
+	}
+	if _, err := lib.ParseGraphModel("smallworld"); err == nil {
+		t.Errorf("Unknown graph model parsed")
+	}
+}
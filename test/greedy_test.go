@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestGreedyDecomp checks that the greedy decompositions are correct, not narrower than the width computed by brute
+// force, and only returned by FindDecomp for large enough widths
+func TestGreedyDecomp(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(10)
+		if i%2 == 0 {
+			graph = getDenseGraph(r, 10, 12)
+		}
+
+		for _, minFill := range []bool{false, true} {
+			greedy := algo.GreedyDecomp{Graph: graph, MinFill: minFill}
+			decomp := greedy.Decompose(graph)
+			if !decomp.Correct(graph) {
+				t.Fatalf("Greedy decomposition (min fill: %v) of %v not correct: %v", minFill, graph, decomp)
+			}
+
+			width := decomp.CheckWidth()
+			if ghw, ok := lib.BruteForceWidth(graph); ok && width < ghw {
+				t.Errorf("Greedy decomposition of width %v below the width %v of %v", width, ghw, graph)
+			}
+
+			greedy.SetWidth(width - 1)
+			if found := greedy.FindDecomp(); found.Correct(graph) {
+				t.Errorf("Greedy decomposition of width %v returned for width %v", width, width-1)
+			}
+			greedy.SetWidth(width)
+			if found := greedy.FindDecomp(); !found.Correct(graph) {
+				t.Errorf("Greedy decomposition of width %v not returned for its width", width)
+			}
+		}
+	}
+}
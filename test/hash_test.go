@@ -286,7 +286,7 @@ func TestGraphHash1(t *testing.T) {
 	s := rand.NewSource(time.Now().UnixNano())
 	r := rand.New(s)
 
-	var Sp []lib.Edges
+	var Sp []lib.SpecialEdge
 
 	lengthSpeciale := r.Intn(20) + 1
 
@@ -306,7 +306,7 @@ func TestGraphHash1(t *testing.T) {
 			slice = append(slice, lib.Edge{Vertices: vertices})
 		}
 
-		Sp = append(Sp, lib.NewEdges(slice))
+		Sp = append(Sp, lib.NewSpecialEdge(lib.NewEdges(slice)))
 	}
 
 	for x := 0; x < 100; x++ {
@@ -359,7 +359,7 @@ func TestGraphHash2(t *testing.T) {
 	s := rand.NewSource(time.Now().UnixNano())
 	r := rand.New(s)
 
-	var Sp []lib.Edges
+	var Sp []lib.SpecialEdge
 
 	lengthSpeciale := r.Intn(20) + 1
 
@@ -379,7 +379,7 @@ func TestGraphHash2(t *testing.T) {
 			slice = append(slice, lib.Edge{Vertices: vertices})
 		}
 
-		Sp = append(Sp, lib.NewEdges(slice))
+		Sp = append(Sp, lib.NewSpecialEdge(lib.NewEdges(slice)))
 	}
 
 	// generate two different edges and see if their hashs collide
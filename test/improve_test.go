@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestImprove checks that improving trivial and greedy decompositions keeps them correct, never makes them wider,
+// and never goes below the width computed by brute force
+func TestImprove(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(10)
+		if i%2 == 0 {
+			graph = getDenseGraph(r, 10, 12)
+		}
+
+		decomps := []lib.Decomp{lib.TrivialDecomp(graph), algo.GreedyDecomp{Graph: graph}.Decompose(graph)}
+		for _, decomp := range decomps {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			improved := decomp.Improve(ctx)
+			cancel()
+
+			if !improved.Correct(graph) {
+				t.Fatalf("improving %v produced an incorrect decomposition: %v", decomp, improved)
+			}
+			if improved.Width() > decomp.Width() {
+				t.Errorf("improving raised the width from %v to %v", decomp.Width(), improved.Width())
+			}
+			if ghw, ok := lib.BruteForceWidth(graph); ok && improved.Width() < ghw {
+				t.Errorf("improved decomposition of width %v below the width %v of %v", improved.Width(), ghw, graph)
+			}
+		}
+	}
+
+	// the trivial decomposition of a path can be split all the way down to width 1
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e),\ne5(e,f),\ne6(f,g).")
+	improved := lib.TrivialDecomp(graph).Improve(context.Background())
+	if !improved.Correct(graph) || improved.Width() != 1 {
+		t.Errorf("trivial decomposition of a path improved to width %v: %v", improved.Width(), improved)
+	}
+
+	// with the context done, the decomposition is only minimized
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	trivial := lib.TrivialDecomp(graph)
+	if improved := trivial.Improve(ctx); improved.Width() != trivial.Minimize(trivial.Width()).Width() {
+		t.Errorf("decomposition improved to width %v, though the context was done", improved.Width())
+	}
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestInfeasible checks that widths reported as infeasible indeed have no decomposition, and that the clique
+// condition catches a simple cyclic graph
+func TestInfeasible(t *testing.T) {
+	triangle, _ := lib.GetGraph("R(x,y),\nS(y,z),\nT(z,x).")
+	if infeasible, _ := triangle.Infeasible(1); !infeasible {
+		t.Errorf("Width 1 not reported infeasible for triangle")
+	}
+	if infeasible, reason := triangle.Infeasible(2); infeasible {
+		t.Errorf("Width 2 reported infeasible for triangle: %v", reason)
+	}
+
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(8)
+
+		for k := 1; k <= 3; k++ {
+			infeasible, reason := graph.Infeasible(k)
+			if !infeasible {
+				continue
+			}
+			det := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			if decomp := det.FindDecomp(); decomp.Correct(graph) {
+				t.Errorf("Width %v reported infeasible (%v), but found %v", k, reason, decomp)
+			}
+		}
+	}
+
+	// the 40 vertices need 20 of the binary edges, which an unbounded search only finds out after trying all 39^10
+	// choices of 10 edges
+	var k40 []string
+	for i := 0; i < 40; i++ {
+		for j := i + 1; j < 40; j++ {
+			k40 = append(k40, fmt.Sprintf("E%v_%v(v%v,v%v)", i, j, i, j))
+		}
+	}
+	clique, _ := lib.GetGraph(strings.Join(k40, ",\n") + ".")
+	if infeasible, reason := clique.Infeasible(10); infeasible {
+		t.Errorf("Width 10 reported infeasible for a clique the budget doesn't suffice for: %v", reason)
+	}
+}
+
+// TestTrivialDecomp checks that the single node decomposition is correct and as wide as the number of edges
+func TestTrivialDecomp(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(8)
+
+		decomp := lib.TrivialDecomp(graph)
+		if !decomp.Correct(graph) {
+			t.Errorf("Trivial decomposition not correct: %v", decomp)
+		}
+		if decomp.CheckWidth() != graph.Edges.Len() {
+			t.Errorf("Trivial decomposition has width %v for %v edges", decomp.CheckWidth(), graph.Edges.Len())
+		}
+	}
+}
+
+// TestLowerBound checks the lower bound on graphs of known width, and that no decomposition below it is found on
+// random graphs
+func TestLowerBound(t *testing.T) {
+	var k5 []string
+	for i := 0; i < 5; i++ {
+		for j := i + 1; j < 5; j++ {
+			k5 = append(k5, fmt.Sprintf("E%v%v(v%v,v%v)", i, j, i, j))
+		}
+	}
+	clique, _ := lib.GetGraph(strings.Join(k5, ",\n") + ".")
+	triangle, _ := lib.GetGraph("R(x,y),\nS(y,z),\nT(z,x).")
+	path, _ := lib.GetGraph("R(a,b,c),\nS(b,c,d),\nT(d,e).")
+
+	for _, c := range []struct {
+		graph lib.Graph
+		bound int
+	}{{clique, 3}, {triangle, 2}, {path, 1}} {
+		if got := lib.LowerBound(c.graph); got != c.bound {
+			t.Errorf("Lower bound of %v: %v, expected %v", c.graph, got, c.bound)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(8)
+		bound := lib.LowerBound(graph)
+
+		for k := 1; k < bound; k++ {
+			det := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			if decomp := det.FindDecomp(); decomp.Correct(graph) {
+				t.Errorf("Lower bound %v, but found %v of width %v", bound, decomp, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestIsolatedVertices checks that vertices in no edge are kept by the parsers, are required in some bag by the
+// checks, and are added to a decomposition by AddIsolated without changing its width
+func TestIsolatedVertices(t *testing.T) {
+	pace := lib.GetGraphPACE("p htd 5 3\n1 1 2\n2 2 3\n3 3 1\n")
+	incidence, _, err := lib.GetGraphFormat("incidence", "a e1\nb e1\nz\nb e2\nc e2\nc\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		graph    lib.Graph
+		isolated []string
+	}{{pace, []string{"V4", "V5"}}, {incidence, []string{"z"}}} {
+		var names []string
+		for _, v := range test.graph.Isolated {
+			names = append(names, test.graph.Encoding().Name(v))
+		}
+		if strings.Join(names, ",") != strings.Join(test.isolated, ",") {
+			t.Fatalf("isolated vertices %v, expected %v", names, test.isolated)
+		}
+
+		var buffer bytes.Buffer
+		if err := gob.NewEncoder(&buffer).Encode(test.graph); err != nil {
+			t.Fatal(err)
+		}
+		var decoded lib.Graph
+		if err := gob.NewDecoder(&buffer).Decode(&decoded); err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded.Isolated) != len(test.graph.Isolated) {
+			t.Errorf("isolated vertices %v lost by gob encoding", test.graph.Isolated)
+		}
+
+		det := &algo.DetKDecomp{K: 2, Graph: test.graph, BalFactor: 2}
+		det.SetGenerator(lib.ParallelSearchGen{})
+		decomp := det.FindDecomp()
+		if decomp.Verify(test.graph).EdgesCovered {
+			t.Errorf("decomp without the isolated vertices %v accepted", names)
+		}
+
+		width := decomp.CheckWidth()
+		decomp.Graph = test.graph
+		decomp = decomp.AddIsolated()
+		if !decomp.Correct(test.graph) || !decomp.CorrectTD(test.graph) || decomp.CheckWidth() != width {
+			t.Errorf("decomp with isolated vertices %v not correct or of changed width: %v", names, decomp)
+		}
+		if again := decomp.AddIsolated(); len(again.Root.Children) != len(decomp.Root.Children) {
+			t.Errorf("isolated vertices added twice")
+		}
+	}
+}
+
+// TestEmptyEdges checks that edges without vertices are accepted by the parsers and don't trip up the algorithms
+func TestEmptyEdges(t *testing.T) {
+	graph, _, err := lib.GetGraphFormat("json", `{"e1": ["a", "b"], "e2": [], "e3": ["b", "c"], "e4": ["c", "a"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graph.Edges.Len() != 4 {
+		t.Fatalf("expected 4 edges, got %v", graph)
+	}
+
+	solvers := []algo.Algorithm{
+		&algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2},
+		&algo.BalSepGlobal{K: 2, Graph: graph, BalFactor: 2},
+		&algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2},
+		&algo.GreedyDecomp{K: 2, Graph: graph},
+	}
+	for _, solver := range solvers {
+		solver.SetGenerator(lib.ParallelSearchGen{})
+		decomp := solver.FindDecomp()
+		decomp.Graph = graph
+		if !decomp.Correct(graph) {
+			t.Errorf("%v found no correct decomp of %v", solver.Name(), graph)
+		}
+	}
+
+	empty, _ := lib.GetGraph("e1(),\ne2().")
+	decomp := algo.GreedyDecomp{K: 1}.Decompose(empty)
+	if !decomp.Correct(empty) {
+		t.Errorf("no greedy decomp of a graph with only empty edges")
+	}
+}
@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestIsoMapping checks that two isomorphic components attached to the same separator are detected, and that a
+// decomposition of one can be transferred to the other
+func TestIsoMapping(t *testing.T) {
+	sep := lib.Edge{Name: 100, Vertices: []int{1}}
+	e1 := lib.Edge{Name: 101, Vertices: []int{1, 2}}
+	e2 := lib.Edge{Name: 102, Vertices: []int{2, 3}}
+	e3 := lib.Edge{Name: 103, Vertices: []int{1, 4}}
+	e4 := lib.Edge{Name: 104, Vertices: []int{4, 5}}
+	e5 := lib.Edge{Name: 105, Vertices: []int{4, 5, 6}}
+
+	all := lib.NewEdges([]lib.Edge{sep, e1, e2, e3, e4, e5})
+	comp1 := lib.Graph{Edges: lib.NewEdges([]lib.Edge{e1, e2})}
+	comp2 := lib.Graph{Edges: lib.NewEdges([]lib.Edge{e3, e4})}
+	comp3 := lib.Graph{Edges: lib.NewEdges([]lib.Edge{e3, e5})}
+	fixed := sep.Vertices
+
+	if comp1.IsoHash(fixed) != comp2.IsoHash(fixed) {
+		t.Errorf("Isomorphic components hashed differently")
+	}
+	if comp1.IsoMapping(comp3, fixed) != nil {
+		t.Errorf("Found mapping between non-isomorphic components")
+	}
+
+	mapping := comp1.IsoMapping(comp2, fixed)
+	if mapping == nil {
+		t.Fatal("No mapping found between isomorphic components")
+	}
+
+	child := lib.Node{Bag: []int{2, 3}, Cover: lib.NewEdges([]lib.Edge{e2})}
+	root := lib.Node{Bag: []int{1, 2}, Cover: lib.NewEdges([]lib.Edge{e1}), Children: []lib.Node{child}}
+	remapped, ok := root.Remap(mapping, all)
+	if !ok {
+		t.Fatal("Couldn't remap decomposition")
+	}
+
+	decomp := lib.Decomp{Graph: comp2, Root: remapped}
+	if !decomp.Correct(comp2) {
+		t.Errorf("Remapped decomposition not correct: %v", decomp)
+	}
+}
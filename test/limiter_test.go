@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestMaxGoroutines checks that bounding the goroutines of all algorithms, down to none at all where every search
+// runs inline, neither changes the widths found nor is exceeded, and that all goroutines are returned afterwards
+func TestMaxGoroutines(t *testing.T) {
+	defer lib.Goroutines.SetMax(0)
+
+	for i := 0; i < 5; i++ {
+		graph, _ := getRandomGraph(8)
+
+		for k := 1; k <= 3; k++ {
+			var found []bool
+
+			for _, max := range []int{0, 1, 3} {
+				lib.Goroutines.SetMax(max)
+				solvers := []algo.Algorithm{
+					&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2},
+					&algo.BalSepGlobal{K: k, Graph: graph.ComputeSubEdges(k), BalFactor: 2},
+					&algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2},
+				}
+
+				for j, solver := range solvers {
+					solver.SetGenerator(lib.ParallelSearchGen{})
+					decomp := solver.FindDecomp()
+					decomp.Graph = graph
+					correct := decomp.Correct(graph)
+
+					if len(found) < len(solvers) {
+						found = append(found, correct)
+					} else if found[j] != correct {
+						t.Errorf("%v with at most %v goroutines at width %v: found %v, but %v without bound",
+							solver.Name(), max, k, correct, found[j])
+					}
+				}
+
+				if max > 0 && lib.Goroutines.Peak() > max {
+					t.Errorf("Peak of %v goroutines, exceeding the bound %v", lib.Goroutines.Peak(), max)
+				}
+			}
+		}
+	}
+
+	// the goroutines for the components of rejected separators are stopped before the searches return
+	if lib.Goroutines.Running() != 0 {
+		t.Errorf("%v goroutines still granted after all searches returned", lib.Goroutines.Running())
+	}
+}
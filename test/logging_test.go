@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestLogging checks that the levels of the loggers are set by component, that a spec with an error changes nothing,
+// and that messages are only written up to the level of their logger, without allocating anything when off
+func TestLogging(t *testing.T) {
+	var out bytes.Buffer
+	lib.SetLogOutput(&out)
+	defer lib.SetLogOutput(os.Stderr)
+	defer lib.SetLogLevels("off")
+
+	if err := lib.SetLogLevels("info,search=debug,parser=off"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lib.LogLevels(), "cache=info,recursion=info,search=debug"; got != want {
+		t.Errorf("levels %q, expected %q", got, want)
+	}
+	if !lib.LogSearch.Enabled(lib.LogDebug) || lib.LogCache.Enabled(lib.LogDebug) || !lib.LogCache.Enabled(lib.LogWarn) ||
+		lib.LogParser.Enabled(lib.LogError) {
+		t.Errorf("loggers enabled at the wrong levels: %v", lib.LogLevels())
+	}
+
+	for _, spec := range []string{"search=verbose", "planner=debug", "debug,search"} {
+		if err := lib.SetLogLevels(spec); err == nil {
+			t.Errorf("no error for spec %q", spec)
+		}
+	}
+	if got, want := lib.LogLevels(), "cache=info,recursion=info,search=debug"; got != want {
+		t.Errorf("levels changed to %q by specs with errors", got)
+	}
+
+	lib.LogCache.Printf(lib.LogDebug, "not written")
+	lib.LogParser.Printf(lib.LogError, "not written either")
+	lib.LogCache.Printf(lib.LogInfo, "moving to width %d\n", 3)
+	if got, want := out.String(), "cache info: moving to width 3\n"; got != want {
+		t.Errorf("logged %q, expected %q", got, want)
+	}
+
+	lib.SetLogLevels("off")
+	graph, _ := getRandomGraph(5)
+	allocs := testing.AllocsPerRun(100, func() {
+		if lib.LogRecursion.Enabled(lib.LogDebug) {
+			lib.LogRecursion.Printf(lib.LogDebug, "Decomposing %v", graph)
+		}
+	})
+	if allocs > 0 || out.Len() > len("cache info: moving to width 3\n") {
+		t.Errorf("logging turned off allocated %v times, wrote %q", allocs, out.String())
+	}
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestMetrics checks the width, depth, number of nodes and bag sizes of a small decomposition of a path
+func TestMetrics(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e).")
+	edges := graph.Edges.Slice()
+	e12 := lib.NewEdges(edges[:2])
+	e3 := lib.NewEdges(edges[2:3])
+	e4 := lib.NewEdges(edges[3:])
+
+	// the root covers the first two edges, with a path of two nodes below it for the others
+	decomp := lib.Decomp{Graph: graph, Root: lib.Node{Bag: e12.Vertices(), Cover: e12, Children: []lib.Node{
+		{Bag: e3.Vertices(), Cover: e3, Children: []lib.Node{{Bag: e4.Vertices(), Cover: e4}}},
+	}}}
+	if !decomp.Correct(graph) {
+		t.Fatalf("decomposition not correct: %v", decomp)
+	}
+
+	if decomp.Width() != 2 || decomp.CheckWidth() != 2 {
+		t.Errorf("width %v, expected 2", decomp.Width())
+	}
+	if decomp.Depth() != 2 {
+		t.Errorf("depth %v, expected 2", decomp.Depth())
+	}
+	if decomp.NumNodes() != 3 {
+		t.Errorf("%v nodes, expected 3", decomp.NumNodes())
+	}
+	if stats := decomp.BagStats(); stats != (lib.BagStats{Min: 2, Max: 3, Mean: 7.0 / 3}) {
+		t.Errorf("bag sizes %v, expected min 2, max 3, mean 2.33", stats)
+	}
+
+	leaf := lib.Decomp{Graph: graph, Root: lib.Node{Bag: e4.Vertices(), Cover: e4}}
+	if leaf.Depth() != 0 || leaf.NumNodes() != 1 {
+		t.Errorf("single node has depth %v and %v nodes", leaf.Depth(), leaf.NumNodes())
+	}
+}
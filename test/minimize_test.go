@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// checkMinimal reports any node whose bag is contained in its parent's or vice versa, and any cover with an edge
+// not needed to cover the bag
+func checkMinimal(t *testing.T, n lib.Node) {
+	for _, c := range n.Children {
+		if lib.Subset(c.Bag, n.Bag) || lib.Subset(n.Bag, c.Bag) {
+			t.Errorf("Bags %v and %v of adjacent nodes contained in one another", n.Bag, c.Bag)
+		}
+		checkMinimal(t, c)
+	}
+
+	cover := n.Cover.Slice()
+	for i := range cover {
+		var rest []int
+		for j := range cover {
+			if j != i {
+				rest = append(rest, cover[j].Vertices...)
+			}
+		}
+		if lib.Subset(n.Bag, rest) {
+			t.Errorf("Edge %v not needed to cover bag %v", cover[i], n.Bag)
+		}
+	}
+}
+
+// TestMinimize checks that minimized decompositions stay correct and within the width, with no more nodes than
+// before, no redundant nodes and minimal covers
+func TestMinimize(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 20; i++ {
+		graph := getDenseGraph(r, 10, 8+i%8)
+
+		var found lib.Decomp
+		var width int
+		for width = 1; width <= graph.Edges.Len(); width++ {
+			det := &algo.DetKDecomp{K: width, Graph: graph, BalFactor: 2}
+			if found = det.FindDecomp(); found.Correct(graph) {
+				break
+			}
+		}
+		greedy := algo.GreedyDecomp{}.Decompose(graph)
+
+		for _, source := range []lib.Decomp{found, greedy} {
+			K := source.CheckWidth()
+			for _, k := range []int{K, K + 1} {
+				decomp := source.Minimize(k)
+				if !decomp.Correct(graph) || decomp.CheckWidth() > k {
+					t.Fatalf("Minimizing %v at width %v yields %v", source, k, decomp)
+				}
+				if len(bagsOf(decomp.Root)) > len(bagsOf(source.Root)) {
+					t.Errorf("Minimized decomposition has more nodes: %v", decomp)
+				}
+				checkMinimal(t, decomp.Root)
+			}
+		}
+	}
+
+	if decomp := (lib.Decomp{}).Minimize(2); decomp.Root.Bag != nil {
+		t.Errorf("Empty decomp changed: %v", decomp)
+	}
+}
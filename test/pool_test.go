@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"runtime"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestProcs checks that the algorithms decomposing components in parallel find the same widths no matter how many
+// goroutines the pool allows, including a single one where all recursive calls are run inline, and that all slots
+// of the pool are released afterwards
+func TestProcs(t *testing.T) {
+	defer algo.SetProcs(0)
+
+	algo.SetProcs(0)
+	if algo.Procs() != runtime.GOMAXPROCS(0) {
+		t.Errorf("Default pool size %v, expected %v", algo.Procs(), runtime.GOMAXPROCS(0))
+	}
+
+	for i := 0; i < 5; i++ {
+		graph, _ := getRandomGraph(8)
+
+		for k := 1; k <= 3; k++ {
+			var found []bool
+
+			for _, procs := range []int{1, 2, 64} {
+				algo.SetProcs(procs)
+				solvers := []algo.Algorithm{
+					&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2},
+					&algo.BalSepGlobal{K: k, Graph: graph.ComputeSubEdges(k), BalFactor: 2},
+					&algo.BalSepHybrid{K: k, Graph: graph, BalFactor: 2, Depth: 1},
+				}
+
+				for j, solver := range solvers {
+					solver.SetGenerator(lib.ParallelSearchGen{})
+					decomp := solver.FindDecomp()
+					decomp.Graph = graph
+					correct := decomp.Correct(graph)
+
+					if len(found) < len(solvers) {
+						found = append(found, correct)
+					} else if found[j] != correct {
+						t.Errorf("%v with %v procs at width %v: found %v, but %v with 1", solver.Name(), procs, k,
+							correct, found[j])
+					}
+				}
+			}
+		}
+	}
+
+	// the goroutines for the components of rejected separators are stopped before the searches return
+	if algo.Busy() != 0 {
+		t.Errorf("%v goroutines of the pool still busy after all searches returned", algo.Busy())
+	}
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestPortfolio checks that a portfolio pairing an incomplete algorithm with a complete one finds a correct
+// decomposition exactly when the complete one does, names the member which won, and returns none once cancelled
+func TestPortfolio(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(10)
+
+		for k := 1; k <= 3; k++ {
+			portfolio, err := algo.NewPortfolio([]string{"split", "local"}, algo.Config{K: k, Graph: graph,
+				BalFactor: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			portfolio.SetGenerator(lib.ParallelSearchGen{})
+			decomp := portfolio.FindDecomp()
+
+			local := &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2}
+			local.SetGenerator(lib.ParallelSearchGen{})
+			found := local.FindDecomp().Found()
+
+			if !decomp.Found() {
+				if found {
+					t.Errorf("portfolio found no decomposition of %v at width %v, BalSepLocal did", graph, k)
+				}
+				if portfolio.Winner() != "" {
+					t.Errorf("portfolio failed on %v, but was won by %v", graph, portfolio.Winner())
+				}
+				continue
+			}
+			decomp.Graph = graph
+			if !decomp.Correct(graph) || decomp.Width() > k {
+				t.Fatalf("portfolio produced an incorrect decomposition of %v at width %v: %v", graph, k, decomp)
+			}
+			if !found {
+				t.Errorf("portfolio found a decomposition of %v at width %v, BalSepLocal none", graph, k)
+			}
+			if w := portfolio.Winner(); w != (algo.BalSepSplit{}).Name() && w != (algo.BalSepLocal{}).Name() {
+				t.Errorf("portfolio won by %q, not one of its members", w)
+			}
+		}
+	}
+
+	_, err := algo.NewPortfolio([]string{"local", "unknown"}, algo.Config{})
+	if err == nil {
+		t.Error("portfolio set up with an unknown algorithm")
+	}
+
+	graph, _ := getRandomGraph(10)
+	portfolio, _ := algo.NewPortfolio([]string{"local", "det"}, algo.Config{K: 3, Graph: graph, BalFactor: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	portfolio.SetGenerator(lib.ParallelSearchGen{Ctx: ctx})
+	if decomp := portfolio.FindDecomp(); decomp.Found() && !decomp.Correct(graph) {
+		t.Errorf("cancelled portfolio produced an incorrect decomposition: %v", decomp)
+	}
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestDecompGYÖ checks that acyclic graphs are fully reduced, and that decompositions of reducts are restored correctly
+func TestDecompGYÖ(t *testing.T) {
+	path, _ := lib.GetGraph("e1(a,b,c),\ne2(c,d),\ne3(d,e,f),\ne4(f,a2),\ne5(d,g).")
+	reduced, _ := path.GYÖReduct()
+	if reduced.Edges.Len() != 0 {
+		t.Errorf("Acyclic graph not fully reduced: %v", reduced)
+	}
+
+	decomp := path.DecompGYÖ(&algo.DetKDecomp{K: 1, Graph: path, BalFactor: 2})
+	if !decomp.Correct(path) || decomp.CheckWidth() != 1 {
+		t.Errorf("Acyclic graph has no correct decomposition of width 1: %v", decomp)
+	}
+
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(8)
+		k := graph.Edges.Len()
+
+		decomp := graph.DecompGYÖ(&algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2})
+		if !decomp.Found() {
+			t.Fatalf("No decomposition of width %v found for %v", k, graph)
+		}
+		if !decomp.Correct(graph) {
+			t.Errorf("Restored decomposition not correct for %v: %v", graph, decomp)
+		}
+	}
+}
+
+// TestRemoveTwins checks that duplicate edges and twin vertices are removed, and that decompositions of the result
+// are restored to correct decompositions of the original graph of the same width
+func TestRemoveTwins(t *testing.T) {
+	graph, _ := lib.GetGraph("c1(x,y,z,v),\nc2(z,v,y,x),\nc3(z,w,u,t),\nc4(u,x),\nc5(w,u,z,t).")
+	reduced, twins := graph.RemoveTwins()
+	if vertices, edges := twins.Count(); vertices != 2 || edges != 2 || reduced.Edges.Len() != 3 {
+		t.Fatalf("removed %v twin vertices and %v duplicate edges, leaving %v", vertices, edges, reduced)
+	}
+
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(8)
+		reduced, twins := graph.RemoveTwins()
+
+		det := &algo.DetKDecomp{K: reduced.Edges.Len(), Graph: reduced, BalFactor: 2}
+		det.SetGenerator(lib.ParallelSearchGen{})
+		decomp := det.FindDecomp()
+		if !decomp.Found() {
+			t.Fatalf("No decomposition found for %v", reduced)
+		}
+		width := decomp.CheckWidth()
+
+		var ok bool
+		if decomp.Root, ok = decomp.Root.RestoreTwins(twins); !ok {
+			t.Fatalf("Twins %v not restored in %v", twins, decomp)
+		}
+		decomp.Graph = graph
+		if !decomp.Correct(graph) || decomp.CheckWidth() != width {
+			t.Errorf("Restored decomposition not correct or of changed width for %v: %v", graph, decomp)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// gridGraph produces the hypergraph of an n x n grid, with one binary edge between neighbouring vertices
+func gridGraph(n int) lib.Graph {
+	var buffer bytes.Buffer
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i+1 < n {
+				buffer.WriteString(fmt.Sprintf("h%d_%d(v%d_%d,v%d_%d),\n", i, j, i, j, i+1, j))
+			}
+			if j+1 < n {
+				buffer.WriteString(fmt.Sprintf("w%d_%d(v%d_%d,v%d_%d),\n", i, j, i, j, i, j+1))
+			}
+		}
+	}
+	input := buffer.String()
+	graph, _ := lib.GetGraph(input[:len(input)-2] + ".")
+	return graph
+}
+
+// TestProbe checks that probing accepts an acyclic graph for width 1, and rejects a grid
+func TestProbe(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	path, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e,f).")
+	det := &algo.DetKDecomp{K: 1, Graph: path, BalFactor: 2}
+	result := algo.Probe(det, path, 4, r)
+	if !result.Plausible() || result.Samples != 4 || result.Confidence() <= 0 {
+		t.Errorf("Acyclic graph not accepted for width 1: %v", result)
+	}
+
+	grid := gridGraph(6)
+	det = &algo.DetKDecomp{K: 1, Graph: grid, BalFactor: 2}
+	result = algo.Probe(det, grid, 3, r)
+	if result.Plausible() || result.Confidence() != 0 {
+		t.Errorf("Grid not rejected for width 1: %v", result)
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestParseDatalog checks that the atoms of a query become edges over its variables, named uniquely after their
+// relations
+func TestParseDatalog(t *testing.T) {
+	q, err := lib.ParseDatalog("q(X) :- r(X,Y), s(Y, Z), r(Z, 'a,b'), t(_, X, _), u(c, 1).")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []lib.Atom{
+		{Edge: "r", Relation: "r", Args: []string{"X", "Y"}},
+		{Edge: "s", Relation: "s", Args: []string{"Y", "Z"}},
+		{Edge: "r_2", Relation: "r", Args: []string{"Z"}},
+		{Edge: "t", Relation: "t", Args: []string{"_1", "X", "_2"}},
+	}
+	if !reflect.DeepEqual(q.Atoms, expected) {
+		t.Errorf("Wrong atoms: %v, expected %v", q.Atoms, expected)
+	}
+
+	graph, pgraph := q.Graph()
+	hyperBench, _ := lib.GetGraph("r(X,Y),\ns(Y,Z),\nr_2(Z),\nt(_1,X,_2).")
+	if graph.Edges.FullString() != hyperBench.Edges.FullString() {
+		t.Errorf("Query parsed differently: %v, %v", graph.Edges.FullString(), hyperBench.Edges.FullString())
+	}
+	if pgraph.Query == nil {
+		t.Errorf("Query not attached to the parsed graph")
+	}
+
+	for _, s := range []string{"q :- r(X,Y), s(Y", "q :- r(X,,Y)", "q :- , r(X)"} {
+		if _, err := lib.ParseDatalog(s); err == nil {
+			t.Errorf("Malformed query %q not rejected", s)
+		}
+	}
+}
+
+// TestParseSQL checks that the tables of a join query become edges over the classes of columns equal to each other
+func TestParseSQL(t *testing.T) {
+	q, err := lib.ParseSQL(`SELECT a.x, COUNT(*) AS cnt FROM r AS a, s b JOIN t ON b.z = t.z
+		WHERE a.y = b.y AND (t.x = a.x) AND a.v LIKE 'foo%' AND b.w > 3 GROUP BY a.x;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []lib.Atom{
+		{Edge: "a", Relation: "r", Args: []string{"a.x", "a.y", "a.v"}},
+		{Edge: "b", Relation: "s", Args: []string{"b.z", "a.y", "b.w"}},
+		{Edge: "t", Relation: "t", Args: []string{"b.z", "a.x"}},
+	}
+	if !reflect.DeepEqual(q.Atoms, expected) {
+		t.Errorf("Wrong atoms: %v, expected %v", q.Atoms, expected)
+	}
+
+	single, err := lib.ParseSQL("select x, y from r where x = y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(single.Atoms) != 1 || !reflect.DeepEqual(single.Atoms[0].Args, []string{"r.x"}) {
+		t.Errorf("Wrong atoms for a single table: %v", single.Atoms)
+	}
+
+	for _, s := range []string{"DELETE FROM r", "SELECT * FROM r, s WHERE x = y", "SELECT * FROM r, r",
+		"SELECT * FROM r, s WHERE r.x = s.x OR r.y = s.y", "SELECT * FROM r WHERE u.x = 1"} {
+		if _, err := lib.ParseSQL(s); err == nil {
+			t.Errorf("Unsupported query %q not rejected", s)
+		}
+	}
+}
+
+// TestBagAtoms checks that the bags of a decomposition of a query are described by the atoms covering them
+func TestBagAtoms(t *testing.T) {
+	graph, pgraph, err := lib.GetGraphFormat("datalog", "r(X,Y), s(Y,Z), t(Z,X), u(X,W)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solver := &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}
+	solver.SetGenerator(lib.ParallelSearchGen{})
+	decomp := solver.FindDecomp()
+	decomp.Graph = graph
+	if !decomp.Correct(graph) {
+		t.Fatalf("No correct decomposition found: %v", decomp)
+	}
+
+	output := pgraph.Query.BagAtoms(decomp)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != len(bagsOf(decomp.Root)) {
+		t.Errorf("Expected one line per node, got: %v", output)
+	}
+	if !strings.Contains(output, "u(X, W)") {
+		t.Errorf("Atom u(X, W) missing: %v", output)
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestRecursionTrace checks that the calls recorded form a tree whose root succeeded exactly if a decomposition was
+// found, that the trace survives both of its encodings, and that recording stops at the limit
+func TestRecursionTrace(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(10)
+
+		local := &algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2, Recursion: &lib.RecursionTrace{}}
+		det := &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2, Recursion: &lib.RecursionTrace{}}
+		for _, solver := range []algo.Algorithm{local, det} {
+			solver.SetGenerator(lib.ParallelSearchGen{})
+			decomp := solver.FindDecomp()
+
+			recursion := local.Recursion
+			if solver == algo.Algorithm(det) {
+				recursion = det.Recursion
+			}
+			if len(recursion.Calls) == 0 || recursion.Calls[0].Parent != 0 {
+				t.Fatalf("%v recorded no call on the whole graph %v", solver.Name(), graph)
+			}
+			for _, c := range recursion.Calls {
+				if c.Parent >= c.ID || c.Outcome == lib.RecursionOpen {
+					t.Errorf("%v recorded call %+v out of order or without an outcome", solver.Name(), c)
+				}
+			}
+			found := decomp.Found()
+			if found != (recursion.Calls[0].Outcome == lib.RecursionAccepted) {
+				t.Errorf("%v recorded outcome %v for the whole graph, found a decomposition: %v", solver.Name(),
+					recursion.Calls[0].Outcome, found)
+			}
+
+			encoded, err := recursion.GobEncode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromGob, err := lib.ReadRecursionTrace(bytes.NewReader(encoded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err = recursion.ToJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromJSON, err := lib.ReadRecursionTrace(bytes.NewReader(encoded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fromGob.Calls, recursion.Calls) || len(fromJSON.Calls) != len(recursion.Calls) ||
+				fromJSON.ToDOT() != recursion.ToDOT() {
+				t.Errorf("%v: trace changed by encoding", solver.Name())
+			}
+			if strings.Count(recursion.ToDOT(), "->") != len(recursion.Calls)-1 {
+				t.Errorf("%v: DOT tree doesn't link every call but the first", solver.Name())
+			}
+		}
+	}
+
+	graph, _ := getRandomGraph(10)
+	local := &algo.BalSepLocal{K: 1, Graph: graph, BalFactor: 2, Recursion: &lib.RecursionTrace{Limit: 1}}
+	local.SetGenerator(lib.ParallelSearchGen{})
+	local.FindDecomp()
+	if len(local.Recursion.Calls) != 1 || (!local.Recursion.Truncated && local.Recursion.Calls[0].Tried > 0) {
+		t.Errorf("recorded %d calls with a limit of 1, truncated: %v", len(local.Recursion.Calls),
+			local.Recursion.Truncated)
+	}
+
+	if _, err := lib.ReadRecursionTrace(strings.NewReader(`{"calls": [{"id": 2, "parent": 0}]}`)); err == nil {
+		t.Error("trace with calls out of order was read")
+	}
+}
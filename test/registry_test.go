@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"sort"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestRegistry checks that each registered algorithm decomposes a cycle at the width it needs, and that algorithms
+// can be registered by name once only
+func TestRegistry(t *testing.T) {
+	names := algo.Names()
+	if !sort.StringsAreSorted(names) || len(names) == 0 {
+		t.Errorf("names of the algorithms not sorted: %v", names)
+	}
+
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,a).")
+	for _, name := range names {
+		factory, err := algo.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		solver := factory(algo.Config{K: 2, Graph: graph, BalFactor: 2})
+		solver.SetGenerator(lib.ParallelSearchGen{})
+		decomp := solver.FindDecomp()
+		if !decomp.Found() {
+			t.Errorf("%v found no decomposition of width 2 for %v", name, graph)
+			continue
+		}
+		decomp.Graph = graph
+		if !decomp.Correct(graph) || decomp.Width() > 2 {
+			t.Errorf("%v produced an incorrect decomposition: %v", name, decomp)
+		}
+	}
+
+	if _, err := algo.Get("unknown"); err == nil {
+		t.Error("no error for an unknown algorithm")
+	}
+
+	if _, err := algo.Get("trivialForTest"); err != nil { // not yet registered by an earlier run with -count
+		algo.Register("trivialForTest", func(c algo.Config) algo.Algorithm {
+			return &algo.GreedyDecomp{K: c.K, Graph: c.Graph}
+		})
+	}
+	if _, err := algo.Get("trivialForTest"); err != nil {
+		t.Error(err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic when registering a name twice")
+		}
+	}()
+	algo.Register("local", func(c algo.Config) algo.Algorithm { return nil })
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestRerootAt checks that rerooting a decomposition at some vertices keeps it correct, with all of them in the bag
+// of the new root, and fails if they aren't found together in any bag
+func TestRerootAt(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 20; i++ {
+		graph := getDenseGraph(r, 10, 8+i%8)
+		decomp := algo.GreedyDecomp{}.Decompose(graph)
+		bags := bagsOf(decomp.Root)
+
+		bag := bags[r.Intn(len(bags))]
+		vertices := append([]int{}, bag[:1+r.Intn(len(bag))]...)
+		rerooted, err := decomp.RerootAt(vertices)
+		if err != nil {
+			t.Fatalf("Rerooting at %v failed: %v", vertices, err)
+		}
+		if !rerooted.Correct(graph) || !lib.Subset(vertices, rerooted.Root.Bag) {
+			t.Fatalf("Rerooting %v at %v yields %v", decomp, vertices, rerooted)
+		}
+		if len(bagsOf(rerooted.Root)) != len(bags) {
+			t.Errorf("Rerooting changed the number of nodes: %v", rerooted)
+		}
+
+		// vertices not found together in any bag
+		for _, v := range graph.Vertices() {
+			for _, w := range graph.Vertices() {
+				together := false
+				for _, b := range bags {
+					together = together || lib.Subset([]int{v, w}, b)
+				}
+				if together {
+					continue
+				}
+				if _, err := decomp.RerootAt([]int{v, w}); err == nil {
+					t.Errorf("Rerooting at %v and %v, not in any bag, didn't fail", v, w)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestRestarts checks that restarting searches with random offsets finds a decomposition exactly if one exists, even
+// if the first budgets are far too small for any search to finish
+func TestRestarts(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(10)
+		for k := 1; k <= 3; k++ {
+			det := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			det.SetGenerator(lib.ParallelSearchGen{})
+			expected := det.FindDecomp().Found()
+
+			local := &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2, SepOrder: lib.RandomOffset}
+			restarts := algo.NewRestarts(k, graph, local, time.Nanosecond, lib.ParallelSearchGen{})
+			decomp := restarts.FindDecomp()
+			if decomp.Found() != expected {
+				t.Fatalf("width %d: found %v with restarts, expected %v on %v", k, decomp.Found(), expected, graph)
+			}
+			if expected && !decomp.Correct(graph) {
+				t.Errorf("width %d: decomp found with restarts not correct: %v", k, decomp)
+			}
+			if graph.Len() > 2 && restarts.Count() == 0 {
+				t.Errorf("width %d: no restarts, though the first budget ran out before any search", k)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestRootWidth checks that DetK uses a cover of up to RootK edges at the root only, so that a clique needing three
+// edges in one bag is decomposed at width 1 below a root of width 3
+func TestRootWidth(t *testing.T) {
+	clique, _ := lib.GetGraph("e01(a,b),\ne02(a,c),\ne03(a,d),\ne04(a,e),\ne12(b,c),\ne13(b,d),\ne14(b,e),\n" +
+		"e23(c,d),\ne24(c,e),\ne34(d,e).")
+
+	for rootK, expected := range map[int]bool{0: false, 2: false, 3: true} {
+		det := &algo.DetKDecomp{K: 1, RootK: rootK, Graph: clique, BalFactor: 2}
+		det.SetGenerator(lib.ParallelSearchGen{})
+		decomp := det.FindDecomp()
+		if found := decomp.Found(); found != expected {
+			t.Fatalf("root width %d: decomposition found %v, expected %v", rootK, found, expected)
+		}
+		if expected && (!decomp.Correct(clique) || decomp.Root.Cover.Len() > rootK) {
+			t.Errorf("root width %d: decomposition not correct: %v", rootK, decomp)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+		det := &algo.DetKDecomp{K: 2, RootK: 3, Graph: graph, BalFactor: 2, SubEdge: true}
+		det.SetGenerator(lib.ParallelSearchGen{})
+		decomp := det.FindDecomp()
+		if !decomp.Found() {
+			continue
+		}
+		if !decomp.Correct(graph) || decomp.Root.Cover.Len() > 3 {
+			t.Errorf("decomposition not correct or root too wide: %v", decomp)
+		}
+		for _, c := range decomp.Root.Children {
+			if width := (lib.Decomp{Graph: graph, Root: c}).CheckWidth(); width > 2 {
+				t.Errorf("node below the root of width %d: %v", width, decomp)
+			}
+		}
+	}
+}
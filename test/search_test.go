@@ -1,13 +1,16 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/disjoint"
 )
 
 // max returns the larger of two integers a and b
@@ -119,3 +122,267 @@ OUTER:
 		t.Errorf("Mismatch in returned seps between sequential and parallel Search")
 	}
 }
+
+// countingCheck wraps BalancedCheck, recording how often each candidate separator has been evaluated
+type countingCheck struct {
+	mux    *sync.Mutex
+	counts map[string]int
+}
+
+func (c countingCheck) Check(H *lib.Graph, sep *lib.Edges, balFactor int, Vertices map[int]*disjoint.Element) bool {
+	c.mux.Lock()
+	c.counts[sep.String()]++
+	c.mux.Unlock()
+
+	return lib.BalancedCheck{}.Check(H, sep, balFactor, Vertices)
+}
+
+//TestSearchNoRepeat ensures that over all calls of FindNext, no candidate separator is evaluated more than once
+func TestSearchNoRepeat(t *testing.T) {
+	s := rand.NewSource(time.Now().UnixNano())
+	r := rand.New(s)
+
+	for x := 0; x < 10; x++ {
+		randGraph, _ := getRandomGraph(15)
+		k := r.Intn(4) + 1
+
+		search := lib.ParallelSearch{
+			H:          &randGraph,
+			Edges:      &randGraph.Edges,
+			BalFactor:  2,
+			Generators: lib.SplitCombin(randGraph.Edges.Len(), k, runtime.GOMAXPROCS(-1), false),
+		}
+		pred := countingCheck{mux: &sync.Mutex{}, counts: make(map[string]int)}
+
+		for search.FindNext(pred); !search.ExhaustedSearch; search.FindNext(pred) {
+		}
+
+		for sep, count := range pred.counts {
+			if count > 1 {
+				t.Errorf("Candidate %v evaluated %v times", sep, count)
+			}
+		}
+	}
+}
+
+// TestSearchStats ensures that all candidates checked are counted, for each generator of the search. Goroutines of
+// earlier tests may still add to the counts, so they only need to be large enough.
+func TestSearchStats(t *testing.T) {
+	randGraph, _ := getRandomGraph(12)
+	split := 3
+
+	lib.Stats.Reset()
+	search := lib.ParallelSearch{
+		H:          &randGraph,
+		Edges:      &randGraph.Edges,
+		BalFactor:  2,
+		Generators: lib.SplitCombin(randGraph.Edges.Len(), 2, split, false),
+	}
+	pred := countingCheck{mux: &sync.Mutex{}, counts: make(map[string]int)}
+
+	for search.FindNext(pred); !search.ExhaustedSearch; search.FindNext(pred) {
+	}
+
+	consumed := lib.Stats.Consumed()
+	var total int64
+	for _, n := range consumed {
+		total += n
+	}
+	if len(consumed) < len(search.Generators) || total < int64(len(pred.counts)) {
+		t.Errorf("Counted %v candidates by generator %v, but %v were checked by %v generators", total, consumed,
+			len(pred.counts), len(search.Generators))
+	}
+}
+
+//TestSearchDegenerate ensures the search ends immediately on degenerate inputs, and that a width larger than the
+// number of edges still covers all combinations
+func TestSearchDegenerate(t *testing.T) {
+	randGraph, _ := getRandomGraph(5)
+	var emptyEdges lib.Edges
+	pred := lib.BalancedCheck{}
+
+	cases := []struct {
+		name  string
+		edges *lib.Edges
+		gens  []lib.Generator
+	}{
+		{"zero generators", &randGraph.Edges, []lib.Generator{}},
+		{"empty edge set", &emptyEdges, lib.SplitCombin(emptyEdges.Len(), 3, 4, false)},
+		{"width zero", &randGraph.Edges, lib.SplitCombin(randGraph.Edges.Len(), 0, 4, false)},
+		{"negative width", &randGraph.Edges, lib.SplitCombin(randGraph.Edges.Len(), -1, 4, false)},
+	}
+
+	for _, c := range cases {
+		search := lib.ParallelSearch{H: &randGraph, Edges: c.edges, BalFactor: 2, Generators: c.gens}
+
+		done := make(chan bool)
+		go func() {
+			search.FindNext(pred)
+			done <- true
+		}()
+
+		select {
+		case <-done:
+			if !search.SearchEnded() || len(search.GetResult()) > 0 {
+				t.Errorf("%v: search not exhausted immediately, result %v", c.name, search.GetResult())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: search didn't terminate", c.name)
+		}
+	}
+
+	// k larger than the number of edges, with a split size of zero
+	n := 4
+	seen := make(map[string]bool)
+	for _, gen := range lib.SplitCombin(n, n+3, 0, false) {
+		for gen.HasNext() {
+			seen[fmt.Sprint(gen.GetNext())] = true
+			gen.Confirm()
+		}
+	}
+	if len(seen) != (1<<uint(n))-1 {
+		t.Errorf("Expected all %v non-empty subsets, got %v", (1<<uint(n))-1, len(seen))
+	}
+}
+
+//TestSearchShutdown ensures that all workers have stopped once FindNext returns, whether a result was found or not
+func TestSearchShutdown(t *testing.T) {
+	randGraph, _ := getRandomGraph(15)
+	before := runtime.NumGoroutine()
+
+	search := lib.ParallelSearch{
+		H:          &randGraph,
+		Edges:      &randGraph.Edges,
+		BalFactor:  2,
+		Generators: lib.SplitCombin(randGraph.Edges.Len(), 2, runtime.GOMAXPROCS(-1), false),
+	}
+	for search.FindNext(lib.BalancedCheck{}); !search.ExhaustedSearch; search.FindNext(lib.BalancedCheck{}) {
+	}
+
+	// the goroutine waiting on the workers might need a moment to return
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Goroutines leaked: %v before search, %v after", before, after)
+	}
+}
+
+// TestBalancedSeparators ensures that the separator iterator returns each balanced separator of at most k edges
+// exactly once, the same as a plain sequential check of all combinations, and nothing once cancelled
+func TestBalancedSeparators(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for x := 0; x < 10; x++ {
+		randGraph, _ := getRandomGraph(12)
+		k := r.Intn(3) + 1
+
+		var expected []string
+		gen := lib.SplitCombin(randGraph.Edges.Len(), k, 1, false)[0]
+		for gen.HasNext() {
+			sep := lib.GetSubset(randGraph.Edges, gen.GetNext())
+			if (lib.BalancedCheck{}).Check(&randGraph, &sep, 2, make(map[int]*disjoint.Element)) {
+				expected = append(expected, sep.String())
+			}
+			gen.Confirm()
+		}
+
+		var sequential []string
+		for it := lib.BalancedSeparators(context.Background(), randGraph, k, 2, true); it.Next(); {
+			sequential = append(sequential, it.Separator().String())
+		}
+		if fmt.Sprint(sequential) != fmt.Sprint(expected) {
+			t.Errorf("Sequential iterator returned %v, expected %v", sequential, expected)
+		}
+
+		found := make(map[string]int)
+		it := lib.BalancedSeparators(context.Background(), randGraph, k, 2, false)
+		for it.Next() {
+			found[it.Separator().String()]++
+		}
+		if it.Count() != len(expected) || len(found) != len(expected) {
+			t.Errorf("Parallel iterator returned %v separators, expected %v", it.Count(), len(expected))
+		}
+		for _, sep := range expected {
+			if found[sep] != 1 {
+				t.Errorf("Separator %v returned %v times", sep, found[sep])
+			}
+		}
+	}
+
+	randGraph, _ := getRandomGraph(12)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if it := lib.BalancedSeparators(ctx, randGraph, 3, 2, false); it.Next() {
+		t.Errorf("Cancelled iterator returned %v", it.Separator())
+	}
+}
+
+// TestCheckBalFactor ensures that balance factors are accepted exactly when some separator can be balanced
+func TestCheckBalFactor(t *testing.T) {
+	for _, b := range []int{-1, 0, 1} {
+		if lib.CheckBalFactor(b) == nil {
+			t.Errorf("Balance factor %v accepted", b)
+		}
+	}
+	for _, b := range []int{2, 3, 10} {
+		if err := lib.CheckBalFactor(b); err != nil {
+			t.Errorf("Balance factor %v rejected: %v", b, err)
+		}
+	}
+}
+
+// TestSeparatorCheckpoint ensures that an iteration resumed from a saved state returns the remaining separators, and
+// that checkpoints taken while searching don't change the separators returned
+func TestSeparatorCheckpoint(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for x := 0; x < 10; x++ {
+		randGraph, _ := getRandomGraph(12)
+		k := r.Intn(3) + 1
+
+		var all []string
+		for it := lib.BalancedSeparators(context.Background(), randGraph, k, 2, true); it.Next(); {
+			all = append(all, it.Separator().String())
+		}
+
+		cut := r.Intn(len(all) + 1)
+		first := lib.BalancedSeparators(context.Background(), randGraph, k, 2, true)
+		for i := 0; i < cut; i++ {
+			first.Next()
+		}
+		resumed := lib.BalancedSeparators(context.Background(), randGraph, k, 2, false)
+		if err := resumed.Restore(first.Save()); err != nil {
+			t.Fatalf("Restoring failed: %v", err)
+		}
+		found := make(map[string]int)
+		for resumed.Next() {
+			found[resumed.Separator().String()]++
+		}
+		if resumed.Count() != len(all) || len(found) != len(all)-cut {
+			t.Errorf("Resumed iterator returned %v separators out of %v, expected %v", len(found), resumed.Count(),
+				len(all)-cut)
+		}
+		for _, sep := range all[cut:] {
+			if found[sep] != 1 {
+				t.Errorf("Separator %v returned %v times", sep, found[sep])
+			}
+		}
+
+		wider := lib.BalancedSeparators(context.Background(), randGraph, k+1, 2, true)
+		if err := wider.Restore(first.Save()); err == nil {
+			t.Errorf("State restored for another width")
+		}
+
+		var checkpointed []string
+		saves := 0
+		it := lib.BalancedSeparators(context.Background(), randGraph, k, 2, true)
+		it.Checkpoint(time.Microsecond, func(state []byte) { saves++ })
+		for it.Next() {
+			checkpointed = append(checkpointed, it.Separator().String())
+		}
+		if fmt.Sprint(checkpointed) != fmt.Sprint(all) || len(all) > 0 && saves == 0 {
+			t.Errorf("Checkpointed iterator returned %v after %v saves, expected %v", checkpointed, saves, all)
+		}
+	}
+}
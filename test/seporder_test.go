@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestSeparatorOrder checks that the heuristic orders produce each combination exactly once, the same ones as the
+// lexicographic order, and that they don't change whether the algorithms find a decomposition
+func TestSeparatorOrder(t *testing.T) {
+	combinations := func(gens []lib.Generator) []string {
+		var output []string
+		for _, gen := range gens {
+			for gen.HasNext() {
+				c := append([]int{}, gen.GetNext()...)
+				sort.Ints(c)
+				output = append(output, fmt.Sprint(c))
+				gen.Confirm()
+			}
+		}
+		sort.Strings(output)
+		return output
+	}
+
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(10)
+		k := i%3 + 1
+		want := combinations(lib.Lexicographic.Generators(graph, graph.Edges, k, 3, true))
+
+		for _, name := range lib.SeparatorOrders() {
+			order, err := lib.ParseSeparatorOrder(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := combinations(order.Generators(graph, graph.Edges, k, 3, true)); fmt.Sprint(got) !=
+				fmt.Sprint(want) {
+				t.Errorf("Order %v produced combinations %v, expected %v", name, got, want)
+			}
+
+			var found []bool
+			solvers := []algo.Algorithm{
+				&algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2, SepOrder: order},
+				&algo.BalSepGlobal{K: k, Graph: graph.ComputeSubEdges(k), BalFactor: 2, SepOrder: order},
+			}
+			for _, solver := range solvers {
+				solver.SetGenerator(lib.ParallelSearchGen{})
+				decomp := solver.FindDecomp()
+				decomp.Graph = graph
+				found = append(found, decomp.Correct(graph))
+			}
+			if found[0] != found[1] {
+				t.Errorf("With order %v, local found %v and global %v at width %v on %v", name, found[0], found[1],
+					k, graph)
+			}
+		}
+	}
+
+	if _, err := lib.ParseSeparatorOrder("alphabetical"); err == nil {
+		t.Errorf("Unknown order parsed")
+	}
+}
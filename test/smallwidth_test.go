@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+)
+
+// TestJoinTree checks that a join tree is found exactly when DetKDecomp finds a decomposition of width 1
+func TestJoinTree(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		graph, _ := getRandomGraph(6)
+
+		joinTree, acyclic := algo.JoinTree(graph)
+		det := &algo.DetKDecomp{K: 1, Graph: graph, BalFactor: 2}
+		decomp := det.FindDecomp()
+
+		if acyclic != decomp.Correct(graph) {
+			t.Fatalf("Join tree and DetK disagree on graph %v: %v, %v", graph, acyclic, decomp)
+		}
+		if acyclic && (!joinTree.Correct(graph) || joinTree.CheckWidth() != 1) {
+			t.Errorf("Join tree not correct for graph %v: %v", graph, joinTree)
+		}
+	}
+}
+
+// TestWidth2 checks that the search for width 2 finds a decomposition exactly when DetKDecomp does, and that
+// SmallWidth reports which procedure answered
+func TestWidth2(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		graph, _ := getRandomGraph(8)
+
+		decomp, found := algo.Width2(context.Background(), graph)
+		det := &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}
+		if expected := det.FindDecomp().Found(); found != expected {
+			t.Fatalf("Width 2 search and DetK disagree on graph %v: %v, %v", graph, found, expected)
+		}
+		if found && (!decomp.Correct(graph) || decomp.CheckWidth() > 2) {
+			t.Errorf("Width 2 search not correct for graph %v: %v", graph, decomp)
+		}
+
+		small := &algo.SmallWidth{K: 2, Graph: graph, Fallback: &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}}
+		if answer := small.FindDecomp().Found(); answer != found || small.Used() == "" {
+			t.Errorf("SmallWidth answered %v using %q for graph %v", answer, small.Used(), graph)
+		}
+	}
+}
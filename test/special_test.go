@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestSpecialCondition checks that a GHD hiding a vertex used further below violates the special condition, and that
+// DetK without subedges only produces hypertree decompositions
+func TestSpecialCondition(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c).")
+	e1 := lib.NewEdges(graph.Edges.Slice()[:1])
+	e2 := lib.NewEdges(graph.Edges.Slice()[1:])
+
+	// the root hides a, which appears in the first child
+	ghd := lib.Decomp{Graph: graph, Root: lib.Node{Bag: e1.Vertices()[1:], Cover: e1, Children: []lib.Node{
+		{Bag: e1.Vertices(), Cover: e1},
+		{Bag: e2.Vertices(), Cover: e2},
+	}}}
+	if !ghd.Correct(graph) {
+		t.Fatalf("GHD not correct: %v", ghd)
+	}
+	if ghd.SpecialCondition() {
+		t.Errorf("Special condition not violated by %v", ghd)
+	}
+
+	for i := 0; i < 50; i++ {
+		graph, _ := getRandomGraph(12)
+		for k := 1; k <= 3; k++ {
+			det := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+			decomp := det.FindDecomp()
+			if decomp.Correct(graph) && !decomp.SpecialCondition() {
+				t.Errorf("DetK produced no hypertree decomposition: %v", decomp)
+			}
+		}
+	}
+}
+
+// TestSpecialEdge checks that special edges are equal exactly if they have the same vertices, that a graph recognises
+// separators with the vertices of one of its special edges, and that special edges keep their identity when the graph
+// is serialised
+func TestSpecialEdge(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(a,c),\ne4(c,d).")
+	edges := graph.Edges.Slice()
+	e12 := lib.NewEdges([]lib.Edge{edges[0], edges[1]})
+	e13 := lib.NewEdges([]lib.Edge{edges[0], edges[2]})
+	e34 := lib.NewEdges([]lib.Edge{edges[2], edges[3]})
+
+	sp12, sp13, sp34 := lib.NewSpecialEdge(e12), lib.NewSpecialEdge(e13), lib.NewSpecialEdge(e34)
+	if !sp12.Equal(sp13) {
+		t.Errorf("%v and %v have the same vertices, but are not equal", sp12, sp13)
+	}
+	if sp12.Equal(sp34) {
+		t.Errorf("%v and %v have different vertices, but are equal", sp12, sp34)
+	}
+	if sp12.ID == sp13.ID {
+		t.Errorf("%v and %v share the identity %v", sp12, sp13, sp12.ID)
+	}
+
+	H := lib.Graph{Edges: lib.NewEdges(edges[3:])}
+	H.AddSpecial(sp12)
+	other := H
+	other.AddSpecial(sp34)
+	if H.NumSpecial() != 1 || other.NumSpecial() != 2 {
+		t.Errorf("adding a special edge to a copy changed the original: %v", H)
+	}
+	if !H.IsSpecial(e13) || H.IsSpecial(e34) || !other.IsSpecial(e34) {
+		t.Errorf("separators with the vertices of special edges not recognised in %v and %v", H, other)
+	}
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(other); err != nil {
+		t.Fatal(err)
+	}
+	var decoded lib.Graph
+	if err := gob.NewDecoder(&buffer).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	for i := range other.Special {
+		if decoded.Special[i].ID != other.Special[i].ID || !decoded.Special[i].Equal(other.Special[i]) ||
+			decoded.Special[i].Hash() != other.Special[i].Hash() {
+			t.Errorf("special edge %v not restored, got %v", other.Special[i], decoded.Special[i])
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestBalSepSplit checks that the decompositions found without backtracking are correct and of width at most K, and
+// that none are found at widths at which BalSepLocal finds none either
+func TestBalSepSplit(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(10)
+
+		for k := 1; k <= 3; k++ {
+			split := &algo.BalSepSplit{K: k, Graph: graph, BalFactor: 2}
+			split.SetGenerator(lib.ParallelSearchGen{})
+			decomp := split.FindDecomp()
+			if !decomp.Found() {
+				continue
+			}
+			decomp.Graph = graph
+			if !decomp.Correct(graph) || decomp.Width() > k {
+				t.Fatalf("BalSepSplit produced an incorrect decomposition of %v at width %v: %v", graph, k, decomp)
+			}
+
+			local := &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2}
+			local.SetGenerator(lib.ParallelSearchGen{})
+			if !local.FindDecomp().Found() {
+				t.Errorf("BalSepSplit found a decomposition of %v at width %v, BalSepLocal none", graph, k)
+			}
+		}
+	}
+
+	// a path can be split by single edges all the way down
+	graph, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e),\ne5(e,f),\ne6(f,g).")
+	split := &algo.BalSepSplit{K: 1, Graph: graph, BalFactor: 2}
+	split.SetGenerator(lib.ParallelSearchGen{})
+	if decomp := split.FindDecomp(); !decomp.Correct(graph) {
+		t.Errorf("no decomposition of width 1 found for the path %v", graph)
+	}
+}
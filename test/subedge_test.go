@@ -3,8 +3,12 @@ package tests
 // some basic unit tests for the subedge package
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
+	"time"
 
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
 	"github.com/cem-okulmus/BalancedGo/lib"
 )
 
@@ -31,3 +35,60 @@ func TestSubedge(t *testing.T) {
 		t.Errorf("No subedges produced")
 	}
 }
+
+// vertexSets returns the distinct non-empty sets of vertices of the edges, with their sizes
+func vertexSets(edges lib.Edges) map[string]int {
+	output := make(map[string]int)
+	for _, e := range edges.Slice() {
+		if len(e.Vertices) == 0 {
+			continue
+		}
+		vertices := lib.RemoveDuplicates(append([]int{}, e.Vertices...))
+		output[fmt.Sprint(vertices)] = len(vertices)
+	}
+	return output
+}
+
+// TestAddSubEdges checks that without a limit the same subedges are added as by ComputeSubEdges, that a limit keeps
+// the largest of them, and that det finds a decomposition on the augmented graph whenever there is one
+func TestAddSubEdges(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 10; i++ {
+		graph := getDenseGraph(r, 8, 6+i%4)
+		k := 2 + i%2
+
+		all, complete := graph.AddSubEdges(k, -1)
+		want := vertexSets(graph.ComputeSubEdges(k).Edges)
+		if got := vertexSets(all.Edges); !complete || fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("Added subedges %v, expected %v", got, want)
+		}
+		added := all.Edges.Len() - graph.Edges.Len()
+
+		limit := r.Intn(added + 1)
+		bounded, complete := graph.AddSubEdges(k, limit)
+		if bounded.Edges.Len()-graph.Edges.Len() != limit || complete != (limit == added) {
+			t.Errorf("Added %v subedges with limit %v out of %v, complete: %v", bounded.Edges.Len()-
+				graph.Edges.Len(), limit, added, complete)
+		}
+		kept := vertexSets(lib.NewEdges(bounded.Edges.Slice()[graph.Edges.Len():]))
+		smallest := len(graph.Vertices())
+		for _, size := range kept {
+			if size < smallest {
+				smallest = size
+			}
+		}
+		for set, size := range vertexSets(lib.NewEdges(all.Edges.Slice()[graph.Edges.Len():])) {
+			if _, ok := kept[set]; !ok && size > smallest {
+				t.Errorf("Subedge %v left out, but one of size %v added", set, smallest)
+			}
+		}
+
+		det := &algo.DetKDecomp{K: k, Graph: all, BalFactor: 2}
+		decomp := det.FindDecomp()
+		decomp.Graph = graph
+		if ghw, _ := lib.BruteForceWidth(graph); ghw <= k && !decomp.Correct(graph) {
+			t.Errorf("Det with subedges found no decomposition of width %v for %v of width %v", k, graph, ghw)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestSearchTrace checks that tracing doesn't affect the decompositions, that the subgraph searched first is
+// marked as accepted or rejected depending on the outcome, and that the limit on the number of nodes is kept
+func TestSearchTrace(t *testing.T) {
+	cycle, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e),\ne5(e,f),\ne6(f,g),\ne7(g,h),\ne8(h,a).")
+
+	for _, k := range []int{1, 2} {
+		solvers := []algo.Algorithm{
+			&algo.DetKDecomp{K: k, Graph: cycle, BalFactor: 2, Trace: &lib.SearchTrace{}},
+			&algo.BalSepLocal{K: k, Graph: cycle, BalFactor: 2, Trace: &lib.SearchTrace{}},
+			&algo.BalSepGlobal{K: k, Graph: cycle.ComputeSubEdges(k), BalFactor: 2, Trace: &lib.SearchTrace{}},
+		}
+
+		for _, solver := range solvers {
+			solver.SetGenerator(lib.ParallelSearchGen{})
+			decomp := solver.FindDecomp()
+			decomp.Graph = cycle
+			found := decomp.Correct(cycle)
+			if found != (k == 2) {
+				t.Errorf("%v with trace: found decomposition %v at width %v", solver.Name(), decomp, k)
+			}
+
+			var trace *lib.SearchTrace
+			switch s := solver.(type) {
+			case *algo.DetKDecomp:
+				trace = s.Trace
+			case *algo.BalSepLocal:
+				trace = s.Trace
+			case *algo.BalSepGlobal:
+				trace = s.Trace
+			}
+
+			if trace.Len() == 0 {
+				t.Errorf("%v recorded no separators", solver.Name())
+				continue
+			}
+			want := lib.TraceRejected
+			if found {
+				want = lib.TraceAccepted
+			}
+			for _, n := range trace.Nodes {
+				if n.Kind != "subgraph" {
+					continue
+				}
+				if n.Status != want { // the first subgraph recorded is the whole graph
+					t.Errorf("%v: graph marked as %v instead of %v in trace", solver.Name(), n.Status, want)
+				}
+				break
+			}
+
+			out, err := trace.ToJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var parsed struct {
+				Nodes []lib.TraceNode
+				Links []lib.TraceLink
+			}
+			if err := json.Unmarshal(out, &parsed); err != nil || len(parsed.Nodes) != trace.Len() {
+				t.Errorf("%v: JSON of trace not readable: %v", solver.Name(), err)
+			}
+			if dot := trace.ToDOT(); strings.Count(dot, "->") != len(trace.Links) {
+				t.Errorf("%v: DOT of trace doesn't contain all links", solver.Name())
+			}
+		}
+	}
+
+	limited := &algo.DetKDecomp{K: 1, Graph: cycle, BalFactor: 2, Trace: &lib.SearchTrace{Limit: 5}}
+	limited.FindDecomp()
+	if limited.Trace.Len() != 5 || !limited.Trace.Truncated {
+		t.Errorf("Trace with limit 5 has %v nodes, truncated: %v", limited.Trace.Len(), limited.Trace.Truncated)
+	}
+}
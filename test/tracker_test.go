@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/disjoint"
+)
+
+// TestComponentTracker compares the components maintained by a tracker over random sequences of separators,
+// sharing prefixes as consecutive candidates of a search do, with those computed from scratch by GetComponents,
+// on graphs with and without special edges, and with the buffers of the tracker reused or not
+func TestComponentTracker(t *testing.T) {
+	defer lib.SetPooling(true)
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for x := 0; x < 50; x++ {
+		lib.SetPooling(x%4 < 2)
+		graph, _ := getRandomGraph(15)
+		if x%2 == 1 {
+			graph.Special = []lib.SpecialEdge{lib.NewSpecialEdge(getRandomSep(graph, 2)),
+				lib.NewSpecialEdge(getRandomSep(graph, 3))}
+		}
+		tracker := lib.NewComponentTracker(&graph, &graph.Edges)
+
+		var combination []int
+		for step := 0; step < 30; step++ {
+			// keep a random prefix, and extend it by up to three edges
+			combination = combination[:r.Intn(len(combination)+1)]
+			for i := r.Intn(4); i > 0; i-- {
+				combination = append(combination, r.Intn(graph.Edges.Len()))
+			}
+			tracker.Set(combination)
+
+			sep := lib.GetSubset(graph.Edges, combination)
+			comps, _, _ := graph.GetComponents(sep, make(map[int]*disjoint.Element))
+			var want []int
+			for _, c := range comps {
+				want = append(want, c.Len())
+			}
+			got := tracker.Sizes()
+			sort.Ints(want)
+			sort.Ints(got)
+			if len(want) != len(got) || (len(want) > 0 && !reflect.DeepEqual(want, got)) {
+				t.Errorf("Component sizes of %v w.r.t. %v: %v, expected %v", graph, sep, got, want)
+			}
+
+			for _, balFactor := range []int{2, 3} {
+				if len(graph.Special) == 0 && tracker.Balanced(balFactor) != (lib.BalancedCheck{}).Check(&graph,
+					&sep, balFactor, make(map[int]*disjoint.Element)) {
+					t.Errorf("Balancedness of %v w.r.t. %v differs from BalancedCheck", graph, sep)
+				}
+			}
+		}
+	}
+}
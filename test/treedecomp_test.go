@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestTreeDecomp checks the treewidth found for small graphs whose treewidth is known, and that on random graphs
+// tree decompositions are found no wider than those given by the greedy elimination, and none narrower than the
+// largest edge allows
+func TestTreeDecomp(t *testing.T) {
+	known := []struct {
+		graph string
+		tw    int
+	}{
+		{"a(x,y),b(y,z),c(z,w).", 1},
+		{"a(x,y),b(y,z),c(z,w),d(w,x).", 2},
+		{"a(x,y,z,w),b(w,v).", 3},
+		{"a(x1,x2),b(x2,x3),c(x4,x5),d(x5,x6),e(x7,x8),f(x8,x9),g(x1,x4),h(x4,x7),i(x2,x5),j(x5,x8),k(x3,x6)," +
+			"l(x6,x9).", 3},
+		{"a(x),b(y).", 0},
+	}
+
+	for _, c := range known {
+		graph, _ := lib.GetGraph(c.graph)
+		td := &algo.TreeDecomp{K: c.tw, Graph: graph, BalFactor: 2}
+		decomp := td.FindDecomp()
+		if !decomp.CorrectTD(graph) || decomp.TreeWidth() > c.tw {
+			t.Errorf("No tree decomposition of width %v found for %v: %v", c.tw, graph, decomp)
+		}
+		if c.tw > 0 {
+			td.SetWidth(c.tw - 1)
+			if found := td.FindDecomp(); found.Found() {
+				t.Errorf("Tree decomposition of width %v found for %v, expected %v", c.tw-1, graph, c.tw)
+			}
+		}
+
+		var header string
+		bags, treeEdges := 0, 0
+		for _, line := range strings.Split(strings.TrimSpace(decomp.ToTD()), "\n") {
+			switch {
+			case strings.HasPrefix(line, "s td "):
+				header = line
+			case strings.HasPrefix(line, "b "):
+				bags++
+			case !strings.HasPrefix(line, "c "):
+				treeEdges++
+			}
+		}
+		if header != fmt.Sprintf("s td %v %v %v", bags, decomp.TreeWidth()+1, len(graph.Vertices())) ||
+			treeEdges != bags-1 {
+			t.Errorf("Malformed td output for %v:\n%v", graph, decomp.ToTD())
+		}
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 20; i++ {
+		graph := getDenseGraph(r, 10, 8+i%8)
+
+		upper := algo.GreedyDecomp{Graph: graph}.Decompose(graph).TreeWidth()
+		lower := 0
+		for _, e := range graph.Edges.Slice() {
+			if len(lib.RemoveDuplicates(append([]int{}, e.Vertices...)))-1 > lower {
+				lower = len(lib.RemoveDuplicates(append([]int{}, e.Vertices...))) - 1
+			}
+		}
+
+		td := &algo.TreeDecomp{K: upper, Graph: graph, BalFactor: 2}
+		td.SetGenerator(lib.ParallelSearchGen{})
+		decomp := td.FindDecomp()
+		if !decomp.CorrectTD(graph) || decomp.TreeWidth() > upper {
+			t.Errorf("No tree decomposition of width %v found for %v, as given by greedy elimination", upper, graph)
+		}
+		td.SetWidth(lower - 1)
+		if found := td.FindDecomp(); lower > 0 && found.Found() {
+			t.Errorf("Tree decomposition of width %v found for %v, with an edge of %v vertices", lower-1, graph,
+				lower+1)
+		}
+	}
+}
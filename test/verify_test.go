@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestVerify checks that each condition of a GHD is reported on its own, when breaking a correct decomposition
+func TestVerify(t *testing.T) {
+	cycle, _ := lib.GetGraph("e1(a,b),\ne2(b,c),\ne3(c,d),\ne4(d,e),\ne5(e,a).")
+	det := &algo.DetKDecomp{K: 2, Graph: cycle, BalFactor: 2}
+	decomp := det.FindDecomp()
+
+	verdict := decomp.Verify(cycle)
+	if !verdict.Valid() || len(verdict.Problems) > 0 || verdict.Width != 2 {
+		t.Fatalf("Correct decomposition not verified: %+v", verdict)
+	}
+
+	// a single node whose bag leaves out a vertex, covered by too few edges
+	half := lib.NewEdges(cycle.Edges.Slice()[:2])
+	broken := lib.Decomp{Graph: cycle, Root: lib.Node{Bag: cycle.Vertices(), Cover: half}}
+	verdict = broken.Verify(cycle)
+	if verdict.Valid() || verdict.BagsInCovers || !verdict.EdgesCovered || !verdict.Connected {
+		t.Errorf("Bag not subset of cover not reported alone: %+v", verdict)
+	}
+
+	// a path of three nodes, where a appears at both ends but not in the middle
+	e := cycle.Edges.Slice()
+	first := lib.NewEdges(e[:1])
+	second := lib.NewEdges(e[1:3])
+	third := lib.NewEdges(e[3:])
+	disconnected := lib.Decomp{Graph: cycle, Root: lib.Node{Bag: first.Vertices(), Cover: first,
+		Children: []lib.Node{{Bag: second.Vertices(), Cover: second,
+			Children: []lib.Node{{Bag: third.Vertices(), Cover: third}}}}}}
+	verdict = disconnected.Verify(cycle)
+	if verdict.Valid() || !verdict.BagsInCovers || !verdict.EdgesCovered || verdict.Connected {
+		t.Errorf("Disconnected vertex not reported alone: %+v", verdict)
+	}
+
+	if verdict := (lib.Decomp{}).Verify(cycle); verdict.SameGraph || verdict.Valid() {
+		t.Errorf("Empty decomposition verified: %+v", verdict)
+	}
+}
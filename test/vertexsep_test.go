@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestVertexSep checks that decompositions found with vertex separators are correct, and that one is found whenever
+// DetKDecomp finds a decomposition of the same width
+func TestVertexSep(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(5)
+		k := 2
+
+		vertex := &algo.BalSepVertex{K: k, Graph: graph, BalFactor: 2}
+		vertex.SetGenerator(lib.ParallelSearchGen{})
+		decomp := vertex.FindDecomp()
+
+		if decomp.Found() && (!decomp.Correct(graph) || decomp.CheckWidth() > k) {
+			t.Errorf("Incorrect decomposition for graph %v: %v", graph, decomp)
+		}
+
+		det := &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}
+		if det.FindDecomp().Correct(graph) && !decomp.Found() {
+			t.Errorf("No decomposition of width %v found for graph %v", k, graph)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func randomVertices(r *rand.Rand, n, max int) []int {
+	var output []int
+	for i := 0; i < n; i++ {
+		output = append(output, r.Intn(max)+1)
+	}
+	return lib.RemoveDuplicates(output)
+}
+
+// sorted returns a sorted copy, so that slices can be compared as sets
+func sorted(vertices []int) []int {
+	output := append([]int{}, vertices...)
+	sort.Ints(output)
+	return output
+}
+
+// TestVertexSet compares the operations on vertex sets with those on slices, for both small and large slices
+func TestVertexSet(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < 100; i++ {
+		max := r.Intn(300) + 1
+		as := randomVertices(r, r.Intn(30), max)
+		bs := randomVertices(r, r.Intn(30), max)
+		a, b := lib.NewVertexSet(as), lib.NewVertexSet(bs)
+
+		if !reflect.DeepEqual(a.Slice(), sorted(as)) || a.Len() != len(as) {
+			t.Errorf("Set of %v contains %v", as, a.Slice())
+		}
+		if got, want := a.Inter(b).Slice(), sorted(lib.Inter(as, bs)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Intersection of %v and %v: %v, expected %v", as, bs, got, want)
+		}
+		if got, want := a.Diff(b).Slice(), sorted(lib.Diff(as, bs)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Difference of %v and %v: %v, expected %v", as, bs, got, want)
+		}
+		if got, want := a.Union(b).Slice(), lib.RemoveDuplicates(append(sorted(as), bs...)); !reflect.DeepEqual(got,
+			want) {
+			t.Errorf("Union of %v and %v: %v, expected %v", as, bs, got, want)
+		}
+		if a.Subset(b) != lib.Subset(as, bs) {
+			t.Errorf("Subset of %v and %v: %v", as, bs, a.Subset(b))
+		}
+		if a.Intersects(b) != (len(lib.Inter(as, bs)) > 0) {
+			t.Errorf("Intersects of %v and %v: %v", as, bs, a.Intersects(b))
+		}
+		if !a.Inter(b).Subset(a) || !a.Subset(a.Union(b)) || !a.Union(b).Equal(b.Union(a)) {
+			t.Errorf("Set laws don't hold for %v and %v", as, bs)
+		}
+
+		for _, v := range as {
+			a.Remove(v)
+		}
+		if a.Len() != 0 {
+			t.Errorf("Vertices left after removing all of %v: %v", as, a.Slice())
+		}
+	}
+}
+
+// BenchmarkInter measures the intersection of vertex slices, for dense instances where a VertexSet is used
+func BenchmarkInter(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	as := randomVertices(r, 200, 1000)
+	bs := randomVertices(r, 200, 1000)
+
+	for i := 0; i < b.N; i++ {
+		lib.Inter(as, bs)
+	}
+}
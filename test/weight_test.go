@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestWeights checks reading weights, and that minimizing the weight only uses the heavy edge where it's needed
+func TestWeights(t *testing.T) {
+	graph, _ := lib.GetGraph("A(x,y,z),\nB(z,w),\nC(w,x),\nD(x,z).")
+
+	if _, err := lib.ReadWeights(strings.NewReader("A,-1\n")); err == nil {
+		t.Errorf("Negative weight accepted")
+	}
+	weights, err := lib.ReadWeights(strings.NewReader("A,10\nB,1\nC,1\nD,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := graph.SetWeights(map[string]float64{"E": 1}); err == nil {
+		t.Errorf("Weight of unknown edge accepted")
+	}
+	graph, err = graph.SetWeights(weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := graph.Edges.Weight(); w != 14 {
+		t.Errorf("Total weight %v, expected 14", w)
+	}
+
+	local := algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2, Generator: lib.ParallelSearchGen{}}
+	decomp := algo.MinimizeWeight(local)
+	decomp.Graph = graph
+	if !decomp.Correct(graph) || decomp.WeightedWidth() != 10 {
+		t.Errorf("Minimized decomposition %v of weighted width %v, expected 10", decomp, decomp.WeightedWidth())
+	}
+
+	// with unit weights, the weighted width is just the width
+	for i := 0; i < 10; i++ {
+		random, _ := getRandomGraph(6)
+		local := algo.BalSepLocal{K: 3, Graph: random, BalFactor: 2, Generator: lib.ParallelSearchGen{}}
+		decomp := algo.MinimizeWeight(local)
+		if decomp.Graph = random; decomp.Correct(random) && decomp.WeightedWidth() != float64(decomp.CheckWidth()) {
+			t.Errorf("Weighted width %v of %v with unit weights, but width %v", decomp.WeightedWidth(), decomp,
+				decomp.CheckWidth())
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// The trace subcommand converts a recursion trace, as recorded with the traceRecursion flag, into a tree of the calls
+// in the DOT format of Graphviz, or into folded stacks for flame graphs, e.g. by flamegraph.pl:
+//
+//	BalancedGo trace -in calls.bin [-dot calls.dot] [-folded calls.folded]
+//
+// Without an output, a summary of the calls is printed. The exit code is 0 on success, and 2 if the trace couldn't
+// be read or the outputs written.
+
+// trace runs the trace subcommand on the given arguments, and returns the exit code
+func trace(args []string) int {
+	flagSet := flag.NewFlagSet("trace", flag.ContinueOnError)
+	in := flagSet.String("in", "", "The recursion trace, as JSON or in the compact binary format")
+	dot := flagSet.String("dot", "", "Write the tree of calls into the given file, in the DOT format of Graphviz")
+	folded := flagSet.String("folded", "", "Write the calls into the given file as folded stacks, weighted by the "+
+		"time spent in each call itself in microseconds")
+
+	if err := flagSet.Parse(args); err != nil || *in == "" {
+		fmt.Fprintln(os.Stderr, "Usage: BalancedGo trace -in <trace> [-dot <file>] [-folded <file>]")
+		flagSet.SetOutput(os.Stderr)
+		flagSet.PrintDefaults()
+		return 2
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer f.Close()
+	recursion, err := lib.ReadRecursionTrace(f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't read the recursion trace:", err)
+		return 2
+	}
+
+	if *dot != "" {
+		if err := ioutil.WriteFile(*dot, []byte(recursion.ToDOT()), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	if *folded != "" {
+		if err := ioutil.WriteFile(*folded, []byte(recursion.ToFolded()), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	if *dot == "" && *folded == "" {
+		accepted, depth := 0, 0
+		depths := make([]int, len(recursion.Calls))
+		for i, c := range recursion.Calls {
+			if c.Outcome == lib.RecursionAccepted {
+				accepted++
+			}
+			if c.Parent > 0 {
+				depths[i] = depths[c.Parent-1] + 1
+			}
+			if depths[i] > depth {
+				depth = depths[i]
+			}
+		}
+		fmt.Printf("Calls: %d, accepted: %d, depth: %d, truncated: %v\n", len(recursion.Calls), accepted, depth,
+			recursion.Truncated)
+	}
+
+	return 0
+}
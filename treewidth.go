@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// treeDecomposition computes a tree decomposition of the primal graph of graph, of treewidth at most width, or of
+// the smallest treewidth if exact is set, trying widths in increasing order from the size of the largest edge on.
+// A correct result is written to tdPath in PACE .td format, unless tdPath is empty.
+func treeDecomposition(ctx context.Context, graph lib.Graph, width int, exact bool, balFactor int, tdPath string,
+	deterministic bool) {
+	td := &algo.TreeDecomp{K: width, Graph: graph, BalFactor: balFactor}
+	td.SetGenerator(lib.ParallelSearchGen{Ctx: ctx, Sequential: deterministic})
+
+	if exact {
+		width = 0
+		for _, e := range graph.Edges.Slice() {
+			if n := len(lib.RemoveDuplicates(append([]int{}, e.Vertices...))) - 1; n > width {
+				width = n // the vertices of an edge form a clique, which needs to be in one bag
+			}
+		}
+	}
+
+	start := time.Now()
+	var decomp lib.Decomp
+	for {
+		td.SetWidth(width)
+		decomp = td.FindDecomp()
+		if !exact || decomp.Found() || ctx.Err() != nil {
+			break
+		}
+		width++
+	}
+	msec := time.Since(start).Seconds() * 1000
+
+	if ctx.Err() != nil {
+		fmt.Println("Search timed out at width", width)
+		return
+	}
+	if !decomp.Found() {
+		fmt.Println("No tree decomposition of width", width, "found")
+		return
+	}
+	decomp.Graph = graph
+	decomp = decomp.AddIsolated()
+
+	fmt.Println("Used algorithm: " + td.Name() + " @" + Version)
+	fmt.Println("Result ( ran with K =", width, ")\n", decomp)
+	fmt.Printf("Time: %.5f ms\n", msec)
+	if exact {
+		fmt.Println("Exact treewidth: ", width)
+	}
+
+	fmt.Println("\nTreewidth: ", decomp.TreeWidth())
+	correct := decomp.CorrectTD(graph)
+	fmt.Println("Correct: ", correct)
+
+	if correct && tdPath != "" {
+		f, err := os.Create(tdPath)
+		check(err)
+		defer f.Close()
+		f.WriteString(decomp.ToTD())
+	}
+}